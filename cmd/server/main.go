@@ -23,6 +23,14 @@ func run() error {
 	}
 	defer application.Shutdown()
 
+	// Wait for dependencies, then fail fast on an unmigrated database
+	if err := application.WaitReady(ctx, application.Config.App.ReadyTimeout); err != nil {
+		return err
+	}
+	if err := application.SelfCheck(ctx); err != nil {
+		return err
+	}
+
 	// Start server (blocks until shutdown)
 	return application.Start(ctx)
 }
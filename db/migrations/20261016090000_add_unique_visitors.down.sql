@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS link_visitors;
+ALTER TABLE links DROP COLUMN IF EXISTS unique_access_count;
@@ -0,0 +1,12 @@
+ALTER TABLE links
+    ADD COLUMN unique_access_count BIGINT NOT NULL DEFAULT 0;
+
+-- One row per (link, daily visitor fingerprint). The fingerprint is a hash,
+-- so no raw IP or user agent is stored.
+CREATE TABLE link_visitors (
+    link_id       UUID NOT NULL REFERENCES links (id) ON DELETE CASCADE,
+    fingerprint   TEXT NOT NULL,
+    first_seen_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+    PRIMARY KEY (link_id, fingerprint)
+);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS links_deleted_at_idx;
+DROP INDEX IF EXISTS links_expires_at_idx;
+ALTER TABLE links
+    DROP COLUMN IF EXISTS deleted_at,
+    DROP COLUMN IF EXISTS expires_at;
@@ -0,0 +1,7 @@
+ALTER TABLE links
+    ADD COLUMN expires_at TIMESTAMPTZ,
+    ADD COLUMN deleted_at TIMESTAMPTZ;
+
+-- Partial indexes keep the purge sweeper's scans cheap.
+CREATE INDEX links_expires_at_idx ON links (expires_at) WHERE expires_at IS NOT NULL;
+CREATE INDEX links_deleted_at_idx ON links (deleted_at) WHERE deleted_at IS NOT NULL;
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS links_original_url_idx;
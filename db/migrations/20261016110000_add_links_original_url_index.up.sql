@@ -0,0 +1,2 @@
+-- Supports reverse lookup of slugs by destination URL.
+CREATE INDEX links_original_url_idx ON links (original_url);
@@ -0,0 +1,8 @@
+-- One row per resolution, for time series. Only written when click event
+-- recording is enabled.
+CREATE TABLE link_clicks (
+    link_id    UUID NOT NULL REFERENCES links (id) ON DELETE CASCADE,
+    clicked_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX link_clicks_link_id_clicked_at_idx ON link_clicks (link_id, clicked_at);
@@ -0,0 +1 @@
+ALTER TABLE link_clicks DROP COLUMN IF EXISTS request_id;
@@ -0,0 +1,4 @@
+-- Correlates click events with request logs. NULL when the request ID is
+-- not recorded.
+ALTER TABLE link_clicks
+    ADD COLUMN request_id TEXT;
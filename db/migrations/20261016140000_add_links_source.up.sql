@@ -0,0 +1,4 @@
+-- Where a link was created ("api", "dashboard", "import"). NULL for links
+-- created before tagging or without a source.
+ALTER TABLE links
+    ADD COLUMN source TEXT;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS links_owner_idx;
+ALTER TABLE links DROP COLUMN IF EXISTS owner;
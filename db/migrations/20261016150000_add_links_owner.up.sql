@@ -0,0 +1,6 @@
+-- The API key principal that created the link. NULL for anonymous links.
+ALTER TABLE links
+    ADD COLUMN owner TEXT;
+
+-- Backs the per-owner quota count.
+CREATE INDEX links_owner_idx ON links (owner) WHERE deleted_at IS NULL;
@@ -0,0 +1,12 @@
+-- Append-only record of link mutations for compliance. Rows are never
+-- updated or deleted by the application, and outlive the links they name.
+CREATE TABLE audit_log (
+    id         BIGSERIAL PRIMARY KEY,
+    op         TEXT NOT NULL,
+    slug       TEXT NOT NULL,
+    owner      TEXT,
+    request_id TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX audit_log_slug_created_at_idx ON audit_log (slug, created_at);
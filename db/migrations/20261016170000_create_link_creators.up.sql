@@ -0,0 +1,8 @@
+-- The client that created each link, for abuse investigation. Kept apart
+-- from links so it can be purged on its own, and only written when
+-- creator metadata recording is enabled.
+CREATE TABLE link_creators (
+    link_id            UUID PRIMARY KEY REFERENCES links (id) ON DELETE CASCADE,
+    created_ip         TEXT,
+    created_user_agent TEXT
+);
@@ -0,0 +1,4 @@
+-- Fails while links with slugs longer than 64 characters exist.
+ALTER TABLE links
+    DROP CONSTRAINT links_slug_length,
+    ADD CONSTRAINT links_slug_length CHECK (char_length(slug) BETWEEN 7 AND 64);
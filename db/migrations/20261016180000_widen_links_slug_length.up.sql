@@ -0,0 +1,5 @@
+-- Custom slugs may be configured longer than generated ones, up to 128
+-- characters (shortener.MaxCustomSlugLength).
+ALTER TABLE links
+    DROP CONSTRAINT links_slug_length,
+    ADD CONSTRAINT links_slug_length CHECK (char_length(slug) BETWEEN 7 AND 128);
@@ -0,0 +1,6 @@
+-- Per-link UTM defaults, appended to the destination at resolve time when
+-- it doesn't already carry the parameter. NULL means no default.
+ALTER TABLE links
+    ADD COLUMN utm_source TEXT,
+    ADD COLUMN utm_medium TEXT,
+    ADD COLUMN utm_campaign TEXT;
@@ -0,0 +1,5 @@
+-- Per-link redirect status, overriding the server default. NULL means the
+-- server default; 307 and 308 preserve the request method and body.
+ALTER TABLE links
+    ADD COLUMN redirect_status SMALLINT,
+    ADD CONSTRAINT links_redirect_status_valid CHECK (redirect_status IN (301, 302, 307, 308));
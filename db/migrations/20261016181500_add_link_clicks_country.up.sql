@@ -0,0 +1,5 @@
+-- Coarse client location for analytics: an ISO 3166-1 alpha-2 country
+-- code. NULL when no geolocation resolver is configured or the IP is
+-- unknown; the IP itself is never stored.
+ALTER TABLE link_clicks
+    ADD COLUMN country TEXT;
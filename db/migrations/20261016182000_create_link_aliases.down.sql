@@ -0,0 +1,4 @@
+DROP TRIGGER IF EXISTS links_reject_aliases ON links;
+DROP FUNCTION IF EXISTS links_reject_aliases();
+DROP TABLE IF EXISTS link_aliases;
+DROP FUNCTION IF EXISTS link_aliases_reject_slugs();
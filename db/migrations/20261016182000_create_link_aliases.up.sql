@@ -0,0 +1,45 @@
+-- Extra slugs that resolve to an existing link, so renamed links keep
+-- working. Slugs and aliases share one namespace: the triggers reject an
+-- alias equal to any link slug, and a slug equal to any alias, reporting
+-- the clash as a links_slug_unique violation like any other taken slug.
+CREATE TABLE link_aliases (
+    alias      TEXT PRIMARY KEY,
+    link_id    UUID NOT NULL REFERENCES links (id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+    CONSTRAINT link_aliases_alias_length CHECK (char_length(alias) BETWEEN 7 AND 128)
+);
+
+CREATE INDEX link_aliases_link_id_idx ON link_aliases (link_id);
+
+CREATE OR REPLACE FUNCTION link_aliases_reject_slugs()
+RETURNS trigger AS $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM links WHERE slug = NEW.alias) THEN
+        RAISE EXCEPTION 'alias "%" is already a link slug', NEW.alias
+            USING ERRCODE = 'unique_violation', CONSTRAINT = 'links_slug_unique';
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER link_aliases_reject_slugs
+BEFORE INSERT OR UPDATE OF alias ON link_aliases
+FOR EACH ROW
+EXECUTE FUNCTION link_aliases_reject_slugs();
+
+CREATE OR REPLACE FUNCTION links_reject_aliases()
+RETURNS trigger AS $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM link_aliases WHERE alias = NEW.slug) THEN
+        RAISE EXCEPTION 'slug "%" is already a link alias', NEW.slug
+            USING ERRCODE = 'unique_violation', CONSTRAINT = 'links_slug_unique';
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER links_reject_aliases
+BEFORE INSERT OR UPDATE OF slug ON links
+FOR EACH ROW
+EXECUTE FUNCTION links_reject_aliases();
@@ -0,0 +1,4 @@
+-- Paused links keep their slug, counts and history but don't resolve until
+-- resumed. Unlike soft delete, pausing is undone in place.
+ALTER TABLE links
+    ADD COLUMN paused BOOLEAN NOT NULL DEFAULT false;
@@ -0,0 +1,43 @@
+DROP TRIGGER links_reject_aliases ON links;
+DROP TRIGGER link_aliases_reject_slugs ON link_aliases;
+
+CREATE OR REPLACE FUNCTION link_aliases_reject_slugs()
+RETURNS trigger AS $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM links WHERE slug = NEW.alias) THEN
+        RAISE EXCEPTION 'alias "%" is already a link slug', NEW.alias
+            USING ERRCODE = 'unique_violation', CONSTRAINT = 'links_slug_unique';
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION links_reject_aliases()
+RETURNS trigger AS $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM link_aliases WHERE alias = NEW.slug) THEN
+        RAISE EXCEPTION 'slug "%" is already a link alias', NEW.slug
+            USING ERRCODE = 'unique_violation', CONSTRAINT = 'links_slug_unique';
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+ALTER TABLE link_aliases
+    DROP CONSTRAINT link_aliases_alias_key_unique,
+    DROP COLUMN alias_key;
+
+ALTER TABLE links
+    DROP CONSTRAINT links_slug_unique,
+    DROP COLUMN slug_key,
+    ADD CONSTRAINT links_slug_unique UNIQUE (slug);
+
+CREATE TRIGGER link_aliases_reject_slugs
+BEFORE INSERT OR UPDATE OF alias ON link_aliases
+FOR EACH ROW
+EXECUTE FUNCTION link_aliases_reject_slugs();
+
+CREATE TRIGGER links_reject_aliases
+BEFORE INSERT OR UPDATE OF slug ON links
+FOR EACH ROW
+EXECUTE FUNCTION links_reject_aliases();
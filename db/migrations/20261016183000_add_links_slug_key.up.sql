@@ -0,0 +1,59 @@
+-- Slugs are unique by, and looked up by, slug_key: the slug itself, or its
+-- lowercase form when the service runs with case-insensitive slugs, so a
+-- link keeps the case it was created with while "MyLink" and "mylink"
+-- collide. The constraint keeps its name, which conflicts are reported by.
+ALTER TABLE links
+    ADD COLUMN slug_key TEXT;
+
+UPDATE links SET slug_key = slug;
+
+ALTER TABLE links
+    ALTER COLUMN slug_key SET NOT NULL,
+    DROP CONSTRAINT links_slug_unique,
+    ADD CONSTRAINT links_slug_unique UNIQUE (slug_key);
+
+-- Aliases are keyed the same way, and the shared namespace is enforced on
+-- the keys, so an alias cannot differ from a slug only in case when slugs
+-- are case-insensitive.
+ALTER TABLE link_aliases
+    ADD COLUMN alias_key TEXT;
+
+UPDATE link_aliases SET alias_key = alias;
+
+ALTER TABLE link_aliases
+    ALTER COLUMN alias_key SET NOT NULL,
+    ADD CONSTRAINT link_aliases_alias_key_unique UNIQUE (alias_key);
+
+CREATE OR REPLACE FUNCTION link_aliases_reject_slugs()
+RETURNS trigger AS $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM links WHERE slug_key = NEW.alias_key) THEN
+        RAISE EXCEPTION 'alias "%" is already a link slug', NEW.alias
+            USING ERRCODE = 'unique_violation', CONSTRAINT = 'links_slug_unique';
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER link_aliases_reject_slugs ON link_aliases;
+CREATE TRIGGER link_aliases_reject_slugs
+BEFORE INSERT OR UPDATE OF alias_key ON link_aliases
+FOR EACH ROW
+EXECUTE FUNCTION link_aliases_reject_slugs();
+
+CREATE OR REPLACE FUNCTION links_reject_aliases()
+RETURNS trigger AS $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM link_aliases WHERE alias_key = NEW.slug_key) THEN
+        RAISE EXCEPTION 'slug "%" is already a link alias', NEW.slug
+            USING ERRCODE = 'unique_violation', CONSTRAINT = 'links_slug_unique';
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER links_reject_aliases ON links;
+CREATE TRIGGER links_reject_aliases
+BEFORE INSERT OR UPDATE OF slug_key ON links
+FOR EACH ROW
+EXECUTE FUNCTION links_reject_aliases();
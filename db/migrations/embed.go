@@ -0,0 +1,10 @@
+// Package migrations embeds the SQL migration files so the server can apply
+// them at startup without the source tree.
+package migrations
+
+import "embed"
+
+// FS holds every *.up.sql and *.down.sql file in this directory.
+//
+//go:embed *.sql
+var FS embed.FS
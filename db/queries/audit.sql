@@ -0,0 +1,9 @@
+-- name: InsertAuditEntry :exec
+INSERT INTO audit_log (op, slug, owner, request_id, created_at)
+VALUES (
+    sqlc.arg('op'),
+    sqlc.arg('slug'),
+    sqlc.narg('owner'),
+    sqlc.narg('request_id'),
+    sqlc.arg('created_at')
+);
@@ -0,0 +1,16 @@
+-- name: InsertLinkAlias :exec
+INSERT INTO link_aliases (alias, link_id, alias_key)
+VALUES (sqlc.arg('alias'), sqlc.arg('link_id'), sqlc.arg('alias_key'));
+
+-- name: GetSlugByAlias :one
+-- The slug of the link an alias points at, whether or not it is live.
+SELECT l.slug
+FROM link_aliases a
+JOIN links l ON l.id = a.link_id
+WHERE a.alias_key = sqlc.arg('alias_key');
+
+-- name: ListLinkAliases :many
+SELECT alias
+FROM link_aliases
+WHERE link_id = sqlc.arg('link_id')
+ORDER BY alias;
@@ -0,0 +1,12 @@
+-- name: InsertLinkCreator :exec
+INSERT INTO link_creators (link_id, created_ip, created_user_agent)
+VALUES (
+    sqlc.arg('link_id'),
+    sqlc.narg('created_ip'),
+    sqlc.narg('created_user_agent')
+);
+
+-- name: GetLinkCreator :one
+SELECT link_id, created_ip, created_user_agent
+FROM link_creators
+WHERE link_id = sqlc.arg('link_id');
@@ -2,18 +2,84 @@
 INSERT INTO links (
     id,
     original_url,
-    slug
+    slug,
+    source,
+    owner,
+    utm_source,
+    utm_medium,
+    utm_campaign,
+    redirect_status,
+    slug_key,
+    expires_at
+) VALUES (
+    $1, $2, $3, sqlc.narg('source'), sqlc.narg('owner'),
+    sqlc.narg('utm_source'), sqlc.narg('utm_medium'), sqlc.narg('utm_campaign'),
+    sqlc.narg('redirect_status'), sqlc.arg('slug_key'), sqlc.narg('expires_at')
+)
+RETURNING
+    id,
+    original_url,
+    slug,
+    access_count,
+    unique_access_count,
+    created_at,
+    updated_at,
+    last_accessed_at,
+    expires_at,
+    deleted_at,
+    source,
+    owner,
+    utm_source,
+    utm_medium,
+    utm_campaign,
+    redirect_status,
+    paused,
+    slug_key;
+
+-- name: CreateOrUpdateLink :one
+-- Inserts the link, or points the live link already using its slug key at
+-- the new URL. A slug held by a soft-deleted link updates nothing and returns
+-- no row. inserted is false when an existing link was updated.
+INSERT INTO links (
+    id,
+    original_url,
+    slug,
+    source,
+    owner,
+    utm_source,
+    utm_medium,
+    utm_campaign,
+    redirect_status,
+    slug_key,
+    expires_at
 ) VALUES (
-    $1, $2, $3
+    $1, $2, $3, sqlc.narg('source'), sqlc.narg('owner'),
+    sqlc.narg('utm_source'), sqlc.narg('utm_medium'), sqlc.narg('utm_campaign'),
+    sqlc.narg('redirect_status'), sqlc.arg('slug_key'), sqlc.narg('expires_at')
 )
+ON CONFLICT (slug_key) WHERE deleted_at IS NULL
+DO UPDATE SET original_url = EXCLUDED.original_url
+WHERE links.deleted_at IS NULL
 RETURNING
     id,
     original_url,
     slug,
     access_count,
+    unique_access_count,
     created_at,
     updated_at,
-    last_accessed_at;
+    last_accessed_at,
+    expires_at,
+    deleted_at,
+    source,
+    owner,
+    utm_source,
+    utm_medium,
+    utm_campaign,
+    redirect_status,
+    paused,
+    slug_key,
+    (xmax = 0)::boolean AS inserted;
 
 -- name: GetLinkBySLug :one
 SELECT
@@ -21,27 +87,274 @@ SELECT
     original_url,
     slug,
     access_count,
+    unique_access_count,
+    created_at,
+    updated_at,
+    last_accessed_at,
+    expires_at,
+    deleted_at,
+    source,
+    owner,
+    utm_source,
+    utm_medium,
+    utm_campaign,
+    redirect_status,
+    paused,
+    slug_key
+FROM links
+WHERE slug_key = $1
+  AND deleted_at IS NULL;
+
+-- name: GetLinkByID :one
+SELECT
+    id,
+    original_url,
+    slug,
+    access_count,
+    unique_access_count,
+    created_at,
+    updated_at,
+    last_accessed_at,
+    expires_at,
+    deleted_at,
+    source,
+    owner,
+    utm_source,
+    utm_medium,
+    utm_campaign,
+    redirect_status,
+    paused,
+    slug_key
+FROM links
+WHERE id = $1
+  AND deleted_at IS NULL;
+
+-- name: GetLinksByURL :many
+SELECT
+    id,
+    original_url,
+    slug,
+    access_count,
+    unique_access_count,
+    created_at,
+    updated_at,
+    last_accessed_at,
+    expires_at,
+    deleted_at,
+    source,
+    owner,
+    utm_source,
+    utm_medium,
+    utm_campaign,
+    redirect_status,
+    paused,
+    slug_key
+FROM links
+WHERE original_url = $1
+  AND deleted_at IS NULL
+ORDER BY created_at DESC, id DESC;
+
+-- name: GetTakenSlugs :many
+-- Includes soft-deleted links: their slug keys still hold the unique
+-- constraint. Aliases share the slug namespace, so they count as taken too.
+SELECT slug_key
+FROM links
+WHERE slug_key = ANY(sqlc.arg('slug_keys')::text[])
+UNION
+SELECT alias_key
+FROM link_aliases
+WHERE alias_key = ANY(sqlc.arg('slug_keys')::text[]);
+
+-- name: CountLinksByOwner :one
+SELECT count(*) FROM links
+WHERE owner = $1
+  AND deleted_at IS NULL;
+
+-- name: CountLinksBySource :many
+-- Links without a source are grouped under an empty string.
+SELECT
+    coalesce(source, '')::text AS source,
+    count(*) AS links
+FROM links
+WHERE deleted_at IS NULL
+GROUP BY 1
+ORDER BY 2 DESC, 1;
+
+-- name: CountLinksFiltered :one
+-- A NULL owner counts every owner; an unknown status counts every state.
+SELECT count(*) FROM links
+WHERE (sqlc.narg('owner')::text IS NULL OR owner = sqlc.narg('owner')::text)
+  AND CASE sqlc.arg('status')::text
+    WHEN 'active' THEN deleted_at IS NULL AND NOT paused
+      AND (expires_at IS NULL OR expires_at > sqlc.arg('now')::timestamptz)
+    WHEN 'paused' THEN deleted_at IS NULL AND paused
+    WHEN 'expired' THEN deleted_at IS NULL
+      AND expires_at <= sqlc.arg('now')::timestamptz
+    WHEN 'deleted' THEN deleted_at IS NOT NULL
+    ELSE true
+  END;
+
+-- name: ListLinks :many
+-- Keyset pagination, newest first. A NULL cursor starts from the top.
+SELECT
+    id,
+    original_url,
+    slug,
+    access_count,
+    unique_access_count,
     created_at,
     updated_at,
-    last_accessed_at
+    last_accessed_at,
+    expires_at,
+    deleted_at,
+    source,
+    owner,
+    utm_source,
+    utm_medium,
+    utm_campaign,
+    redirect_status,
+    paused,
+    slug_key
 FROM links
-WHERE slug = $1;
+WHERE deleted_at IS NULL
+  AND (sqlc.narg('cursor_created_at')::timestamptz IS NULL
+   OR (created_at, id) < (sqlc.narg('cursor_created_at')::timestamptz, sqlc.narg('cursor_id')::uuid))
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg('row_limit');
 
 -- name: ResolveAndTrackLink :one
 UPDATE links
 SET
-  access_count     = access_count + 1,
-  last_accessed_at = now()
-WHERE slug = $1
+  -- Saturate rather than fail with bigint out of range
+  access_count     = access_count + (access_count < 9223372036854775807)::int,
+  last_accessed_at = sqlc.arg('now')::timestamptz
+WHERE slug_key = sqlc.arg('slug_key')
+  AND deleted_at IS NULL
+  AND NOT paused
+  AND (expires_at IS NULL OR expires_at > sqlc.arg('expires_after')::timestamptz)
 RETURNING
   id,
   original_url,
   slug,
   access_count,
+  unique_access_count,
   created_at,
   updated_at,
-  last_accessed_at;
+  last_accessed_at,
+  expires_at,
+  deleted_at,
+  source,
+  owner,
+  utm_source,
+  utm_medium,
+  utm_campaign,
+  redirect_status,
+  paused,
+  slug_key;
+
+-- name: DeleteLink :one
+-- Soft delete: the row is hard-deleted later by PurgeDeletedLinks.
+UPDATE links
+SET deleted_at = now()
+WHERE slug_key = $1
+  AND deleted_at IS NULL
+RETURNING
+  id,
+  original_url,
+  slug,
+  access_count,
+  unique_access_count,
+  created_at,
+  updated_at,
+  last_accessed_at,
+  expires_at,
+  deleted_at,
+  source,
+  owner,
+  utm_source,
+  utm_medium,
+  utm_campaign,
+  redirect_status,
+  paused,
+  slug_key;
+
+-- name: SetLinkPaused :one
+-- Pausing keeps the link's counts and history; resuming undoes it in place.
+UPDATE links
+SET paused = sqlc.arg('paused')
+WHERE slug_key = sqlc.arg('slug_key')
+  AND deleted_at IS NULL
+RETURNING
+  id,
+  original_url,
+  slug,
+  access_count,
+  unique_access_count,
+  created_at,
+  updated_at,
+  last_accessed_at,
+  expires_at,
+  deleted_at,
+  source,
+  owner,
+  utm_source,
+  utm_medium,
+  utm_campaign,
+  redirect_status,
+  paused,
+  slug_key;
+
+-- name: CountLinks :one
+SELECT count(*) FROM links;
+
+-- name: TrackUniqueVisitor :execrows
+WITH first_visit AS (
+    INSERT INTO link_visitors (link_id, fingerprint)
+    VALUES ($1, $2)
+    ON CONFLICT DO NOTHING
+    RETURNING link_id
+)
+UPDATE links
+SET unique_access_count = unique_access_count + 1
+WHERE id IN (SELECT link_id FROM first_visit);
+
+-- name: RecordClick :exec
+INSERT INTO link_clicks (link_id, request_id, country)
+VALUES ($1, sqlc.narg('request_id'), sqlc.narg('country'));
+
+-- name: CountClicksByCountry :many
+-- Clicks without a country are grouped under an empty string.
+SELECT
+    coalesce(country, '')::text AS country,
+    count(*) AS clicks
+FROM link_clicks
+GROUP BY 1
+ORDER BY 2 DESC, 1;
+
+-- name: GetClickTimeSeries :many
+-- Only buckets with at least one click are returned.
+SELECT
+    date_trunc(sqlc.arg('bucket')::text, clicked_at, 'UTC')::timestamptz AS bucket_start,
+    count(*) AS clicks
+FROM link_clicks
+WHERE link_id = sqlc.arg('link_id')
+  AND clicked_at >= sqlc.arg('from_time')::timestamptz
+  AND clicked_at < sqlc.arg('to_time')::timestamptz
+GROUP BY bucket_start
+ORDER BY bucket_start;
+
+-- name: PurgeExpiredLinks :execrows
+DELETE FROM links
+WHERE id IN (
+    SELECT id FROM links
+    WHERE expires_at < sqlc.arg('expired_before')::timestamptz
+    LIMIT sqlc.arg('batch_size')
+);
 
--- name: DeleteLink :exec
+-- name: PurgeDeletedLinks :execrows
 DELETE FROM links
-WHERE slug = $1;
+WHERE id IN (
+    SELECT id FROM links
+    WHERE deleted_at < sqlc.arg('deleted_before')::timestamptz
+    LIMIT sqlc.arg('batch_size')
+);
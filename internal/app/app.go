@@ -3,6 +3,7 @@ package app
 import (
 	"context"
 	"fmt"
+	"io"
 	"log"
 	"log/slog"
 	"os"
@@ -10,22 +11,42 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/joho/godotenv"
 
+	"github.com/sundayezeilo/urlshortener/db/migrations"
+	"github.com/sundayezeilo/urlshortener/internal/bootstrap"
 	"github.com/sundayezeilo/urlshortener/internal/config"
 	db "github.com/sundayezeilo/urlshortener/internal/db/sqlc"
+	"github.com/sundayezeilo/urlshortener/internal/errx"
+	"github.com/sundayezeilo/urlshortener/internal/health"
+	"github.com/sundayezeilo/urlshortener/internal/httpx"
+	"github.com/sundayezeilo/urlshortener/internal/migrate"
 	"github.com/sundayezeilo/urlshortener/internal/server"
 	"github.com/sundayezeilo/urlshortener/internal/shortener"
+	"github.com/sundayezeilo/urlshortener/sluggen"
 )
 
 // App holds the application dependencies and configuration.
 type App struct {
-	Config  *config.Config
-	Logger  *slog.Logger
-	DBPool  *pgxpool.Pool
-	Server  *server.Server
-	Handler *shortener.Handler
+	Config      *config.Config
+	Logger      *slog.Logger
+	DBPool      *pgxpool.Pool
+	PoolMonitor *health.PoolMonitor
+	Purger      *shortener.Purger
+	Keyspace    *shortener.KeyspaceMonitor
+	Tracker     *shortener.ClickTracker
+	Server      *server.Server
+	Handler     *shortener.Handler
+
+	service shortener.Service // Slug generator checked by SelfCheck
+
+	database pinger                      // Waited for by WaitReady
+	migrate  func(context.Context) error // Run by WaitReady when AutoMigrate is on
+
+	checkSlugKeys        bool // Scan stored slug keys in SelfCheck
+	caseInsensitiveSlugs bool
 }
 
 // New initializes and returns a new App instance with all dependencies wired up.
+// It doesn't wait for the database; run WaitReady before serving.
 func New(ctx context.Context) (*App, error) {
 	if err := loadEnv(); err != nil {
 		return nil, fmt.Errorf("failed to load environment: %w", err)
@@ -36,31 +57,155 @@ func New(ctx context.Context) (*App, error) {
 		return nil, fmt.Errorf("failed to load config: %w", err)
 	}
 
-	logger := setupLogger(cfg.App.LogLevel)
+	svcCfg, err := serviceConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("invalid shortener config: %w", err)
+	}
+
+	errorCodes, err := httpx.ParseErrorCodes(cfg.Server.ErrorCodes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid server config: %w", err)
+	}
+	trustedProxies, err := httpx.ParseTrustedProxies(cfg.Server.TrustedProxies)
+	if err != nil {
+		return nil, fmt.Errorf("invalid server config: %w", err)
+	}
+
+	logger := bootstrap.NewLogger(cfg.App)
+
+	tracker, err := clickTracker(cfg, logger)
+	if err != nil {
+		return nil, fmt.Errorf("invalid shortener config: %w", err)
+	}
 
 	logger.Info("starting application",
 		"env", cfg.App.Environment,
 		"version", cfg.Observability.ServiceVersion,
 	)
 
-	// Connect to database
-	dbPool, err := connectDatabase(ctx, cfg, logger)
+	// Connect lazily: WaitReady waits for the database to come up and then
+	// migrates it
+	dbPool, err := bootstrap.ConnectDatabase(ctx, cfg, logger)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
 
+	var migrateUp func(context.Context) error
+	if cfg.Database.AutoMigrate {
+		migrateUp = func(ctx context.Context) error {
+			applied, err := migrate.Up(ctx, dbPool, migrations.FS, logger)
+			if err != nil {
+				return fmt.Errorf("failed to run migrations: %w", err)
+			}
+			logger.Info("database migrations complete", "applied", applied)
+			return nil
+		}
+	}
+
 	// Setup application dependencies
 	queries := db.New(dbPool)
-	repo := shortener.NewRepository(queries, nil)
-	svc := shortener.NewService(repo, nil)
+	if cfg.Shortener.AuditLogEnabled {
+		svcCfg.AuditLogger = shortener.NewDBAuditLogger(queries, logger)
+	}
+	repo := shortener.NewRepository(queries, &shortener.RepositoryConfig{
+		BreakerThreshold:     cfg.Database.BreakerThreshold,
+		BreakerCooldown:      cfg.Database.BreakerCooldown,
+		QueryTimeout:         cfg.Database.QueryTimeout,
+		CaseInsensitiveSlugs: cfg.Shortener.SlugCaseInsensitive,
+	})
+
+	svcCfg.Logger = logger
+
+	// Optional background click tracking
+	if tracker != nil {
+		tracker.Start()
+		svcCfg.ClickTracker = tracker
+	}
+
+	svc := shortener.NewService(repo, svcCfg)
+
+	redirectCache := make(map[int]string, len(cfg.Server.RedirectCacheMaxAge))
+	for status, maxAge := range cfg.Server.RedirectCacheMaxAge {
+		redirectCache[status] = shortener.CacheControlMaxAge(maxAge)
+	}
+
+	var errorCounts *errx.Counts
+	if cfg.Server.ErrorCounters {
+		errorCounts = &errx.Counts{}
+	}
+
 	handler := shortener.NewHandler(shortener.HandlerConfig{
 		Service: svc,
 		Logger:  logger,
 		BaseURL: cfg.Server.BaseURL,
+
+		OversizedSlugStatus: cfg.Server.OversizedSlugStatus,
+		NotFoundRedirectURL: cfg.Server.NotFoundRedirectURL,
+		RootRedirectURL:     cfg.Server.RootRedirectURL,
+		IgnoredPaths:        cfg.Server.IgnoredPaths,
+		RedactParams:        cfg.Server.LogRedactParams,
+		Beacons:             cfg.Server.ResolveBeacons,
+		ForwardQueryParams:  cfg.Server.ForwardQueryParams,
+		ShortLinkHeader:     cfg.Server.ShortLinkHeader,
+		OriginalURIHeader:   cfg.Server.OriginalURIHeader,
+
+		RedirectStatus:       cfg.Server.RedirectStatus,
+		RedirectCacheControl: redirectCache,
+
+		MaxBatchSize: cfg.Shortener.BatchMaxItems,
+		ErrorCodes:   errorCodes,
+		ErrorCounts:  errorCounts,
+	})
+
+	serverOpts := []server.Option{server.WithTrustedProxies(trustedProxies)}
+	if errorCounts != nil {
+		serverOpts = append(serverOpts, server.WithErrorCounts(errorCounts))
+	}
+	if tracker != nil {
+		serverOpts = append(serverOpts, server.WithClickTracker(tracker))
+	}
+	if cache, ok := svcCfg.NotFoundCache.(*shortener.MemoryCache); ok {
+		serverOpts = append(serverOpts, server.WithCacheStats("not_found", cache))
+	}
+
+	// Optional background pool health monitor
+	var poolMonitor *health.PoolMonitor
+	if cfg.Database.HealthCheckEnabled {
+		poolMonitor = health.NewPoolMonitor(health.PoolMonitorConfig{
+			Pool:     health.PgxPool(dbPool),
+			Interval: cfg.Database.HealthCheckInterval,
+			Logger:   logger,
+		})
+		serverOpts = append(serverOpts, server.WithPoolMonitor(poolMonitor))
+	}
+
+	// Optional background purge of expired and soft-deleted links
+	var purger *shortener.Purger
+	if cfg.Shortener.PurgeEnabled {
+		purger = shortener.NewPurger(shortener.PurgerConfig{
+			Repo:             repo,
+			Interval:         cfg.Shortener.PurgeInterval,
+			ExpiredGrace:     cfg.Shortener.PurgeExpiredGrace,
+			DeletedRetention: cfg.Shortener.PurgeDeletedRetention,
+			BatchSize:        cfg.Shortener.PurgeBatchSize,
+			Logger:           logger,
+		})
+	}
+
+	// Warn at startup and periodically when generated slugs start colliding
+	keyspace := shortener.NewKeyspaceMonitor(shortener.KeyspaceMonitorConfig{
+		Repo:                 repo,
+		Interval:             cfg.Shortener.KeyspaceCheckInterval,
+		Logger:               logger,
+		Alphabet:             svcCfg.SlugAlphabet,
+		SlugLength:           svcCfg.SlugLength,
+		SlugLengthThresholds: svcCfg.SlugLengthThresholds,
+		WarnFraction:         cfg.Shortener.KeyspaceWarnFraction,
 	})
+	serverOpts = append(serverOpts, server.WithKeyspaceMonitor(keyspace))
 
 	// Create server
-	srv := server.New(cfg, logger, handler)
+	srv := server.New(cfg, logger, handler, serverOpts...)
 
 	logger.Info("application initialized",
 		"port", cfg.Server.Port,
@@ -68,16 +213,30 @@ func New(ctx context.Context) (*App, error) {
 	)
 
 	return &App{
-		Config:  cfg,
-		Logger:  logger,
-		DBPool:  dbPool,
-		Server:  srv,
-		Handler: handler,
+		Config:      cfg,
+		Logger:      logger,
+		DBPool:      dbPool,
+		PoolMonitor: poolMonitor,
+		Purger:      purger,
+		Keyspace:    keyspace,
+		Tracker:     tracker,
+		Server:      srv,
+		Handler:     handler,
+
+		service:  svc,
+		database: dbPool,
+		migrate:  migrateUp,
+
+		checkSlugKeys:        cfg.App.SelfCheckSlugKeys,
+		caseInsensitiveSlugs: cfg.Shortener.SlugCaseInsensitive,
 	}, nil
 }
 
-// Start starts the application server.
+// Start starts the background workers and then the application server.
+// Call WaitReady first: the workers query the database as they start.
 func (a *App) Start(ctx context.Context) error {
+	a.startWorkers()
+
 	a.Logger.Info("server starting",
 		"port", a.Config.Server.Port,
 		"base_url", a.Config.Server.BaseURL,
@@ -90,10 +249,54 @@ func (a *App) Start(ctx context.Context) error {
 	return nil
 }
 
+// startWorkers starts the background workers that need the database.
+func (a *App) startWorkers() {
+	if a.PoolMonitor != nil {
+		a.PoolMonitor.Start(context.Background())
+		a.Logger.Info("database health monitor started",
+			"interval", a.Config.Database.HealthCheckInterval.String(),
+		)
+	}
+
+	if a.Purger != nil {
+		a.Purger.Start(context.Background())
+		a.Logger.Info("link purger started",
+			"interval", a.Config.Shortener.PurgeInterval.String(),
+			"expired_grace", a.Config.Shortener.PurgeExpiredGrace.String(),
+			"deleted_retention", a.Config.Shortener.PurgeDeletedRetention.String(),
+		)
+	}
+
+	if a.Keyspace != nil {
+		a.Keyspace.Start(context.Background())
+	}
+}
+
 // Shutdown gracefully shuts down the application.
 func (a *App) Shutdown() error {
 	a.Logger.Info("shutting down application")
 
+	if a.Purger != nil {
+		a.Purger.Stop()
+		a.Logger.Info("link purger stopped")
+	}
+
+	if a.Keyspace != nil {
+		a.Keyspace.Stop()
+		a.Logger.Info("keyspace monitor stopped")
+	}
+
+	// Flush buffered clicks while the database is still reachable
+	if a.Tracker != nil {
+		a.Tracker.Stop()
+		a.Logger.Info("click tracker stopped")
+	}
+
+	if a.PoolMonitor != nil {
+		a.PoolMonitor.Stop()
+		a.Logger.Info("database health monitor stopped")
+	}
+
 	if a.DBPool != nil {
 		a.DBPool.Close()
 		a.Logger.Info("database connection closed")
@@ -102,70 +305,143 @@ func (a *App) Shutdown() error {
 	return nil
 }
 
-// loadEnv loads .env file only in non-production environments.
-func loadEnv() error {
-	env := os.Getenv("APP_ENV")
-	if env == "development" || env == "test" {
-		if err := godotenv.Load("../.env"); err != nil {
-			log.Println("no .env file found.")
-		}
+// serviceConfig builds the shortener service configuration from cfg.
+func serviceConfig(cfg *config.Config) (*shortener.ServiceConfig, error) {
+	thresholds := make([]shortener.SlugLengthThreshold, 0, len(cfg.Shortener.SlugLengthThresholds))
+	for minLinks, length := range cfg.Shortener.SlugLengthThresholds {
+		thresholds = append(thresholds, shortener.SlugLengthThreshold{
+			MinLinks: minLinks,
+			Length:   length,
+		})
 	}
-	return nil
-}
 
-// setupLogger creates a structured logger based on the log level.
-func setupLogger(level string) *slog.Logger {
-	var logLevel slog.Level
-	switch level {
-	case "debug":
-		logLevel = slog.LevelDebug
-	case "info":
-		logLevel = slog.LevelInfo
-	case "warn":
-		logLevel = slog.LevelWarn
-	case "error":
-		logLevel = slog.LevelError
-	default:
-		logLevel = slog.LevelInfo
+	charset, err := shortener.ParseSlugCharset(cfg.Shortener.SlugCharset)
+	if err != nil {
+		return nil, err
 	}
 
-	opts := &slog.HandlerOptions{
-		Level: logLevel,
+	slugGen, err := sluggen.New(cfg.Shortener.SlugEncoding)
+	if err != nil {
+		return nil, err
+	}
+	alphabet, err := sluggen.AlphabetSize(cfg.Shortener.SlugEncoding)
+	if err != nil {
+		return nil, err
 	}
 
-	handler := slog.NewJSONHandler(os.Stdout, opts)
-	return slog.New(handler)
-}
+	duplicatePolicy, err := shortener.ParseDuplicateSlugPolicy(cfg.Shortener.BatchDuplicateSlugPolicy)
+	if err != nil {
+		return nil, err
+	}
 
-// connectDatabase establishes a connection to the PostgreSQL database.
-func connectDatabase(ctx context.Context, cfg *config.Config, logger *slog.Logger) (*pgxpool.Pool, error) {
-	poolConfig, err := pgxpool.ParseConfig(cfg.Database.ConnectionString())
+	geo, err := loadGeoResolver(cfg)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse database config: %w", err)
+		return nil, err
 	}
 
-	// Set pool configuration
-	poolConfig.MaxConns = cfg.Database.MaxConns
-	poolConfig.MinConns = cfg.Database.MinConns
+	var reachability shortener.ReachabilityChecker
+	if cfg.Shortener.BatchCheckReachability {
+		reachability = shortener.NewHTTPReachabilityChecker(cfg.Shortener.BatchReachabilityTimeout)
+	}
 
-	logger.Info("connecting to database",
-		"host", cfg.Database.Host,
-		"port", cfg.Database.Port,
-		"database", cfg.Database.Name,
-	)
+	var notFoundCache shortener.SlugCache
+	if cfg.Shortener.NotFoundCacheTTL > 0 {
+		notFoundCache = shortener.NewMemoryCache(cfg.Shortener.NotFoundCacheSize, cfg.Shortener.NotFoundCacheTTL, nil)
+	}
+
+	return &shortener.ServiceConfig{
+		SlugGenerator:          slugGen,
+		SlugLengthThresholds:   thresholds,
+		SlugLengthCacheTTL:     cfg.Shortener.SlugLengthCacheTTL,
+		AutoSlugLength:         cfg.Shortener.SlugAutoLength,
+		SlugCollisionTarget:    cfg.Shortener.SlugCollisionTarget,
+		SlugAlphabet:           alphabet,
+		MinSlugLength:          cfg.Shortener.MinCustomSlugLength,
+		MaxCustomSlugLength:    cfg.Shortener.MaxCustomSlugLength,
+		MaxGeneratedSlugLength: cfg.Shortener.MaxGeneratedSlugLength,
+		SlugCharset:            charset,
+		SlugNoLeadingDigit:     cfg.Shortener.SlugNoLeadingDigit,
+		TrackUniqueVisitors:    cfg.Shortener.TrackUniqueVisitors,
+		RecordClicks:           cfg.Shortener.RecordClickEvents,
+		RecordClickRequestIDs:  cfg.Shortener.RecordClickRequestIDs,
+		GeoResolver:            geo,
+		RecordCreators:         cfg.Shortener.RecordCreators,
+		SlugPrefixes:           cfg.Shortener.SlugPrefixes,
+		CaseInsensitiveSlugs:   cfg.Shortener.SlugCaseInsensitive,
+		MaxLinksPerOwner:       cfg.Shortener.MaxLinksPerOwner,
+		IdempotentCreate:       cfg.Shortener.IdempotentCreate,
+		PreserveURLFragments:   cfg.Shortener.PreserveURLFragments,
+		BlockedDomains:         cfg.Shortener.BlockedDomains,
+		AllowedDomains:         cfg.Shortener.AllowedDomains,
+		AllowListMode:          cfg.Shortener.AllowListMode,
+		DuplicateSlugPolicy:    duplicatePolicy,
+		MaxBatchSize:           cfg.Shortener.BatchMaxItems,
+		ListMaxLimit:           cfg.Shortener.ListMaxLimit,
+		ReachabilityChecker:    reachability,
+		NotFoundCache:          notFoundCache,
+		ExpiryGrace:            cfg.Shortener.LinkExpiryGrace,
+		DefaultLinkTTL:         cfg.Shortener.DefaultLinkTTL,
+		AccessCountThreshold:   cfg.Shortener.AccessCountThreshold,
+		AutoPauseOverThreshold: cfg.Shortener.AccessCountAutoPause,
+	}, nil
+}
 
-	pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
+// clickTracker builds the background click tracker, or returns nil when
+// tracking runs inline.
+func clickTracker(cfg *config.Config, logger *slog.Logger) (*shortener.ClickTracker, error) {
+	if !cfg.Shortener.TrackingAsync {
+		return nil, nil
+	}
+
+	overflow, err := shortener.ParseTrackingOverflowPolicy(cfg.Shortener.TrackingOverflowPolicy)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create connection pool: %w", err)
+		return nil, err
 	}
+	return shortener.NewClickTracker(shortener.ClickTrackerConfig{
+		BufferSize:     cfg.Shortener.TrackingBufferSize,
+		OverflowPolicy: overflow,
+		BlockTimeout:   cfg.Shortener.TrackingBlockTimeout,
+		Logger:         logger,
+	}), nil
+}
 
-	// Verify connection
-	if err := pool.Ping(ctx); err != nil {
-		pool.Close()
-		return nil, fmt.Errorf("failed to ping database: %w", err)
+// loadGeoResolver loads the configured GeoIP databases. It returns nil when
+// none are configured.
+func loadGeoResolver(cfg *config.Config) (shortener.GeoResolver, error) {
+	if cfg.Shortener.GeoIPLocationsFile == "" {
+		return nil, nil
 	}
 
-	logger.Info("database connection established")
+	locations, err := os.Open(cfg.Shortener.GeoIPLocationsFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open GeoIP locations: %w", err)
+	}
+	defer locations.Close()
+
+	blocks := make([]io.Reader, 0, len(cfg.Shortener.GeoIPBlocksFiles))
+	for _, path := range cfg.Shortener.GeoIPBlocksFiles {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open GeoIP blocks: %w", err)
+		}
+		defer f.Close()
+		blocks = append(blocks, f)
+	}
 
-	return pool, nil
+	geo, err := shortener.LoadMaxMindCSV(locations, blocks...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load GeoIP database: %w", err)
+	}
+	return geo, nil
+}
+
+// loadEnv loads .env file only in non-production environments.
+func loadEnv() error {
+	env := os.Getenv("APP_ENV")
+	if env == "development" || env == "test" {
+		if err := godotenv.Load("../.env"); err != nil {
+			log.Println("no .env file found.")
+		}
+	}
+	return nil
 }
@@ -0,0 +1,75 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// readyPollInterval is how often WaitReady retries a dependency that is not
+// ready yet.
+const readyPollInterval = 500 * time.Millisecond
+
+// pinger is a dependency health check.
+type pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// dependency is a named pinger WaitReady waits for.
+type dependency struct {
+	name string
+	ping pinger
+}
+
+// WaitReady blocks until every dependency answers its health check or
+// timeout elapses, so orchestrators can sequence startup on the process
+// rather than on a fixed delay, and then applies pending migrations when
+// AutoMigrate is on. New doesn't touch the database, so one still starting
+// alongside the process delays startup instead of failing it. The database
+// is the only external dependency; the not-found cache lives in process
+// and is always ready.
+func (a *App) WaitReady(ctx context.Context, timeout time.Duration) error {
+	deps := []dependency{{name: "database", ping: a.database}}
+	if err := waitReady(ctx, a.Logger, deps, timeout, readyPollInterval); err != nil {
+		return err
+	}
+	if a.migrate == nil {
+		return nil
+	}
+	return a.migrate(ctx)
+}
+
+func waitReady(ctx context.Context, logger *slog.Logger, deps []dependency, timeout, interval time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	for _, dep := range deps {
+		for attempt := 1; ; attempt++ {
+			err := dep.ping.Ping(ctx)
+			if err == nil {
+				logger.Info("dependency ready",
+					"dependency", dep.name,
+					"attempts", attempt,
+					"elapsed", time.Since(start),
+				)
+				break
+			}
+
+			logger.Info("waiting for dependency",
+				"dependency", dep.name,
+				"attempt", attempt,
+				"error", err,
+			)
+
+			select {
+			case <-ctx.Done():
+				return fmt.Errorf("wait ready: %s not ready after %s: %w (last error: %v)",
+					dep.name, timeout, ctx.Err(), err)
+			case <-time.After(interval):
+			}
+		}
+	}
+	return nil
+}
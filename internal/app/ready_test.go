@@ -0,0 +1,99 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+// delayedPinger fails its health check until readyAt.
+type delayedPinger struct {
+	readyAt time.Time
+}
+
+func (p delayedPinger) Ping(context.Context) error {
+	if time.Now().Before(p.readyAt) {
+		return errors.New("connection refused")
+	}
+	return nil
+}
+
+func TestWaitReady(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	t.Run("returns once dependencies become healthy", func(t *testing.T) {
+		deps := []dependency{
+			{name: "database", ping: delayedPinger{readyAt: time.Now().Add(30 * time.Millisecond)}},
+			{name: "cache", ping: delayedPinger{}},
+		}
+
+		if err := waitReady(context.Background(), logger, deps, time.Second, 5*time.Millisecond); err != nil {
+			t.Fatalf("waitReady() unexpected error: %v", err)
+		}
+	})
+
+	t.Run("times out on a dependency that never becomes healthy", func(t *testing.T) {
+		deps := []dependency{
+			{name: "database", ping: delayedPinger{readyAt: time.Now().Add(time.Hour)}},
+		}
+
+		err := waitReady(context.Background(), logger, deps, 30*time.Millisecond, 5*time.Millisecond)
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("waitReady() error = %v, want deadline exceeded", err)
+		}
+		if !strings.Contains(err.Error(), "database not ready") || !strings.Contains(err.Error(), "connection refused") {
+			t.Errorf("waitReady() error = %q, want the dependency and its last error", err)
+		}
+	})
+}
+
+func TestApp_WaitReady(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	t.Run("migrates once a database starting late comes up", func(t *testing.T) {
+		db := delayedPinger{readyAt: time.Now().Add(30 * time.Millisecond)}
+
+		var migratedAt time.Time
+		a := &App{Logger: logger, database: db, migrate: func(context.Context) error {
+			migratedAt = time.Now()
+			return nil
+		}}
+
+		if err := a.WaitReady(context.Background(), 5*time.Second); err != nil {
+			t.Fatalf("WaitReady() unexpected error: %v", err)
+		}
+		if migratedAt.IsZero() || migratedAt.Before(db.readyAt) {
+			t.Errorf("migrated at %v, want after the database came up at %v", migratedAt, db.readyAt)
+		}
+	})
+
+	t.Run("times out without migrating a database that never comes up", func(t *testing.T) {
+		a := &App{Logger: logger, database: delayedPinger{readyAt: time.Now().Add(time.Hour)}, migrate: func(context.Context) error {
+			t.Error("migrate should not run before the database is ready")
+			return nil
+		}}
+
+		err := a.WaitReady(context.Background(), 30*time.Millisecond)
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("WaitReady() error = %v, want deadline exceeded", err)
+		}
+		if !strings.Contains(err.Error(), "database not ready") {
+			t.Errorf("WaitReady() error = %q, want the dependency named", err)
+		}
+	})
+
+	t.Run("reports migration failure", func(t *testing.T) {
+		a := &App{Logger: logger, database: delayedPinger{}, migrate: func(context.Context) error {
+			return errors.New("dirty schema")
+		}}
+
+		err := a.WaitReady(context.Background(), time.Second)
+		if err == nil || !strings.Contains(err.Error(), "dirty schema") {
+			t.Errorf("WaitReady() error = %v, want the migration error", err)
+		}
+	})
+}
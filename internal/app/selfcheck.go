@@ -0,0 +1,111 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// requiredLinkConstraints are the links table constraints the repository
+// relies on to map database errors to domain errors.
+var requiredLinkConstraints = []string{
+	"links_slug_unique",
+	"links_slug_length",
+}
+
+// schemaDB is the subset of *pgxpool.Pool the self-check needs.
+type schemaDB interface {
+	Ping(ctx context.Context) error
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// slugChecker is the subset of shortener.Service the self-check needs.
+type slugChecker interface {
+	CheckSlugGenerator(ctx context.Context) error
+}
+
+// SelfCheck verifies that the application can serve traffic: the database
+// is reachable, the schema has been migrated and the slug generator
+// produces valid slugs. With SELF_CHECK_SLUG_KEYS it also confirms that the
+// stored slug keys match the configured slug case mode, which scans the
+// links and link_aliases tables. Run it after New to fail fast on a
+// misconfigured deployment rather than on the first request.
+func (a *App) SelfCheck(ctx context.Context) error {
+	if err := selfCheck(ctx, a.DBPool, a.service, a.checkSlugKeys, a.caseInsensitiveSlugs); err != nil {
+		return err
+	}
+	a.Logger.Info("startup self-check passed")
+	return nil
+}
+
+func selfCheck(ctx context.Context, conn schemaDB, slugs slugChecker, checkSlugKeys, caseInsensitiveSlugs bool) error {
+	if err := conn.Ping(ctx); err != nil {
+		return fmt.Errorf("self-check: database unreachable: %w", err)
+	}
+
+	var exists bool
+	err := conn.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM information_schema.tables
+			WHERE table_schema = current_schema() AND table_name = $1
+		)`, "links").Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("self-check: failed to look up table %q: %w", "links", err)
+	}
+	if !exists {
+		return fmt.Errorf("self-check: table %q is missing (have migrations been applied?)", "links")
+	}
+
+	for _, name := range requiredLinkConstraints {
+		err := conn.QueryRow(ctx, `
+			SELECT EXISTS (
+				SELECT 1 FROM information_schema.table_constraints
+				WHERE table_schema = current_schema() AND table_name = $1 AND constraint_name = $2
+			)`, "links", name).Scan(&exists)
+		if err != nil {
+			return fmt.Errorf("self-check: failed to look up constraint %q: %w", name, err)
+		}
+		if !exists {
+			return fmt.Errorf("self-check: constraint %q on table %q is missing (have migrations been applied?)", name, "links")
+		}
+	}
+
+	if checkSlugKeys {
+		if err := verifySlugKeys(ctx, conn, caseInsensitiveSlugs); err != nil {
+			return err
+		}
+	}
+
+	// The service checks with its own generator, slug rules and
+	// generated length, so this matches what Create would produce.
+	if err := slugs.CheckSlugGenerator(ctx); err != nil {
+		return fmt.Errorf("self-check: %w", err)
+	}
+
+	return nil
+}
+
+// verifySlugKeys fails when a stored slug or alias key doesn't match the
+// case mode. Keys written under the other mode make their links
+// unreachable: lookups fold the input the configured way and miss them.
+// No index covers the comparison, so this scans both tables.
+func verifySlugKeys(ctx context.Context, conn schemaDB, caseInsensitiveSlugs bool) error {
+	var miskeyed bool
+	err := conn.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM links
+			WHERE slug_key <> CASE WHEN $1::boolean THEN lower(slug) ELSE slug END
+		) OR EXISTS (
+			SELECT 1 FROM link_aliases
+			WHERE alias_key <> CASE WHEN $1::boolean THEN lower(alias) ELSE alias END
+		)`, caseInsensitiveSlugs).Scan(&miskeyed)
+	if err != nil {
+		return fmt.Errorf("self-check: failed to check slug keys: %w", err)
+	}
+	if miskeyed {
+		return fmt.Errorf("self-check: stored slug keys do not match SLUG_CASE_INSENSITIVE=%t "+
+			"(rewrite them as described on RepositoryConfig.CaseInsensitiveSlugs)", caseInsensitiveSlugs)
+	}
+	return nil
+}
@@ -0,0 +1,178 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/sundayezeilo/urlshortener/internal/shortener"
+	"github.com/sundayezeilo/urlshortener/sluggen"
+)
+
+// fakeSchemaDB answers the self-check's information_schema lookups from
+// in-memory sets.
+type fakeSchemaDB struct {
+	pingErr     error
+	tables      map[string]bool
+	constraints map[string]bool
+	miskeyed    bool // Some slug keys don't match the case mode
+}
+
+func (f *fakeSchemaDB) Ping(context.Context) error { return f.pingErr }
+
+func (f *fakeSchemaDB) QueryRow(_ context.Context, sql string, args ...any) pgx.Row {
+	if strings.Contains(sql, "table_constraints") {
+		return boolRow(f.constraints[args[1].(string)])
+	}
+	if strings.Contains(sql, "slug_key") {
+		return boolRow(f.miskeyed)
+	}
+	return boolRow(f.tables[args[0].(string)])
+}
+
+// boolRow is a single-column boolean row.
+type boolRow bool
+
+func (r boolRow) Scan(dest ...any) error {
+	*dest[0].(*bool) = bool(r)
+	return nil
+}
+
+// fixedGenerator returns the same slug every time.
+type fixedGenerator string
+
+func (g fixedGenerator) Generate(int) (string, error) { return string(g), nil }
+
+func migratedDB() *fakeSchemaDB {
+	return &fakeSchemaDB{
+		tables: map[string]bool{"links": true},
+		constraints: map[string]bool{
+			"links_slug_unique": true,
+			"links_slug_length": true,
+		},
+	}
+}
+
+func TestSelfCheck(t *testing.T) {
+	tests := []struct {
+		name    string
+		db      func() *fakeSchemaDB
+		cfg     *shortener.ServiceConfig
+		keys    bool // SELF_CHECK_SLUG_KEYS
+		wantErr string
+	}{
+		{
+			name: "migrated database",
+			db:   migratedDB,
+			cfg:  &shortener.ServiceConfig{SlugGenerator: sluggen.NewBase62()},
+		},
+		{
+			name: "database unreachable",
+			db: func() *fakeSchemaDB {
+				db := migratedDB()
+				db.pingErr = errors.New("connection refused")
+				return db
+			},
+			cfg:     &shortener.ServiceConfig{SlugGenerator: sluggen.NewBase62()},
+			wantErr: "database unreachable: connection refused",
+		},
+		{
+			name:    "links table missing",
+			db:      func() *fakeSchemaDB { return &fakeSchemaDB{} },
+			cfg:     &shortener.ServiceConfig{SlugGenerator: sluggen.NewBase62()},
+			wantErr: `table "links" is missing`,
+		},
+		{
+			name: "constraint missing",
+			db: func() *fakeSchemaDB {
+				db := migratedDB()
+				delete(db.constraints, "links_slug_length")
+				return db
+			},
+			cfg:     &shortener.ServiceConfig{SlugGenerator: sluggen.NewBase62()},
+			wantErr: `constraint "links_slug_length" on table "links" is missing`,
+		},
+		{
+			name: "slug keys from another case mode",
+			db: func() *fakeSchemaDB {
+				db := migratedDB()
+				db.miskeyed = true
+				return db
+			},
+			cfg:     &shortener.ServiceConfig{SlugGenerator: sluggen.NewBase62()},
+			keys:    true,
+			wantErr: "stored slug keys do not match SLUG_CASE_INSENSITIVE=false",
+		},
+		{
+			name: "slug keys not scanned by default",
+			db: func() *fakeSchemaDB {
+				db := migratedDB()
+				db.miskeyed = true
+				return db
+			},
+			cfg: &shortener.ServiceConfig{SlugGenerator: sluggen.NewBase62()},
+		},
+		{
+			name:    "generator returns wrong length",
+			db:      migratedDB,
+			cfg:     &shortener.ServiceConfig{SlugGenerator: fixedGenerator("abc")},
+			wantErr: "want 7 characters",
+		},
+		{
+			name:    "generator returns invalid slug",
+			db:      migratedDB,
+			cfg:     &shortener.ServiceConfig{SlugGenerator: fixedGenerator("abc/def")},
+			wantErr: `invalid slug "abc/def"`,
+		},
+		{
+			name: "generator ignores threshold length",
+			db:   migratedDB,
+			cfg: &shortener.ServiceConfig{
+				SlugGenerator:        fixedGenerator("abcdefg"),
+				SlugLengthThresholds: []shortener.SlugLengthThreshold{{MinLinks: 0, Length: 9}},
+			},
+			wantErr: "want 9 characters",
+		},
+		{
+			name: "generator keeps leading digits",
+			db:   migratedDB,
+			cfg: &shortener.ServiceConfig{
+				SlugGenerator:      fixedGenerator("1abcdef"),
+				SlugNoLeadingDigit: true,
+			},
+			wantErr: "leading digit",
+		},
+		{
+			name: "prefixed slug breaks custom slug rules",
+			db:   migratedDB,
+			cfg: &shortener.ServiceConfig{
+				SlugGenerator:       sluggen.NewBase62(),
+				MaxCustomSlugLength: 10,
+				SlugPrefixes:        map[string]string{"team-app": "team"},
+			},
+			wantErr: `invalid slug "team-`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := shortener.NewService(shortener.NewMemoryRepository(), tt.cfg)
+			err := selfCheck(context.Background(), tt.db(), svc, tt.keys, false)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("selfCheck() unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("selfCheck() error = %v, want containing %q", err, tt.wantErr)
+			}
+			if !strings.HasPrefix(err.Error(), "self-check: ") {
+				t.Errorf("selfCheck() error = %q, want self-check prefix", err)
+			}
+		})
+	}
+}
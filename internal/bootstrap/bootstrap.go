@@ -0,0 +1,91 @@
+// Package bootstrap builds the process-wide dependencies every entrypoint
+// needs before wiring the application: the logger and the database pool.
+package bootstrap
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/sundayezeilo/urlshortener/internal/config"
+)
+
+// NewLogger creates a structured logger writing to the configured output.
+func NewLogger(cfg config.AppConfig) *slog.Logger {
+	out := os.Stdout
+	if cfg.LogOutput == "stderr" {
+		out = os.Stderr
+	}
+	return newLogger(out, cfg.LogFormat, cfg.LogLevel)
+}
+
+// newLogger creates a structured logger writing to w in the given format
+// ("json" or "text") at the given level.
+func newLogger(w io.Writer, format, level string) *slog.Logger {
+	var logLevel slog.Level
+	switch level {
+	case "debug":
+		logLevel = slog.LevelDebug
+	case "info":
+		logLevel = slog.LevelInfo
+	case "warn":
+		logLevel = slog.LevelWarn
+	case "error":
+		logLevel = slog.LevelError
+	default:
+		logLevel = slog.LevelInfo
+	}
+
+	opts := &slog.HandlerOptions{
+		Level: logLevel,
+	}
+
+	var handler slog.Handler
+	if format == "text" {
+		handler = slog.NewTextHandler(w, opts)
+	} else {
+		handler = slog.NewJSONHandler(w, opts)
+	}
+	return slog.New(handler)
+}
+
+// newPoolConfig builds the connection pool configuration from cfg.
+func newPoolConfig(cfg *config.Config) (*pgxpool.Config, error) {
+	poolConfig, err := pgxpool.ParseConfig(cfg.Database.ConnectionString())
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse database config: %w", err)
+	}
+
+	poolConfig.MaxConns = cfg.Database.MaxConns
+	poolConfig.MinConns = cfg.Database.MinConns
+	poolConfig.MaxConnLifetime = cfg.Database.MaxConnLifetime
+	poolConfig.MaxConnIdleTime = cfg.Database.MaxConnIdleTime
+
+	return poolConfig, nil
+}
+
+// ConnectDatabase creates the PostgreSQL connection pool. Connections are
+// opened lazily, so a database that is still starting does not fail it;
+// callers wait for the database to answer before using it.
+func ConnectDatabase(ctx context.Context, cfg *config.Config, logger *slog.Logger) (*pgxpool.Pool, error) {
+	poolConfig, err := newPoolConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info("connecting to database",
+		"host", cfg.Database.Host,
+		"port", cfg.Database.Port,
+		"database", cfg.Database.Name,
+	)
+
+	pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create connection pool: %w", err)
+	}
+	return pool, nil
+}
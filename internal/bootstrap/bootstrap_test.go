@@ -0,0 +1,101 @@
+package bootstrap
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sundayezeilo/urlshortener/internal/config"
+)
+
+func TestNewPoolConfig(t *testing.T) {
+	cfg := &config.Config{Database: config.DatabaseConfig{
+		Host:            "localhost",
+		Port:            "5432",
+		User:            "postgres",
+		Password:        "postgres",
+		Name:            "urlshortener",
+		SSLMode:         "disable",
+		MaxConns:        10,
+		MinConns:        2,
+		MaxConnLifetime: 15 * time.Minute,
+		MaxConnIdleTime: 90 * time.Second,
+	}}
+
+	got, err := newPoolConfig(cfg)
+	if err != nil {
+		t.Fatalf("newPoolConfig() unexpected error: %v", err)
+	}
+	if got.MaxConns != 10 || got.MinConns != 2 {
+		t.Errorf("MaxConns=%d MinConns=%d, want 10 and 2", got.MaxConns, got.MinConns)
+	}
+	if got.MaxConnLifetime != 15*time.Minute {
+		t.Errorf("MaxConnLifetime = %v, want 15m", got.MaxConnLifetime)
+	}
+	if got.MaxConnIdleTime != 90*time.Second {
+		t.Errorf("MaxConnIdleTime = %v, want 90s", got.MaxConnIdleTime)
+	}
+}
+
+func TestNewLogger(t *testing.T) {
+	t.Run("text format is not JSON", func(t *testing.T) {
+		var buf bytes.Buffer
+		newLogger(&buf, "text", "info").Info("hello", "slug", "abc1234")
+
+		if json.Valid(buf.Bytes()) {
+			t.Errorf("text output is valid JSON: %s", buf.String())
+		}
+		if !strings.Contains(buf.String(), "msg=hello slug=abc1234") {
+			t.Errorf("output = %q, want text key=value pairs", buf.String())
+		}
+	})
+
+	t.Run("json format", func(t *testing.T) {
+		var buf bytes.Buffer
+		newLogger(&buf, "json", "info").Info("hello")
+
+		var entry map[string]any
+		if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+			t.Fatalf("output is not JSON: %v: %s", err, buf.String())
+		}
+		if entry["msg"] != "hello" {
+			t.Errorf("msg = %v, want hello", entry["msg"])
+		}
+	})
+
+	levels := []struct {
+		level     string
+		wantDebug bool
+		wantInfo  bool
+		wantWarn  bool
+	}{
+		{"debug", true, true, true},
+		{"info", false, true, true},
+		{"warn", false, false, true},
+		{"error", false, false, false},
+		{"", false, true, true},
+	}
+	for _, format := range []string{"json", "text"} {
+		for _, tt := range levels {
+			t.Run(format+" level "+tt.level, func(t *testing.T) {
+				var buf bytes.Buffer
+				logger := newLogger(&buf, format, tt.level)
+
+				logger.Debug("debug")
+				logger.Info("info")
+				logger.Warn("warn")
+				logger.Error("error")
+
+				got := buf.String()
+				if strings.Contains(got, "DEBUG") != tt.wantDebug ||
+					strings.Contains(got, "INFO") != tt.wantInfo ||
+					strings.Contains(got, "WARN") != tt.wantWarn ||
+					!strings.Contains(got, "ERROR") {
+					t.Errorf("output at level %q:\n%s", tt.level, got)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,287 @@
+// Package client is a typed Go client for the shortener HTTP API, so other
+// services can create and look up links without hand-rolling requests.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/sundayezeilo/urlshortener/internal/errx"
+	"github.com/sundayezeilo/urlshortener/internal/httpx"
+	"github.com/sundayezeilo/urlshortener/internal/shortener"
+)
+
+// The client speaks the handler's wire types directly, so the two cannot
+// drift apart.
+type (
+	CreateRequest = shortener.HTTPCreateLinkRequest
+	Link          = shortener.LinkResponse
+	LinkPage      = shortener.ListLinksResponse
+)
+
+// ListOptions selects a page of links. Zero values use the server defaults
+// and start from the newest link.
+type ListOptions struct {
+	Limit  int
+	Cursor string // PageInfo.NextCursor of the previous page
+}
+
+// Error is a non-2xx API response. Client methods return it wrapped in an
+// errx.Error carrying Kind, so errx.KindOf works on every returned error.
+type Error struct {
+	Status  int
+	Code    string // e.g. "not_found"; empty if the body was not an API error
+	Message string
+	Kind    errx.Kind
+}
+
+func (e *Error) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("shortener api: %d %s", e.Status, e.Code)
+	}
+	return fmt.Sprintf("shortener api: %d %s: %s", e.Status, e.Code, e.Message)
+}
+
+// Client calls the shortener API at a base URL. It is safe for concurrent
+// use.
+type Client struct {
+	baseURL string
+	apiKey  string
+	http    *http.Client
+	noRedir *http.Client // Same transport, but returns redirects unfollowed
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithHTTPClient sets the HTTP client used for requests (default:
+// http.DefaultClient).
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) { c.http = hc }
+}
+
+// WithAPIKey sends key as a bearer token, as the admin endpoints and
+// namespaced creates require.
+func WithAPIKey(key string) Option {
+	return func(c *Client) { c.apiKey = key }
+}
+
+// New returns a Client for the API served at baseURL, e.g.
+// "https://short.example.com".
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		http:    http.DefaultClient,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	noRedir := *c.http
+	noRedir.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	c.noRedir = &noRedir
+	return c
+}
+
+// Create shortens a URL. A create the server answers as an idempotent
+// replay (200) is returned like a fresh one (201).
+func (c *Client) Create(ctx context.Context, req CreateRequest) (Link, error) {
+	const op = "client.Create"
+
+	var link Link
+	err := c.do(ctx, http.MethodPost, "/api/links", req, &link)
+	if err != nil {
+		return Link{}, errx.E(op, errx.KindOf(err), err)
+	}
+	return link, nil
+}
+
+// Get returns the link with slug.
+func (c *Client) Get(ctx context.Context, slug string) (Link, error) {
+	const op = "client.Get"
+
+	var link Link
+	err := c.do(ctx, http.MethodGet, "/api/links/"+url.PathEscape(slug), nil, &link)
+	if err != nil {
+		return Link{}, errx.E(op, errx.KindOf(err), err)
+	}
+	return link, nil
+}
+
+// Resolve returns the destination slug redirects to, without following
+// the redirect. Like a browser visit, it counts as a click.
+func (c *Client) Resolve(ctx context.Context, slug string) (string, error) {
+	const op = "client.Resolve"
+
+	req, err := c.newRequest(ctx, http.MethodGet, "/"+url.PathEscape(slug), nil)
+	if err != nil {
+		return "", errx.E(op, errx.Invalid, err)
+	}
+	resp, err := c.noRedir.Do(req)
+	if err != nil {
+		return "", errx.E(op, transportKind(err), err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 300 || resp.StatusCode >= 400 {
+		err := responseError(resp)
+		return "", errx.E(op, errx.KindOf(err), err)
+	}
+	location := resp.Header.Get("Location")
+	if location == "" {
+		return "", errx.E(op, errx.Internal, errors.New("redirect without a Location header"))
+	}
+	return location, nil
+}
+
+// Delete deletes the link with slug. It needs an admin API key.
+func (c *Client) Delete(ctx context.Context, slug string) error {
+	const op = "client.Delete"
+
+	if err := c.do(ctx, http.MethodDelete, "/api/links/"+url.PathEscape(slug), nil, nil); err != nil {
+		return errx.E(op, errx.KindOf(err), err)
+	}
+	return nil
+}
+
+// List returns a page of links, newest first. It needs an admin API key.
+func (c *Client) List(ctx context.Context, opts ListOptions) (LinkPage, error) {
+	const op = "client.List"
+
+	query := url.Values{}
+	if opts.Limit > 0 {
+		query.Set("limit", strconv.Itoa(opts.Limit))
+	}
+	if opts.Cursor != "" {
+		query.Set("cursor", opts.Cursor)
+	}
+	path := "/api/links"
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+
+	var page LinkPage
+	if err := c.do(ctx, http.MethodGet, path, nil, &page); err != nil {
+		return LinkPage{}, errx.E(op, errx.KindOf(err), err)
+	}
+	return page, nil
+}
+
+// do sends a JSON request and decodes a 2xx JSON response into out, which
+// may be nil to discard the body.
+func (c *Client) do(ctx context.Context, method, path string, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		b, err := json.Marshal(in)
+		if err != nil {
+			return errx.E("client.do", errx.Invalid, err)
+		}
+		body = bytes.NewReader(b)
+	}
+
+	req, err := c.newRequest(ctx, method, path, body)
+	if err != nil {
+		return errx.E("client.do", errx.Invalid, err)
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return errx.E("client.do", transportKind(err), err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return responseError(resp)
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return errx.E("client.do", errx.Internal, fmt.Errorf("decode response: %w", err))
+	}
+	return nil
+}
+
+func (c *Client) newRequest(ctx context.Context, method, path string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+	if id := httpx.GetRequestID(ctx); id != "" {
+		req.Header.Set(httpx.RequestIDHeader, id)
+	}
+	return req, nil
+}
+
+// responseError reads an error response in either of the server's formats,
+// the default JSON error or RFC 7807 problem details.
+func responseError(resp *http.Response) error {
+	var body struct {
+		Error   string `json:"error"`
+		Message string `json:"message"`
+		Type    string `json:"type"`
+		Detail  string `json:"detail"`
+	}
+	_ = json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&body)
+
+	apiErr := &Error{Status: resp.StatusCode, Code: body.Error, Message: body.Message}
+	if apiErr.Code == "" && body.Type != "" {
+		apiErr.Code = strings.TrimPrefix(body.Type, httpx.ProblemTypePrefix)
+		apiErr.Message = body.Detail
+	}
+	apiErr.Kind = statusKind(resp.StatusCode, apiErr.Code)
+	return errx.E("client.response", apiErr.Kind, apiErr)
+}
+
+// statusKind maps a response back to the errx.Kind the server reported,
+// inverting httpx.ErrorKindToStatus. The code tells apart kinds sharing a
+// status.
+func statusKind(status int, code string) errx.Kind {
+	switch status {
+	case http.StatusNotFound:
+		return errx.NotFound
+	case http.StatusConflict:
+		return errx.Conflict
+	case http.StatusBadRequest, http.StatusMethodNotAllowed,
+		http.StatusRequestEntityTooLarge, http.StatusRequestURITooLong,
+		http.StatusUnsupportedMediaType:
+		return errx.Invalid
+	case http.StatusUnauthorized:
+		return errx.Unauthorized
+	case http.StatusForbidden:
+		if code == httpx.ErrorKindToCode(errx.QuotaExceeded) {
+			return errx.QuotaExceeded
+		}
+		return errx.Forbidden
+	case http.StatusServiceUnavailable, http.StatusTooManyRequests:
+		return errx.Unavailable
+	case http.StatusGatewayTimeout:
+		return errx.Timeout
+	default:
+		return errx.Internal
+	}
+}
+
+// transportKind classifies an error from sending a request.
+func transportKind(err error) errx.Kind {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return errx.Timeout
+	}
+	return errx.Unavailable
+}
@@ -0,0 +1,335 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/sundayezeilo/urlshortener/internal/errx"
+	"github.com/sundayezeilo/urlshortener/internal/httpx"
+	"github.com/sundayezeilo/urlshortener/internal/shortener"
+)
+
+const testAPIKey = "admin-key"
+
+// memService is an in-memory shortener.Service covering the calls the
+// client makes. The embedded interface is nil: other calls panic.
+type memService struct {
+	shortener.Service
+
+	mu    sync.Mutex
+	links []shortener.Link // Newest last
+	err   error            // Returned by every call when set
+}
+
+func (s *memService) Create(_ context.Context, req shortener.CreateLinkRequest) (shortener.Link, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.err != nil {
+		return shortener.Link{}, s.err
+	}
+	slug := req.CustomSlug
+	if slug == "" {
+		slug = fmt.Sprintf("gen%04d", len(s.links))
+	}
+	for _, l := range s.links {
+		if l.Slug == slug {
+			return shortener.Link{}, errx.E("memService.Create", errx.Conflict, errors.New("slug taken"))
+		}
+	}
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC).Add(time.Duration(len(s.links)) * time.Second)
+	link := shortener.Link{
+		ID:          uuid.New(),
+		Slug:        slug,
+		OriginalURL: req.OriginalURL,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	s.links = append(s.links, link)
+	return link, nil
+}
+
+func (s *memService) find(slug string) (shortener.Link, error) {
+	if s.err != nil {
+		return shortener.Link{}, s.err
+	}
+	for _, l := range s.links {
+		if l.Slug == slug {
+			return l, nil
+		}
+	}
+	return shortener.Link{}, errx.E("memService.find", errx.NotFound, errors.New("not found"))
+}
+
+func (s *memService) GetBySlug(_ context.Context, slug string) (shortener.Link, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.find(slug)
+}
+
+func (s *memService) Resolve(_ context.Context, slug string) (shortener.Resolution, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	link, err := s.find(slug)
+	if err != nil {
+		return shortener.Resolution{}, err
+	}
+	return shortener.Resolution{URL: link.Destination()}, nil
+}
+
+func (s *memService) Delete(_ context.Context, slug string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.find(slug); err != nil {
+		return err
+	}
+	for i, l := range s.links {
+		if l.Slug == slug {
+			s.links = append(s.links[:i], s.links[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+// List pages newest first; the cursor is the slug to continue after.
+func (s *memService) List(_ context.Context, req shortener.ListLinksRequest) (shortener.LinkPage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.err != nil {
+		return shortener.LinkPage{}, s.err
+	}
+	var newest []shortener.Link
+	for i := len(s.links) - 1; i >= 0; i-- {
+		newest = append(newest, s.links[i])
+	}
+	start := 0
+	if req.Cursor != "" {
+		for start < len(newest) && newest[start].Slug != req.Cursor {
+			start++
+		}
+		start++
+	}
+	start = min(start, len(newest))
+	end := min(start+req.Limit, len(newest))
+	page := shortener.LinkPage{Links: newest[start:end], Limit: req.Limit, HasMore: end < len(newest)}
+	if page.HasMore {
+		page.NextCursor = newest[end-1].Slug
+	}
+	return page, nil
+}
+
+// newTestServer serves the real handler for svc with the server's routes
+// and auth, in the given error format.
+func newTestServer(t *testing.T, svc shortener.Service, format httpx.ErrorFormat) *httptest.Server {
+	t.Helper()
+
+	h := shortener.NewHandler(shortener.HandlerConfig{
+		Service: svc,
+		Logger:  slog.New(slog.NewTextHandler(io.Discard, nil)),
+		BaseURL: "https://sho.rt",
+	})
+	adminAuth := httpx.APIKeyAuth(map[string]string{testAPIKey: "admin"})
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/links", h.CreateLink)
+	mux.HandleFunc("GET /api/links", h.ListLinks)
+	mux.HandleFunc("GET /api/links/{slug}", h.GetLink)
+	mux.Handle("DELETE /api/links/{slug}", adminAuth(http.HandlerFunc(h.DeleteLink)))
+	mux.HandleFunc("GET /{slug}", h.ResolveLink)
+
+	ts := httptest.NewServer(httpx.Chain(httpx.RequestID, httpx.ProblemErrors(format))(mux))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func TestClient_RoundTrip(t *testing.T) {
+	ts := newTestServer(t, &memService{}, httpx.ErrorFormatJSON)
+	c := New(ts.URL, WithAPIKey(testAPIKey))
+	ctx := context.Background()
+
+	created, err := c.Create(ctx, CreateRequest{URL: "https://example.com/docs", CustomSlug: "docs-link"})
+	if err != nil {
+		t.Fatalf("Create() unexpected error: %v", err)
+	}
+	if created.Slug != "docs-link" || created.ShortURL != "https://sho.rt/docs-link" {
+		t.Errorf("Create() = %+v, want docs-link", created)
+	}
+
+	got, err := c.Get(ctx, "docs-link")
+	if err != nil {
+		t.Fatalf("Get() unexpected error: %v", err)
+	}
+	if got.ID != created.ID || got.OriginalURL != "https://example.com/docs" {
+		t.Errorf("Get() = %+v, want the created link %+v", got, created)
+	}
+
+	dest, err := c.Resolve(ctx, "docs-link")
+	if err != nil {
+		t.Fatalf("Resolve() unexpected error: %v", err)
+	}
+	if dest != "https://example.com/docs" {
+		t.Errorf("Resolve() = %q, want https://example.com/docs", dest)
+	}
+
+	if err := c.Delete(ctx, "docs-link"); err != nil {
+		t.Fatalf("Delete() unexpected error: %v", err)
+	}
+	if _, err := c.Get(ctx, "docs-link"); errx.KindOf(err) != errx.NotFound {
+		t.Errorf("Get() after Delete error kind = %v, want %v", errx.KindOf(err), errx.NotFound)
+	}
+}
+
+func TestClient_List(t *testing.T) {
+	svc := &memService{}
+	ts := newTestServer(t, svc, httpx.ErrorFormatJSON)
+	c := New(ts.URL)
+	ctx := context.Background()
+
+	for _, slug := range []string{"first-link", "second-link", "third-link"} {
+		if _, err := c.Create(ctx, CreateRequest{URL: "https://example.com/" + slug, CustomSlug: slug}); err != nil {
+			t.Fatalf("Create(%s) unexpected error: %v", slug, err)
+		}
+	}
+
+	var slugs []string
+	opts := ListOptions{Limit: 2}
+	for {
+		page, err := c.List(ctx, opts)
+		if err != nil {
+			t.Fatalf("List() unexpected error: %v", err)
+		}
+		for _, l := range page.Links {
+			slugs = append(slugs, l.Slug)
+		}
+		if !page.Page.HasMore {
+			break
+		}
+		opts.Cursor = page.Page.NextCursor
+	}
+
+	want := []string{"third-link", "second-link", "first-link"}
+	if fmt.Sprint(slugs) != fmt.Sprint(want) {
+		t.Errorf("listed slugs = %v, want %v", slugs, want)
+	}
+}
+
+func TestClient_ErrorMapping(t *testing.T) {
+	tests := []struct {
+		name       string
+		svcErr     error
+		apiKey     string
+		call       func(ctx context.Context, c *Client) error
+		wantKind   errx.Kind
+		wantStatus int
+		wantCode   string
+	}{
+		{
+			name:       "unknown slug",
+			call:       func(ctx context.Context, c *Client) error { _, err := c.Get(ctx, "missing-link"); return err },
+			wantKind:   errx.NotFound,
+			wantStatus: http.StatusNotFound,
+			wantCode:   "not_found",
+		},
+		{
+			name:       "unknown slug on resolve",
+			call:       func(ctx context.Context, c *Client) error { _, err := c.Resolve(ctx, "missing-link"); return err },
+			wantKind:   errx.NotFound,
+			wantStatus: http.StatusNotFound,
+			wantCode:   "not_found",
+		},
+		{
+			name: "taken slug",
+			call: func(ctx context.Context, c *Client) error {
+				req := CreateRequest{URL: "https://example.com", CustomSlug: "taken-link"}
+				if _, err := c.Create(ctx, req); err != nil {
+					return err
+				}
+				_, err := c.Create(ctx, req)
+				return err
+			},
+			wantKind:   errx.Conflict,
+			wantStatus: http.StatusConflict,
+			wantCode:   "conflict",
+		},
+		{
+			name:       "invalid request",
+			call:       func(ctx context.Context, c *Client) error { _, err := c.Create(ctx, CreateRequest{}); return err },
+			wantKind:   errx.Invalid,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "missing API key",
+			apiKey:     "",
+			call:       func(ctx context.Context, c *Client) error { return c.Delete(ctx, "some-link") },
+			wantKind:   errx.Unauthorized,
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:   "quota exceeded",
+			svcErr: errx.E("service.Create", errx.QuotaExceeded, errors.New("limit reached")),
+			call: func(ctx context.Context, c *Client) error {
+				_, err := c.Create(ctx, CreateRequest{URL: "https://example.com"})
+				return err
+			},
+			wantKind:   errx.QuotaExceeded,
+			wantStatus: http.StatusForbidden,
+			wantCode:   "quota_exceeded",
+		},
+		{
+			name:       "service unavailable",
+			svcErr:     errx.E("service.List", errx.Unavailable, errors.New("db down")),
+			call:       func(ctx context.Context, c *Client) error { _, err := c.List(ctx, ListOptions{}); return err },
+			wantKind:   errx.Unavailable,
+			wantStatus: http.StatusServiceUnavailable,
+			wantCode:   "unavailable",
+		},
+	}
+
+	for _, format := range []httpx.ErrorFormat{httpx.ErrorFormatJSON, httpx.ErrorFormatProblem} {
+		for _, tt := range tests {
+			t.Run(string(format)+" "+tt.name, func(t *testing.T) {
+				ts := newTestServer(t, &memService{err: tt.svcErr}, format)
+				c := New(ts.URL, WithAPIKey(tt.apiKey))
+
+				err := tt.call(context.Background(), c)
+				if errx.KindOf(err) != tt.wantKind {
+					t.Fatalf("error kind = %v, want %v (err: %v)", errx.KindOf(err), tt.wantKind, err)
+				}
+				var apiErr *Error
+				if !errors.As(err, &apiErr) {
+					t.Fatalf("error %v does not wrap *Error", err)
+				}
+				if apiErr.Status != tt.wantStatus {
+					t.Errorf("Status = %d, want %d", apiErr.Status, tt.wantStatus)
+				}
+				if tt.wantCode != "" && apiErr.Code != tt.wantCode {
+					t.Errorf("Code = %q, want %q", apiErr.Code, tt.wantCode)
+				}
+			})
+		}
+	}
+}
+
+func TestClient_TransportError(t *testing.T) {
+	ts := httptest.NewServer(http.NotFoundHandler())
+	ts.Close()
+
+	_, err := New(ts.URL).Get(context.Background(), "docs-link")
+	if errx.KindOf(err) != errx.Unavailable {
+		t.Errorf("error kind = %v, want %v (err: %v)", errx.KindOf(err), errx.Unavailable, err)
+	}
+}
@@ -0,0 +1,34 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFake(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	f := NewFake(start)
+
+	if got := f.Now(); !got.Equal(start) {
+		t.Errorf("Now() = %v, want %v", got, start)
+	}
+
+	f.Advance(90 * time.Minute)
+	if got, want := f.Now(), start.Add(90*time.Minute); !got.Equal(want) {
+		t.Errorf("after Advance, Now() = %v, want %v", got, want)
+	}
+
+	later := start.Add(48 * time.Hour)
+	f.Set(later)
+	if got := f.Now(); !got.Equal(later) {
+		t.Errorf("after Set, Now() = %v, want %v", got, later)
+	}
+}
+
+func TestReal(t *testing.T) {
+	before := time.Now()
+	got := Real.Now()
+	if got.Before(before) || got.After(time.Now()) {
+		t.Errorf("Real.Now() = %v, want the current time", got)
+	}
+}
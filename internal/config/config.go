@@ -1,7 +1,12 @@
 package config
 
 import (
+	"crypto/tls"
 	"fmt"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/kelseyhightower/envconfig"
@@ -12,9 +17,40 @@ type Config struct {
 	Server        ServerConfig
 	Database      DatabaseConfig
 	App           AppConfig
+	Shortener     ShortenerConfig
 	Observability ObservabilityConfig
 }
 
+// RedactedValue replaces secrets in Redacted copies of the configuration.
+const RedactedValue = "REDACTED"
+
+// Redacted returns a copy of c that is safe to log or serve: the database
+// password is masked and API keys are replaced by numbered placeholders
+// that keep their principals.
+func (c *Config) Redacted() *Config {
+	out := *c
+
+	if out.Database.Password != "" {
+		out.Database.Password = RedactedValue
+	}
+
+	if c.Server.APIKeys != nil {
+		keys := make([]string, 0, len(c.Server.APIKeys))
+		for key := range c.Server.APIKeys {
+			keys = append(keys, key)
+		}
+		slices.SortFunc(keys, func(a, b string) int {
+			return strings.Compare(c.Server.APIKeys[a], c.Server.APIKeys[b])
+		})
+		out.Server.APIKeys = make(map[string]string, len(keys))
+		for i, key := range keys {
+			out.Server.APIKeys[fmt.Sprintf("%s-%d", RedactedValue, i+1)] = c.Server.APIKeys[key]
+		}
+	}
+
+	return &out
+}
+
 // ServerConfig holds HTTP server configuration.
 type ServerConfig struct {
 	Port            string        `envconfig:"SERVER_PORT" required:"true"`
@@ -24,6 +60,122 @@ type ServerConfig struct {
 	WriteTimeout    time.Duration `envconfig:"SERVER_WRITE_TIMEOUT" required:"true"`
 	IdleTimeout     time.Duration `envconfig:"SERVER_IDLE_TIMEOUT" required:"true"`
 	ShutdownTimeout time.Duration `envconfig:"SERVER_SHUTDOWN_TIMEOUT" required:"true"`
+	MaxInFlight     int           `envconfig:"SERVER_MAX_IN_FLIGHT" default:"0"`  // 0 disables the limit
+	PrestopDelay    time.Duration `envconfig:"SERVER_PRESTOP_DELAY" default:"0s"` // readiness fails for this long before shutdown
+
+	// Also serve HTTP/2 over cleartext (h2c) alongside HTTP/1.1. Only for
+	// internal deployments behind a mesh or gateway that speaks h2c.
+	H2C bool `envconfig:"SERVER_H2C" default:"false"`
+
+	// Serve HTTPS with this certificate and key instead of plain HTTP.
+	TLSCertFile string `envconfig:"SERVER_TLS_CERT_FILE"`
+	TLSKeyFile  string `envconfig:"SERVER_TLS_KEY_FILE"`
+	// Oldest TLS version accepted: "1.2" or "1.3".
+	TLSMinVersion string `envconfig:"SERVER_TLS_MIN_VERSION" default:"1.2"`
+	// TLS 1.2 cipher suites by name, e.g. "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256",
+	// in preference order. Empty uses Go's secure defaults. TLS 1.3 suites
+	// are not configurable.
+	TLSCipherSuites []string `envconfig:"SERVER_TLS_CIPHER_SUITES"`
+
+	// Per (client IP, slug) limit on resolves; 0 disables it.
+	ResolveRateLimit  int           `envconfig:"RESOLVE_RATE_LIMIT" default:"0"`
+	ResolveRateWindow time.Duration `envconfig:"RESOLVE_RATE_WINDOW" default:"1m"`
+
+	// TrustedProxies lists the load balancers and proxies, as IP addresses
+	// or CIDR prefixes, whose X-Forwarded-For and X-Real-IP headers name
+	// the client. Without it the client IP is the connection's peer, which
+	// behind a proxy is the proxy for every client.
+	TrustedProxies []string `envconfig:"TRUSTED_PROXIES"`
+
+	// Leave out the public "/" and "/{slug}" routes, for internal
+	// deployments that only serve the API and health endpoints.
+	ResolveDisabled bool `envconfig:"RESOLVE_DISABLED" default:"false"`
+
+	// Maintenance mode rejects writes with 503 while resolves keep working.
+	// Paths in MaintenanceExemptPaths are matched by prefix, e.g. "/api/admin".
+	MaintenanceMode        bool     `envconfig:"MAINTENANCE_MODE" default:"false"`
+	MaintenanceMessage     string   `envconfig:"MAINTENANCE_MESSAGE"`
+	MaintenanceExemptPaths []string `envconfig:"MAINTENANCE_EXEMPT_PATHS"`
+
+	// Status for slug paths longer than the maximum slug length: 400 or 414.
+	OversizedSlugStatus int `envconfig:"OVERSIZED_SLUG_STATUS" default:"400"`
+
+	// Unknown slugs redirect here with a 302 when set; otherwise they 404.
+	NotFoundRedirectURL string `envconfig:"NOT_FOUND_REDIRECT_URL"`
+
+	// "/" redirects here with a 302 when set; otherwise it 404s.
+	RootRedirectURL string `envconfig:"ROOT_REDIRECT_URL"`
+
+	// Paths browsers request on their own; they get a bare 404 without
+	// handler warnings instead of being resolved as slugs.
+	IgnoredPaths []string `envconfig:"IGNORED_PATHS" default:"/favicon.ico,/robots.txt,/apple-touch-icon.png,/apple-touch-icon-precomposed.png"`
+
+	// Status for resolved links (301, 302, 307 or 308) and how long each
+	// redirect status may be cached, e.g. "301:24h,302:0s". A zero max age
+	// sends "no-cache"; unlisted statuses keep the handler defaults.
+	RedirectStatus      int                   `envconfig:"REDIRECT_STATUS" default:"302"`
+	RedirectCacheMaxAge map[int]time.Duration `envconfig:"REDIRECT_CACHE_MAX_AGE"`
+
+	// Serve a 1x1 image for "/{slug}.gif" and "/{slug}.png" tracking pixels.
+	ResolveBeacons bool `envconfig:"RESOLVE_BEACONS" default:"false"`
+
+	// Append a resolve request's query parameters to the destination;
+	// parameters the stored destination already has take precedence.
+	ForwardQueryParams bool `envconfig:"FORWARD_QUERY_PARAMS" default:"false"`
+
+	// Add a `Link: <short URL>; rel="shortlink"` header to create responses.
+	ShortLinkHeader bool `envconfig:"SHORT_LINK_HEADER" default:"false"`
+
+	// Header that resolve redirects echo the incoming request URI in, e.g.
+	// "X-Original-Request-URI"; empty disables it.
+	OriginalURIHeader string `envconfig:"ORIGINAL_URI_HEADER"`
+
+	// Header carrying the request ID in and out, and how missing IDs are
+	// generated: "uuid" or "traceparent" (reuse the W3C trace ID).
+	RequestIDHeader string `envconfig:"REQUEST_ID_HEADER" default:"X-Request-ID"`
+	RequestIDSource string `envconfig:"REQUEST_ID_SOURCE" default:"uuid"`
+
+	// How incoming request IDs are vetted: "none", "uuid", or "length"
+	// (printable, at most RequestIDMaxLength bytes). Rejected IDs are
+	// replaced and echoed in X-Original-Request-ID.
+	RequestIDValidation string `envconfig:"REQUEST_ID_VALIDATION" default:"none"`
+	RequestIDMaxLength  int    `envconfig:"REQUEST_ID_MAX_LENGTH" default:"128"`
+
+	// Error body format: "json" (the ErrorResponse shape) or "problem"
+	// (RFC 7807 application/problem+json with the request ID as instance).
+	ErrorFormat string `envconfig:"ERROR_FORMAT" default:"json"`
+
+	// How API paths with a trailing slash, e.g. "POST /api/links/", are
+	// handled: "strict" (404 like any unknown path), "redirect" (308 to the
+	// path without it) or "strip" (served like the path without it).
+	TrailingSlash string `envconfig:"TRAILING_SLASH" default:"strict"`
+
+	// ErrorCodes replaces error codes in error bodies, keyed by the default
+	// code, e.g. "not_found:urlshortener.not_found". Unlisted codes are kept.
+	ErrorCodes map[string]string `envconfig:"ERROR_CODES"`
+
+	// Count the errors written to clients by kind and report the totals on
+	// the readiness endpoint.
+	ErrorCounters bool `envconfig:"ERROR_COUNTERS" default:"false"`
+
+	// Indent JSON response bodies; meant for development, compact otherwise.
+	PrettyJSON bool `envconfig:"PRETTY_JSON" default:"false"`
+
+	// Requests taking longer are logged at WARN with slow=true; 0 disables.
+	SlowRequestThreshold time.Duration `envconfig:"SLOW_REQUEST_THRESHOLD" default:"0s"`
+
+	// Query parameters whose values are masked when URLs are logged.
+	LogRedactParams []string `envconfig:"LOG_REDACT_PARAMS" default:"token,access_token,sig"`
+
+	// Log a capped copy of each request body at DEBUG, with the values of
+	// LogRedactFields masked. Development only: ignored in production.
+	LogRequestBodies bool     `envconfig:"LOG_REQUEST_BODIES" default:"false"`
+	LogBodyMaxBytes  int      `envconfig:"LOG_BODY_MAX_BYTES" default:"2048"`
+	LogRedactFields  []string `envconfig:"LOG_REDACT_FIELDS" default:"password,token,secret,api_key"`
+
+	// APIKeys maps API key to principal for admin endpoints, e.g.
+	// "key1:ops,key2:support". With none set, admin endpoints reject all requests.
+	APIKeys map[string]string `envconfig:"API_KEYS"`
 }
 
 // Validate validates the server configuration.
@@ -37,6 +189,9 @@ func (c *ServerConfig) Validate() error {
 	if c.BaseURL == "" {
 		return fmt.Errorf("base URL cannot be empty")
 	}
+	if err := validateBaseURL(c.BaseURL); err != nil {
+		return err
+	}
 	if c.ReadTimeout <= 0 {
 		return fmt.Errorf("read timeout must be positive")
 	}
@@ -49,6 +204,110 @@ func (c *ServerConfig) Validate() error {
 	if c.ShutdownTimeout <= 0 {
 		return fmt.Errorf("shutdown timeout must be positive")
 	}
+	if c.NotFoundRedirectURL != "" {
+		if u, err := url.Parse(c.NotFoundRedirectURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("not found redirect URL %q must be an absolute http(s) URL", c.NotFoundRedirectURL)
+		}
+	}
+	if c.RootRedirectURL != "" {
+		if u, err := url.Parse(c.RootRedirectURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("root redirect URL %q must be an absolute http(s) URL", c.RootRedirectURL)
+		}
+	}
+	for _, p := range c.IgnoredPaths {
+		if !strings.HasPrefix(p, "/") || p == "/" {
+			return fmt.Errorf("invalid ignored path: %q (must start with / and name a resource)", p)
+		}
+	}
+	validRedirects := map[int]bool{301: true, 302: true, 307: true, 308: true}
+	if !validRedirects[c.RedirectStatus] {
+		return fmt.Errorf("invalid redirect status: %d (must be one of: 301, 302, 307, 308)", c.RedirectStatus)
+	}
+	for status, maxAge := range c.RedirectCacheMaxAge {
+		if !validRedirects[status] {
+			return fmt.Errorf("invalid redirect cache status: %d (must be one of: 301, 302, 307, 308)", status)
+		}
+		if maxAge < 0 {
+			return fmt.Errorf("redirect cache max age for %d cannot be negative", status)
+		}
+	}
+	if c.OversizedSlugStatus != 400 && c.OversizedSlugStatus != 414 {
+		return fmt.Errorf("oversized slug status must be 400 or 414, got %d", c.OversizedSlugStatus)
+	}
+	if c.PrestopDelay < 0 {
+		return fmt.Errorf("prestop delay cannot be negative")
+	}
+	if (c.TLSCertFile == "") != (c.TLSKeyFile == "") {
+		return fmt.Errorf("TLS certificate and key files must be set together")
+	}
+	if c.TLSEnabled() && c.H2C {
+		return fmt.Errorf("h2c cannot be combined with TLS")
+	}
+	if _, err := c.TLSConfig(); err != nil {
+		return err
+	}
+	if c.MaxInFlight < 0 {
+		return fmt.Errorf("max in-flight requests cannot be negative")
+	}
+	if c.ResolveRateLimit < 0 {
+		return fmt.Errorf("resolve rate limit cannot be negative")
+	}
+	if c.ResolveRateLimit > 0 && c.ResolveRateWindow <= 0 {
+		return fmt.Errorf("resolve rate window must be positive when rate limiting is enabled")
+	}
+	if strings.TrimSpace(c.RequestIDHeader) == "" {
+		return fmt.Errorf("request ID header cannot be empty")
+	}
+	if c.RequestIDSource != "uuid" && c.RequestIDSource != "traceparent" {
+		return fmt.Errorf("invalid request ID source: %s (must be one of: uuid, traceparent)", c.RequestIDSource)
+	}
+	validValidations := map[string]bool{"none": true, "uuid": true, "length": true}
+	if !validValidations[c.RequestIDValidation] {
+		return fmt.Errorf("invalid request ID validation: %s (must be one of: none, uuid, length)", c.RequestIDValidation)
+	}
+	if c.RequestIDMaxLength <= 0 {
+		return fmt.Errorf("request ID max length must be positive")
+	}
+	if c.ErrorFormat != "json" && c.ErrorFormat != "problem" {
+		return fmt.Errorf("invalid error format: %s (must be one of: json, problem)", c.ErrorFormat)
+	}
+	if c.SlowRequestThreshold < 0 {
+		return fmt.Errorf("slow request threshold cannot be negative")
+	}
+	if c.LogBodyMaxBytes <= 0 {
+		return fmt.Errorf("log body max bytes must be positive, got %d", c.LogBodyMaxBytes)
+	}
+	if c.TrailingSlash != "strict" && c.TrailingSlash != "redirect" && c.TrailingSlash != "strip" {
+		return fmt.Errorf("invalid trailing slash policy: %s (must be one of: strict, redirect, strip)", c.TrailingSlash)
+	}
+	for key, principal := range c.APIKeys {
+		if key == "" || principal == "" {
+			return fmt.Errorf("API keys must be non-empty key:principal pairs")
+		}
+	}
+	return nil
+}
+
+// validateBaseURL ensures short URLs built as BaseURL + "/" + slug are
+// well-formed: an absolute http(s) URL with a host and no trailing slash,
+// query, or fragment.
+func validateBaseURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("invalid base URL %q: %w", raw, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("base URL %q must start with http:// or https://", raw)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("base URL %q must include a host", raw)
+	}
+	if strings.HasSuffix(raw, "/") {
+		return fmt.Errorf("base URL %q must not end with a slash", raw)
+	}
+	if u.RawQuery != "" || u.Fragment != "" {
+		return fmt.Errorf("base URL %q must not include a query or fragment", raw)
+	}
 	return nil
 }
 
@@ -62,6 +321,27 @@ type DatabaseConfig struct {
 	SSLMode  string `envconfig:"DB_SSLMODE" required:"true"`
 	MaxConns int32  `envconfig:"DB_MAX_CONNS" required:"true"`
 	MinConns int32  `envconfig:"DB_MIN_CONNS" required:"true"`
+
+	// Connections are closed and replaced once they reach MaxConnLifetime
+	// or sit idle for MaxConnIdleTime, so load balancers that silently drop
+	// long-lived or idle TCP connections never hand the pool a dead one.
+	MaxConnLifetime time.Duration `envconfig:"DB_MAX_CONN_LIFETIME" default:"1h"`
+	MaxConnIdleTime time.Duration `envconfig:"DB_MAX_CONN_IDLE_TIME" default:"30m"`
+
+	// Background pool health monitor (optional).
+	HealthCheckEnabled  bool          `envconfig:"DB_HEALTH_CHECK_ENABLED" default:"false"`
+	HealthCheckInterval time.Duration `envconfig:"DB_HEALTH_CHECK_INTERVAL" default:"30s"`
+
+	// Circuit breaker: fail fast after this many consecutive query failures
+	// (0 disables it), probing again after the cooldown.
+	BreakerThreshold int           `envconfig:"DB_BREAKER_THRESHOLD" default:"0"`
+	BreakerCooldown  time.Duration `envconfig:"DB_BREAKER_COOLDOWN" default:"30s"`
+
+	// QueryTimeout bounds every query; 0 disables the limit.
+	QueryTimeout time.Duration `envconfig:"DB_QUERY_TIMEOUT" default:"0s"`
+
+	// Apply pending embedded migrations at startup, before serving.
+	AutoMigrate bool `envconfig:"DB_AUTO_MIGRATE" default:"false"`
 }
 
 // Validate validates the database configuration.
@@ -90,6 +370,24 @@ func (c *DatabaseConfig) Validate() error {
 	if c.MinConns > c.MaxConns {
 		return fmt.Errorf("min connections (%d) cannot be greater than max connections (%d)", c.MinConns, c.MaxConns)
 	}
+	if c.MaxConnLifetime <= 0 {
+		return fmt.Errorf("max connection lifetime must be positive")
+	}
+	if c.MaxConnIdleTime <= 0 {
+		return fmt.Errorf("max connection idle time must be positive")
+	}
+	if c.HealthCheckEnabled && c.HealthCheckInterval <= 0 {
+		return fmt.Errorf("health check interval must be positive when health check is enabled")
+	}
+	if c.BreakerThreshold < 0 {
+		return fmt.Errorf("breaker threshold cannot be negative")
+	}
+	if c.BreakerThreshold > 0 && c.BreakerCooldown <= 0 {
+		return fmt.Errorf("breaker cooldown must be positive when the breaker is enabled")
+	}
+	if c.QueryTimeout < 0 {
+		return fmt.Errorf("query timeout cannot be negative")
+	}
 
 	validSSLModes := map[string]bool{
 		"disable":     true,
@@ -103,6 +401,47 @@ func (c *DatabaseConfig) Validate() error {
 	return nil
 }
 
+// TLSEnabled reports whether the server is configured to serve HTTPS.
+func (c *ServerConfig) TLSEnabled() bool {
+	return c.TLSCertFile != "" && c.TLSKeyFile != ""
+}
+
+// tlsVersions maps the accepted TLSMinVersion values. Older versions are
+// deliberately absent.
+var tlsVersions = map[string]uint16{
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// TLSConfig returns the TLS settings the server applies: TLSMinVersion and
+// TLSCipherSuites. It fails on unknown versions and on suites Go considers
+// insecure or that only exist in TLS 1.3.
+func (c *ServerConfig) TLSConfig() (*tls.Config, error) {
+	version, ok := tlsVersions[c.TLSMinVersion]
+	if !ok {
+		return nil, fmt.Errorf("invalid TLS min version: %s (must be one of: 1.2, 1.3)", c.TLSMinVersion)
+	}
+
+	secure := make(map[string]*tls.CipherSuite)
+	for _, s := range tls.CipherSuites() {
+		secure[s.Name] = s
+	}
+
+	var suites []uint16
+	for _, name := range c.TLSCipherSuites {
+		s, ok := secure[name]
+		if !ok {
+			return nil, fmt.Errorf("invalid TLS cipher suite: %s (unknown or insecure)", name)
+		}
+		if !slices.Contains(s.SupportedVersions, tls.VersionTLS12) {
+			return nil, fmt.Errorf("invalid TLS cipher suite: %s (TLS 1.3 suites are not configurable)", name)
+		}
+		suites = append(suites, s.ID)
+	}
+
+	return &tls.Config{MinVersion: version, CipherSuites: suites}, nil
+}
+
 // ConnectionString returns the PostgreSQL connection string.
 func (c *DatabaseConfig) ConnectionString() string {
 	return fmt.Sprintf(
@@ -113,8 +452,19 @@ func (c *DatabaseConfig) ConnectionString() string {
 
 // AppConfig holds application-specific configuration.
 type AppConfig struct {
-	Environment string `envconfig:"APP_ENV" required:"true"`   // development, staging, production, test
-	LogLevel    string `envconfig:"LOG_LEVEL" required:"true"` // debug, info, warn, error
+	Environment string `envconfig:"APP_ENV" required:"true"`     // development, staging, production, test
+	LogLevel    string `envconfig:"LOG_LEVEL" required:"true"`   // debug, info, warn, error
+	LogFormat   string `envconfig:"LOG_FORMAT" default:"json"`   // json, text
+	LogOutput   string `envconfig:"LOG_OUTPUT" default:"stdout"` // stdout, stderr
+
+	// ReadyTimeout bounds how long startup waits for dependencies to pass
+	// their health checks before giving up.
+	ReadyTimeout time.Duration `envconfig:"STARTUP_READY_TIMEOUT" default:"30s"`
+	// SelfCheckSlugKeys adds a scan of every stored slug and alias key to
+	// the startup self-check, confirming they match SLUG_CASE_INSENSITIVE.
+	// Off by default: it reads both tables in full. Enable it for the
+	// rollout that switches the case mode.
+	SelfCheckSlugKeys bool `envconfig:"SELF_CHECK_SLUG_KEYS" default:"false"`
 }
 
 // Validate validates the app configuration.
@@ -138,9 +488,321 @@ func (c *AppConfig) Validate() error {
 	if !validLogLevels[c.LogLevel] {
 		return fmt.Errorf("invalid log level: %s (must be one of: debug, info, warn, error)", c.LogLevel)
 	}
+
+	if c.LogFormat != "json" && c.LogFormat != "text" {
+		return fmt.Errorf("invalid log format: %s (must be one of: json, text)", c.LogFormat)
+	}
+	if c.LogOutput != "stdout" && c.LogOutput != "stderr" {
+		return fmt.Errorf("invalid log output: %s (must be one of: stdout, stderr)", c.LogOutput)
+	}
+	if c.ReadyTimeout <= 0 {
+		return fmt.Errorf("startup ready timeout must be positive, got %s", c.ReadyTimeout)
+	}
+	return nil
+}
+
+// ShortenerConfig holds link shortening behavior configuration.
+type ShortenerConfig struct {
+	// SlugLengthThresholds maps a minimum link count to the generated slug
+	// length used once that count is reached, e.g. "100000:8,10000000:9".
+	SlugLengthThresholds map[int64]int `envconfig:"SLUG_LENGTH_THRESHOLDS"`
+	SlugLengthCacheTTL   time.Duration `envconfig:"SLUG_LENGTH_CACHE_TTL" default:"1m"`
+	// SlugAutoLength picks the generated slug length from the link count,
+	// as the shortest keeping the chance of a collision below
+	// SlugCollisionTarget, instead of using SlugLengthThresholds.
+	SlugAutoLength      bool    `envconfig:"SLUG_AUTO_LENGTH" default:"false"`
+	SlugCollisionTarget float64 `envconfig:"SLUG_COLLISION_TARGET" default:"0.001"`
+	// MinCustomSlugLength is the shortest custom slug accepted. It may not go
+	// below the links_slug_length check constraint.
+	MinCustomSlugLength int `envconfig:"SLUG_MIN_LENGTH" default:"7"`
+	// MaxCustomSlugLength is the longest custom slug accepted, up to 128 to
+	// allow vanity slugs longer than generated ones.
+	MaxCustomSlugLength int `envconfig:"SLUG_MAX_CUSTOM_LENGTH" default:"64"`
+	// MaxGeneratedSlugLength caps generated slugs, including the lengths in
+	// SlugLengthThresholds.
+	MaxGeneratedSlugLength int `envconfig:"SLUG_MAX_GENERATED_LENGTH" default:"64"`
+	// SlugCharset is the character policy for custom slugs:
+	// "alphanum_dash_underscore" or "alphanum_dash_underscore_dot".
+	SlugCharset string `envconfig:"SLUG_CHARSET" default:"alphanum_dash_underscore"`
+	// SlugNoLeadingDigit keeps custom and generated slugs from starting
+	// with a digit, so they can't be confused with numeric IDs.
+	SlugNoLeadingDigit bool `envconfig:"SLUG_NO_LEADING_DIGIT" default:"false"`
+	// SlugEncoding is the alphabet for generated slugs: "base62", "base32"
+	// (lowercase, case-insensitive) or "base58" (no look-alike characters).
+	SlugEncoding string `envconfig:"SLUG_ENCODING" default:"base62"`
+	// SlugCaseInsensitive makes slugs differing only in case collide and
+	// resolve to the same link, keeping the case they were created with for
+	// display. Requires the base32 encoding so generated slugs stay
+	// lowercase; switching it on an existing database needs slug keys
+	// rewritten first (see SelfCheckSlugKeys).
+	SlugCaseInsensitive bool `envconfig:"SLUG_CASE_INSENSITIVE" default:"false"`
+	// TrackUniqueVisitors stores a hashed daily fingerprint per visitor to
+	// count unique clicks. Off by default for privacy and write volume.
+	TrackUniqueVisitors bool `envconfig:"TRACK_UNIQUE_VISITORS" default:"false"`
+	// RecordClickEvents stores one row per resolution to back the link time
+	// series endpoint. Off by default for write volume.
+	RecordClickEvents bool `envconfig:"RECORD_CLICK_EVENTS" default:"false"`
+	// RecordClickRequestIDs stores the request ID with each click event to
+	// correlate analytics with logs. Only applies with RecordClickEvents.
+	RecordClickRequestIDs bool `envconfig:"RECORD_CLICK_REQUEST_IDS" default:"false"`
+	// TrackingAsync moves unique visitor and click event writes off the
+	// redirect path onto background workers with a TrackingBufferSize
+	// buffer. TrackingOverflowPolicy decides what happens when it is full:
+	// "block" (wait up to TrackingBlockTimeout, then drop), "drop", or
+	// "sync-fallback" (track inline).
+	TrackingAsync          bool          `envconfig:"TRACKING_ASYNC" default:"false"`
+	TrackingBufferSize     int           `envconfig:"TRACKING_BUFFER_SIZE" default:"1024"`
+	TrackingOverflowPolicy string        `envconfig:"TRACKING_OVERFLOW_POLICY" default:"block"`
+	TrackingBlockTimeout   time.Duration `envconfig:"TRACKING_BLOCK_TIMEOUT" default:"50ms"`
+	// GeoIPLocationsFile and GeoIPBlocksFiles are MaxMind GeoLite2/GeoIP2
+	// Country CSVs. When set, click events are tagged with the client's
+	// country. Only applies with RecordClickEvents, and requires
+	// TrackingAsync so lookups run on the bounded tracking workers.
+	GeoIPLocationsFile string   `envconfig:"GEOIP_LOCATIONS_FILE"`
+	GeoIPBlocksFiles   []string `envconfig:"GEOIP_BLOCKS_FILES"`
+	// RecordCreators stores the IP address and user agent of each link's
+	// creator for abuse investigation. Off by default: it is personal data.
+	RecordCreators bool `envconfig:"RECORD_CREATORS" default:"false"`
+	// SlugPrefixes maps an API key principal to the namespace its slugs are
+	// created under, e.g. "acme:acme,globex:gx".
+	SlugPrefixes map[string]string `envconfig:"SLUG_PREFIXES"`
+	// BatchDuplicateSlugPolicy decides what happens when two rows of a batch
+	// create request the same custom slug: "fail", "skip" or "suffix".
+	BatchDuplicateSlugPolicy string `envconfig:"BATCH_DUPLICATE_SLUG_POLICY" default:"fail"`
+	// BatchMaxItems caps the rows of one batch request. Request bodies may
+	// be up to 16 KiB per row.
+	BatchMaxItems int `envconfig:"BATCH_MAX_ITEMS" default:"100"`
+	// ListMaxLimit caps the page size of link listings; larger requested
+	// limits are clamped to it.
+	ListMaxLimit int `envconfig:"LIST_MAX_LIMIT" default:"100"`
+	// Send a HEAD request to each destination created by a batch import
+	// and flag unreachable ones in the response. Off by default: it makes
+	// outbound requests to user-supplied URLs (non-public addresses are
+	// refused) and adds up to the timeout to each batch.
+	BatchCheckReachability   bool          `envconfig:"BATCH_CHECK_REACHABILITY" default:"false"`
+	BatchReachabilityTimeout time.Duration `envconfig:"BATCH_REACHABILITY_TIMEOUT" default:"3s"`
+	// MaxLinksPerOwner caps the live links each API key principal may
+	// create; 0 means unlimited.
+	MaxLinksPerOwner int `envconfig:"MAX_LINKS_PER_OWNER" default:"0"`
+	// NotFoundCacheTTL, when positive, caches failed resolves for that long
+	// so repeated probes of unknown slugs skip the database. Other replicas
+	// only see a new slug once their entry expires; keep it to seconds.
+	NotFoundCacheTTL  time.Duration `envconfig:"NOT_FOUND_CACHE_TTL" default:"0s"`
+	NotFoundCacheSize int           `envconfig:"NOT_FOUND_CACHE_SIZE" default:"10000"`
+	// IdempotentCreate answers a create repeating an existing link's custom
+	// slug, URL and owner with that link (200) instead of a conflict (409).
+	IdempotentCreate bool `envconfig:"IDEMPOTENT_CREATE" default:"false"`
+	// PreserveURLFragments keeps "#fragment" on stored destinations, so
+	// redirects carry it; by default it is stripped on create.
+	PreserveURLFragments bool `envconfig:"PRESERVE_URL_FRAGMENTS" default:"false"`
+
+	// Destination hosts that may not be shortened: "example.com" blocks that
+	// host, "*.example.com" its subdomains. BlockedDomainsFile adds one
+	// entry per line; blank lines and "#" comments are skipped.
+	BlockedDomains     []string `envconfig:"BLOCKED_DOMAINS"`
+	BlockedDomainsFile string   `envconfig:"BLOCKED_DOMAINS_FILE"`
+
+	// With AllowListMode on, only destinations matching AllowedDomains (same
+	// patterns and file format as above) may be shortened, and the blocked
+	// list is not consulted.
+	AllowListMode      bool     `envconfig:"ALLOW_LIST_MODE" default:"false"`
+	AllowedDomains     []string `envconfig:"ALLOWED_DOMAINS"`
+	AllowedDomainsFile string   `envconfig:"ALLOWED_DOMAINS_FILE"`
+
+	// Append every link create and delete to the audit_log table.
+	AuditLogEnabled bool `envconfig:"AUDIT_LOG_ENABLED" default:"false"`
+
+	// Background purge of expired and soft-deleted links.
+	PurgeEnabled          bool          `envconfig:"PURGE_ENABLED" default:"false"`
+	PurgeInterval         time.Duration `envconfig:"PURGE_INTERVAL" default:"1h"`
+	PurgeExpiredGrace     time.Duration `envconfig:"PURGE_EXPIRED_GRACE" default:"24h"`
+	PurgeDeletedRetention time.Duration `envconfig:"PURGE_DELETED_RETENTION" default:"720h"`
+	PurgeBatchSize        int           `envconfig:"PURGE_BATCH_SIZE" default:"500"`
+
+	// Keyspace check at startup and every KeyspaceCheckInterval: warn once
+	// stored links fill KeyspaceWarnFraction of the generated slug keyspace.
+	KeyspaceCheckInterval time.Duration `envconfig:"KEYSPACE_CHECK_INTERVAL" default:"1h"`
+	KeyspaceWarnFraction  float64       `envconfig:"KEYSPACE_WARN_FRACTION" default:"0.01"`
+
+	// Expired links keep redirecting for LinkExpiryGrace, flagged with an
+	// X-Link-Expired header, to ease migrations; 0 cuts them off at expiry.
+	// PurgeExpiredGrace may not be shorter when purging is enabled.
+	LinkExpiryGrace time.Duration `envconfig:"LINK_EXPIRY_GRACE" default:"0s"`
+
+	// Links expire DefaultLinkTTL after creation, for ephemeral-link
+	// deployments; 0 creates links that never expire.
+	DefaultLinkTTL time.Duration `envconfig:"DEFAULT_LINK_TTL" default:"0s"`
+
+	// Warn when a link's access count reaches AccessCountThreshold, a sign
+	// of bot traffic, and with AccessCountAutoPause also pause it; 0 disables.
+	AccessCountThreshold int64 `envconfig:"ACCESS_COUNT_THRESHOLD" default:"0"`
+	AccessCountAutoPause bool  `envconfig:"ACCESS_COUNT_AUTO_PAUSE" default:"false"`
+}
+
+// Validate validates the shortener configuration.
+func (c *ShortenerConfig) Validate() error {
+	if c.MaxGeneratedSlugLength < 7 || c.MaxGeneratedSlugLength > 64 {
+		return fmt.Errorf("maximum generated slug length must be between 7 and 64, got %d", c.MaxGeneratedSlugLength)
+	}
+	for minLinks, length := range c.SlugLengthThresholds {
+		if minLinks < 0 {
+			return fmt.Errorf("slug length threshold must be non-negative, got %d", minLinks)
+		}
+		if length < 7 || length > c.MaxGeneratedSlugLength {
+			return fmt.Errorf("slug length for threshold %d must be between 7 and %d, got %d",
+				minLinks, c.MaxGeneratedSlugLength, length)
+		}
+	}
+	if c.SlugLengthCacheTTL <= 0 {
+		return fmt.Errorf("slug length cache TTL must be positive")
+	}
+	if c.SlugCollisionTarget <= 0 || c.SlugCollisionTarget >= 1 {
+		return fmt.Errorf("slug collision target must be in (0, 1), got %g", c.SlugCollisionTarget)
+	}
+	if c.MinCustomSlugLength < 7 || c.MinCustomSlugLength > 64 {
+		return fmt.Errorf("minimum slug length must be between 7 and 64, got %d", c.MinCustomSlugLength)
+	}
+	// Bounds match the links_slug_length check constraint.
+	if c.MaxCustomSlugLength < c.MinCustomSlugLength || c.MaxCustomSlugLength > 128 {
+		return fmt.Errorf("maximum custom slug length must be between %d and 128, got %d",
+			c.MinCustomSlugLength, c.MaxCustomSlugLength)
+	}
+	validCharsets := map[string]bool{
+		"alphanum_dash_underscore":     true,
+		"alphanum_dash_underscore_dot": true,
+	}
+	if !validCharsets[c.SlugCharset] {
+		return fmt.Errorf("invalid slug charset: %s (must be one of: alphanum_dash_underscore, alphanum_dash_underscore_dot)", c.SlugCharset)
+	}
+	if c.MaxLinksPerOwner < 0 {
+		return fmt.Errorf("max links per owner cannot be negative")
+	}
+	if c.NotFoundCacheTTL < 0 {
+		return fmt.Errorf("not-found cache TTL cannot be negative")
+	}
+	if c.NotFoundCacheTTL > 0 && c.NotFoundCacheSize <= 0 {
+		return fmt.Errorf("not-found cache size must be positive when the cache is enabled, got %d", c.NotFoundCacheSize)
+	}
+	validEncodings := map[string]bool{
+		"base62": true,
+		"base32": true,
+		"base58": true,
+	}
+	if !validEncodings[c.SlugEncoding] {
+		return fmt.Errorf("invalid slug encoding: %s (must be one of: base62, base32, base58)", c.SlugEncoding)
+	}
+	if c.SlugCaseInsensitive && c.SlugEncoding != "base32" {
+		return fmt.Errorf("case-insensitive slugs require the base32 slug encoding, got %s", c.SlugEncoding)
+	}
+	if (c.GeoIPLocationsFile == "") != (len(c.GeoIPBlocksFiles) == 0) {
+		return fmt.Errorf("GeoIP locations and blocks files must be set together")
+	}
+	if c.GeoIPLocationsFile != "" && !c.TrackingAsync {
+		return fmt.Errorf("GeoIP lookups require async tracking")
+	}
+	if c.BatchMaxItems <= 0 {
+		return fmt.Errorf("batch max items must be positive, got %d", c.BatchMaxItems)
+	}
+	if c.ListMaxLimit <= 0 {
+		return fmt.Errorf("list max limit must be positive, got %d", c.ListMaxLimit)
+	}
+	if c.BatchCheckReachability && c.BatchReachabilityTimeout <= 0 {
+		return fmt.Errorf("batch reachability timeout must be positive when the check is enabled")
+	}
+	validPolicies := map[string]bool{
+		"fail":   true,
+		"skip":   true,
+		"suffix": true,
+	}
+	if !validPolicies[c.BatchDuplicateSlugPolicy] {
+		return fmt.Errorf("invalid batch duplicate slug policy: %s (must be one of: fail, skip, suffix)", c.BatchDuplicateSlugPolicy)
+	}
+	validOverflowPolicies := map[string]bool{
+		"block":         true,
+		"drop":          true,
+		"sync-fallback": true,
+	}
+	if !validOverflowPolicies[c.TrackingOverflowPolicy] {
+		return fmt.Errorf("invalid tracking overflow policy: %s (must be one of: block, drop, sync-fallback)", c.TrackingOverflowPolicy)
+	}
+	if c.TrackingAsync && c.TrackingBufferSize <= 0 {
+		return fmt.Errorf("tracking buffer size must be positive, got %d", c.TrackingBufferSize)
+	}
+	if c.TrackingAsync && c.TrackingBlockTimeout <= 0 {
+		return fmt.Errorf("tracking block timeout must be positive")
+	}
+	for _, d := range c.BlockedDomains {
+		if !validDomainPattern(d) {
+			return fmt.Errorf("invalid blocked domain %q (want example.com or *.example.com)", d)
+		}
+	}
+	for _, d := range c.AllowedDomains {
+		if !validDomainPattern(d) {
+			return fmt.Errorf("invalid allowed domain %q (want example.com or *.example.com)", d)
+		}
+	}
+	if c.AllowListMode && len(c.AllowedDomains) == 0 {
+		return fmt.Errorf("allow list mode requires at least one allowed domain")
+	}
+	for principal, prefix := range c.SlugPrefixes {
+		if !validSlugPrefix(prefix) {
+			return fmt.Errorf("slug prefix for %q must be 1 to 16 alphanumeric characters, got %q", principal, prefix)
+		}
+	}
+	if c.PurgeEnabled {
+		if c.PurgeInterval <= 0 {
+			return fmt.Errorf("purge interval must be positive when purge is enabled")
+		}
+		if c.PurgeExpiredGrace <= 0 {
+			return fmt.Errorf("purge expired grace must be positive when purge is enabled")
+		}
+		if c.PurgeDeletedRetention <= 0 {
+			return fmt.Errorf("purge deleted retention must be positive when purge is enabled")
+		}
+		if c.PurgeBatchSize <= 0 {
+			return fmt.Errorf("purge batch size must be positive, got %d", c.PurgeBatchSize)
+		}
+	}
+	if c.KeyspaceCheckInterval <= 0 {
+		return fmt.Errorf("keyspace check interval must be positive")
+	}
+	if c.KeyspaceWarnFraction <= 0 || c.KeyspaceWarnFraction > 1 {
+		return fmt.Errorf("keyspace warn fraction must be in (0, 1], got %g", c.KeyspaceWarnFraction)
+	}
+	if c.LinkExpiryGrace < 0 {
+		return fmt.Errorf("link expiry grace cannot be negative, got %s", c.LinkExpiryGrace)
+	}
+	// Purging a link inside its grace window would cut its redirect short.
+	if c.PurgeEnabled && c.PurgeExpiredGrace < c.LinkExpiryGrace {
+		return fmt.Errorf("purge expired grace (%s) must be at least the link expiry grace (%s)",
+			c.PurgeExpiredGrace, c.LinkExpiryGrace)
+	}
+	if c.DefaultLinkTTL < 0 {
+		return fmt.Errorf("default link TTL cannot be negative, got %s", c.DefaultLinkTTL)
+	}
+	if c.AccessCountThreshold < 0 {
+		return fmt.Errorf("access count threshold cannot be negative, got %d", c.AccessCountThreshold)
+	}
+	if c.AccessCountAutoPause && c.AccessCountThreshold == 0 {
+		return fmt.Errorf("access count auto-pause requires an access count threshold")
+	}
 	return nil
 }
 
+// validSlugPrefix mirrors the shortener's prefix rules: alphanumeric so the
+// dash separator is unambiguous, and short enough to leave room for a slug.
+func validSlugPrefix(prefix string) bool {
+	if prefix == "" || len(prefix) > 16 {
+		return false
+	}
+	for _, c := range prefix {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
 // ObservabilityConfig holds configuration for tracing/metrics.
 type ObservabilityConfig struct {
 	Enabled           bool    `envconfig:"OTEL_ENABLED" required:"true"`
@@ -199,6 +861,27 @@ func Load() (*Config, error) {
 		return nil, fmt.Errorf("invalid App config: %w", err)
 	}
 
+	if err := envconfig.Process("", &cfg.Shortener); err != nil {
+		return nil, fmt.Errorf("failed to load Shortener config: %w", err)
+	}
+	if path := cfg.Shortener.BlockedDomainsFile; path != "" {
+		domains, err := readDomainFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load blocked domains: %w", err)
+		}
+		cfg.Shortener.BlockedDomains = append(cfg.Shortener.BlockedDomains, domains...)
+	}
+	if path := cfg.Shortener.AllowedDomainsFile; path != "" {
+		domains, err := readDomainFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load allowed domains: %w", err)
+		}
+		cfg.Shortener.AllowedDomains = append(cfg.Shortener.AllowedDomains, domains...)
+	}
+	if err := cfg.Shortener.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid Shortener config: %w", err)
+	}
+
 	if err := envconfig.Process("", &cfg.Observability); err != nil {
 		return nil, fmt.Errorf("failed to load Observability config: %w", err)
 	}
@@ -208,3 +891,36 @@ func Load() (*Config, error) {
 
 	return cfg, nil
 }
+
+// readDomainFile reads one domain pattern per line, skipping blank lines and
+// "#" comments.
+func readDomainFile(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var domains []string
+	for line := range strings.Lines(string(data)) {
+		line, _, _ = strings.Cut(line, "#")
+		if line = strings.TrimSpace(line); line != "" {
+			domains = append(domains, line)
+		}
+	}
+	return domains, nil
+}
+
+// validDomainPattern accepts a host name, optionally prefixed with "*." to
+// match its subdomains.
+func validDomainPattern(d string) bool {
+	d = strings.TrimPrefix(d, "*.")
+	if d == "" || len(d) > 253 {
+		return false
+	}
+	for _, c := range d {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '.') {
+			return false
+		}
+	}
+	return true
+}
@@ -1,7 +1,14 @@
 package config
 
 import (
+	"crypto/tls"
+	"maps"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 )
@@ -275,3 +282,1462 @@ func TestLoad_DurationParsing_WhenOTelDisabled_DoesNotRequireOTelFields(t *testi
 		t.Errorf("Observability.Enabled = true, want false")
 	}
 }
+
+// validEnv returns a complete set of environment variables that Load accepts.
+func validEnv() map[string]string {
+	return map[string]string{
+		"SERVER_PORT":             "8080",
+		"SERVER_HOST":             "0.0.0.0",
+		"SERVER_BASE_URL":         "http://localhost:8080",
+		"SERVER_READ_TIMEOUT":     "10s",
+		"SERVER_WRITE_TIMEOUT":    "10s",
+		"SERVER_IDLE_TIMEOUT":     "120s",
+		"SERVER_SHUTDOWN_TIMEOUT": "30s",
+
+		"DB_HOST":      "localhost",
+		"DB_PORT":      "5432",
+		"DB_USER":      "testuser",
+		"DB_PASSWORD":  "testpass",
+		"DB_NAME":      "testdb",
+		"DB_SSLMODE":   "disable",
+		"DB_MAX_CONNS": "25",
+		"DB_MIN_CONNS": "5",
+
+		"APP_ENV":   "test",
+		"LOG_LEVEL": "debug",
+
+		"OTEL_ENABLED": "false",
+	}
+}
+
+// setEnv applies env for the duration of the test.
+func setEnv(t *testing.T, env map[string]string) {
+	t.Helper()
+	for key, value := range env {
+		t.Setenv(key, value)
+	}
+}
+
+func TestLoad_DBHealthCheck(t *testing.T) {
+	t.Run("defaults when unset", func(t *testing.T) {
+		setEnv(t, validEnv())
+
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		if cfg.Database.HealthCheckEnabled {
+			t.Error("Database.HealthCheckEnabled = true, want false")
+		}
+		if cfg.Database.HealthCheckInterval != 30*time.Second {
+			t.Errorf("Database.HealthCheckInterval = %v, want 30s", cfg.Database.HealthCheckInterval)
+		}
+	})
+
+	t.Run("parses configured values", func(t *testing.T) {
+		env := validEnv()
+		env["DB_HEALTH_CHECK_ENABLED"] = "true"
+		env["DB_HEALTH_CHECK_INTERVAL"] = "15s"
+		setEnv(t, env)
+
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		if !cfg.Database.HealthCheckEnabled {
+			t.Error("Database.HealthCheckEnabled = false, want true")
+		}
+		if cfg.Database.HealthCheckInterval != 15*time.Second {
+			t.Errorf("Database.HealthCheckInterval = %v, want 15s", cfg.Database.HealthCheckInterval)
+		}
+	})
+
+	t.Run("rejects non-positive interval when enabled", func(t *testing.T) {
+		env := validEnv()
+		env["DB_HEALTH_CHECK_ENABLED"] = "true"
+		env["DB_HEALTH_CHECK_INTERVAL"] = "0s"
+		setEnv(t, env)
+
+		if _, err := Load(); err == nil {
+			t.Error("Load() should fail with a zero health check interval")
+		}
+	})
+}
+
+func TestLoad_DBBreaker(t *testing.T) {
+	t.Run("disabled by default", func(t *testing.T) {
+		setEnv(t, validEnv())
+
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		if cfg.Database.BreakerThreshold != 0 {
+			t.Errorf("Database.BreakerThreshold = %d, want 0", cfg.Database.BreakerThreshold)
+		}
+		if cfg.Database.BreakerCooldown != 30*time.Second {
+			t.Errorf("Database.BreakerCooldown = %v, want 30s", cfg.Database.BreakerCooldown)
+		}
+	})
+
+	t.Run("rejects negative threshold", func(t *testing.T) {
+		env := validEnv()
+		env["DB_BREAKER_THRESHOLD"] = "-1"
+		setEnv(t, env)
+
+		if _, err := Load(); err == nil {
+			t.Error("Load() should fail with a negative breaker threshold")
+		}
+	})
+}
+
+func TestLoad_DBQueryTimeout(t *testing.T) {
+	t.Run("disabled by default", func(t *testing.T) {
+		setEnv(t, validEnv())
+
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		if cfg.Database.QueryTimeout != 0 {
+			t.Errorf("Database.QueryTimeout = %v, want 0", cfg.Database.QueryTimeout)
+		}
+	})
+
+	t.Run("parses duration", func(t *testing.T) {
+		env := validEnv()
+		env["DB_QUERY_TIMEOUT"] = "2s"
+		setEnv(t, env)
+
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		if cfg.Database.QueryTimeout != 2*time.Second {
+			t.Errorf("Database.QueryTimeout = %v, want 2s", cfg.Database.QueryTimeout)
+		}
+	})
+
+	t.Run("rejects negative timeout", func(t *testing.T) {
+		env := validEnv()
+		env["DB_QUERY_TIMEOUT"] = "-1s"
+		setEnv(t, env)
+
+		if _, err := Load(); err == nil {
+			t.Error("Load() should fail with a negative query timeout")
+		}
+	})
+}
+
+func TestLoad_LogFormatAndOutput(t *testing.T) {
+	setEnv(t, validEnv())
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if cfg.App.LogFormat != "json" || cfg.App.LogOutput != "stdout" {
+		t.Errorf("LogFormat, LogOutput = %q, %q, want json, stdout by default", cfg.App.LogFormat, cfg.App.LogOutput)
+	}
+
+	tests := []struct {
+		name    string
+		format  string
+		output  string
+		wantErr bool
+	}{
+		{"text to stderr", "text", "stderr", false},
+		{"json to stdout", "json", "stdout", false},
+		{"unknown format", "logfmt", "stdout", true},
+		{"unknown output", "json", "file", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := validEnv()
+			env["LOG_FORMAT"] = tt.format
+			env["LOG_OUTPUT"] = tt.output
+			setEnv(t, env)
+
+			_, err := Load()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Load() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoad_DBAutoMigrate(t *testing.T) {
+	setEnv(t, validEnv())
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if cfg.Database.AutoMigrate {
+		t.Error("Database.AutoMigrate = true, want false by default")
+	}
+
+	env := validEnv()
+	env["DB_AUTO_MIGRATE"] = "true"
+	setEnv(t, env)
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if !cfg.Database.AutoMigrate {
+		t.Error("Database.AutoMigrate = false, want true")
+	}
+}
+
+func TestLoad_DBConnLifetime(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		setEnv(t, validEnv())
+
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		if cfg.Database.MaxConnLifetime != time.Hour {
+			t.Errorf("Database.MaxConnLifetime = %v, want 1h", cfg.Database.MaxConnLifetime)
+		}
+		if cfg.Database.MaxConnIdleTime != 30*time.Minute {
+			t.Errorf("Database.MaxConnIdleTime = %v, want 30m", cfg.Database.MaxConnIdleTime)
+		}
+	})
+
+	t.Run("parses durations", func(t *testing.T) {
+		env := validEnv()
+		env["DB_MAX_CONN_LIFETIME"] = "15m"
+		env["DB_MAX_CONN_IDLE_TIME"] = "90s"
+		setEnv(t, env)
+
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		if cfg.Database.MaxConnLifetime != 15*time.Minute {
+			t.Errorf("Database.MaxConnLifetime = %v, want 15m", cfg.Database.MaxConnLifetime)
+		}
+		if cfg.Database.MaxConnIdleTime != 90*time.Second {
+			t.Errorf("Database.MaxConnIdleTime = %v, want 90s", cfg.Database.MaxConnIdleTime)
+		}
+	})
+
+	for _, tt := range []struct{ key, value string }{
+		{"DB_MAX_CONN_LIFETIME", "0s"},
+		{"DB_MAX_CONN_LIFETIME", "-1m"},
+		{"DB_MAX_CONN_LIFETIME", "forever"},
+		{"DB_MAX_CONN_IDLE_TIME", "0s"},
+		{"DB_MAX_CONN_IDLE_TIME", "-1m"},
+	} {
+		t.Run("rejects "+tt.key+"="+tt.value, func(t *testing.T) {
+			env := validEnv()
+			env[tt.key] = tt.value
+			setEnv(t, env)
+
+			if _, err := Load(); err == nil {
+				t.Errorf("Load() should fail with %s=%s", tt.key, tt.value)
+			}
+		})
+	}
+}
+
+func TestLoad_SlugLengthThresholds(t *testing.T) {
+	t.Run("parses thresholds", func(t *testing.T) {
+		env := validEnv()
+		env["SLUG_LENGTH_THRESHOLDS"] = "100000:8,10000000:9"
+		setEnv(t, env)
+
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		want := map[int64]int{100000: 8, 10000000: 9}
+		if len(cfg.Shortener.SlugLengthThresholds) != len(want) {
+			t.Fatalf("Shortener.SlugLengthThresholds = %v, want %v", cfg.Shortener.SlugLengthThresholds, want)
+		}
+		for k, v := range want {
+			if cfg.Shortener.SlugLengthThresholds[k] != v {
+				t.Errorf("Shortener.SlugLengthThresholds[%d] = %d, want %d", k, cfg.Shortener.SlugLengthThresholds[k], v)
+			}
+		}
+		if cfg.Shortener.SlugLengthCacheTTL != time.Minute {
+			t.Errorf("Shortener.SlugLengthCacheTTL = %v, want 1m", cfg.Shortener.SlugLengthCacheTTL)
+		}
+	})
+
+	t.Run("rejects length outside schema bounds", func(t *testing.T) {
+		env := validEnv()
+		env["SLUG_LENGTH_THRESHOLDS"] = "1000:5"
+		setEnv(t, env)
+
+		if _, err := Load(); err == nil {
+			t.Error("Load() should fail with a slug length below 7")
+		}
+	})
+}
+
+func TestLoad_SlugAutoLength(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		setEnv(t, validEnv())
+
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		if cfg.Shortener.SlugAutoLength {
+			t.Error("Shortener.SlugAutoLength = true, want false")
+		}
+		if cfg.Shortener.SlugCollisionTarget != 0.001 {
+			t.Errorf("Shortener.SlugCollisionTarget = %g, want 0.001", cfg.Shortener.SlugCollisionTarget)
+		}
+	})
+
+	for _, target := range []string{"0", "1", "-0.5"} {
+		t.Run("rejects target "+target, func(t *testing.T) {
+			env := validEnv()
+			env["SLUG_AUTO_LENGTH"] = "true"
+			env["SLUG_COLLISION_TARGET"] = target
+			setEnv(t, env)
+
+			if _, err := Load(); err == nil {
+				t.Errorf("Load() should fail with collision target %s", target)
+			}
+		})
+	}
+}
+
+func TestLoad_SlugMinLength(t *testing.T) {
+	t.Run("defaults to schema minimum", func(t *testing.T) {
+		setEnv(t, validEnv())
+
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		if cfg.Shortener.MinCustomSlugLength != 7 {
+			t.Errorf("Shortener.MinCustomSlugLength = %d, want 7", cfg.Shortener.MinCustomSlugLength)
+		}
+	})
+
+	t.Run("rejects length below schema minimum", func(t *testing.T) {
+		env := validEnv()
+		env["SLUG_MIN_LENGTH"] = "3"
+		setEnv(t, env)
+
+		if _, err := Load(); err == nil {
+			t.Error("Load() should fail with a minimum slug length below 7")
+		}
+	})
+}
+
+func TestLoad_SlugMaxLengths(t *testing.T) {
+	t.Run("default to schema maximum for generated slugs", func(t *testing.T) {
+		setEnv(t, validEnv())
+
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		if cfg.Shortener.MaxCustomSlugLength != 64 || cfg.Shortener.MaxGeneratedSlugLength != 64 {
+			t.Errorf("max custom/generated = %d/%d, want 64/64",
+				cfg.Shortener.MaxCustomSlugLength, cfg.Shortener.MaxGeneratedSlugLength)
+		}
+	})
+
+	t.Run("custom maximum may exceed generated", func(t *testing.T) {
+		env := validEnv()
+		env["SLUG_MAX_CUSTOM_LENGTH"] = "128"
+		env["SLUG_MAX_GENERATED_LENGTH"] = "10"
+		setEnv(t, env)
+
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		if cfg.Shortener.MaxCustomSlugLength != 128 || cfg.Shortener.MaxGeneratedSlugLength != 10 {
+			t.Errorf("max custom/generated = %d/%d, want 128/10",
+				cfg.Shortener.MaxCustomSlugLength, cfg.Shortener.MaxGeneratedSlugLength)
+		}
+	})
+
+	tests := []struct {
+		name string
+		env  map[string]string
+	}{
+		{"custom above schema bound", map[string]string{"SLUG_MAX_CUSTOM_LENGTH": "129"}},
+		{"custom below minimum", map[string]string{"SLUG_MIN_LENGTH": "10", "SLUG_MAX_CUSTOM_LENGTH": "9"}},
+		{"generated above 64", map[string]string{"SLUG_MAX_GENERATED_LENGTH": "65"}},
+		{"threshold above generated maximum", map[string]string{"SLUG_MAX_GENERATED_LENGTH": "8", "SLUG_LENGTH_THRESHOLDS": "1000:9"}},
+	}
+	for _, tt := range tests {
+		t.Run("rejects "+tt.name, func(t *testing.T) {
+			env := validEnv()
+			maps.Copy(env, tt.env)
+			setEnv(t, env)
+
+			if _, err := Load(); err == nil {
+				t.Errorf("Load() should fail with %v", tt.env)
+			}
+		})
+	}
+}
+
+func TestLoad_Keyspace(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		setEnv(t, validEnv())
+
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		if cfg.Shortener.KeyspaceCheckInterval != time.Hour {
+			t.Errorf("Shortener.KeyspaceCheckInterval = %v, want 1h", cfg.Shortener.KeyspaceCheckInterval)
+		}
+		if cfg.Shortener.KeyspaceWarnFraction != 0.01 {
+			t.Errorf("Shortener.KeyspaceWarnFraction = %v, want 0.01", cfg.Shortener.KeyspaceWarnFraction)
+		}
+	})
+
+	for _, fraction := range []string{"0", "1.5", "-0.1"} {
+		t.Run("rejects warn fraction "+fraction, func(t *testing.T) {
+			env := validEnv()
+			env["KEYSPACE_WARN_FRACTION"] = fraction
+			setEnv(t, env)
+
+			if _, err := Load(); err == nil {
+				t.Errorf("Load() should fail with warn fraction %s", fraction)
+			}
+		})
+	}
+}
+
+func TestLoad_BaseURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		baseURL string
+		wantErr bool
+	}{
+		{"http with port", "http://localhost:8080", false},
+		{"https with path", "https://example.com/s", false},
+		{"scheme-less host and port", "localhost:8080", true},
+		{"scheme-less host", "short.ly", true},
+		{"unsupported scheme", "ftp://short.ly", true},
+		{"trailing slash", "https://short.ly/", true},
+		{"query string", "https://short.ly?ref=x", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := validEnv()
+			env["SERVER_BASE_URL"] = tt.baseURL
+			setEnv(t, env)
+
+			cfg, err := Load()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Load() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && cfg.Server.BaseURL != tt.baseURL {
+				t.Errorf("Server.BaseURL = %q, want %q", cfg.Server.BaseURL, tt.baseURL)
+			}
+		})
+	}
+}
+
+func TestLoad_SlugCharset(t *testing.T) {
+	tests := []struct {
+		value   string
+		wantErr bool
+	}{
+		{"alphanum_dash_underscore", false},
+		{"alphanum_dash_underscore_dot", false},
+		{"unicode", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			env := validEnv()
+			env["SLUG_CHARSET"] = tt.value
+			setEnv(t, env)
+
+			_, err := Load()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Load() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoad_SlugEncoding(t *testing.T) {
+	tests := []struct {
+		value   string
+		wantErr bool
+	}{
+		{"base62", false},
+		{"base32", false},
+		{"base58", false},
+		{"base64", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			env := validEnv()
+			env["SLUG_ENCODING"] = tt.value
+			setEnv(t, env)
+
+			_, err := Load()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Load() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoad_SlugCaseInsensitive(t *testing.T) {
+	tests := []struct {
+		encoding string
+		wantErr  bool
+	}{
+		{"base32", false},
+		{"base62", true},
+		{"base58", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.encoding, func(t *testing.T) {
+			env := validEnv()
+			env["SLUG_ENCODING"] = tt.encoding
+			env["SLUG_CASE_INSENSITIVE"] = "true"
+			setEnv(t, env)
+
+			cfg, err := Load()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Load() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && !cfg.Shortener.SlugCaseInsensitive {
+				t.Error("SlugCaseInsensitive = false, want true")
+			}
+		})
+	}
+}
+
+func TestLoad_BatchDuplicateSlugPolicy(t *testing.T) {
+	tests := []struct {
+		value   string
+		wantErr bool
+	}{
+		{"fail", false},
+		{"skip", false},
+		{"suffix", false},
+		{"rename", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			env := validEnv()
+			env["BATCH_DUPLICATE_SLUG_POLICY"] = tt.value
+			setEnv(t, env)
+
+			_, err := Load()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Load() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoad_BatchMaxItems(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    int
+		wantErr bool
+	}{
+		{"", 100, false},
+		{"500", 500, false},
+		{"0", 0, true},
+		{"-1", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			env := validEnv()
+			if tt.value != "" {
+				env["BATCH_MAX_ITEMS"] = tt.value
+			}
+			setEnv(t, env)
+
+			cfg, err := Load()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Load() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && cfg.Shortener.BatchMaxItems != tt.want {
+				t.Errorf("Shortener.BatchMaxItems = %d, want %d", cfg.Shortener.BatchMaxItems, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoad_NotFoundCache(t *testing.T) {
+	tests := []struct {
+		name    string
+		ttl     string
+		size    string
+		wantErr bool
+	}{
+		{"disabled", "0s", "10000", false},
+		{"enabled", "5s", "500", false},
+		{"negative TTL", "-1s", "10000", true},
+		{"enabled without room", "5s", "0", true},
+		{"size ignored when disabled", "0s", "0", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := validEnv()
+			env["NOT_FOUND_CACHE_TTL"] = tt.ttl
+			env["NOT_FOUND_CACHE_SIZE"] = tt.size
+			setEnv(t, env)
+
+			_, err := Load()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Load() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoad_Tracking(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		wantErr bool
+	}{
+		{"defaults", nil, false},
+		{"async drop", map[string]string{"TRACKING_ASYNC": "true", "TRACKING_OVERFLOW_POLICY": "drop"}, false},
+		{"async sync-fallback", map[string]string{"TRACKING_ASYNC": "true", "TRACKING_OVERFLOW_POLICY": "sync-fallback"}, false},
+		{"unknown policy", map[string]string{"TRACKING_OVERFLOW_POLICY": "queue"}, true},
+		{"async without buffer", map[string]string{"TRACKING_ASYNC": "true", "TRACKING_BUFFER_SIZE": "0"}, true},
+		{"async without block timeout", map[string]string{"TRACKING_ASYNC": "true", "TRACKING_BLOCK_TIMEOUT": "0s"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := validEnv()
+			maps.Copy(env, tt.env)
+			setEnv(t, env)
+
+			_, err := Load()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Load() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoad_GeoIPFiles(t *testing.T) {
+	tests := []struct {
+		name      string
+		locations string
+		blocks    string
+		async     string
+		wantErr   bool
+	}{
+		{"unset", "", "", "false", false},
+		{"both set", "/geo/locations.csv", "/geo/ipv4.csv,/geo/ipv6.csv", "true", false},
+		{"without async tracking", "/geo/locations.csv", "/geo/ipv4.csv", "false", true},
+		{"locations only", "/geo/locations.csv", "", "true", true},
+		{"blocks only", "", "/geo/ipv4.csv", "true", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := validEnv()
+			env["GEOIP_LOCATIONS_FILE"] = tt.locations
+			env["GEOIP_BLOCKS_FILES"] = tt.blocks
+			env["TRACKING_ASYNC"] = tt.async
+			setEnv(t, env)
+
+			_, err := Load()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Load() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoad_BatchReachability(t *testing.T) {
+	t.Run("disabled by default", func(t *testing.T) {
+		setEnv(t, validEnv())
+
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		if cfg.Shortener.BatchCheckReachability {
+			t.Error("Shortener.BatchCheckReachability = true, want false")
+		}
+		if cfg.Shortener.BatchReachabilityTimeout != 3*time.Second {
+			t.Errorf("Shortener.BatchReachabilityTimeout = %v, want 3s", cfg.Shortener.BatchReachabilityTimeout)
+		}
+	})
+
+	t.Run("rejects non-positive timeout when enabled", func(t *testing.T) {
+		env := validEnv()
+		env["BATCH_CHECK_REACHABILITY"] = "true"
+		env["BATCH_REACHABILITY_TIMEOUT"] = "0s"
+		setEnv(t, env)
+
+		if _, err := Load(); err == nil {
+			t.Error("Load() should fail with a zero reachability timeout")
+		}
+	})
+}
+
+func TestLoad_MaxLinksPerOwner(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    int
+		wantErr bool
+	}{
+		{"", 0, false},
+		{"0", 0, false},
+		{"500", 500, false},
+		{"-1", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			env := validEnv()
+			if tt.value != "" {
+				env["MAX_LINKS_PER_OWNER"] = tt.value
+			}
+			setEnv(t, env)
+
+			cfg, err := Load()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Load() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && cfg.Shortener.MaxLinksPerOwner != tt.want {
+				t.Errorf("Shortener.MaxLinksPerOwner = %d, want %d", cfg.Shortener.MaxLinksPerOwner, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoad_BlockedDomains(t *testing.T) {
+	t.Run("merges env and file entries", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "blocked.txt")
+		content := "# known phishing hosts\nphish.example\n\n*.ads.example  # ad networks\n"
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		env := validEnv()
+		env["BLOCKED_DOMAINS"] = "evil.com"
+		env["BLOCKED_DOMAINS_FILE"] = path
+		setEnv(t, env)
+
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		want := []string{"evil.com", "phish.example", "*.ads.example"}
+		if !slices.Equal(cfg.Shortener.BlockedDomains, want) {
+			t.Errorf("Shortener.BlockedDomains = %q, want %q", cfg.Shortener.BlockedDomains, want)
+		}
+	})
+
+	t.Run("fails on a missing file", func(t *testing.T) {
+		env := validEnv()
+		env["BLOCKED_DOMAINS_FILE"] = filepath.Join(t.TempDir(), "missing.txt")
+		setEnv(t, env)
+
+		if _, err := Load(); err == nil {
+			t.Error("Load() should fail when the blocked domains file is missing")
+		}
+	})
+
+	for _, bad := range []string{"https://evil.com", "evil.com/path", "*evil.com", "ev il.com"} {
+		t.Run("rejects "+bad, func(t *testing.T) {
+			env := validEnv()
+			env["BLOCKED_DOMAINS"] = bad
+			setEnv(t, env)
+
+			if _, err := Load(); err == nil {
+				t.Errorf("Load() should fail with blocked domain %q", bad)
+			}
+		})
+	}
+}
+
+func TestLoad_AllowListMode(t *testing.T) {
+	t.Run("requires allowed domains", func(t *testing.T) {
+		env := validEnv()
+		env["ALLOW_LIST_MODE"] = "true"
+		setEnv(t, env)
+
+		if _, err := Load(); err == nil {
+			t.Error("Load() should fail in allow list mode without allowed domains")
+		}
+	})
+
+	t.Run("loads allowed domains from env and file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "allowed.txt")
+		if err := os.WriteFile(path, []byte("*.corp.example\n"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		env := validEnv()
+		env["ALLOW_LIST_MODE"] = "true"
+		env["ALLOWED_DOMAINS"] = "intranet.corp"
+		env["ALLOWED_DOMAINS_FILE"] = path
+		setEnv(t, env)
+
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		want := []string{"intranet.corp", "*.corp.example"}
+		if !cfg.Shortener.AllowListMode || !slices.Equal(cfg.Shortener.AllowedDomains, want) {
+			t.Errorf("AllowListMode = %v, AllowedDomains = %q; want true, %q",
+				cfg.Shortener.AllowListMode, cfg.Shortener.AllowedDomains, want)
+		}
+	})
+}
+
+func TestLoad_Purge(t *testing.T) {
+	t.Run("defaults when unset", func(t *testing.T) {
+		setEnv(t, validEnv())
+
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		if cfg.Shortener.PurgeEnabled {
+			t.Error("Shortener.PurgeEnabled = true, want false")
+		}
+		if cfg.Shortener.PurgeDeletedRetention != 720*time.Hour {
+			t.Errorf("Shortener.PurgeDeletedRetention = %v, want 720h", cfg.Shortener.PurgeDeletedRetention)
+		}
+	})
+
+	t.Run("rejects non-positive batch size when enabled", func(t *testing.T) {
+		env := validEnv()
+		env["PURGE_ENABLED"] = "true"
+		env["PURGE_BATCH_SIZE"] = "0"
+		setEnv(t, env)
+
+		if _, err := Load(); err == nil {
+			t.Error("Load() should fail with a zero purge batch size")
+		}
+	})
+}
+
+func TestLoad_NotFoundRedirectURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		wantErr bool
+	}{
+		{"unset", "", false},
+		{"absolute https", "https://example.com/", false},
+		{"relative path", "/home", true},
+		{"scheme-less", "example.com", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := validEnv()
+			env["NOT_FOUND_REDIRECT_URL"] = tt.value
+			setEnv(t, env)
+
+			_, err := Load()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Load() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoad_RootAndIgnoredPaths(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		setEnv(t, validEnv())
+
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		if cfg.Server.RootRedirectURL != "" {
+			t.Errorf("Server.RootRedirectURL = %q, want empty", cfg.Server.RootRedirectURL)
+		}
+		if !slices.Contains(cfg.Server.IgnoredPaths, "/favicon.ico") {
+			t.Errorf("Server.IgnoredPaths = %v, want /favicon.ico included", cfg.Server.IgnoredPaths)
+		}
+	})
+
+	tests := []struct {
+		name    string
+		key     string
+		value   string
+		wantErr bool
+	}{
+		{"absolute root redirect", "ROOT_REDIRECT_URL", "https://example.com/", false},
+		{"relative root redirect", "ROOT_REDIRECT_URL", "/home", true},
+		{"custom ignored paths", "IGNORED_PATHS", "/favicon.ico,/sitemap.xml", false},
+		{"ignored path without slash", "IGNORED_PATHS", "favicon.ico", true},
+		{"ignoring root", "IGNORED_PATHS", "/", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := validEnv()
+			env[tt.key] = tt.value
+			setEnv(t, env)
+
+			_, err := Load()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Load() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoad_RedirectCache(t *testing.T) {
+	t.Run("parses per-status max ages", func(t *testing.T) {
+		env := validEnv()
+		env["REDIRECT_STATUS"] = "301"
+		env["REDIRECT_CACHE_MAX_AGE"] = "301:24h,302:0s"
+		setEnv(t, env)
+
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		if cfg.Server.RedirectStatus != 301 {
+			t.Errorf("Server.RedirectStatus = %d, want 301", cfg.Server.RedirectStatus)
+		}
+		want := map[int]time.Duration{301: 24 * time.Hour, 302: 0}
+		if !maps.Equal(cfg.Server.RedirectCacheMaxAge, want) {
+			t.Errorf("Server.RedirectCacheMaxAge = %v, want %v", cfg.Server.RedirectCacheMaxAge, want)
+		}
+	})
+
+	tests := []struct {
+		name string
+		key  string
+		val  string
+	}{
+		{"non-redirect status", "REDIRECT_STATUS", "200"},
+		{"non-redirect cache status", "REDIRECT_CACHE_MAX_AGE", "404:1h"},
+		{"negative max age", "REDIRECT_CACHE_MAX_AGE", "301:-1h"},
+	}
+	for _, tt := range tests {
+		t.Run("rejects "+tt.name, func(t *testing.T) {
+			env := validEnv()
+			env[tt.key] = tt.val
+			setEnv(t, env)
+
+			if _, err := Load(); err == nil {
+				t.Errorf("Load() should fail with %s=%s", tt.key, tt.val)
+			}
+		})
+	}
+}
+
+func TestLoad_SlugPrefixes(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		wantErr bool
+	}{
+		{"unset", "", false},
+		{"alphanumeric prefixes", "acme:acme,globex:gx2", false},
+		{"prefix with dash", "acme:ac-me", true},
+		{"prefix too long", "acme:abcdefghijklmnopq", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := validEnv()
+			env["SLUG_PREFIXES"] = tt.value
+			setEnv(t, env)
+
+			_, err := Load()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Load() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoad_RequestID(t *testing.T) {
+	t.Run("defaults when unset", func(t *testing.T) {
+		setEnv(t, validEnv())
+
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		if cfg.Server.RequestIDHeader != "X-Request-ID" {
+			t.Errorf("Server.RequestIDHeader = %q, want X-Request-ID", cfg.Server.RequestIDHeader)
+		}
+		if cfg.Server.RequestIDSource != "uuid" {
+			t.Errorf("Server.RequestIDSource = %q, want uuid", cfg.Server.RequestIDSource)
+		}
+		if cfg.Server.RequestIDValidation != "none" {
+			t.Errorf("Server.RequestIDValidation = %q, want none", cfg.Server.RequestIDValidation)
+		}
+		if cfg.Server.RequestIDMaxLength != 128 {
+			t.Errorf("Server.RequestIDMaxLength = %d, want 128", cfg.Server.RequestIDMaxLength)
+		}
+	})
+
+	t.Run("rejects unknown source", func(t *testing.T) {
+		env := validEnv()
+		env["REQUEST_ID_SOURCE"] = "random"
+		setEnv(t, env)
+
+		if _, err := Load(); err == nil {
+			t.Error("Load() should fail with an unknown request ID source")
+		}
+	})
+
+	t.Run("rejects unknown validation", func(t *testing.T) {
+		env := validEnv()
+		env["REQUEST_ID_VALIDATION"] = "regex"
+		setEnv(t, env)
+
+		if _, err := Load(); err == nil {
+			t.Error("Load() should fail with an unknown request ID validation")
+		}
+	})
+
+	t.Run("rejects non-positive max length", func(t *testing.T) {
+		env := validEnv()
+		env["REQUEST_ID_MAX_LENGTH"] = "0"
+		setEnv(t, env)
+
+		if _, err := Load(); err == nil {
+			t.Error("Load() should fail with a zero request ID max length")
+		}
+	})
+}
+
+func TestLoad_ErrorFormat(t *testing.T) {
+	t.Run("defaults to json", func(t *testing.T) {
+		setEnv(t, validEnv())
+
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		if cfg.Server.ErrorFormat != "json" {
+			t.Errorf("Server.ErrorFormat = %q, want json", cfg.Server.ErrorFormat)
+		}
+	})
+
+	t.Run("accepts problem", func(t *testing.T) {
+		env := validEnv()
+		env["ERROR_FORMAT"] = "problem"
+		setEnv(t, env)
+
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		if cfg.Server.ErrorFormat != "problem" {
+			t.Errorf("Server.ErrorFormat = %q, want problem", cfg.Server.ErrorFormat)
+		}
+	})
+
+	t.Run("rejects unknown format", func(t *testing.T) {
+		env := validEnv()
+		env["ERROR_FORMAT"] = "xml"
+		setEnv(t, env)
+
+		if _, err := Load(); err == nil {
+			t.Error("Load() should fail with an unknown error format")
+		}
+	})
+}
+
+func TestLoad_TrailingSlash(t *testing.T) {
+	t.Run("defaults to strict", func(t *testing.T) {
+		setEnv(t, validEnv())
+
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		if cfg.Server.TrailingSlash != "strict" {
+			t.Errorf("Server.TrailingSlash = %q, want strict", cfg.Server.TrailingSlash)
+		}
+	})
+
+	t.Run("rejects unknown policy", func(t *testing.T) {
+		env := validEnv()
+		env["TRAILING_SLASH"] = "ignore"
+		setEnv(t, env)
+
+		if _, err := Load(); err == nil {
+			t.Error("Load() should fail with an unknown trailing slash policy")
+		}
+	})
+}
+
+func TestLoad_ErrorCodes(t *testing.T) {
+	env := validEnv()
+	env["ERROR_CODES"] = "not_found:urlshortener.not_found,conflict:urlshortener.conflict"
+	setEnv(t, env)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	want := map[string]string{
+		"not_found": "urlshortener.not_found",
+		"conflict":  "urlshortener.conflict",
+	}
+	if !maps.Equal(cfg.Server.ErrorCodes, want) {
+		t.Errorf("Server.ErrorCodes = %v, want %v", cfg.Server.ErrorCodes, want)
+	}
+}
+
+func TestLoad_LogRedactParams(t *testing.T) {
+	t.Run("defaults when unset", func(t *testing.T) {
+		setEnv(t, validEnv())
+
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		want := []string{"token", "access_token", "sig"}
+		if !slices.Equal(cfg.Server.LogRedactParams, want) {
+			t.Errorf("Server.LogRedactParams = %v, want %v", cfg.Server.LogRedactParams, want)
+		}
+	})
+
+	t.Run("parses configured list", func(t *testing.T) {
+		env := validEnv()
+		env["LOG_REDACT_PARAMS"] = "password,session"
+		setEnv(t, env)
+
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		want := []string{"password", "session"}
+		if !slices.Equal(cfg.Server.LogRedactParams, want) {
+			t.Errorf("Server.LogRedactParams = %v, want %v", cfg.Server.LogRedactParams, want)
+		}
+	})
+}
+
+func TestConfig_Redacted(t *testing.T) {
+	env := validEnv()
+	env["API_KEYS"] = "k-ops-123:ops,k-support-456:support"
+	setEnv(t, env)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+
+	red := cfg.Redacted()
+	if red.Database.Password != RedactedValue {
+		t.Errorf("Database.Password = %q, want %q", red.Database.Password, RedactedValue)
+	}
+	if strings.Contains(red.Database.ConnectionString(), "testpass") {
+		t.Errorf("ConnectionString() leaks the password: %s", red.Database.ConnectionString())
+	}
+
+	want := map[string]string{RedactedValue + "-1": "ops", RedactedValue + "-2": "support"}
+	if !maps.Equal(red.Server.APIKeys, want) {
+		t.Errorf("Server.APIKeys = %v, want %v", red.Server.APIKeys, want)
+	}
+
+	// The original is left untouched.
+	if cfg.Database.Password != "testpass" || cfg.Server.APIKeys["k-ops-123"] != "ops" {
+		t.Errorf("Redacted() modified the original config: %+v", cfg)
+	}
+	if red.Database.Host != cfg.Database.Host || red.Server.Port != cfg.Server.Port {
+		t.Error("Redacted() dropped non-secret fields")
+	}
+}
+
+func TestLoad_SlowRequestThreshold(t *testing.T) {
+	t.Run("disabled by default", func(t *testing.T) {
+		setEnv(t, validEnv())
+
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		if cfg.Server.SlowRequestThreshold != 0 {
+			t.Errorf("Server.SlowRequestThreshold = %v, want 0", cfg.Server.SlowRequestThreshold)
+		}
+	})
+
+	t.Run("rejects negative threshold", func(t *testing.T) {
+		env := validEnv()
+		env["SLOW_REQUEST_THRESHOLD"] = "-1s"
+		setEnv(t, env)
+
+		if _, err := Load(); err == nil {
+			t.Error("Load() should fail with a negative slow request threshold")
+		}
+	})
+}
+
+func TestLoad_LogRequestBodies(t *testing.T) {
+	t.Run("disabled by default", func(t *testing.T) {
+		setEnv(t, validEnv())
+
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		if cfg.Server.LogRequestBodies {
+			t.Error("Server.LogRequestBodies should default to false")
+		}
+		if cfg.Server.LogBodyMaxBytes != 2048 {
+			t.Errorf("Server.LogBodyMaxBytes = %d, want 2048", cfg.Server.LogBodyMaxBytes)
+		}
+		if !slices.Equal(cfg.Server.LogRedactFields, []string{"password", "token", "secret", "api_key"}) {
+			t.Errorf("Server.LogRedactFields = %v, want the defaults", cfg.Server.LogRedactFields)
+		}
+	})
+
+	t.Run("rejects non-positive max bytes", func(t *testing.T) {
+		env := validEnv()
+		env["LOG_BODY_MAX_BYTES"] = "0"
+		setEnv(t, env)
+
+		if _, err := Load(); err == nil {
+			t.Error("Load() should fail with a non-positive log body max bytes")
+		}
+	})
+}
+
+func TestLoad_LinkExpiryGrace(t *testing.T) {
+	env := validEnv()
+	env["LINK_EXPIRY_GRACE"] = "-1h"
+	setEnv(t, env)
+
+	if _, err := Load(); err == nil {
+		t.Error("Load() should fail with a negative link expiry grace")
+	}
+
+	env["LINK_EXPIRY_GRACE"] = "72h"
+	setEnv(t, env)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if cfg.Shortener.LinkExpiryGrace != 72*time.Hour {
+		t.Errorf("Shortener.LinkExpiryGrace = %v, want 72h", cfg.Shortener.LinkExpiryGrace)
+	}
+
+	t.Run("rejects purging inside the grace window", func(t *testing.T) {
+		env := validEnv()
+		env["LINK_EXPIRY_GRACE"] = "72h"
+		env["PURGE_ENABLED"] = "true"
+		env["PURGE_EXPIRED_GRACE"] = "24h"
+		setEnv(t, env)
+
+		if _, err := Load(); err == nil {
+			t.Error("Load() should fail when expired links are purged before their grace ends")
+		}
+
+		env["PURGE_EXPIRED_GRACE"] = "72h"
+		setEnv(t, env)
+		if _, err := Load(); err != nil {
+			t.Errorf("Load() failed: %v", err)
+		}
+	})
+}
+
+func TestLoad_DefaultLinkTTL(t *testing.T) {
+	env := validEnv()
+	env["DEFAULT_LINK_TTL"] = "-1h"
+	setEnv(t, env)
+
+	if _, err := Load(); err == nil {
+		t.Error("Load() should fail with a negative default link TTL")
+	}
+
+	env["DEFAULT_LINK_TTL"] = "168h"
+	setEnv(t, env)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if cfg.Shortener.DefaultLinkTTL != 168*time.Hour {
+		t.Errorf("Shortener.DefaultLinkTTL = %v, want 168h", cfg.Shortener.DefaultLinkTTL)
+	}
+}
+
+func TestLoad_AccessCountThreshold(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		wantErr bool
+	}{
+		{"disabled by default", nil, false},
+		{"threshold with auto-pause", map[string]string{"ACCESS_COUNT_THRESHOLD": "1000000000", "ACCESS_COUNT_AUTO_PAUSE": "true"}, false},
+		{"negative threshold", map[string]string{"ACCESS_COUNT_THRESHOLD": "-1"}, true},
+		{"auto-pause without threshold", map[string]string{"ACCESS_COUNT_AUTO_PAUSE": "true"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := validEnv()
+			maps.Copy(env, tt.env)
+			setEnv(t, env)
+
+			_, err := Load()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Load() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoad_PrettyJSON(t *testing.T) {
+	setEnv(t, validEnv())
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if cfg.Server.PrettyJSON {
+		t.Error("Server.PrettyJSON should default to false")
+	}
+
+	env := validEnv()
+	env["PRETTY_JSON"] = "true"
+	setEnv(t, env)
+
+	if cfg, err = Load(); err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if !cfg.Server.PrettyJSON {
+		t.Error("Server.PrettyJSON = false, want true")
+	}
+}
+
+func TestLoad_ListMaxLimit(t *testing.T) {
+	env := validEnv()
+	env["LIST_MAX_LIMIT"] = "0"
+	setEnv(t, env)
+
+	if _, err := Load(); err == nil {
+		t.Error("Load() should fail with a non-positive list max limit")
+	}
+}
+
+func TestLoad_ReadyTimeout(t *testing.T) {
+	env := validEnv()
+	env["STARTUP_READY_TIMEOUT"] = "0s"
+	setEnv(t, env)
+
+	if _, err := Load(); err == nil {
+		t.Error("Load() should fail with a non-positive startup ready timeout")
+	}
+}
+
+func TestLoad_TLS(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		wantErr string
+	}{
+		{
+			name: "modern settings",
+			env: map[string]string{
+				"SERVER_TLS_MIN_VERSION":   "1.2",
+				"SERVER_TLS_CIPHER_SUITES": "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256",
+			},
+		},
+		{
+			name:    "rejects TLS 1.1",
+			env:     map[string]string{"SERVER_TLS_MIN_VERSION": "1.1"},
+			wantErr: "invalid TLS min version",
+		},
+		{
+			name:    "rejects insecure cipher suite",
+			env:     map[string]string{"SERVER_TLS_CIPHER_SUITES": "TLS_RSA_WITH_RC4_128_SHA"},
+			wantErr: "unknown or insecure",
+		},
+		{
+			name:    "rejects TLS 1.3 cipher suite",
+			env:     map[string]string{"SERVER_TLS_CIPHER_SUITES": "TLS_AES_128_GCM_SHA256"},
+			wantErr: "not configurable",
+		},
+		{
+			name:    "rejects certificate without key",
+			env:     map[string]string{"SERVER_TLS_CERT_FILE": "cert.pem"},
+			wantErr: "must be set together",
+		},
+		{
+			name: "rejects h2c with TLS",
+			env: map[string]string{
+				"SERVER_TLS_CERT_FILE": "cert.pem",
+				"SERVER_TLS_KEY_FILE":  "key.pem",
+				"SERVER_H2C":           "true",
+			},
+			wantErr: "h2c",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := validEnv()
+			maps.Copy(env, tt.env)
+			setEnv(t, env)
+
+			_, err := Load()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Load() failed: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Load() error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestServerConfig_TLSConfig_Handshake(t *testing.T) {
+	cfg := ServerConfig{TLSMinVersion: "1.2"}
+	tlsConfig, err := cfg.TLSConfig()
+	if err != nil {
+		t.Fatalf("TLSConfig() failed: %v", err)
+	}
+
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	srv.TLS = tlsConfig
+	srv.StartTLS()
+	defer srv.Close()
+
+	dial := func(version uint16) error {
+		conn, err := tls.Dial("tcp", srv.Listener.Addr().String(), &tls.Config{
+			InsecureSkipVerify: true, // httptest's self-signed certificate
+			MinVersion:         version,
+			MaxVersion:         version,
+		})
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	}
+
+	if err := dial(tls.VersionTLS11); err == nil {
+		t.Error("TLS 1.1 handshake succeeded, want it refused")
+	}
+	if err := dial(tls.VersionTLS13); err != nil {
+		t.Errorf("TLS 1.3 handshake failed: %v", err)
+	}
+}
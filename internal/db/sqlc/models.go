@@ -9,12 +9,58 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type AuditLog struct {
+	ID        int64
+	Op        string
+	Slug      string
+	Owner     pgtype.Text
+	RequestID pgtype.Text
+	CreatedAt pgtype.Timestamptz
+}
+
 type Link struct {
-	ID             uuid.UUID
-	OriginalUrl    string
-	Slug           string
-	AccessCount    int64
-	CreatedAt      pgtype.Timestamptz
-	UpdatedAt      pgtype.Timestamptz
-	LastAccessedAt pgtype.Timestamptz
+	ID                uuid.UUID
+	OriginalUrl       string
+	Slug              string
+	AccessCount       int64
+	CreatedAt         pgtype.Timestamptz
+	UpdatedAt         pgtype.Timestamptz
+	LastAccessedAt    pgtype.Timestamptz
+	UniqueAccessCount int64
+	ExpiresAt         pgtype.Timestamptz
+	DeletedAt         pgtype.Timestamptz
+	Source            pgtype.Text
+	Owner             pgtype.Text
+	UtmSource         pgtype.Text
+	UtmMedium         pgtype.Text
+	UtmCampaign       pgtype.Text
+	RedirectStatus    pgtype.Int2
+	Paused            bool
+	SlugKey           string
+}
+
+type LinkAlias struct {
+	Alias     string
+	LinkID    uuid.UUID
+	CreatedAt pgtype.Timestamptz
+	AliasKey  string
+}
+
+type LinkClick struct {
+	LinkID    uuid.UUID
+	ClickedAt pgtype.Timestamptz
+	RequestID pgtype.Text
+	Country   pgtype.Text
+}
+
+type LinkCreator struct {
+	LinkID           uuid.UUID
+	CreatedIp        pgtype.Text
+	CreatedUserAgent pgtype.Text
+}
+
+type LinkVisitor struct {
+	LinkID      uuid.UUID
+	Fingerprint string
+	FirstSeenAt pgtype.Timestamptz
 }
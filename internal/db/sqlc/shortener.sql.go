@@ -9,55 +9,496 @@ import (
 	"context"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const countClicksByCountry = `-- name: CountClicksByCountry :many
+SELECT
+    coalesce(country, '')::text AS country,
+    count(*) AS clicks
+FROM link_clicks
+GROUP BY 1
+ORDER BY 2 DESC, 1
+`
+
+type CountClicksByCountryRow struct {
+	Country string
+	Clicks  int64
+}
+
+// Clicks without a country are grouped under an empty string.
+func (q *Queries) CountClicksByCountry(ctx context.Context) ([]CountClicksByCountryRow, error) {
+	rows, err := q.db.Query(ctx, countClicksByCountry)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CountClicksByCountryRow
+	for rows.Next() {
+		var i CountClicksByCountryRow
+		if err := rows.Scan(&i.Country, &i.Clicks); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const countLinks = `-- name: CountLinks :one
+SELECT count(*) FROM links
+`
+
+func (q *Queries) CountLinks(ctx context.Context) (int64, error) {
+	row := q.db.QueryRow(ctx, countLinks)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countLinksByOwner = `-- name: CountLinksByOwner :one
+SELECT count(*) FROM links
+WHERE owner = $1
+  AND deleted_at IS NULL
+`
+
+func (q *Queries) CountLinksByOwner(ctx context.Context, owner pgtype.Text) (int64, error) {
+	row := q.db.QueryRow(ctx, countLinksByOwner, owner)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countLinksBySource = `-- name: CountLinksBySource :many
+SELECT
+    coalesce(source, '')::text AS source,
+    count(*) AS links
+FROM links
+WHERE deleted_at IS NULL
+GROUP BY 1
+ORDER BY 2 DESC, 1
+`
+
+type CountLinksBySourceRow struct {
+	Source string
+	Links  int64
+}
+
+// Links without a source are grouped under an empty string.
+func (q *Queries) CountLinksBySource(ctx context.Context) ([]CountLinksBySourceRow, error) {
+	rows, err := q.db.Query(ctx, countLinksBySource)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CountLinksBySourceRow
+	for rows.Next() {
+		var i CountLinksBySourceRow
+		if err := rows.Scan(&i.Source, &i.Links); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const countLinksFiltered = `-- name: CountLinksFiltered :one
+SELECT count(*) FROM links
+WHERE ($1::text IS NULL OR owner = $1::text)
+  AND CASE $2::text
+    WHEN 'active' THEN deleted_at IS NULL AND NOT paused
+      AND (expires_at IS NULL OR expires_at > $3::timestamptz)
+    WHEN 'paused' THEN deleted_at IS NULL AND paused
+    WHEN 'expired' THEN deleted_at IS NULL
+      AND expires_at <= $3::timestamptz
+    WHEN 'deleted' THEN deleted_at IS NOT NULL
+    ELSE true
+  END
+`
+
+type CountLinksFilteredParams struct {
+	Owner  pgtype.Text
+	Status string
+	Now    pgtype.Timestamptz
+}
+
+// A NULL owner counts every owner; an unknown status counts every state.
+func (q *Queries) CountLinksFiltered(ctx context.Context, arg CountLinksFilteredParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countLinksFiltered, arg.Owner, arg.Status, arg.Now)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createLink = `-- name: CreateLink :one
 INSERT INTO links (
     id,
     original_url,
-    slug
+    slug,
+    source,
+    owner,
+    utm_source,
+    utm_medium,
+    utm_campaign,
+    redirect_status,
+    slug_key,
+    expires_at
 ) VALUES (
-    $1, $2, $3
+    $1, $2, $3, $4, $5,
+    $6, $7, $8,
+    $9, $10, $11
 )
 RETURNING
     id,
     original_url,
     slug,
     access_count,
+    unique_access_count,
     created_at,
     updated_at,
-    last_accessed_at
+    last_accessed_at,
+    expires_at,
+    deleted_at,
+    source,
+    owner,
+    utm_source,
+    utm_medium,
+    utm_campaign,
+    redirect_status,
+    paused,
+    slug_key
 `
 
 type CreateLinkParams struct {
-	ID          uuid.UUID
-	OriginalUrl string
-	Slug        string
+	ID             uuid.UUID
+	OriginalUrl    string
+	Slug           string
+	Source         pgtype.Text
+	Owner          pgtype.Text
+	UtmSource      pgtype.Text
+	UtmMedium      pgtype.Text
+	UtmCampaign    pgtype.Text
+	RedirectStatus pgtype.Int2
+	SlugKey        string
+	ExpiresAt      pgtype.Timestamptz
 }
 
 func (q *Queries) CreateLink(ctx context.Context, arg CreateLinkParams) (Link, error) {
-	row := q.db.QueryRow(ctx, createLink, arg.ID, arg.OriginalUrl, arg.Slug)
+	row := q.db.QueryRow(ctx, createLink,
+		arg.ID,
+		arg.OriginalUrl,
+		arg.Slug,
+		arg.Source,
+		arg.Owner,
+		arg.UtmSource,
+		arg.UtmMedium,
+		arg.UtmCampaign,
+		arg.RedirectStatus,
+		arg.SlugKey,
+		arg.ExpiresAt,
+	)
 	var i Link
 	err := row.Scan(
 		&i.ID,
 		&i.OriginalUrl,
 		&i.Slug,
 		&i.AccessCount,
+		&i.UniqueAccessCount,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.LastAccessedAt,
+		&i.ExpiresAt,
+		&i.DeletedAt,
+		&i.Source,
+		&i.Owner,
+		&i.UtmSource,
+		&i.UtmMedium,
+		&i.UtmCampaign,
+		&i.RedirectStatus,
+		&i.Paused,
+		&i.SlugKey,
 	)
 	return i, err
 }
 
-const deleteLink = `-- name: DeleteLink :exec
-DELETE FROM links
-WHERE slug = $1
+const createOrUpdateLink = `-- name: CreateOrUpdateLink :one
+INSERT INTO links (
+    id,
+    original_url,
+    slug,
+    source,
+    owner,
+    utm_source,
+    utm_medium,
+    utm_campaign,
+    redirect_status,
+    slug_key,
+    expires_at
+) VALUES (
+    $1, $2, $3, $4, $5,
+    $6, $7, $8,
+    $9, $10, $11
+)
+ON CONFLICT (slug_key) WHERE deleted_at IS NULL
+DO UPDATE SET original_url = EXCLUDED.original_url
+WHERE links.deleted_at IS NULL
+RETURNING
+    id,
+    original_url,
+    slug,
+    access_count,
+    unique_access_count,
+    created_at,
+    updated_at,
+    last_accessed_at,
+    expires_at,
+    deleted_at,
+    source,
+    owner,
+    utm_source,
+    utm_medium,
+    utm_campaign,
+    redirect_status,
+    paused,
+    slug_key,
+    (xmax = 0)::boolean AS inserted
 `
 
-func (q *Queries) DeleteLink(ctx context.Context, slug string) error {
-	_, err := q.db.Exec(ctx, deleteLink, slug)
-	return err
+type CreateOrUpdateLinkParams struct {
+	ID             uuid.UUID
+	OriginalUrl    string
+	Slug           string
+	Source         pgtype.Text
+	Owner          pgtype.Text
+	UtmSource      pgtype.Text
+	UtmMedium      pgtype.Text
+	UtmCampaign    pgtype.Text
+	RedirectStatus pgtype.Int2
+	SlugKey        string
+	ExpiresAt      pgtype.Timestamptz
+}
+
+type CreateOrUpdateLinkRow struct {
+	ID                uuid.UUID
+	OriginalUrl       string
+	Slug              string
+	AccessCount       int64
+	UniqueAccessCount int64
+	CreatedAt         pgtype.Timestamptz
+	UpdatedAt         pgtype.Timestamptz
+	LastAccessedAt    pgtype.Timestamptz
+	ExpiresAt         pgtype.Timestamptz
+	DeletedAt         pgtype.Timestamptz
+	Source            pgtype.Text
+	Owner             pgtype.Text
+	UtmSource         pgtype.Text
+	UtmMedium         pgtype.Text
+	UtmCampaign       pgtype.Text
+	RedirectStatus    pgtype.Int2
+	Paused            bool
+	SlugKey           string
+	Inserted          bool
+}
+
+// Inserts the link, or points the live link already using its slug key at
+// the new URL. A slug held by a soft-deleted link updates nothing and returns
+// no row. inserted is false when an existing link was updated.
+func (q *Queries) CreateOrUpdateLink(ctx context.Context, arg CreateOrUpdateLinkParams) (CreateOrUpdateLinkRow, error) {
+	row := q.db.QueryRow(ctx, createOrUpdateLink,
+		arg.ID,
+		arg.OriginalUrl,
+		arg.Slug,
+		arg.Source,
+		arg.Owner,
+		arg.UtmSource,
+		arg.UtmMedium,
+		arg.UtmCampaign,
+		arg.RedirectStatus,
+		arg.SlugKey,
+		arg.ExpiresAt,
+	)
+	var i CreateOrUpdateLinkRow
+	err := row.Scan(
+		&i.ID,
+		&i.OriginalUrl,
+		&i.Slug,
+		&i.AccessCount,
+		&i.UniqueAccessCount,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.LastAccessedAt,
+		&i.ExpiresAt,
+		&i.DeletedAt,
+		&i.Source,
+		&i.Owner,
+		&i.UtmSource,
+		&i.UtmMedium,
+		&i.UtmCampaign,
+		&i.RedirectStatus,
+		&i.Paused,
+		&i.SlugKey,
+		&i.Inserted,
+	)
+	return i, err
+}
+
+const deleteLink = `-- name: DeleteLink :one
+UPDATE links
+SET deleted_at = now()
+WHERE slug_key = $1
+  AND deleted_at IS NULL
+RETURNING
+  id,
+  original_url,
+  slug,
+  access_count,
+  unique_access_count,
+  created_at,
+  updated_at,
+  last_accessed_at,
+  expires_at,
+  deleted_at,
+  source,
+  owner,
+  utm_source,
+  utm_medium,
+  utm_campaign,
+  redirect_status,
+  paused,
+  slug_key
+`
+
+// Soft delete: the row is hard-deleted later by PurgeDeletedLinks.
+func (q *Queries) DeleteLink(ctx context.Context, slugKey string) (Link, error) {
+	row := q.db.QueryRow(ctx, deleteLink, slugKey)
+	var i Link
+	err := row.Scan(
+		&i.ID,
+		&i.OriginalUrl,
+		&i.Slug,
+		&i.AccessCount,
+		&i.UniqueAccessCount,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.LastAccessedAt,
+		&i.ExpiresAt,
+		&i.DeletedAt,
+		&i.Source,
+		&i.Owner,
+		&i.UtmSource,
+		&i.UtmMedium,
+		&i.UtmCampaign,
+		&i.RedirectStatus,
+		&i.Paused,
+		&i.SlugKey,
+	)
+	return i, err
+}
+
+const getClickTimeSeries = `-- name: GetClickTimeSeries :many
+SELECT
+    date_trunc($1::text, clicked_at, 'UTC')::timestamptz AS bucket_start,
+    count(*) AS clicks
+FROM link_clicks
+WHERE link_id = $2
+  AND clicked_at >= $3::timestamptz
+  AND clicked_at < $4::timestamptz
+GROUP BY bucket_start
+ORDER BY bucket_start
+`
+
+type GetClickTimeSeriesParams struct {
+	Bucket   string
+	LinkID   uuid.UUID
+	FromTime pgtype.Timestamptz
+	ToTime   pgtype.Timestamptz
+}
+
+type GetClickTimeSeriesRow struct {
+	BucketStart pgtype.Timestamptz
+	Clicks      int64
+}
+
+// Only buckets with at least one click are returned.
+func (q *Queries) GetClickTimeSeries(ctx context.Context, arg GetClickTimeSeriesParams) ([]GetClickTimeSeriesRow, error) {
+	rows, err := q.db.Query(ctx, getClickTimeSeries,
+		arg.Bucket,
+		arg.LinkID,
+		arg.FromTime,
+		arg.ToTime,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetClickTimeSeriesRow
+	for rows.Next() {
+		var i GetClickTimeSeriesRow
+		if err := rows.Scan(&i.BucketStart, &i.Clicks); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getLinkByID = `-- name: GetLinkByID :one
+SELECT
+    id,
+    original_url,
+    slug,
+    access_count,
+    unique_access_count,
+    created_at,
+    updated_at,
+    last_accessed_at,
+    expires_at,
+    deleted_at,
+    source,
+    owner,
+    utm_source,
+    utm_medium,
+    utm_campaign,
+    redirect_status,
+    paused,
+    slug_key
+FROM links
+WHERE id = $1
+  AND deleted_at IS NULL
+`
+
+func (q *Queries) GetLinkByID(ctx context.Context, id uuid.UUID) (Link, error) {
+	row := q.db.QueryRow(ctx, getLinkByID, id)
+	var i Link
+	err := row.Scan(
+		&i.ID,
+		&i.OriginalUrl,
+		&i.Slug,
+		&i.AccessCount,
+		&i.UniqueAccessCount,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.LastAccessedAt,
+		&i.ExpiresAt,
+		&i.DeletedAt,
+		&i.Source,
+		&i.Owner,
+		&i.UtmSource,
+		&i.UtmMedium,
+		&i.UtmCampaign,
+		&i.RedirectStatus,
+		&i.Paused,
+		&i.SlugKey,
+	)
+	return i, err
 }
 
 const getLinkBySLug = `-- name: GetLinkBySLug :one
@@ -66,55 +507,424 @@ SELECT
     original_url,
     slug,
     access_count,
+    unique_access_count,
     created_at,
     updated_at,
-    last_accessed_at
+    last_accessed_at,
+    expires_at,
+    deleted_at,
+    source,
+    owner,
+    utm_source,
+    utm_medium,
+    utm_campaign,
+    redirect_status,
+    paused,
+    slug_key
 FROM links
-WHERE slug = $1
+WHERE slug_key = $1
+  AND deleted_at IS NULL
 `
 
-func (q *Queries) GetLinkBySLug(ctx context.Context, slug string) (Link, error) {
-	row := q.db.QueryRow(ctx, getLinkBySLug, slug)
+func (q *Queries) GetLinkBySLug(ctx context.Context, slugKey string) (Link, error) {
+	row := q.db.QueryRow(ctx, getLinkBySLug, slugKey)
 	var i Link
 	err := row.Scan(
 		&i.ID,
 		&i.OriginalUrl,
 		&i.Slug,
 		&i.AccessCount,
+		&i.UniqueAccessCount,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.LastAccessedAt,
+		&i.ExpiresAt,
+		&i.DeletedAt,
+		&i.Source,
+		&i.Owner,
+		&i.UtmSource,
+		&i.UtmMedium,
+		&i.UtmCampaign,
+		&i.RedirectStatus,
+		&i.Paused,
+		&i.SlugKey,
 	)
 	return i, err
 }
 
+const getLinksByURL = `-- name: GetLinksByURL :many
+SELECT
+    id,
+    original_url,
+    slug,
+    access_count,
+    unique_access_count,
+    created_at,
+    updated_at,
+    last_accessed_at,
+    expires_at,
+    deleted_at,
+    source,
+    owner,
+    utm_source,
+    utm_medium,
+    utm_campaign,
+    redirect_status,
+    paused,
+    slug_key
+FROM links
+WHERE original_url = $1
+  AND deleted_at IS NULL
+ORDER BY created_at DESC, id DESC
+`
+
+func (q *Queries) GetLinksByURL(ctx context.Context, originalUrl string) ([]Link, error) {
+	rows, err := q.db.Query(ctx, getLinksByURL, originalUrl)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Link
+	for rows.Next() {
+		var i Link
+		if err := rows.Scan(
+			&i.ID,
+			&i.OriginalUrl,
+			&i.Slug,
+			&i.AccessCount,
+			&i.UniqueAccessCount,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.LastAccessedAt,
+			&i.ExpiresAt,
+			&i.DeletedAt,
+			&i.Source,
+			&i.Owner,
+			&i.UtmSource,
+			&i.UtmMedium,
+			&i.UtmCampaign,
+			&i.RedirectStatus,
+			&i.Paused,
+			&i.SlugKey,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTakenSlugs = `-- name: GetTakenSlugs :many
+SELECT slug_key
+FROM links
+WHERE slug_key = ANY($1::text[])
+UNION
+SELECT alias_key
+FROM link_aliases
+WHERE alias_key = ANY($1::text[])
+`
+
+// Includes soft-deleted links: their slug keys still hold the unique
+// constraint. Aliases share the slug namespace, so they count as taken too.
+func (q *Queries) GetTakenSlugs(ctx context.Context, slugKeys []string) ([]string, error) {
+	rows, err := q.db.Query(ctx, getTakenSlugs, slugKeys)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var slug_key string
+		if err := rows.Scan(&slug_key); err != nil {
+			return nil, err
+		}
+		items = append(items, slug_key)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listLinks = `-- name: ListLinks :many
+SELECT
+    id,
+    original_url,
+    slug,
+    access_count,
+    unique_access_count,
+    created_at,
+    updated_at,
+    last_accessed_at,
+    expires_at,
+    deleted_at,
+    source,
+    owner,
+    utm_source,
+    utm_medium,
+    utm_campaign,
+    redirect_status,
+    paused,
+    slug_key
+FROM links
+WHERE deleted_at IS NULL
+  AND ($1::timestamptz IS NULL
+   OR (created_at, id) < ($1::timestamptz, $2::uuid))
+ORDER BY created_at DESC, id DESC
+LIMIT $3
+`
+
+type ListLinksParams struct {
+	CursorCreatedAt pgtype.Timestamptz
+	CursorID        pgtype.UUID
+	RowLimit        int32
+}
+
+// Keyset pagination, newest first. A NULL cursor starts from the top.
+func (q *Queries) ListLinks(ctx context.Context, arg ListLinksParams) ([]Link, error) {
+	rows, err := q.db.Query(ctx, listLinks, arg.CursorCreatedAt, arg.CursorID, arg.RowLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Link
+	for rows.Next() {
+		var i Link
+		if err := rows.Scan(
+			&i.ID,
+			&i.OriginalUrl,
+			&i.Slug,
+			&i.AccessCount,
+			&i.UniqueAccessCount,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.LastAccessedAt,
+			&i.ExpiresAt,
+			&i.DeletedAt,
+			&i.Source,
+			&i.Owner,
+			&i.UtmSource,
+			&i.UtmMedium,
+			&i.UtmCampaign,
+			&i.RedirectStatus,
+			&i.Paused,
+			&i.SlugKey,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const purgeDeletedLinks = `-- name: PurgeDeletedLinks :execrows
+DELETE FROM links
+WHERE id IN (
+    SELECT id FROM links
+    WHERE deleted_at < $1::timestamptz
+    LIMIT $2
+)
+`
+
+type PurgeDeletedLinksParams struct {
+	DeletedBefore pgtype.Timestamptz
+	BatchSize     int32
+}
+
+func (q *Queries) PurgeDeletedLinks(ctx context.Context, arg PurgeDeletedLinksParams) (int64, error) {
+	result, err := q.db.Exec(ctx, purgeDeletedLinks, arg.DeletedBefore, arg.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const purgeExpiredLinks = `-- name: PurgeExpiredLinks :execrows
+DELETE FROM links
+WHERE id IN (
+    SELECT id FROM links
+    WHERE expires_at < $1::timestamptz
+    LIMIT $2
+)
+`
+
+type PurgeExpiredLinksParams struct {
+	ExpiredBefore pgtype.Timestamptz
+	BatchSize     int32
+}
+
+func (q *Queries) PurgeExpiredLinks(ctx context.Context, arg PurgeExpiredLinksParams) (int64, error) {
+	result, err := q.db.Exec(ctx, purgeExpiredLinks, arg.ExpiredBefore, arg.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const recordClick = `-- name: RecordClick :exec
+INSERT INTO link_clicks (link_id, request_id, country)
+VALUES ($1, $2, $3)
+`
+
+type RecordClickParams struct {
+	LinkID    uuid.UUID
+	RequestID pgtype.Text
+	Country   pgtype.Text
+}
+
+func (q *Queries) RecordClick(ctx context.Context, arg RecordClickParams) error {
+	_, err := q.db.Exec(ctx, recordClick, arg.LinkID, arg.RequestID, arg.Country)
+	return err
+}
+
 const resolveAndTrackLink = `-- name: ResolveAndTrackLink :one
 UPDATE links
 SET
-  access_count     = access_count + 1,
-  last_accessed_at = now()
-WHERE slug = $1
+  -- Saturate rather than fail with bigint out of range
+  access_count     = access_count + (access_count < 9223372036854775807)::int,
+  last_accessed_at = $1::timestamptz
+WHERE slug_key = $2
+  AND deleted_at IS NULL
+  AND NOT paused
+  AND (expires_at IS NULL OR expires_at > $3::timestamptz)
 RETURNING
   id,
   original_url,
   slug,
   access_count,
+  unique_access_count,
   created_at,
   updated_at,
-  last_accessed_at
+  last_accessed_at,
+  expires_at,
+  deleted_at,
+  source,
+  owner,
+  utm_source,
+  utm_medium,
+  utm_campaign,
+  redirect_status,
+  paused,
+  slug_key
 `
 
-func (q *Queries) ResolveAndTrackLink(ctx context.Context, slug string) (Link, error) {
-	row := q.db.QueryRow(ctx, resolveAndTrackLink, slug)
+type ResolveAndTrackLinkParams struct {
+	Now          pgtype.Timestamptz
+	SlugKey      string
+	ExpiresAfter pgtype.Timestamptz
+}
+
+func (q *Queries) ResolveAndTrackLink(ctx context.Context, arg ResolveAndTrackLinkParams) (Link, error) {
+	row := q.db.QueryRow(ctx, resolveAndTrackLink, arg.Now, arg.SlugKey, arg.ExpiresAfter)
 	var i Link
 	err := row.Scan(
 		&i.ID,
 		&i.OriginalUrl,
 		&i.Slug,
 		&i.AccessCount,
+		&i.UniqueAccessCount,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.LastAccessedAt,
+		&i.ExpiresAt,
+		&i.DeletedAt,
+		&i.Source,
+		&i.Owner,
+		&i.UtmSource,
+		&i.UtmMedium,
+		&i.UtmCampaign,
+		&i.RedirectStatus,
+		&i.Paused,
+		&i.SlugKey,
 	)
 	return i, err
 }
+
+const setLinkPaused = `-- name: SetLinkPaused :one
+UPDATE links
+SET paused = $1
+WHERE slug_key = $2
+  AND deleted_at IS NULL
+RETURNING
+  id,
+  original_url,
+  slug,
+  access_count,
+  unique_access_count,
+  created_at,
+  updated_at,
+  last_accessed_at,
+  expires_at,
+  deleted_at,
+  source,
+  owner,
+  utm_source,
+  utm_medium,
+  utm_campaign,
+  redirect_status,
+  paused,
+  slug_key
+`
+
+type SetLinkPausedParams struct {
+	Paused  bool
+	SlugKey string
+}
+
+// Pausing keeps the link's counts and history; resuming undoes it in place.
+func (q *Queries) SetLinkPaused(ctx context.Context, arg SetLinkPausedParams) (Link, error) {
+	row := q.db.QueryRow(ctx, setLinkPaused, arg.Paused, arg.SlugKey)
+	var i Link
+	err := row.Scan(
+		&i.ID,
+		&i.OriginalUrl,
+		&i.Slug,
+		&i.AccessCount,
+		&i.UniqueAccessCount,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.LastAccessedAt,
+		&i.ExpiresAt,
+		&i.DeletedAt,
+		&i.Source,
+		&i.Owner,
+		&i.UtmSource,
+		&i.UtmMedium,
+		&i.UtmCampaign,
+		&i.RedirectStatus,
+		&i.Paused,
+		&i.SlugKey,
+	)
+	return i, err
+}
+
+const trackUniqueVisitor = `-- name: TrackUniqueVisitor :execrows
+WITH first_visit AS (
+    INSERT INTO link_visitors (link_id, fingerprint)
+    VALUES ($1, $2)
+    ON CONFLICT DO NOTHING
+    RETURNING link_id
+)
+UPDATE links
+SET unique_access_count = unique_access_count + 1
+WHERE id IN (SELECT link_id FROM first_visit)
+`
+
+type TrackUniqueVisitorParams struct {
+	LinkID      uuid.UUID
+	Fingerprint string
+}
+
+func (q *Queries) TrackUniqueVisitor(ctx context.Context, arg TrackUniqueVisitorParams) (int64, error) {
+	result, err := q.db.Exec(ctx, trackUniqueVisitor, arg.LinkID, arg.Fingerprint)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
@@ -0,0 +1,37 @@
+package errx
+
+import "sync/atomic"
+
+// numKinds is the number of defined kinds; Timeout is the last.
+const numKinds = int(Timeout) + 1
+
+// Counts tallies errors by Kind, for alerting on spikes of a kind. It is
+// safe for concurrent use, and the zero value is ready to use.
+type Counts struct {
+	n [numKinds]atomic.Uint64
+}
+
+// Add counts one error of kind. Undefined kinds count as Unknown.
+func (c *Counts) Add(kind Kind) {
+	if int(kind) >= numKinds {
+		kind = Unknown
+	}
+	c.n[kind].Add(1)
+}
+
+// Get returns the number of errors of kind counted so far.
+func (c *Counts) Get(kind Kind) uint64 {
+	if int(kind) >= numKinds {
+		return 0
+	}
+	return c.n[kind].Load()
+}
+
+// Snapshot returns the current count of every kind, keyed by its name.
+func (c *Counts) Snapshot() map[string]uint64 {
+	out := make(map[string]uint64, numKinds)
+	for k := range numKinds {
+		out[Kind(k).String()] = c.n[k].Load()
+	}
+	return out
+}
@@ -0,0 +1,35 @@
+package errx
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestCounts(t *testing.T) {
+	var c Counts
+
+	var wg sync.WaitGroup
+	for range 50 {
+		wg.Go(func() {
+			c.Add(Conflict)
+			c.Add(Unavailable)
+		})
+	}
+	wg.Wait()
+	c.Add(Kind(200))
+
+	if got := c.Get(Conflict); got != 50 {
+		t.Errorf("Get(Conflict) = %d, want 50", got)
+	}
+	if got := c.Get(Unknown); got != 1 {
+		t.Errorf("Get(Unknown) = %d, want 1 for the undefined kind", got)
+	}
+
+	snap := c.Snapshot()
+	if snap["Unavailable"] != 50 || snap["NotFound"] != 0 {
+		t.Errorf("Snapshot() = %v, want 50 Unavailable and 0 NotFound", snap)
+	}
+	if _, ok := snap["Timeout"]; !ok {
+		t.Errorf("Snapshot() = %v, want every kind listed", snap)
+	}
+}
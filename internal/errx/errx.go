@@ -20,6 +20,8 @@ const (
 	Forbidden
 	Unavailable
 	Internal
+	QuotaExceeded
+	Timeout
 )
 
 type Error struct {
@@ -58,6 +60,10 @@ func (k Kind) String() string {
 		return "Unavailable"
 	case Internal:
 		return "Internal"
+	case QuotaExceeded:
+		return "QuotaExceeded"
+	case Timeout:
+		return "Timeout"
 	default:
 		return fmt.Sprintf("Kind(%d)", k)
 	}
@@ -284,6 +284,8 @@ func TestKind_String(t *testing.T) {
 		{Forbidden, "Forbidden"},
 		{Unavailable, "Unavailable"},
 		{Internal, "Internal"},
+		{QuotaExceeded, "QuotaExceeded"},
+		{Timeout, "Timeout"},
 		{Kind(99), "Kind(99)"}, // Unknown kind value
 	}
 
@@ -0,0 +1,210 @@
+// Package health provides background health monitoring for application dependencies.
+package health
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	// DefaultPoolCheckInterval is used when no interval is configured.
+	DefaultPoolCheckInterval = 30 * time.Second
+	// DefaultPoolCheckTimeout bounds a single ping.
+	DefaultPoolCheckTimeout = 5 * time.Second
+)
+
+// PoolStats is a point-in-time view of connection pool usage.
+type PoolStats struct {
+	AcquiredConns int32 `json:"acquired_conns"`
+	IdleConns     int32 `json:"idle_conns"`
+	TotalConns    int32 `json:"total_conns"`
+	MaxConns      int32 `json:"max_conns"`
+}
+
+// Saturated reports whether every connection in the pool is in use.
+func (s PoolStats) Saturated() bool {
+	return s.MaxConns > 0 && s.AcquiredConns >= s.MaxConns
+}
+
+// Pool is the subset of a connection pool the monitor depends on.
+type Pool interface {
+	Ping(ctx context.Context) error
+	Stats() PoolStats
+}
+
+// pgxPool adapts *pgxpool.Pool to the Pool interface.
+type pgxPool struct {
+	pool *pgxpool.Pool
+}
+
+// PgxPool wraps a pgx connection pool so it can be monitored.
+func PgxPool(pool *pgxpool.Pool) Pool {
+	return &pgxPool{pool: pool}
+}
+
+func (p *pgxPool) Ping(ctx context.Context) error {
+	return p.pool.Ping(ctx)
+}
+
+func (p *pgxPool) Stats() PoolStats {
+	st := p.pool.Stat()
+	return PoolStats{
+		AcquiredConns: st.AcquiredConns(),
+		IdleConns:     st.IdleConns(),
+		TotalConns:    st.TotalConns(),
+		MaxConns:      st.MaxConns(),
+	}
+}
+
+// Snapshot is the result of the most recent pool check.
+type Snapshot struct {
+	Healthy   bool      `json:"healthy"`
+	Error     string    `json:"error,omitempty"`
+	Stats     PoolStats `json:"stats"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// PoolMonitorConfig holds configuration for the pool monitor.
+type PoolMonitorConfig struct {
+	Pool     Pool
+	Interval time.Duration
+	Timeout  time.Duration
+	Logger   *slog.Logger
+}
+
+// PoolMonitor periodically pings a connection pool and records its stats.
+// It is safe for concurrent use.
+type PoolMonitor struct {
+	pool     Pool
+	interval time.Duration
+	timeout  time.Duration
+	logger   *slog.Logger
+
+	mu     sync.RWMutex
+	latest Snapshot
+	seen   bool
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewPoolMonitor creates a new PoolMonitor. Call Start to begin monitoring.
+func NewPoolMonitor(cfg PoolMonitorConfig) *PoolMonitor {
+	interval := cfg.Interval
+	if interval <= 0 {
+		interval = DefaultPoolCheckInterval
+	}
+
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = DefaultPoolCheckTimeout
+	}
+
+	logger := cfg.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &PoolMonitor{
+		pool:     cfg.Pool,
+		interval: interval,
+		timeout:  timeout,
+		logger:   logger,
+	}
+}
+
+// Start runs an initial check and then keeps checking in the background
+// until Stop is called or ctx is cancelled. Calling Start twice is a no-op.
+func (m *PoolMonitor) Start(ctx context.Context) {
+	m.mu.Lock()
+	if m.done != nil {
+		m.mu.Unlock()
+		return
+	}
+	ctx, m.cancel = context.WithCancel(ctx)
+	m.done = make(chan struct{})
+	m.mu.Unlock()
+
+	m.Check(ctx)
+
+	go func() {
+		defer close(m.done)
+
+		ticker := time.NewTicker(m.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				m.Check(ctx)
+			}
+		}
+	}()
+}
+
+// Stop halts background checks and waits for the monitor goroutine to exit.
+func (m *PoolMonitor) Stop() {
+	m.mu.RLock()
+	cancel, done := m.cancel, m.done
+	m.mu.RUnlock()
+
+	if cancel == nil {
+		return
+	}
+	cancel()
+	<-done
+}
+
+// Check pings the pool once, records the result, and returns it.
+func (m *PoolMonitor) Check(ctx context.Context) Snapshot {
+	ctx, cancel := context.WithTimeout(ctx, m.timeout)
+	defer cancel()
+
+	snap := Snapshot{
+		Healthy:   true,
+		CheckedAt: time.Now(),
+	}
+
+	if err := m.pool.Ping(ctx); err != nil {
+		snap.Healthy = false
+		snap.Error = err.Error()
+	}
+	snap.Stats = m.pool.Stats()
+
+	m.mu.Lock()
+	m.latest = snap
+	m.seen = true
+	m.mu.Unlock()
+
+	logAttrs := []any{
+		"acquired_conns", snap.Stats.AcquiredConns,
+		"idle_conns", snap.Stats.IdleConns,
+		"total_conns", snap.Stats.TotalConns,
+		"max_conns", snap.Stats.MaxConns,
+	}
+
+	switch {
+	case !snap.Healthy:
+		m.logger.ErrorContext(ctx, "database ping failed", append(logAttrs, "error", snap.Error)...)
+	case snap.Stats.Saturated():
+		m.logger.WarnContext(ctx, "database connection pool saturated", logAttrs...)
+	default:
+		m.logger.DebugContext(ctx, "database pool stats", logAttrs...)
+	}
+
+	return snap
+}
+
+// Snapshot returns the latest recorded check. The boolean is false if no
+// check has run yet.
+func (m *PoolMonitor) Snapshot() (Snapshot, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.latest, m.seen
+}
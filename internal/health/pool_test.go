@@ -0,0 +1,144 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+)
+
+// stubPool implements Pool for testing.
+type stubPool struct {
+	mu      sync.Mutex
+	pingErr error
+	stats   PoolStats
+	pings   int
+}
+
+func (p *stubPool) Ping(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.pings++
+	return p.pingErr
+}
+
+func (p *stubPool) Stats() PoolStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.stats
+}
+
+func (p *stubPool) pingCount() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.pings
+}
+
+func discardLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func TestPoolMonitor_RecordsStats(t *testing.T) {
+	pool := &stubPool{stats: PoolStats{AcquiredConns: 3, IdleConns: 2, TotalConns: 5, MaxConns: 10}}
+	m := NewPoolMonitor(PoolMonitorConfig{
+		Pool:     pool,
+		Interval: time.Hour,
+		Logger:   discardLogger(),
+	})
+
+	if _, ok := m.Snapshot(); ok {
+		t.Fatal("Snapshot() ok = true before any check")
+	}
+
+	m.Start(context.Background())
+	defer m.Stop()
+
+	snap, ok := m.Snapshot()
+	if !ok {
+		t.Fatal("Snapshot() ok = false after Start")
+	}
+	if !snap.Healthy {
+		t.Errorf("Healthy = false, want true")
+	}
+	if snap.Stats != pool.stats {
+		t.Errorf("Stats = %+v, want %+v", snap.Stats, pool.stats)
+	}
+	if snap.CheckedAt.IsZero() {
+		t.Error("CheckedAt is zero")
+	}
+}
+
+func TestPoolMonitor_RecordsPingFailure(t *testing.T) {
+	pool := &stubPool{pingErr: errors.New("connection refused")}
+	m := NewPoolMonitor(PoolMonitorConfig{Pool: pool, Logger: discardLogger()})
+
+	snap := m.Check(context.Background())
+	if snap.Healthy {
+		t.Error("Healthy = true, want false")
+	}
+	if snap.Error != "connection refused" {
+		t.Errorf("Error = %q, want %q", snap.Error, "connection refused")
+	}
+
+	latest, ok := m.Snapshot()
+	if !ok || latest.Healthy {
+		t.Errorf("Snapshot() = %+v, %v; want unhealthy snapshot", latest, ok)
+	}
+}
+
+func TestPoolMonitor_ChecksPeriodically(t *testing.T) {
+	pool := &stubPool{}
+	m := NewPoolMonitor(PoolMonitorConfig{
+		Pool:     pool,
+		Interval: 5 * time.Millisecond,
+		Logger:   discardLogger(),
+	})
+
+	m.Start(context.Background())
+
+	deadline := time.Now().Add(time.Second)
+	for pool.pingCount() < 3 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	m.Stop()
+
+	if got := pool.pingCount(); got < 3 {
+		t.Fatalf("ping count = %d, want at least 3", got)
+	}
+
+	// No further checks after Stop returns.
+	stopped := pool.pingCount()
+	time.Sleep(20 * time.Millisecond)
+	if got := pool.pingCount(); got != stopped {
+		t.Errorf("ping count changed after Stop: %d -> %d", stopped, got)
+	}
+}
+
+func TestPoolMonitor_StopWithoutStart(t *testing.T) {
+	m := NewPoolMonitor(PoolMonitorConfig{Pool: &stubPool{}, Logger: discardLogger()})
+	m.Stop() // must not block or panic
+}
+
+func TestPoolStats_Saturated(t *testing.T) {
+	tests := []struct {
+		name  string
+		stats PoolStats
+		want  bool
+	}{
+		{"idle pool", PoolStats{AcquiredConns: 0, MaxConns: 10}, false},
+		{"partially used", PoolStats{AcquiredConns: 9, MaxConns: 10}, false},
+		{"fully used", PoolStats{AcquiredConns: 10, MaxConns: 10}, true},
+		{"unknown max", PoolStats{AcquiredConns: 5}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.stats.Saturated(); got != tt.want {
+				t.Errorf("Saturated() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
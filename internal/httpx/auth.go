@@ -0,0 +1,103 @@
+package httpx
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+	"strings"
+)
+
+const (
+	// APIKeyHeader is the header name for API keys. A bearer token in the
+	// Authorization header is accepted as well.
+	APIKeyHeader = "X-API-Key"
+)
+
+const principalContextKey contextKey = "principal"
+
+// GetPrincipal extracts the authenticated principal from context.
+// Returns empty string if the request was not authenticated.
+func GetPrincipal(ctx context.Context) string {
+	if p, ok := ctx.Value(principalContextKey).(string); ok {
+		return p
+	}
+	return ""
+}
+
+// WithPrincipal adds an authenticated principal to the context.
+// This is useful for testing or manually authenticating requests.
+func WithPrincipal(ctx context.Context, principal string) context.Context {
+	return context.WithValue(ctx, principalContextKey, principal)
+}
+
+// APIKeyAuth is a middleware that requires a known API key and stores the
+// matching principal in the request context. keys maps API key to principal.
+// With no keys configured every request is rejected, so protected routes
+// fail closed.
+func APIKeyAuth(keys map[string]string) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, ok := lookupAPIKey(keys, apiKeyFromRequest(r))
+			if !ok {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="api"`)
+				WriteError(w, http.StatusUnauthorized, "unauthorized",
+					"a valid API key is required", nil)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(WithPrincipal(r.Context(), principal)))
+		})
+	}
+}
+
+// OptionalAPIKeyAuth is like APIKeyAuth but lets requests without a key
+// through anonymously. A key that is presented must still be valid.
+func OptionalAPIKeyAuth(keys map[string]string) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := apiKeyFromRequest(r)
+			if key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			principal, ok := lookupAPIKey(keys, key)
+			if !ok {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="api"`)
+				WriteError(w, http.StatusUnauthorized, "unauthorized",
+					"invalid API key", nil)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(WithPrincipal(r.Context(), principal)))
+		})
+	}
+}
+
+// apiKeyFromRequest reads the key from X-API-Key or an Authorization bearer token.
+func apiKeyFromRequest(r *http.Request) string {
+	if key := r.Header.Get(APIKeyHeader); key != "" {
+		return key
+	}
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		return strings.TrimSpace(token)
+	}
+	return ""
+}
+
+// lookupAPIKey compares key against every configured key in constant time
+// so response timing doesn't reveal how much of a key matched.
+func lookupAPIKey(keys map[string]string, key string) (string, bool) {
+	if key == "" {
+		return "", false
+	}
+
+	var principal string
+	found := false
+	for k, p := range keys {
+		if subtle.ConstantTimeCompare([]byte(k), []byte(key)) == 1 {
+			principal, found = p, true
+		}
+	}
+	return principal, found
+}
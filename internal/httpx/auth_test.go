@@ -0,0 +1,103 @@
+package httpx
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAPIKeyAuth(t *testing.T) {
+	keys := map[string]string{"secret-1": "acme", "secret-2": "globex"}
+
+	tests := []struct {
+		name          string
+		keys          map[string]string
+		header        string
+		value         string
+		wantStatus    int
+		wantPrincipal string
+	}{
+		{"X-API-Key header", keys, APIKeyHeader, "secret-1", http.StatusOK, "acme"},
+		{"bearer token", keys, "Authorization", "Bearer secret-2", http.StatusOK, "globex"},
+		{"missing key", keys, "", "", http.StatusUnauthorized, ""},
+		{"unknown key", keys, APIKeyHeader, "nope", http.StatusUnauthorized, ""},
+		{"non-bearer authorization", keys, "Authorization", "Basic secret-1", http.StatusUnauthorized, ""},
+		{"no keys configured", nil, APIKeyHeader, "secret-1", http.StatusUnauthorized, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotPrincipal string
+			handler := APIKeyAuth(tt.keys)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotPrincipal = GetPrincipal(r.Context())
+			}))
+
+			req := httptest.NewRequest("GET", "/", nil)
+			if tt.header != "" {
+				req.Header.Set(tt.header, tt.value)
+			}
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, rr.Code)
+			}
+			if gotPrincipal != tt.wantPrincipal {
+				t.Errorf("expected principal %q, got %q", tt.wantPrincipal, gotPrincipal)
+			}
+			if tt.wantStatus == http.StatusUnauthorized && rr.Header().Get("WWW-Authenticate") == "" {
+				t.Error("expected WWW-Authenticate header on 401")
+			}
+		})
+	}
+}
+
+func TestOptionalAPIKeyAuth(t *testing.T) {
+	keys := map[string]string{"secret-1": "acme"}
+
+	tests := []struct {
+		name          string
+		value         string
+		wantStatus    int
+		wantPrincipal string
+	}{
+		{"valid key", "secret-1", http.StatusOK, "acme"},
+		{"no key is anonymous", "", http.StatusOK, ""},
+		{"unknown key", "nope", http.StatusUnauthorized, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotPrincipal string
+			handler := OptionalAPIKeyAuth(keys)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotPrincipal = GetPrincipal(r.Context())
+			}))
+
+			req := httptest.NewRequest("GET", "/", nil)
+			if tt.value != "" {
+				req.Header.Set(APIKeyHeader, tt.value)
+			}
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, rr.Code)
+			}
+			if gotPrincipal != tt.wantPrincipal {
+				t.Errorf("expected principal %q, got %q", tt.wantPrincipal, gotPrincipal)
+			}
+		})
+	}
+}
+
+func TestWithPrincipal(t *testing.T) {
+	if got := GetPrincipal(context.Background()); got != "" {
+		t.Errorf("expected empty principal, got %q", got)
+	}
+
+	ctx := WithPrincipal(context.Background(), "acme")
+	if got := GetPrincipal(ctx); got != "acme" {
+		t.Errorf("expected principal %q, got %q", "acme", got)
+	}
+}
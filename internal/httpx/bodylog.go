@@ -0,0 +1,110 @@
+package httpx
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// DefaultBodyLogMaxBytes caps the logged copy of a request body.
+const DefaultBodyLogMaxBytes = 2 << 10
+
+// DefaultRedactedFields are JSON fields commonly carrying credentials.
+var DefaultRedactedFields = []string{"password", "token", "secret", "api_key"}
+
+// BodyLogConfig configures BodyLogger.
+type BodyLogConfig struct {
+	Logger *slog.Logger
+
+	// MaxBytes caps the logged copy of each body; the handler still reads
+	// all of it (default: DefaultBodyLogMaxBytes).
+	MaxBytes int
+
+	// RedactFields names JSON fields, matched case-insensitively at any
+	// depth, whose string or scalar values are logged as RedactedValue.
+	RedactFields []string
+}
+
+// BodyLogger logs a copy of each request body at DEBUG once the request has
+// been served, to help debug rejected creates. The copy is teed off as the
+// handler reads, so the handler sees the body unchanged; only what the
+// handler read is logged, capped at MaxBytes with truncated=true when cut.
+// Configured fields are redacted even in a truncated copy. Bodies may hold
+// personal data, so this is meant for development only.
+func BodyLogger(cfg BodyLogConfig) Middleware {
+	logger := cfg.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	maxBytes := cfg.MaxBytes
+	if maxBytes <= 0 {
+		maxBytes = DefaultBodyLogMaxBytes
+	}
+	redact := fieldRedactor(cfg.RedactFields)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Body == nil || r.Body == http.NoBody {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			tee := &bodyTee{ReadCloser: r.Body, max: maxBytes}
+			r.Body = tee
+			next.ServeHTTP(w, r)
+
+			body := tee.copy.String()
+			if redact != nil {
+				body = redact(body)
+			}
+			logger.DebugContext(r.Context(), "http request body",
+				"request_id", GetRequestID(r.Context()),
+				"method", r.Method,
+				"path", r.URL.Path,
+				"body", body,
+				"body_bytes", tee.n,
+				"truncated", tee.n > int64(maxBytes),
+			)
+		})
+	}
+}
+
+// bodyTee copies up to max bytes of what is read through it.
+type bodyTee struct {
+	io.ReadCloser
+	max  int
+	copy bytes.Buffer
+	n    int64
+}
+
+func (t *bodyTee) Read(p []byte) (int, error) {
+	n, err := t.ReadCloser.Read(p)
+	if room := t.max - t.copy.Len(); room > 0 {
+		t.copy.Write(p[:min(n, room)])
+	}
+	t.n += int64(n)
+	return n, err
+}
+
+// fieldRedactor returns a function masking the values of fields in a JSON
+// text that may be cut off anywhere, or nil if there are no fields. A value
+// cut off mid-string is masked too.
+func fieldRedactor(fields []string) func(string) string {
+	var names []string
+	for _, f := range fields {
+		if f = strings.TrimSpace(f); f != "" {
+			names = append(names, regexp.QuoteMeta(f))
+		}
+	}
+	if len(names) == 0 {
+		return nil
+	}
+
+	re := regexp.MustCompile(`(?i)("(?:` + strings.Join(names, "|") + `)"\s*:\s*)("(?:[^"\\]|\\.)*(?:"|\\?$)|[^\s,}\]]+)`)
+	return func(s string) string {
+		return re.ReplaceAllString(s, `${1}"`+RedactedValue+`"`)
+	}
+}
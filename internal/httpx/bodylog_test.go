@@ -0,0 +1,88 @@
+package httpx
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestBodyLogger(t *testing.T) {
+	var buf strings.Builder
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	body := `{"url":"https://example.com","password":"hunter2","nested":{"Token":"abc\"def"},"note":"` +
+		strings.Repeat("x", 100) + `","api_key":"sk-live-123"}`
+
+	var received string
+	handler := BodyLogger(BodyLogConfig{
+		Logger:       logger,
+		MaxBytes:     96,
+		RedactFields: []string{"password", "token", "api_key"},
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		received = string(b)
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/api/links", strings.NewReader(body)))
+
+	if received != body {
+		t.Errorf("handler received %q, want the full body", received)
+	}
+
+	var entry struct {
+		Msg       string `json:"msg"`
+		Body      string `json:"body"`
+		BodyBytes int    `json:"body_bytes"`
+		Truncated bool   `json:"truncated"`
+	}
+	if err := json.Unmarshal([]byte(buf.String()), &entry); err != nil {
+		t.Fatalf("failed to decode log entry %q: %v", buf.String(), err)
+	}
+	if !entry.Truncated || entry.BodyBytes != len(body) {
+		t.Errorf("truncated = %v, body_bytes = %d; want true, %d", entry.Truncated, entry.BodyBytes, len(body))
+	}
+	if !strings.HasPrefix(entry.Body, `{"url":"https://example.com","password":"REDACTED","nested":{"Token":"REDACTED"}`) {
+		t.Errorf("logged body = %q, want password and token redacted", entry.Body)
+	}
+	for _, secret := range []string{"hunter2", "abc", "sk-live"} {
+		if strings.Contains(entry.Body, secret) {
+			t.Errorf("logged body %q leaks %q", entry.Body, secret)
+		}
+	}
+	if strings.Contains(entry.Body, "api_key") {
+		t.Errorf("logged body = %q, want it cut off before api_key", entry.Body)
+	}
+}
+
+func TestFieldRedactor_TruncatedValue(t *testing.T) {
+	redact := fieldRedactor([]string{"password"})
+
+	tests := map[string]string{
+		`{"password":"hunt`:    `{"password":"REDACTED"`,
+		`{"password": 12345`:   `{"password": "REDACTED"`,
+		`{"password":"a\`:      `{"password":"REDACTED"`,
+		`{"passw`:              `{"passw`,
+		`{"password_hint":"x"`: `{"password_hint":"x"`,
+	}
+	for in, want := range tests {
+		if got := redact(in); got != want {
+			t.Errorf("redact(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestBodyLogger_NoBody(t *testing.T) {
+	var buf strings.Builder
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	handler := BodyLogger(BodyLogConfig{Logger: logger})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/abc1234", nil))
+
+	if buf.Len() != 0 {
+		t.Errorf("logged %q for a request without a body", buf.String())
+	}
+}
@@ -0,0 +1,124 @@
+package httpx
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+const clientIPContextKey contextKey = "client_ip"
+
+// ParseTrustedProxies parses proxy addresses given as CIDR prefixes or
+// single IP addresses.
+func ParseTrustedProxies(values []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(values))
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		if prefix, err := netip.ParsePrefix(v); err == nil {
+			prefixes = append(prefixes, prefix.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: want an IP address or CIDR prefix", v)
+		}
+		prefixes = append(prefixes, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+	}
+	return prefixes, nil
+}
+
+// TrustedProxies is a middleware that resolves the client IP of requests
+// relayed by one of the trusted proxies, for ClientIP to return. It walks
+// X-Forwarded-For from the nearest hop back and takes the first address
+// that isn't a trusted proxy, falling back to X-Real-IP when there is no
+// X-Forwarded-For. The headers of requests from any other peer are
+// ignored, since clients can set them to anything. With no trusted proxies
+// it does nothing.
+func TrustedProxies(trusted []netip.Prefix) Middleware {
+	return func(next http.Handler) http.Handler {
+		if len(trusted) == 0 {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if ip, ok := forwardedClientIP(r, trusted); ok {
+				r = r.WithContext(context.WithValue(r.Context(), clientIPContextKey, ip))
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// forwardedClientIP returns the client address the proxy headers name,
+// when the request's peer is a trusted proxy.
+func forwardedClientIP(r *http.Request, trusted []netip.Prefix) (string, bool) {
+	peer, err := netip.ParseAddr(remoteHost(r))
+	if err != nil || !isTrusted(peer, trusted) {
+		return "", false
+	}
+
+	var hops []string
+	for _, v := range r.Header.Values("X-Forwarded-For") {
+		hops = append(hops, strings.Split(v, ",")...)
+	}
+	if len(hops) == 0 {
+		addr, err := netip.ParseAddr(strings.TrimSpace(r.Header.Get("X-Real-IP")))
+		if err != nil {
+			return "", false
+		}
+		return addr.Unmap().String(), true
+	}
+
+	// Every hop may be a trusted proxy, e.g. a health check from inside
+	// the network; the first is then the best guess.
+	var client netip.Addr
+	for i := len(hops) - 1; i >= 0; i-- {
+		addr, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+		if err != nil {
+			break
+		}
+		client = addr.Unmap()
+		if !isTrusted(client, trusted) {
+			break
+		}
+	}
+	if !client.IsValid() {
+		return "", false
+	}
+	return client.String(), true
+}
+
+// isTrusted reports whether addr falls in one of the trusted prefixes.
+func isTrusted(addr netip.Addr, trusted []netip.Prefix) bool {
+	addr = addr.Unmap()
+	for _, prefix := range trusted {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientIP returns the IP address of the client that sent the request: the
+// one resolved by TrustedProxies, or else the request's peer address.
+func ClientIP(r *http.Request) string {
+	if ip, ok := r.Context().Value(clientIPContextKey).(string); ok {
+		return ip
+	}
+	return remoteHost(r)
+}
+
+// remoteHost returns the host part of the request's peer address.
+func remoteHost(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
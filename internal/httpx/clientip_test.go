@@ -0,0 +1,142 @@
+package httpx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+)
+
+func TestClientIP(t *testing.T) {
+	tests := []struct {
+		name       string
+		remoteAddr string
+		want       string
+	}{
+		{"ipv4 with port", "192.0.2.1:1234", "192.0.2.1"},
+		{"ipv6 with port", "[2001:db8::1]:1234", "2001:db8::1"},
+		{"no port", "192.0.2.1", "192.0.2.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			if got := ClientIP(req); got != tt.want {
+				t.Errorf("ClientIP() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTrustedProxies(t *testing.T) {
+	got, err := ParseTrustedProxies([]string{"10.0.0.0/8", " 192.0.2.7 ", "2001:db8::/32"})
+	if err != nil {
+		t.Fatalf("ParseTrustedProxies() unexpected error: %v", err)
+	}
+	want := []netip.Prefix{
+		netip.MustParsePrefix("10.0.0.0/8"),
+		netip.MustParsePrefix("192.0.2.7/32"),
+		netip.MustParsePrefix("2001:db8::/32"),
+	}
+	if len(got) != len(want) {
+		t.Fatalf("ParseTrustedProxies() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("prefix %d = %v, want %v", i, got[i], want[i])
+		}
+	}
+
+	if _, err := ParseTrustedProxies([]string{"proxy.internal"}); err == nil {
+		t.Error("ParseTrustedProxies() should reject a hostname")
+	}
+}
+
+func TestTrustedProxies(t *testing.T) {
+	trusted := []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		forwarded  []string
+		realIP     string
+		want       string
+	}{
+		{
+			name:       "trusted proxy forwards the client",
+			remoteAddr: "10.0.0.5:4321",
+			forwarded:  []string{"203.0.113.9"},
+			want:       "203.0.113.9",
+		},
+		{
+			name:       "chained trusted proxies are skipped",
+			remoteAddr: "10.0.0.5:4321",
+			forwarded:  []string{"203.0.113.9, 10.0.0.7", "10.0.0.6"},
+			want:       "203.0.113.9",
+		},
+		{
+			name:       "spoofed hops left of the client are ignored",
+			remoteAddr: "10.0.0.5:4321",
+			forwarded:  []string{"198.51.100.1, 203.0.113.9"},
+			want:       "203.0.113.9",
+		},
+		{
+			name:       "real IP header without forwarded for",
+			remoteAddr: "10.0.0.5:4321",
+			realIP:     "203.0.113.9",
+			want:       "203.0.113.9",
+		},
+		{
+			name:       "trusted proxy without headers",
+			remoteAddr: "10.0.0.5:4321",
+			want:       "10.0.0.5",
+		},
+		{
+			name:       "untrusted peer headers are ignored",
+			remoteAddr: "198.51.100.1:4321",
+			forwarded:  []string{"203.0.113.9"},
+			realIP:     "203.0.113.9",
+			want:       "198.51.100.1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			handler := TrustedProxies(trusted)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = ClientIP(r)
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/abc1234", nil)
+			req.RemoteAddr = tt.remoteAddr
+			for _, v := range tt.forwarded {
+				req.Header.Add("X-Forwarded-For", v)
+			}
+			if tt.realIP != "" {
+				req.Header.Set("X-Real-IP", tt.realIP)
+			}
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			if got != tt.want {
+				t.Errorf("ClientIP() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTrustedProxies_NoneConfigured(t *testing.T) {
+	var got string
+	handler := TrustedProxies(nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = ClientIP(r)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/abc1234", nil)
+	req.RemoteAddr = "10.0.0.5:4321"
+	req.Header.Set("X-Forwarded-For", "203.0.113.9")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if got != "10.0.0.5" {
+		t.Errorf("ClientIP() = %q, want the peer address", got)
+	}
+}
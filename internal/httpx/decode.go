@@ -17,9 +17,15 @@ const (
 // DecodeJSON decodes JSON from the request body with size limits and validation.
 // Type parameter T must be a pointer type (e.g., *CreateLinkRequest).
 func DecodeJSON[T any](r *http.Request) (T, error) {
+	return DecodeJSONWithLimit[T](r, MaxRequestBodySize)
+}
+
+// DecodeJSONWithLimit is DecodeJSON with a body limit of maxBytes instead
+// of MaxRequestBodySize, for endpoints taking larger payloads.
+func DecodeJSONWithLimit[T any](r *http.Request, maxBytes int64) (T, error) {
 	var zeroValue T
 
-	r.Body = http.MaxBytesReader(nil, r.Body, MaxRequestBodySize)
+	r.Body = http.MaxBytesReader(nil, r.Body, maxBytes)
 	defer func() {
 		err := r.Body.Close() // Just to ignore golint warning
 		if err != nil {
@@ -42,7 +48,7 @@ func DecodeJSON[T any](r *http.Request) (T, error) {
 		case errors.As(err, &unmarshalErr):
 			return zeroValue, fmt.Errorf("invalid value for field %q", unmarshalErr.Field)
 		case errors.As(err, &maxBytesErr):
-			return zeroValue, fmt.Errorf("request body too large (max %d bytes)", MaxRequestBodySize)
+			return zeroValue, fmt.Errorf("request body too large (max %d bytes)", maxBytes)
 		case errors.Is(err, io.EOF):
 			return zeroValue, errors.New("request body is empty")
 		default:
@@ -127,6 +127,27 @@ func TestDecodeJSON(t *testing.T) {
 	}
 }
 
+func TestDecodeJSONWithLimit(t *testing.T) {
+	body := `{"name":"` + strings.Repeat("a", 2<<20) + `"}`
+
+	if _, err := DecodeJSON[testRequest](httptest.NewRequest("POST", "/test", strings.NewReader(body))); err == nil {
+		t.Fatal("DecodeJSON: expected error for a 2MB body")
+	}
+
+	got, err := DecodeJSONWithLimit[testRequest](httptest.NewRequest("POST", "/test", strings.NewReader(body)), 4<<20)
+	if err != nil {
+		t.Fatalf("DecodeJSONWithLimit: unexpected error: %v", err)
+	}
+	if len(got.Name) != 2<<20 {
+		t.Errorf("Name has %d bytes, want %d", len(got.Name), 2<<20)
+	}
+
+	_, err = DecodeJSONWithLimit[testRequest](httptest.NewRequest("POST", "/test", strings.NewReader(body)), 1<<10)
+	if err == nil || !strings.Contains(err.Error(), "max 1024 bytes") {
+		t.Errorf("DecodeJSONWithLimit: error = %v, want it to name the 1024 byte limit", err)
+	}
+}
+
 func TestDecodeJSON_ZeroValueOnError(t *testing.T) {
 	req := httptest.NewRequest("POST", "/test", strings.NewReader("invalid json"))
 
@@ -1,7 +1,9 @@
 package httpx
 
 import (
+	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/sundayezeilo/urlshortener/internal/errx"
 )
@@ -18,10 +20,12 @@ func ErrorKindToStatus(kind errx.Kind) int {
 		return http.StatusBadRequest
 	case errx.Unauthorized:
 		return http.StatusUnauthorized
-	case errx.Forbidden:
+	case errx.Forbidden, errx.QuotaExceeded:
 		return http.StatusForbidden
 	case errx.Unavailable:
 		return http.StatusServiceUnavailable
+	case errx.Timeout:
+		return http.StatusGatewayTimeout
 	case errx.Internal:
 		return http.StatusInternalServerError
 	default:
@@ -43,11 +47,69 @@ func ErrorKindToCode(kind errx.Kind) string {
 		return "unauthorized"
 	case errx.Forbidden:
 		return "forbidden"
+	case errx.QuotaExceeded:
+		return "quota_exceeded"
 	case errx.Unavailable:
 		return "unavailable"
+	case errx.Timeout:
+		return "timeout"
 	case errx.Internal:
 		return "internal_error"
 	default:
 		return "internal_error"
 	}
 }
+
+// errorKinds lists the kinds with a code of their own, in declaration order.
+var errorKinds = []errx.Kind{
+	errx.NotFound,
+	errx.Conflict,
+	errx.Invalid,
+	errx.Unauthorized,
+	errx.Forbidden,
+	errx.Unavailable,
+	errx.Internal,
+	errx.QuotaExceeded,
+	errx.Timeout,
+}
+
+// ErrorCodes overrides the codes ErrorKindToCode returns, e.g. to namespace
+// them as "urlshortener.not_found". Kinds it leaves out keep their default
+// code. A nil ErrorCodes uses the defaults throughout.
+type ErrorCodes map[errx.Kind]string
+
+// Code returns the code for kind: the override if one is set, otherwise
+// ErrorKindToCode(kind).
+func (c ErrorCodes) Code(kind errx.Kind) string {
+	if code, ok := c[kind]; ok {
+		return code
+	}
+	return ErrorKindToCode(kind)
+}
+
+// ParseErrorCodes builds ErrorCodes from overrides keyed by default code,
+// e.g. {"not_found": "urlshortener.not_found"}. It rejects keys that are
+// not a default code and empty replacements.
+func ParseErrorCodes(overrides map[string]string) (ErrorCodes, error) {
+	if len(overrides) == 0 {
+		return nil, nil
+	}
+
+	byDefault := make(map[string]errx.Kind, len(errorKinds))
+	for _, kind := range errorKinds {
+		byDefault[ErrorKindToCode(kind)] = kind
+	}
+
+	codes := make(ErrorCodes, len(overrides))
+	for from, to := range overrides {
+		kind, ok := byDefault[from]
+		if !ok {
+			return nil, fmt.Errorf("unknown error code %q", from)
+		}
+		if strings.TrimSpace(to) == "" {
+			return nil, fmt.Errorf("replacement for error code %q cannot be empty", from)
+		}
+		codes[kind] = to
+	}
+	return codes, nil
+}
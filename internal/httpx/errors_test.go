@@ -38,11 +38,21 @@ func TestErrorKindToStatus(t *testing.T) {
 			kind:       errx.Forbidden,
 			wantStatus: http.StatusForbidden,
 		},
+		{
+			name:       "quota exceeded",
+			kind:       errx.QuotaExceeded,
+			wantStatus: http.StatusForbidden,
+		},
 		{
 			name:       "unavailable",
 			kind:       errx.Unavailable,
 			wantStatus: http.StatusServiceUnavailable,
 		},
+		{
+			name:       "timeout",
+			kind:       errx.Timeout,
+			wantStatus: http.StatusGatewayTimeout,
+		},
 		{
 			name:       "internal",
 			kind:       errx.Internal,
@@ -101,11 +111,21 @@ func TestErrorKindToCode(t *testing.T) {
 			kind:     errx.Forbidden,
 			wantCode: "forbidden",
 		},
+		{
+			name:     "quota exceeded",
+			kind:     errx.QuotaExceeded,
+			wantCode: "quota_exceeded",
+		},
 		{
 			name:     "unavailable",
 			kind:     errx.Unavailable,
 			wantCode: "unavailable",
 		},
+		{
+			name:     "timeout",
+			kind:     errx.Timeout,
+			wantCode: "timeout",
+		},
 		{
 			name:     "internal",
 			kind:     errx.Internal,
@@ -144,7 +164,9 @@ func TestErrorKindMappingConsistency(t *testing.T) {
 		{"Invalid", errx.Invalid},
 		{"Unauthorized", errx.Unauthorized},
 		{"Forbidden", errx.Forbidden},
+		{"QuotaExceeded", errx.QuotaExceeded},
 		{"Unavailable", errx.Unavailable},
+		{"Timeout", errx.Timeout},
 		{"Internal", errx.Internal},
 		{"Unknown", errx.Unknown},
 	}
@@ -169,3 +191,39 @@ func TestErrorKindMappingConsistency(t *testing.T) {
 		})
 	}
 }
+
+func TestErrorCodes(t *testing.T) {
+	t.Run("nil uses defaults", func(t *testing.T) {
+		var codes ErrorCodes
+		for _, kind := range errorKinds {
+			if got, want := codes.Code(kind), ErrorKindToCode(kind); got != want {
+				t.Errorf("Code(%v) = %q, want %q", kind, got, want)
+			}
+		}
+	})
+
+	t.Run("overrides listed kinds only", func(t *testing.T) {
+		codes, err := ParseErrorCodes(map[string]string{"not_found": "urlshortener.not_found"})
+		if err != nil {
+			t.Fatalf("ParseErrorCodes() unexpected error: %v", err)
+		}
+		if got := codes.Code(errx.NotFound); got != "urlshortener.not_found" {
+			t.Errorf("Code(NotFound) = %q, want urlshortener.not_found", got)
+		}
+		if got := codes.Code(errx.Conflict); got != "conflict" {
+			t.Errorf("Code(Conflict) = %q, want conflict", got)
+		}
+	})
+
+	t.Run("rejects unknown codes", func(t *testing.T) {
+		if _, err := ParseErrorCodes(map[string]string{"missing": "x"}); err == nil {
+			t.Error("ParseErrorCodes() should fail for an unknown code")
+		}
+	})
+
+	t.Run("rejects empty replacements", func(t *testing.T) {
+		if _, err := ParseErrorCodes(map[string]string{"not_found": " "}); err == nil {
+			t.Error("ParseErrorCodes() should fail for an empty replacement")
+		}
+	})
+}
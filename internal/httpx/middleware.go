@@ -2,10 +2,12 @@ package httpx
 
 import (
 	"context"
+	"encoding/hex"
 	"log/slog"
 	"net/http"
 	"runtime/debug"
 	"slices"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -14,12 +16,22 @@ import (
 const (
 	// RequestIDHeader is the header name for request ID.
 	RequestIDHeader = "X-Request-ID"
+	// OriginalRequestIDHeader carries an incoming request ID that failed
+	// validation and was replaced.
+	OriginalRequestIDHeader = "X-Original-Request-ID"
 )
 
+// maxOriginalRequestIDLength bounds how much of a rejected request ID is
+// kept, so oversized headers can't bloat every log line.
+const maxOriginalRequestIDLength = 256
+
 // contextKey is the type for context keys to avoid collisions.
 type contextKey string
 
-const requestIDContextKey contextKey = "request_id"
+const (
+	requestIDContextKey         contextKey = "request_id"
+	originalRequestIDContextKey contextKey = "original_request_id"
+)
 
 // Middleware represents a function that wraps an http.Handler.
 type Middleware func(http.Handler) http.Handler
@@ -35,23 +47,129 @@ func Chain(middlewares ...Middleware) Middleware {
 	}
 }
 
+// RequestIDGenerator produces an ID for a request that arrived without one.
+type RequestIDGenerator func(r *http.Request) string
+
+// RequestIDValidator reports whether an incoming request ID may be reused.
+type RequestIDValidator func(id string) bool
+
+// RequestIDConfig customizes the RequestIDWith middleware.
+type RequestIDConfig struct {
+	// Header is read for an incoming ID and set on the response
+	// (default: RequestIDHeader).
+	Header string
+	// Generator creates IDs when the header is absent (default: NewUUIDRequestID).
+	Generator RequestIDGenerator
+	// Validator, when set, vets incoming IDs. A rejected ID is replaced by a
+	// generated one and kept under OriginalRequestIDHeader and
+	// GetOriginalRequestID. Nil accepts any incoming ID.
+	Validator RequestIDValidator
+}
+
 // RequestID is a middleware that adds a unique request ID to each request.
 // It first checks for an existing X-Request-ID header, and generates one if not present.
 // The request ID is added to the request context and also set as a response header.
 func RequestID(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		requestID := r.Header.Get(RequestIDHeader)
+	return RequestIDWith(RequestIDConfig{})(next)
+}
 
-		if requestID == "" {
-			requestID = uuid.New().String()
-		}
+// RequestIDWith is like RequestID but with a configurable header name and
+// ID generator, for deployments that propagate e.g. X-Correlation-ID.
+func RequestIDWith(cfg RequestIDConfig) Middleware {
+	header := cfg.Header
+	if header == "" {
+		header = RequestIDHeader
+	}
+	generate := cfg.Generator
+	if generate == nil {
+		generate = NewUUIDRequestID
+	}
 
-		w.Header().Set(RequestIDHeader, requestID)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requestID := r.Header.Get(header)
+			ctx := r.Context()
 
-		ctx := context.WithValue(r.Context(), requestIDContextKey, requestID)
+			if requestID != "" && cfg.Validator != nil && !cfg.Validator(requestID) {
+				original := requestID
+				if len(original) > maxOriginalRequestIDLength {
+					original = original[:maxOriginalRequestIDLength]
+				}
+				w.Header().Set(OriginalRequestIDHeader, original)
+				ctx = context.WithValue(ctx, originalRequestIDContextKey, original)
+				requestID = ""
+			}
+
+			if requestID == "" {
+				requestID = generate(r)
+			}
+
+			w.Header().Set(header, requestID)
+
+			ctx = context.WithValue(ctx, requestIDContextKey, requestID)
 
-		next.ServeHTTP(w, r.WithContext(ctx))
-	})
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// NewUUIDRequestID generates a random UUID request ID.
+func NewUUIDRequestID(*http.Request) string {
+	return uuid.New().String()
+}
+
+// TraceparentRequestID uses the trace ID from a W3C traceparent header so
+// logs line up with distributed traces. It falls back to a random UUID when
+// the header is missing or malformed.
+func TraceparentRequestID(r *http.Request) string {
+	if traceID, ok := traceIDFromTraceparent(r.Header.Get("traceparent")); ok {
+		return traceID
+	}
+	return NewUUIDRequestID(r)
+}
+
+// UUIDRequestIDValidator accepts only canonical 36-character UUIDs.
+func UUIDRequestIDValidator(id string) bool {
+	if len(id) != 36 {
+		return false
+	}
+	_, err := uuid.Parse(id)
+	return err == nil
+}
+
+// MaxLengthRequestIDValidator accepts IDs of at most n bytes made of
+// printable ASCII without spaces.
+func MaxLengthRequestIDValidator(n int) RequestIDValidator {
+	return func(id string) bool {
+		if len(id) > n {
+			return false
+		}
+		for i := 0; i < len(id); i++ {
+			if id[i] <= ' ' || id[i] > '~' {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// traceIDFromTraceparent extracts the trace ID from a version-00 header of
+// the form "00-<32 hex trace id>-<16 hex parent id>-<2 hex flags>".
+func traceIDFromTraceparent(header string) (string, bool) {
+	parts := strings.Split(strings.TrimSpace(header), "-")
+	if len(parts) != 4 || parts[0] != "00" ||
+		len(parts[1]) != 32 || len(parts[2]) != 16 || len(parts[3]) != 2 {
+		return "", false
+	}
+
+	traceID := parts[1]
+	if _, err := hex.DecodeString(traceID); err != nil || traceID != strings.ToLower(traceID) {
+		return "", false
+	}
+	if strings.Trim(traceID, "0") == "" {
+		return "", false // all-zero trace IDs are invalid
+	}
+	return traceID, true
 }
 
 // GetRequestID extracts the request ID from context.
@@ -63,14 +181,48 @@ func GetRequestID(ctx context.Context) string {
 	return ""
 }
 
+// GetOriginalRequestID returns the incoming request ID that RequestIDWith
+// rejected and replaced, or an empty string if none was.
+func GetOriginalRequestID(ctx context.Context) string {
+	if id, ok := ctx.Value(originalRequestIDContextKey).(string); ok {
+		return id
+	}
+	return ""
+}
+
 // WithRequestID adds a request ID to the context.
 // This is useful for testing or manually setting request IDs.
 func WithRequestID(ctx context.Context, requestID string) context.Context {
 	return context.WithValue(ctx, requestIDContextKey, requestID)
 }
 
+// LoggerConfig configures LoggerWith.
+type LoggerConfig struct {
+	Logger *slog.Logger
+
+	// SlowThreshold logs requests taking longer at WARN with slow=true, so
+	// they stand out from the INFO lines of normal requests. Zero disables
+	// it.
+	SlowThreshold time.Duration
+}
+
 // Logger is a middleware that logs HTTP requests with structured logging.
+// Besides the raw path it logs the matched ServeMux pattern as "route"
+// (e.g. "GET /{slug}"), a low-cardinality label suited to aggregation. The
+// pattern is read back from the request after it has been served, so
+// middleware between Logger and the mux must pass the request on as is;
+// "route" is empty when no pattern matched.
 func Logger(logger *slog.Logger) Middleware {
+	return LoggerWith(LoggerConfig{Logger: logger})
+}
+
+// LoggerWith is like Logger but can flag slow requests.
+func LoggerWith(cfg LoggerConfig) Middleware {
+	logger := cfg.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			start := time.Now()
@@ -79,37 +231,55 @@ func Logger(logger *slog.Logger) Middleware {
 			next.ServeHTTP(wrapped, r)
 			duration := time.Since(start)
 
-			logger.InfoContext(r.Context(), "http request",
+			attrs := []any{
 				"request_id", GetRequestID(r.Context()),
 				"method", r.Method,
 				"path", r.URL.Path,
+				"route", r.Pattern,
 				"status", wrapped.statusCode,
 				"duration_ms", duration.Milliseconds(),
 				// "user_agent", r.UserAgent(),
 				"remote_addr", r.RemoteAddr,
-			)
+			}
+			if original := GetOriginalRequestID(r.Context()); original != "" {
+				attrs = append(attrs, "original_request_id", original)
+			}
+			if cfg.SlowThreshold > 0 && duration > cfg.SlowThreshold {
+				attrs = append(attrs, "slow", true)
+				logger.WarnContext(r.Context(), "http request", attrs...)
+				return
+			}
+			logger.InfoContext(r.Context(), "http request", attrs...)
 		})
 	}
 }
 
-// Recovery is a middleware that recovers from panics and returns a 500 error.
+// Recovery is a middleware that recovers from panics and returns a 500 error
+// whose details carry the request ID, if one has been assigned.
 func Recovery(logger *slog.Logger) Middleware {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			defer func() {
 				if err := recover(); err != nil {
+					requestID := recoveredRequestID(w, r)
+
 					// Log the panic with stack trace
 					logger.ErrorContext(r.Context(), "panic recovered",
-						"request_id", GetRequestID(r.Context()),
+						"request_id", requestID,
 						"error", err,
 						"stack", string(debug.Stack()),
 					)
 
-					// Return 500 error
+					// Return 500 error, quoting the request ID so users can
+					// report it
+					var details any
+					if requestID != "" {
+						details = map[string]string{"request_id": requestID}
+					}
 					WriteError(w, http.StatusInternalServerError,
 						"internal_error",
 						"an unexpected error occurred",
-						nil)
+						details)
 				}
 			}()
 
@@ -118,6 +288,104 @@ func Recovery(logger *slog.Logger) Middleware {
 	}
 }
 
+// recoveredRequestID returns the request ID for a recovered panic. When
+// Recovery runs outside the request ID middleware the context lacks it, but
+// the default response header has already been set.
+func recoveredRequestID(w http.ResponseWriter, r *http.Request) string {
+	if id := GetRequestID(r.Context()); id != "" {
+		return id
+	}
+	return w.Header().Get(RequestIDHeader)
+}
+
+// ConcurrencyLimit is a middleware that caps the number of requests being
+// served at once. When all max slots are taken, it responds 503 with a
+// Retry-After header instead of queueing. A max of zero or less disables it.
+// The slot is released even if the handler panics, so it composes safely
+// inside Recovery.
+func ConcurrencyLimit(max int) Middleware {
+	return func(next http.Handler) http.Handler {
+		if max <= 0 {
+			return next
+		}
+
+		slots := make(chan struct{}, max)
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case slots <- struct{}{}:
+			default:
+				w.Header().Set("Retry-After", "1")
+				WriteError(w, http.StatusServiceUnavailable,
+					"unavailable",
+					"server is handling too many requests, please retry",
+					nil)
+				return
+			}
+			defer func() { <-slots }()
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// DefaultMaintenanceMessage is returned when no maintenance message is configured.
+const DefaultMaintenanceMessage = "service is under maintenance, please try again later"
+
+// MaintenanceConfig configures MaintenanceWith.
+type MaintenanceConfig struct {
+	Enabled bool
+	// Message is returned with the 503 (default: DefaultMaintenanceMessage).
+	Message string
+	// ExemptPaths lets requests whose path starts with one of them through.
+	ExemptPaths []string
+	// Exempt, when set, lets matching requests through whatever their
+	// method, e.g. resolves of links that redirect POST.
+	Exempt func(r *http.Request) bool
+}
+
+// Maintenance is a middleware that rejects write requests with 503 while
+// enabled. Safe methods (GET, HEAD, OPTIONS) pass through so existing links
+// keep resolving, as do requests whose path starts with one of exemptPaths.
+// An empty message falls back to DefaultMaintenanceMessage.
+func Maintenance(enabled bool, message string, exemptPaths []string) Middleware {
+	return MaintenanceWith(MaintenanceConfig{Enabled: enabled, Message: message, ExemptPaths: exemptPaths})
+}
+
+// MaintenanceWith is like Maintenance but can exempt requests by more than
+// their path prefix.
+func MaintenanceWith(cfg MaintenanceConfig) Middleware {
+	return func(next http.Handler) http.Handler {
+		if !cfg.Enabled {
+			return next
+		}
+		message := cfg.Message
+		if message == "" {
+			message = DefaultMaintenanceMessage
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+				next.ServeHTTP(w, r)
+				return
+			}
+			for _, prefix := range cfg.ExemptPaths {
+				if strings.HasPrefix(r.URL.Path, prefix) {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+			if cfg.Exempt != nil && cfg.Exempt(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			WriteError(w, http.StatusServiceUnavailable, "maintenance", message, nil)
+		})
+	}
+}
+
 // CORS is a middleware that adds CORS headers.
 // For production, allowed origins should configure more carefully.
 func CORS(allowedOrigins []string) Middleware {
@@ -159,3 +427,9 @@ func (rw *responseWriter) WriteHeader(code int) {
 	rw.statusCode = code
 	rw.ResponseWriter.WriteHeader(code)
 }
+
+// Unwrap exposes the underlying writer to http.ResponseController and
+// WriteError.
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
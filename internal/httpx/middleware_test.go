@@ -2,9 +2,15 @@ package httpx
 
 import (
 	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -210,6 +216,208 @@ func TestRequestID_UsesExistingHeader(t *testing.T) {
 	handler.ServeHTTP(rr, req)
 }
 
+func TestRequestIDWith_CustomHeader(t *testing.T) {
+	const header = "X-Correlation-ID"
+
+	mw := RequestIDWith(RequestIDConfig{
+		Header:    header,
+		Generator: func(*http.Request) string { return "generated-id" },
+	})
+
+	t.Run("reads the custom header", func(t *testing.T) {
+		var got string
+		handler := mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got = GetRequestID(r.Context())
+		}))
+
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set(header, "incoming-id")
+		req.Header.Set(RequestIDHeader, "ignored-id")
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+
+		if got != "incoming-id" {
+			t.Errorf("expected request ID %q, got %q", "incoming-id", got)
+		}
+		if h := rr.Header().Get(header); h != "incoming-id" {
+			t.Errorf("expected %s %q, got %q", header, "incoming-id", h)
+		}
+		if h := rr.Header().Get(RequestIDHeader); h != "" {
+			t.Errorf("expected no %s header, got %q", RequestIDHeader, h)
+		}
+	})
+
+	t.Run("writes a generated id to the custom header", func(t *testing.T) {
+		var got string
+		handler := mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got = GetRequestID(r.Context())
+		}))
+
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest("GET", "/", nil))
+
+		if got != "generated-id" {
+			t.Errorf("expected request ID %q, got %q", "generated-id", got)
+		}
+		if h := rr.Header().Get(header); h != "generated-id" {
+			t.Errorf("expected %s %q, got %q", header, "generated-id", h)
+		}
+	})
+}
+
+func TestRequestIDWith_DefaultsMatchRequestID(t *testing.T) {
+	var got string
+	handler := RequestIDWith(RequestIDConfig{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = GetRequestID(r.Context())
+	}))
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest("GET", "/", nil))
+
+	if _, err := uuid.Parse(got); err != nil {
+		t.Errorf("expected valid UUID, got %q: %v", got, err)
+	}
+	if h := rr.Header().Get(RequestIDHeader); h != got {
+		t.Errorf("expected header %q, got %q", got, h)
+	}
+}
+
+func TestRequestIDWith_Validator(t *testing.T) {
+	const validID = "0b5c6f0e-8a4d-4e3a-9c55-2f1a7d3e9b10"
+
+	mw := RequestIDWith(RequestIDConfig{
+		Generator: func(*http.Request) string { return "fresh-id" },
+		Validator: UUIDRequestIDValidator,
+	})
+
+	serve := func(incoming string) (id, original string, rr *httptest.ResponseRecorder) {
+		handler := mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id = GetRequestID(r.Context())
+			original = GetOriginalRequestID(r.Context())
+		}))
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set(RequestIDHeader, incoming)
+		rr = httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return id, original, rr
+	}
+
+	t.Run("preserves a valid incoming id", func(t *testing.T) {
+		id, original, rr := serve(validID)
+
+		if id != validID {
+			t.Errorf("expected request ID %q, got %q", validID, id)
+		}
+		if original != "" {
+			t.Errorf("expected no original request ID, got %q", original)
+		}
+		if h := rr.Header().Get(OriginalRequestIDHeader); h != "" {
+			t.Errorf("expected no %s header, got %q", OriginalRequestIDHeader, h)
+		}
+	})
+
+	t.Run("replaces an invalid incoming id and keeps the original", func(t *testing.T) {
+		id, original, rr := serve("not-a-uuid")
+
+		if id != "fresh-id" {
+			t.Errorf("expected generated request ID, got %q", id)
+		}
+		if h := rr.Header().Get(RequestIDHeader); h != "fresh-id" {
+			t.Errorf("expected %s %q, got %q", RequestIDHeader, "fresh-id", h)
+		}
+		if original != "not-a-uuid" {
+			t.Errorf("expected original request ID %q, got %q", "not-a-uuid", original)
+		}
+		if h := rr.Header().Get(OriginalRequestIDHeader); h != "not-a-uuid" {
+			t.Errorf("expected %s %q, got %q", OriginalRequestIDHeader, "not-a-uuid", h)
+		}
+	})
+
+	t.Run("truncates an oversized original", func(t *testing.T) {
+		_, original, _ := serve(strings.Repeat("x", 1000))
+
+		if len(original) != maxOriginalRequestIDLength {
+			t.Errorf("expected original truncated to %d bytes, got %d", maxOriginalRequestIDLength, len(original))
+		}
+	})
+}
+
+func TestMaxLengthRequestIDValidator(t *testing.T) {
+	valid := MaxLengthRequestIDValidator(8)
+
+	tests := []struct {
+		id   string
+		want bool
+	}{
+		{"abc-123", true},
+		{"12345678", true},
+		{"123456789", false},
+		{"has space", false},
+		{"tab\tid", false},
+		{"caf\u00e9", false},
+	}
+
+	for _, tt := range tests {
+		if got := valid(tt.id); got != tt.want {
+			t.Errorf("valid(%q) = %v, want %v", tt.id, got, tt.want)
+		}
+	}
+}
+
+func TestUUIDRequestIDValidator(t *testing.T) {
+	tests := []struct {
+		id   string
+		want bool
+	}{
+		{"0b5c6f0e-8a4d-4e3a-9c55-2f1a7d3e9b10", true},
+		{"0b5c6f0e8a4d4e3a9c552f1a7d3e9b10", false},
+		{"urn:uuid:0b5c6f0e-8a4d-4e3a-9c55-2f1a7d3e9b10", false},
+		{"existing-request-id-123", false},
+	}
+
+	for _, tt := range tests {
+		if got := UUIDRequestIDValidator(tt.id); got != tt.want {
+			t.Errorf("UUIDRequestIDValidator(%q) = %v, want %v", tt.id, got, tt.want)
+		}
+	}
+}
+
+func TestTraceparentRequestID(t *testing.T) {
+	tests := []struct {
+		name        string
+		traceparent string
+		want        string // empty means a UUID fallback
+	}{
+		{"valid", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", "4bf92f3577b34da6a3ce929d0e0e4736"},
+		{"missing", "", ""},
+		{"all-zero trace id", "00-00000000000000000000000000000000-00f067aa0ba902b7-01", ""},
+		{"uppercase hex", "00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01", ""},
+		{"unknown version", "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", ""},
+		{"short trace id", "00-4bf92f35-00f067aa0ba902b7-01", ""},
+		{"non-hex", "00-zzf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			if tt.traceparent != "" {
+				req.Header.Set("traceparent", tt.traceparent)
+			}
+
+			got := TraceparentRequestID(req)
+			if tt.want != "" {
+				if got != tt.want {
+					t.Errorf("expected %q, got %q", tt.want, got)
+				}
+				return
+			}
+			if _, err := uuid.Parse(got); err != nil {
+				t.Errorf("expected UUID fallback, got %q", got)
+			}
+		})
+	}
+}
+
 func TestCORS_AllowAllOrigins(t *testing.T) {
 	handler := CORS(nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
@@ -308,6 +516,312 @@ func TestCORS_PreflightRequest(t *testing.T) {
 	}
 }
 
+func TestConcurrencyLimit_RejectsWhenFull(t *testing.T) {
+	const max = 2
+
+	release := make(chan struct{})
+	entered := make(chan struct{}, max)
+	handler := ConcurrencyLimit(max)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		entered <- struct{}{}
+		<-release
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	var wg sync.WaitGroup
+	for range max {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+		}()
+	}
+	for range max {
+		<-entered
+	}
+
+	// All slots are busy: the next request is rejected.
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest("GET", "/", nil))
+
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected status %d, got %d", http.StatusServiceUnavailable, rr.Code)
+	}
+	if got := rr.Header().Get("Retry-After"); got == "" {
+		t.Error("expected Retry-After header to be set")
+	}
+
+	close(release)
+	wg.Wait()
+
+	// Slots are freed once in-flight requests complete.
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest("GET", "/", nil))
+	<-entered
+
+	if rr.Code != http.StatusOK {
+		t.Errorf("expected status %d after slots freed, got %d", http.StatusOK, rr.Code)
+	}
+}
+
+func TestConcurrencyLimit_ReleasesSlotOnPanic(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	panicking := true
+	handler := Chain(
+		Recovery(logger),
+		ConcurrencyLimit(1),
+	)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if panicking {
+			panic("boom")
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest("GET", "/", nil))
+	if rr.Code != http.StatusInternalServerError {
+		t.Fatalf("expected status %d, got %d", http.StatusInternalServerError, rr.Code)
+	}
+
+	panicking = false
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest("GET", "/", nil))
+	if rr.Code != http.StatusOK {
+		t.Errorf("expected slot to be released after panic, got status %d", rr.Code)
+	}
+}
+
+func TestLogger_RoutePattern(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{slug}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusFound)
+	})
+	mux.HandleFunc("GET /api/links/{slug}", func(w http.ResponseWriter, r *http.Request) {})
+
+	tests := []struct {
+		path      string
+		wantRoute string
+	}{
+		{"/abc123", "GET /{slug}"},
+		{"/xyz789", "GET /{slug}"},
+		{"/api/links/abc123", "GET /api/links/{slug}"},
+		{"/api/unknown/route", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			var buf strings.Builder
+			logger := slog.New(slog.NewJSONHandler(&buf, nil))
+			handler := Logger(logger)(mux)
+
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", tt.path, nil))
+
+			var entry struct {
+				Path  string `json:"path"`
+				Route string `json:"route"`
+			}
+			if err := json.Unmarshal([]byte(buf.String()), &entry); err != nil {
+				t.Fatalf("failed to decode log entry %q: %v", buf.String(), err)
+			}
+			if entry.Route != tt.wantRoute {
+				t.Errorf("route = %q, want %q", entry.Route, tt.wantRoute)
+			}
+			if entry.Path != tt.path {
+				t.Errorf("path = %q, want %q", entry.Path, tt.path)
+			}
+		})
+	}
+}
+
+func TestLoggerWith_SlowThreshold(t *testing.T) {
+	tests := []struct {
+		name      string
+		sleep     time.Duration
+		wantLevel string
+		wantSlow  bool
+	}{
+		{"under threshold", 0, "INFO", false},
+		{"past threshold", 30 * time.Millisecond, "WARN", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf strings.Builder
+			logger := slog.New(slog.NewJSONHandler(&buf, nil))
+			handler := LoggerWith(LoggerConfig{Logger: logger, SlowThreshold: 20 * time.Millisecond})(
+				http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					time.Sleep(tt.sleep)
+				}),
+			)
+
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/abc123", nil))
+
+			var entry struct {
+				Level string `json:"level"`
+				Slow  bool   `json:"slow"`
+			}
+			if err := json.Unmarshal([]byte(buf.String()), &entry); err != nil {
+				t.Fatalf("failed to decode log entry %q: %v", buf.String(), err)
+			}
+			if entry.Level != tt.wantLevel {
+				t.Errorf("level = %q, want %q", entry.Level, tt.wantLevel)
+			}
+			if entry.Slow != tt.wantSlow {
+				t.Errorf("slow = %v, want %v", entry.Slow, tt.wantSlow)
+			}
+		})
+	}
+}
+
+func TestRecovery_QuotesRequestID(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	panicking := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	})
+
+	tests := []struct {
+		name    string
+		handler http.Handler
+	}{
+		{"inside request ID middleware", Chain(RequestID, Recovery(logger))(panicking)},
+		{"outside request ID middleware", Chain(Recovery(logger), RequestID)(panicking)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			tt.handler.ServeHTTP(rr, httptest.NewRequest("GET", "/", nil))
+
+			if rr.Code != http.StatusInternalServerError {
+				t.Fatalf("expected status %d, got %d", http.StatusInternalServerError, rr.Code)
+			}
+			header := rr.Header().Get(RequestIDHeader)
+			if header == "" {
+				t.Fatal("expected X-Request-ID header to be set")
+			}
+
+			var resp struct {
+				Error   string            `json:"error"`
+				Details map[string]string `json:"details"`
+			}
+			if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if resp.Error != "internal_error" {
+				t.Errorf("expected code %q, got %q", "internal_error", resp.Error)
+			}
+			if got := resp.Details["request_id"]; got != header {
+				t.Errorf("details.request_id = %q, want header value %q", got, header)
+			}
+		})
+	}
+}
+
+func TestRecovery_NoRequestID(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	handler := Recovery(logger)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}))
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest("GET", "/", nil))
+
+	var resp ErrorResponse
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.Details != nil {
+		t.Errorf("expected no details without a request ID, got %v", resp.Details)
+	}
+}
+
+func TestConcurrencyLimit_DisabledWhenNonPositive(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	handler := ConcurrencyLimit(0)(next)
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest("GET", "/", nil))
+	if rr.Code != http.StatusOK {
+		t.Errorf("expected status %d, got %d", http.StatusOK, rr.Code)
+	}
+}
+
+func TestMaintenance(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	tests := []struct {
+		name       string
+		enabled    bool
+		method     string
+		path       string
+		wantStatus int
+	}{
+		{"disabled allows writes", false, http.MethodPost, "/api/links", http.StatusOK},
+		{"enabled rejects POST", true, http.MethodPost, "/api/links", http.StatusServiceUnavailable},
+		{"enabled rejects DELETE", true, http.MethodDelete, "/api/links/abc1234", http.StatusServiceUnavailable},
+		{"enabled allows GET", true, http.MethodGet, "/abc1234", http.StatusOK},
+		{"enabled allows HEAD", true, http.MethodHead, "/abc1234", http.StatusOK},
+		{"enabled allows exempt path", true, http.MethodPost, "/api/admin/flush", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := Maintenance(tt.enabled, "", []string{"/api/admin"})(next)
+
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, httptest.NewRequest(tt.method, tt.path, nil))
+
+			if rr.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, rr.Code)
+			}
+		})
+	}
+}
+
+func TestMaintenanceWith_Exempt(t *testing.T) {
+	handler := MaintenanceWith(MaintenanceConfig{
+		Enabled: true,
+		Exempt:  func(r *http.Request) bool { return r.URL.Path == "/abc1234" },
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		path       string
+		wantStatus int
+	}{
+		{"/abc1234", http.StatusOK},
+		{"/api/links", http.StatusServiceUnavailable},
+	}
+	for _, tt := range tests {
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, tt.path, nil))
+		if rr.Code != tt.wantStatus {
+			t.Errorf("POST %s: expected status %d, got %d", tt.path, tt.wantStatus, rr.Code)
+		}
+	}
+}
+
+func TestMaintenance_ResponseBody(t *testing.T) {
+	handler := Maintenance(true, "back at 10:00 UTC", nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/api/links", nil))
+
+	var resp ErrorResponse
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.Error != "maintenance" {
+		t.Errorf("expected code %q, got %q", "maintenance", resp.Error)
+	}
+	if resp.Message != "back at 10:00 UTC" {
+		t.Errorf("expected configured message, got %q", resp.Message)
+	}
+}
+
 func TestResponseWriter_CapturesStatusCode(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -0,0 +1,104 @@
+package httpx
+
+import (
+	"net/http"
+	"strings"
+)
+
+// RouteErrors serves mux, replacing the plain-text 404 and 405 responses
+// ServeMux writes for requests no route matches with JSON errors. A 405
+// keeps the Allow header listing the methods the path accepts.
+func RouteErrors(mux *http.ServeMux) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h, pattern := mux.Handler(r)
+		if pattern != "" {
+			mux.ServeHTTP(w, r)
+			return
+		}
+
+		// Without a pattern, h is the mux's own 404 or 405 handler. Run it
+		// against a scratch writer to learn which, and the Allow header.
+		probe := &statusProbe{header: make(http.Header)}
+		h.ServeHTTP(probe, r)
+
+		if probe.status == http.StatusMethodNotAllowed {
+			w.Header().Set("Allow", probe.header.Get("Allow"))
+			WriteError(w, http.StatusMethodNotAllowed, "method_not_allowed",
+				r.Method+" is not allowed for this resource", nil)
+			return
+		}
+		WriteError(w, http.StatusNotFound, "not_found", "Resource not found", nil)
+	})
+}
+
+// statusProbe records the status and headers a handler writes and discards
+// the body.
+type statusProbe struct {
+	header http.Header
+	status int
+}
+
+func (p *statusProbe) Header() http.Header { return p.header }
+
+func (p *statusProbe) WriteHeader(status int) {
+	if p.status == 0 {
+		p.status = status
+	}
+}
+
+func (p *statusProbe) Write(b []byte) (int, error) {
+	p.WriteHeader(http.StatusOK)
+	return len(b), nil
+}
+
+// TrailingSlashPolicy selects how TrailingSlash treats paths ending in "/".
+type TrailingSlashPolicy string
+
+const (
+	// TrailingSlashStrict leaves paths alone, so "/api/links/" does not
+	// match a "/api/links" route. It is the default.
+	TrailingSlashStrict TrailingSlashPolicy = "strict"
+	// TrailingSlashRedirect answers with a 308 to the path without the
+	// trailing slash. Unlike a 301, a 308 keeps the method and body, so
+	// POSTs survive the redirect.
+	TrailingSlashRedirect TrailingSlashPolicy = "redirect"
+	// TrailingSlashStrip serves the request as if it had been made without
+	// the trailing slash.
+	TrailingSlashStrip TrailingSlashPolicy = "strip"
+)
+
+// TrailingSlash makes paths under prefix that end in one or more slashes
+// behave like their canonical form without them, as policy selects. The
+// prefix itself and paths outside it are left alone. It must wrap the mux
+// so routing sees the rewritten path.
+func TrailingSlash(policy TrailingSlashPolicy, prefix string) Middleware {
+	return func(next http.Handler) http.Handler {
+		if policy != TrailingSlashRedirect && policy != TrailingSlashStrip {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			path := r.URL.Path
+			canonical := strings.TrimRight(path, "/")
+			if canonical == path || !strings.HasPrefix(path, prefix) || canonical+"/" == prefix {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if policy == TrailingSlashRedirect {
+				target := canonical
+				if r.URL.RawQuery != "" {
+					target += "?" + r.URL.RawQuery
+				}
+				w.Header().Set("Location", target)
+				w.WriteHeader(http.StatusPermanentRedirect)
+				return
+			}
+
+			r2 := r.Clone(r.Context())
+			r2.URL.Path = canonical
+			r2.URL.RawPath = strings.TrimRight(r.URL.RawPath, "/")
+			next.ServeHTTP(w, r2)
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package httpx
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRouteErrors(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /items/{id}", func(w http.ResponseWriter, r *http.Request) {
+		WriteJSON(w, http.StatusOK, map[string]string{"id": r.PathValue("id")})
+	})
+	mux.HandleFunc("POST /items", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	})
+	h := RouteErrors(mux)
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		wantStatus int
+		wantCode   string
+		wantAllow  string
+	}{
+		{name: "matched route", method: http.MethodGet, path: "/items/42", wantStatus: http.StatusOK},
+		{name: "wrong method", method: http.MethodDelete, path: "/items/42", wantStatus: http.StatusMethodNotAllowed, wantCode: "method_not_allowed", wantAllow: "GET, HEAD"},
+		{name: "wrong method on collection", method: http.MethodGet, path: "/items", wantStatus: http.StatusMethodNotAllowed, wantCode: "method_not_allowed", wantAllow: "POST"},
+		{name: "unknown path", method: http.MethodGet, path: "/nope", wantStatus: http.StatusNotFound, wantCode: "not_found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			h.ServeHTTP(rr, httptest.NewRequest(tt.method, tt.path, nil))
+
+			if rr.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d; body: %s", rr.Code, tt.wantStatus, rr.Body.String())
+			}
+			if got := rr.Header().Get("Allow"); got != tt.wantAllow {
+				t.Errorf("Allow = %q, want %q", got, tt.wantAllow)
+			}
+			if tt.wantCode == "" {
+				return
+			}
+			if ct := rr.Header().Get("Content-Type"); ct != "application/json" {
+				t.Errorf("Content-Type = %q, want application/json", ct)
+			}
+			var resp ErrorResponse
+			if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("failed to unmarshal response: %v", err)
+			}
+			if resp.Error != tt.wantCode {
+				t.Errorf("error = %q, want %q", resp.Error, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestTrailingSlash(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/items", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	})
+	mux.HandleFunc("GET /api/items/{id}", func(w http.ResponseWriter, r *http.Request) {
+		WriteJSON(w, http.StatusOK, map[string]string{"id": r.PathValue("id")})
+	})
+	mux.HandleFunc("GET /{slug}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusFound)
+	})
+
+	tests := []struct {
+		name         string
+		policy       TrailingSlashPolicy
+		method       string
+		target       string
+		wantStatus   int
+		wantLocation string
+	}{
+		{"strict leaves path alone", TrailingSlashStrict, http.MethodPost, "/api/items/", http.StatusNotFound, ""},
+		{"redirect keeps query", TrailingSlashRedirect, http.MethodGet, "/api/items/42/?full=1", http.StatusPermanentRedirect, "/api/items/42?full=1"},
+		{"redirect collapses repeated slashes", TrailingSlashRedirect, http.MethodPost, "/api/items//", http.StatusPermanentRedirect, "/api/items"},
+		{"strip serves canonical route", TrailingSlashStrip, http.MethodPost, "/api/items/", http.StatusCreated, ""},
+		{"strip serves wildcard route", TrailingSlashStrip, http.MethodGet, "/api/items/42/", http.StatusOK, ""},
+		{"canonical path untouched", TrailingSlashRedirect, http.MethodPost, "/api/items", http.StatusCreated, ""},
+		{"prefix itself untouched", TrailingSlashRedirect, http.MethodGet, "/api/", http.StatusNotFound, ""},
+		{"paths outside prefix untouched", TrailingSlashStrip, http.MethodGet, "/abc1234/", http.StatusNotFound, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := TrailingSlash(tt.policy, "/api/")(mux)
+
+			rr := httptest.NewRecorder()
+			h.ServeHTTP(rr, httptest.NewRequest(tt.method, tt.target, nil))
+
+			if rr.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rr.Code, tt.wantStatus)
+			}
+			if got := rr.Header().Get("Location"); got != tt.wantLocation {
+				t.Errorf("Location = %q, want %q", got, tt.wantLocation)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package httpx
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Negotiate picks the media type in offered that the request's Accept
+// header rates highest, honouring q-values and the "type/*" and "*/*"
+// wildcards, the most specific matching range taking precedence. Ties go
+// to the earlier offer, so list the server's preference first. A request
+// without an Accept header accepts anything and gets offered[0]. It
+// returns "" when nothing offered is acceptable.
+func Negotiate(r *http.Request, offered []string) string {
+	ranges := parseAccept(r.Header.Values("Accept"))
+	if len(ranges) == 0 {
+		if len(offered) == 0 {
+			return ""
+		}
+		return offered[0]
+	}
+
+	best, bestQ := "", 0.0
+	for _, offer := range offered {
+		if q := acceptQuality(ranges, strings.ToLower(offer)); q > bestQ {
+			best, bestQ = offer, q
+		}
+	}
+	return best
+}
+
+// mediaRange is one entry of an Accept header.
+type mediaRange struct {
+	typ, subtype string
+	q            float64
+}
+
+// parseAccept parses Accept header values, skipping malformed entries.
+func parseAccept(values []string) []mediaRange {
+	var ranges []mediaRange
+	for _, v := range values {
+		for entry := range strings.SplitSeq(v, ",") {
+			mediaType, params, _ := strings.Cut(entry, ";")
+			typ, subtype, ok := strings.Cut(strings.ToLower(strings.TrimSpace(mediaType)), "/")
+			if !ok || typ == "" || subtype == "" || (typ == "*" && subtype != "*") {
+				continue
+			}
+
+			q := 1.0
+			for param := range strings.SplitSeq(params, ";") {
+				name, value, _ := strings.Cut(param, "=")
+				if strings.EqualFold(strings.TrimSpace(name), "q") {
+					if f, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil && f >= 0 && f <= 1 {
+						q = f
+					}
+				}
+			}
+			ranges = append(ranges, mediaRange{typ: typ, subtype: subtype, q: q})
+		}
+	}
+	return ranges
+}
+
+// acceptQuality returns the q-value of the most specific range matching
+// offer, or 0 when none does.
+func acceptQuality(ranges []mediaRange, offer string) float64 {
+	typ, subtype, _ := strings.Cut(offer, "/")
+
+	q, specificity := 0.0, -1
+	for _, r := range ranges {
+		var s int
+		switch {
+		case r.typ == typ && r.subtype == subtype:
+			s = 2
+		case r.typ == typ && r.subtype == "*":
+			s = 1
+		case r.typ == "*":
+			s = 0
+		default:
+			continue
+		}
+		if s > specificity {
+			q, specificity = r.q, s
+		}
+	}
+	return q
+}
@@ -0,0 +1,50 @@
+package httpx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNegotiate(t *testing.T) {
+	offered := []string{"application/json", "application/problem+json", "text/html"}
+
+	tests := []struct {
+		name    string
+		accept  []string
+		offered []string
+		want    string
+	}{
+		{name: "no Accept header", want: "application/json"},
+		{name: "exact match", accept: []string{"text/html"}, want: "text/html"},
+		{name: "highest q wins", accept: []string{"application/json;q=0.4, text/html;q=0.9, application/problem+json;q=0.7"}, want: "text/html"},
+		{name: "q defaults to 1", accept: []string{"application/json;q=0.9, application/problem+json"}, want: "application/problem+json"},
+		{name: "ties go to the earlier offer", accept: []string{"text/html, application/json"}, want: "application/json"},
+		{name: "any type", accept: []string{"*/*"}, want: "application/json"},
+		{name: "any subtype", accept: []string{"text/*"}, want: "text/html"},
+		{name: "specific range beats wildcard", accept: []string{"*/*;q=0.8, application/json;q=0.1"}, want: "application/problem+json"},
+		{name: "q=0 excludes", accept: []string{"application/json;q=0, */*"}, want: "application/problem+json"},
+		{name: "case-insensitive", accept: []string{"Text/HTML; Q=0.5"}, want: "text/html"},
+		{name: "multiple header values", accept: []string{"image/png", "text/html"}, want: "text/html"},
+		{name: "malformed entries skipped", accept: []string{"json, */json, text/html"}, want: "text/html"},
+		{name: "no match", accept: []string{"image/png, text/*;q=0"}, want: ""},
+		{name: "nothing offered", accept: []string{"*/*"}, offered: []string{}, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			for _, v := range tt.accept {
+				req.Header.Add("Accept", v)
+			}
+			o := offered
+			if tt.offered != nil {
+				o = tt.offered
+			}
+
+			if got := Negotiate(req, o); got != tt.want {
+				t.Errorf("Negotiate(%q) = %q, want %q", tt.accept, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package httpx
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// SlidingWindowLimiter limits events per key using a sliding window counter.
+// The count for the previous fixed window is weighted by how much of it still
+// overlaps the sliding window, which smooths bursts at window boundaries
+// without storing individual timestamps. It is safe for concurrent use.
+type SlidingWindowLimiter struct {
+	limit  int
+	window time.Duration
+	now    func() time.Time
+
+	mu        sync.Mutex
+	counters  map[string]*windowCounter
+	lastSweep time.Time
+}
+
+// windowCounter tracks counts for the current and previous fixed windows.
+type windowCounter struct {
+	start time.Time
+	curr  int
+	prev  int
+}
+
+// NewSlidingWindowLimiter allows up to limit events per key within window.
+func NewSlidingWindowLimiter(limit int, window time.Duration) *SlidingWindowLimiter {
+	return &SlidingWindowLimiter{
+		limit:    limit,
+		window:   window,
+		now:      time.Now,
+		counters: make(map[string]*windowCounter),
+	}
+}
+
+// Allow records an event for key and reports whether it is within the limit.
+// Rejected events are not counted.
+func (l *SlidingWindowLimiter) Allow(key string) bool {
+	now := l.now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sweep(now)
+
+	c, ok := l.counters[key]
+	if !ok {
+		c = &windowCounter{start: now.Truncate(l.window)}
+		l.counters[key] = c
+	}
+	c.advance(now, l.window)
+
+	elapsed := now.Sub(c.start)
+	weight := float64(l.window-elapsed) / float64(l.window)
+	estimate := float64(c.prev)*weight + float64(c.curr)
+
+	if estimate+1 > float64(l.limit) {
+		return false
+	}
+	c.curr++
+	return true
+}
+
+// advance rolls the counter forward so that start is the current window.
+func (c *windowCounter) advance(now time.Time, window time.Duration) {
+	start := now.Truncate(window)
+	switch {
+	case start.Equal(c.start):
+		return
+	case start.Sub(c.start) == window:
+		c.prev = c.curr
+	default:
+		c.prev = 0
+	}
+	c.curr = 0
+	c.start = start
+}
+
+// sweep drops counters that no longer influence any decision.
+// It runs at most once per window to keep Allow cheap.
+func (l *SlidingWindowLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < l.window {
+		return
+	}
+	l.lastSweep = now
+
+	cutoff := now.Truncate(l.window).Add(-l.window)
+	for key, c := range l.counters {
+		if c.start.Before(cutoff) {
+			delete(l.counters, key)
+		}
+	}
+}
+
+// RateLimit is a middleware that rejects requests with 429 once the limiter
+// denies the key derived from the request.
+func RateLimit(limiter *SlidingWindowLimiter, key func(*http.Request) string) Middleware {
+	retryAfter := strconv.Itoa(max(1, int(math.Ceil(limiter.window.Seconds()))))
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !limiter.Allow(key(r)) {
+				w.Header().Set("Retry-After", retryAfter)
+				WriteError(w, http.StatusTooManyRequests,
+					"rate_limited",
+					"too many requests, please slow down",
+					nil)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
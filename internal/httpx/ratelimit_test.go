@@ -0,0 +1,109 @@
+package httpx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// fakeNow returns a controllable clock for limiter tests.
+func fakeNow(start time.Time) (func() time.Time, func(time.Duration)) {
+	now := start
+	return func() time.Time { return now }, func(d time.Duration) { now = now.Add(d) }
+}
+
+func TestSlidingWindowLimiter_Allow(t *testing.T) {
+	now, advance := fakeNow(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	l := NewSlidingWindowLimiter(3, time.Minute)
+	l.now = now
+
+	for i := range 3 {
+		if !l.Allow("k") {
+			t.Fatalf("request %d denied, want allowed", i+1)
+		}
+	}
+	if l.Allow("k") {
+		t.Fatal("4th request allowed, want denied")
+	}
+
+	// Other keys have independent budgets.
+	if !l.Allow("other") {
+		t.Error("different key denied, want allowed")
+	}
+
+	// Halfway into the next window, half of the previous window still counts.
+	advance(90 * time.Second)
+	if !l.Allow("k") {
+		t.Error("request denied after window slid, want allowed")
+	}
+	if l.Allow("k") {
+		t.Error("request allowed while weighted count at limit, want denied")
+	}
+
+	// Two full windows later everything has expired.
+	advance(2 * time.Minute)
+	for i := range 3 {
+		if !l.Allow("k") {
+			t.Fatalf("request %d denied after expiry, want allowed", i+1)
+		}
+	}
+}
+
+func TestSlidingWindowLimiter_SweepsStaleKeys(t *testing.T) {
+	now, advance := fakeNow(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	l := NewSlidingWindowLimiter(1, time.Minute)
+	l.now = now
+
+	l.Allow("a")
+	l.Allow("b")
+	advance(3 * time.Minute)
+	l.Allow("c")
+
+	if _, ok := l.counters["a"]; ok {
+		t.Error("stale key a was not swept")
+	}
+	if len(l.counters) != 1 {
+		t.Errorf("counters = %d, want 1", len(l.counters))
+	}
+}
+
+func TestRateLimit_PerIPAndSlug(t *testing.T) {
+	limiter := NewSlidingWindowLimiter(2, time.Minute)
+
+	mux := http.NewServeMux()
+	mux.Handle("GET /{slug}", RateLimit(limiter, func(r *http.Request) string {
+		return ClientIP(r) + "|" + r.PathValue("slug")
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusFound)
+	})))
+
+	resolve := func(ip, slug string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/"+slug, nil)
+		req.RemoteAddr = ip + ":12345"
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, req)
+		return rr
+	}
+
+	for i := range 2 {
+		if rr := resolve("10.0.0.1", "abc1234"); rr.Code != http.StatusFound {
+			t.Fatalf("request %d status = %d, want %d", i+1, rr.Code, http.StatusFound)
+		}
+	}
+
+	rr := resolve("10.0.0.1", "abc1234")
+	if rr.Code != http.StatusTooManyRequests {
+		t.Fatalf("throttled status = %d, want %d", rr.Code, http.StatusTooManyRequests)
+	}
+	if rr.Header().Get("Retry-After") != "60" {
+		t.Errorf("Retry-After = %q, want %q", rr.Header().Get("Retry-After"), "60")
+	}
+
+	if rr := resolve("10.0.0.1", "other12"); rr.Code != http.StatusFound {
+		t.Errorf("different slug status = %d, want %d", rr.Code, http.StatusFound)
+	}
+	if rr := resolve("10.0.0.2", "abc1234"); rr.Code != http.StatusFound {
+		t.Errorf("different IP status = %d, want %d", rr.Code, http.StatusFound)
+	}
+}
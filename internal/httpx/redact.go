@@ -0,0 +1,63 @@
+package httpx
+
+import (
+	"net/url"
+	"strings"
+)
+
+// RedactedValue replaces the value of a redacted query parameter.
+const RedactedValue = "REDACTED"
+
+// DefaultRedactedParams are query parameters commonly carrying credentials.
+var DefaultRedactedParams = []string{"token", "access_token", "sig"}
+
+// Redactor masks sensitive query parameter values in URLs before they are
+// logged. The zero value redacts nothing. It is safe for concurrent use.
+type Redactor struct {
+	params map[string]struct{}
+}
+
+// NewRedactor returns a Redactor for the given parameter names, matched
+// case-insensitively.
+func NewRedactor(params []string) *Redactor {
+	r := &Redactor{params: make(map[string]struct{}, len(params))}
+	for _, p := range params {
+		if p = strings.TrimSpace(p); p != "" {
+			r.params[strings.ToLower(p)] = struct{}{}
+		}
+	}
+	return r
+}
+
+// URL returns raw with the value of every configured query parameter
+// replaced by RedactedValue. Other parameters, their order, and the rest of
+// the URL are kept as is. raw need not be a valid URL.
+func (r *Redactor) URL(raw string) string {
+	if r == nil || len(r.params) == 0 {
+		return raw
+	}
+
+	start := strings.IndexByte(raw, '?')
+	if start < 0 {
+		return raw
+	}
+	query := raw[start+1:]
+	fragment := ""
+	if end := strings.IndexByte(query, '#'); end >= 0 {
+		query, fragment = query[:end], query[end:]
+	}
+
+	pairs := strings.Split(query, "&")
+	for i, pair := range pairs {
+		key, _, hasValue := strings.Cut(pair, "=")
+		name, err := url.QueryUnescape(key)
+		if err != nil {
+			name = key
+		}
+		if _, ok := r.params[strings.ToLower(name)]; ok && hasValue {
+			pairs[i] = key + "=" + RedactedValue
+		}
+	}
+
+	return raw[:start+1] + strings.Join(pairs, "&") + fragment
+}
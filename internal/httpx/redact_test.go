@@ -0,0 +1,45 @@
+package httpx
+
+import "testing"
+
+func TestRedactor_URL(t *testing.T) {
+	r := NewRedactor([]string{"token", "Access_Token", " sig "})
+
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"masks token and keeps other params", "https://example.com/cb?id=7&token=s3cret&lang=en", "https://example.com/cb?id=7&token=REDACTED&lang=en"},
+		{"case-insensitive names", "https://example.com/?ACCESS_TOKEN=abc", "https://example.com/?ACCESS_TOKEN=REDACTED"},
+		{"escaped name", "https://example.com/?%74oken=abc", "https://example.com/?%74oken=REDACTED"},
+		{"repeated param", "https://example.com/?sig=a&sig=b", "https://example.com/?sig=REDACTED&sig=REDACTED"},
+		{"keeps fragment", "https://example.com/?token=abc#top", "https://example.com/?token=REDACTED#top"},
+		{"no query", "https://example.com/path", "https://example.com/path"},
+		{"param without value", "https://example.com/?token", "https://example.com/?token"},
+		{"similar name untouched", "https://example.com/?tokens=abc", "https://example.com/?tokens=abc"},
+		{"not a valid url", "not a url?token=abc", "not a url?token=REDACTED"},
+		{"empty", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := r.URL(tt.in); got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestRedactor_NoParams(t *testing.T) {
+	in := "https://example.com/?token=abc"
+
+	if got := NewRedactor(nil).URL(in); got != in {
+		t.Errorf("expected %q unchanged, got %q", in, got)
+	}
+
+	var r *Redactor
+	if got := r.URL(in); got != in {
+		t.Errorf("nil Redactor: expected %q unchanged, got %q", in, got)
+	}
+}
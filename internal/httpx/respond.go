@@ -6,6 +6,20 @@ import (
 	"net/http"
 )
 
+// ErrorFormat selects how WriteError renders errors.
+type ErrorFormat string
+
+const (
+	// ErrorFormatJSON writes an ErrorResponse. It is the default.
+	ErrorFormatJSON ErrorFormat = "json"
+	// ErrorFormatProblem writes an RFC 7807 Problem as
+	// application/problem+json.
+	ErrorFormatProblem ErrorFormat = "problem"
+)
+
+// ProblemTypePrefix prefixes the error code to form a Problem's type URI.
+const ProblemTypePrefix = "urn:urlshortener:problem:"
+
 // ErrorResponse represents a JSON error response.
 type ErrorResponse struct {
 	Error   string `json:"error"`
@@ -18,15 +32,49 @@ func WriteJSON(w http.ResponseWriter, status int, v any) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
 
-	if err := json.NewEncoder(w).Encode(v); err != nil {
+	if err := newEncoder(w).Encode(v); err != nil {
 		// At this point headers are already sent, so we can't change the response
 		// Just log the error
 		slog.Error("failed to encode JSON response", "error", err)
 	}
 }
 
-// WriteError writes a JSON error response.
+// Problem is an RFC 7807 problem details document. Details is an extension
+// member carrying the same value as ErrorResponse.Details.
+type Problem struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+	Details  any    `json:"details,omitempty"`
+}
+
+// WriteProblem writes p as application/problem+json with p.Status.
+func WriteProblem(w http.ResponseWriter, p Problem) {
+	w.Header().Set("Content-Type", problemMediaType)
+	w.WriteHeader(p.Status)
+
+	if err := newEncoder(w).Encode(p); err != nil {
+		slog.Error("failed to encode problem response", "error", err)
+	}
+}
+
+// WriteError writes an error response: an ErrorResponse by default, or a
+// Problem when the request passed through ProblemErrors.
 func WriteError(w http.ResponseWriter, status int, code, message string, details any) {
+	if pw, ok := findProblemWriter(w); ok {
+		WriteProblem(w, Problem{
+			Type:     ProblemTypePrefix + code,
+			Title:    http.StatusText(status),
+			Status:   status,
+			Detail:   message,
+			Instance: pw.requestID,
+			Details:  details,
+		})
+		return
+	}
+
 	resp := ErrorResponse{
 		Error:   code,
 		Message: message,
@@ -34,3 +82,93 @@ func WriteError(w http.ResponseWriter, status int, code, message string, details
 	}
 	WriteJSON(w, status, resp)
 }
+
+// problemWriter marks a response whose errors are written as Problems.
+type problemWriter struct {
+	http.ResponseWriter
+	requestID string
+}
+
+func (pw *problemWriter) Unwrap() http.ResponseWriter { return pw.ResponseWriter }
+
+// findProblemWriter looks for a problemWriter under any wrappers that
+// expose Unwrap, as http.ResponseController does.
+func findProblemWriter(w http.ResponseWriter) (*problemWriter, bool) {
+	for {
+		switch t := w.(type) {
+		case *problemWriter:
+			return t, true
+		case interface{ Unwrap() http.ResponseWriter }:
+			w = t.Unwrap()
+		default:
+			return nil, false
+		}
+	}
+}
+
+// prettyWriter marks a response whose JSON bodies are indented.
+type prettyWriter struct {
+	http.ResponseWriter
+}
+
+func (pw *prettyWriter) Unwrap() http.ResponseWriter { return pw.ResponseWriter }
+
+// isPretty looks for a prettyWriter under any wrappers that expose Unwrap.
+func isPretty(w http.ResponseWriter) bool {
+	for {
+		switch t := w.(type) {
+		case *prettyWriter:
+			return true
+		case interface{ Unwrap() http.ResponseWriter }:
+			w = t.Unwrap()
+		default:
+			return false
+		}
+	}
+}
+
+// newEncoder returns a JSON encoder for the response body, indenting when
+// the request passed through an enabled PrettyJSON.
+func newEncoder(w http.ResponseWriter) *json.Encoder {
+	enc := json.NewEncoder(w)
+	if isPretty(w) {
+		enc.SetIndent("", "  ")
+	}
+	return enc
+}
+
+// PrettyJSON makes WriteJSON, WriteError and WriteProblem indent the
+// bodies of requests it wraps, to ease debugging in development. Disabled,
+// it passes requests through and bodies stay compact.
+func PrettyJSON(enabled bool) Middleware {
+	return func(next http.Handler) http.Handler {
+		if !enabled {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(&prettyWriter{ResponseWriter: w}, r)
+		})
+	}
+}
+
+// ProblemErrors makes WriteError emit RFC 7807 problems, with the request ID
+// as the instance, for requests it wraps. It must run after the request ID
+// middleware. Under ErrorFormatJSON only requests whose Accept header
+// prefers application/problem+json get problems; the rest are unchanged.
+func ProblemErrors(format ErrorFormat) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if format != ErrorFormatProblem && Negotiate(r, errorMediaTypes) != problemMediaType {
+				next.ServeHTTP(w, r)
+				return
+			}
+			next.ServeHTTP(&problemWriter{ResponseWriter: w, requestID: GetRequestID(r.Context())}, r)
+		})
+	}
+}
+
+const problemMediaType = "application/problem+json"
+
+// errorMediaTypes are the error bodies a client may ask for, the default
+// first.
+var errorMediaTypes = []string{"application/json", problemMediaType}
@@ -2,8 +2,11 @@ package httpx
 
 import (
 	"encoding/json"
+	"io"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 )
 
@@ -207,3 +210,174 @@ func TestErrorResponse_JSONMarshaling(t *testing.T) {
 		t.Errorf("expected message %q, got %q", resp.Message, unmarshaled.Message)
 	}
 }
+
+func TestWriteProblem(t *testing.T) {
+	rr := httptest.NewRecorder()
+
+	WriteProblem(rr, Problem{
+		Type:     ProblemTypePrefix + "conflict",
+		Title:    "Conflict",
+		Status:   http.StatusConflict,
+		Detail:   "slug already exists",
+		Instance: "req-42",
+	})
+
+	if rr.Code != http.StatusConflict {
+		t.Errorf("expected status %d, got %d", http.StatusConflict, rr.Code)
+	}
+	if ct := rr.Header().Get("Content-Type"); ct != "application/problem+json" {
+		t.Errorf("expected Content-Type application/problem+json, got %q", ct)
+	}
+
+	var got map[string]any
+	if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	want := map[string]any{
+		"type":     "urn:urlshortener:problem:conflict",
+		"title":    "Conflict",
+		"status":   float64(http.StatusConflict),
+		"detail":   "slug already exists",
+		"instance": "req-42",
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("%s = %v, want %v", k, got[k], v)
+		}
+	}
+	if _, ok := got["details"]; ok {
+		t.Errorf("unexpected details member: %v", got["details"])
+	}
+}
+
+func TestProblemErrors(t *testing.T) {
+	writeNotFound := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		WriteError(w, http.StatusNotFound, "not_found", "link not found", map[string]string{"slug": "abc1234"})
+	})
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	t.Run("writes problems with the request ID as instance", func(t *testing.T) {
+		// Logger wraps the writer again; WriteError must see through it.
+		h := Chain(RequestID, ProblemErrors(ErrorFormatProblem), Logger(logger))(writeNotFound)
+
+		req := httptest.NewRequest(http.MethodGet, "/abc1234", nil)
+		req.Header.Set(RequestIDHeader, "req-42")
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusNotFound {
+			t.Errorf("expected status %d, got %d", http.StatusNotFound, rr.Code)
+		}
+		if ct := rr.Header().Get("Content-Type"); ct != "application/problem+json" {
+			t.Errorf("expected Content-Type application/problem+json, got %q", ct)
+		}
+
+		var p Problem
+		if err := json.Unmarshal(rr.Body.Bytes(), &p); err != nil {
+			t.Fatalf("failed to unmarshal response: %v", err)
+		}
+		if p.Type != ProblemTypePrefix+"not_found" || p.Title != "Not Found" || p.Status != http.StatusNotFound {
+			t.Errorf("type/title/status = %q/%q/%d", p.Type, p.Title, p.Status)
+		}
+		if p.Detail != "link not found" {
+			t.Errorf("detail = %q, want %q", p.Detail, "link not found")
+		}
+		if p.Instance != "req-42" {
+			t.Errorf("instance = %q, want req-42", p.Instance)
+		}
+		if p.Details == nil {
+			t.Error("details extension member missing")
+		}
+	})
+
+	t.Run("json format keeps ErrorResponse", func(t *testing.T) {
+		h := Chain(RequestID, ProblemErrors(ErrorFormatJSON))(writeNotFound)
+
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/abc1234", nil))
+
+		if ct := rr.Header().Get("Content-Type"); ct != "application/json" {
+			t.Errorf("expected Content-Type application/json, got %q", ct)
+		}
+		var resp ErrorResponse
+		if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("failed to unmarshal response: %v", err)
+		}
+		if resp.Error != "not_found" {
+			t.Errorf("error = %q, want not_found", resp.Error)
+		}
+	})
+
+	t.Run("json format honours Accept", func(t *testing.T) {
+		h := Chain(RequestID, ProblemErrors(ErrorFormatJSON))(writeNotFound)
+
+		for accept, want := range map[string]string{
+			"application/problem+json":                         "application/problem+json",
+			"application/json;q=0.5, application/problem+json": "application/problem+json",
+			"application/problem+json;q=0.5, application/json": "application/json",
+			"*/*": "application/json",
+		} {
+			req := httptest.NewRequest(http.MethodGet, "/abc1234", nil)
+			req.Header.Set("Accept", accept)
+			rr := httptest.NewRecorder()
+			h.ServeHTTP(rr, req)
+
+			if ct := rr.Header().Get("Content-Type"); ct != want {
+				t.Errorf("Accept %q: Content-Type = %q, want %q", accept, ct, want)
+			}
+		}
+	})
+}
+
+func TestPrettyJSON(t *testing.T) {
+	writeLink := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		WriteJSON(w, http.StatusOK, map[string]string{"slug": "abc1234"})
+	})
+	writeNotFound := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		WriteError(w, http.StatusNotFound, "not_found", "link not found", nil)
+	})
+
+	tests := []struct {
+		name    string
+		handler http.Handler
+		enabled bool
+		want    string
+	}{
+		{"compact by default", writeLink, false, "{\"slug\":\"abc1234\"}\n"},
+		{"indented when enabled", writeLink, true, "{\n  \"slug\": \"abc1234\"\n}\n"},
+		{"indents errors", writeNotFound, true, "{\n  \"error\": \"not_found\",\n  \"message\": \"link not found\"\n}\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Problem errors wrap the writer again; the encoder must see through it.
+			h := Chain(RequestID, PrettyJSON(tt.enabled), ProblemErrors(ErrorFormatJSON))(tt.handler)
+
+			rr := httptest.NewRecorder()
+			h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/abc1234", nil))
+
+			if got := rr.Body.String(); got != tt.want {
+				t.Errorf("body = %q, want %q", got, tt.want)
+			}
+			var v map[string]any
+			if err := json.Unmarshal(rr.Body.Bytes(), &v); err != nil {
+				t.Errorf("failed to unmarshal response: %v", err)
+			}
+		})
+	}
+
+	t.Run("indents problems", func(t *testing.T) {
+		h := Chain(RequestID, PrettyJSON(true), ProblemErrors(ErrorFormatProblem))(writeNotFound)
+
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/abc1234", nil))
+
+		var p Problem
+		if err := json.Unmarshal(rr.Body.Bytes(), &p); err != nil {
+			t.Fatalf("failed to unmarshal response: %v", err)
+		}
+		if p.Status != http.StatusNotFound || !strings.HasPrefix(rr.Body.String(), "{\n  \"type\"") {
+			t.Errorf("body = %q, want an indented problem", rr.Body.String())
+		}
+	})
+}
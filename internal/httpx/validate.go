@@ -0,0 +1,40 @@
+package httpx
+
+import "strings"
+
+// FieldError describes why a single request field was rejected.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// FieldErrors collects every field failure in a request body so clients can
+// fix them in one round trip.
+type FieldErrors []FieldError
+
+// Add records that field failed with message, e.g. Add("url", "is required").
+func (e *FieldErrors) Add(field, message string) {
+	*e = append(*e, FieldError{Field: field, Message: message})
+}
+
+// Err returns the failures as a *ValidationError, or nil when none occurred.
+func (e FieldErrors) Err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return &ValidationError{Fields: e}
+}
+
+// ValidationError is returned when one or more request fields are invalid.
+type ValidationError struct {
+	Fields FieldErrors
+}
+
+// Error joins the failures as "field message" pairs.
+func (e *ValidationError) Error() string {
+	parts := make([]string, 0, len(e.Fields))
+	for _, fe := range e.Fields {
+		parts = append(parts, fe.Field+" "+fe.Message)
+	}
+	return strings.Join(parts, "; ")
+}
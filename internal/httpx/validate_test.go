@@ -0,0 +1,47 @@
+package httpx
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestFieldErrors(t *testing.T) {
+	t.Run("empty is nil error", func(t *testing.T) {
+		var errs FieldErrors
+		if err := errs.Err(); err != nil {
+			t.Errorf("expected nil error, got %v", err)
+		}
+	})
+
+	t.Run("joins every failure", func(t *testing.T) {
+		var errs FieldErrors
+		errs.Add("url", "is required")
+		errs.Add("custom_slug", "is too long")
+
+		err := errs.Err()
+		var verr *ValidationError
+		if !errors.As(err, &verr) {
+			t.Fatalf("expected *ValidationError, got %T", err)
+		}
+		if len(verr.Fields) != 2 {
+			t.Errorf("expected 2 field errors, got %d", len(verr.Fields))
+		}
+		if want := "url is required; custom_slug is too long"; err.Error() != want {
+			t.Errorf("expected %q, got %q", want, err.Error())
+		}
+	})
+
+	t.Run("encodes as field list", func(t *testing.T) {
+		var errs FieldErrors
+		errs.Add("url", "is required")
+
+		b, err := json.Marshal(errs)
+		if err != nil {
+			t.Fatalf("marshal failed: %v", err)
+		}
+		if want := `[{"field":"url","message":"is required"}]`; string(b) != want {
+			t.Errorf("expected %s, got %s", want, b)
+		}
+	})
+}
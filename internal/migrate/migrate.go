@@ -0,0 +1,172 @@
+// Package migrate applies embedded SQL migrations at startup.
+//
+// Migrations are forward-only: each "<version>_<name>.up.sql" file is applied
+// once, in version order, and recorded in the migrations table. Down files are
+// ignored; rolling back is left to the migrate CLI.
+package migrate
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// TableName is the table recording applied migration versions.
+const TableName = "migrations"
+
+// lockKey identifies the advisory lock that serializes migration runs, so
+// replicas starting together apply each migration once.
+const lockKey int64 = 0x75726c6d6967 // "urlmig"
+
+const upSuffix = ".up.sql"
+
+// Migration is a single forward migration.
+type Migration struct {
+	Version int64
+	Name    string
+	SQL     string
+}
+
+// Load reads the up migrations in the root of fsys, sorted by version.
+// Files that are not up migrations are skipped.
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	var migrations []Migration
+	seen := make(map[int64]string)
+	for _, e := range entries {
+		base, ok := strings.CutSuffix(e.Name(), upSuffix)
+		if e.IsDir() || !ok {
+			continue
+		}
+
+		rawVersion, name, _ := strings.Cut(base, "_")
+		version, err := strconv.ParseInt(rawVersion, 10, 64)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("invalid migration file name %q: want <version>_<name>%s", e.Name(), upSuffix)
+		}
+		if prev, dup := seen[version]; dup {
+			return nil, fmt.Errorf("duplicate migration version %d: %s and %s", version, prev, e.Name())
+		}
+		seen[version] = e.Name()
+
+		body, err := fs.ReadFile(fsys, e.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", e.Name(), err)
+		}
+		migrations = append(migrations, Migration{Version: version, Name: name, SQL: string(body)})
+	}
+
+	slices.SortFunc(migrations, func(a, b Migration) int {
+		return cmp.Compare(a.Version, b.Version)
+	})
+	return migrations, nil
+}
+
+// store records applied migrations.
+type store interface {
+	// init creates the migrations table if it does not exist.
+	init(ctx context.Context) error
+	// apply runs m and records its version atomically. It reports false
+	// without running m when the version is already recorded.
+	apply(ctx context.Context, m Migration) (bool, error)
+}
+
+// Up applies the pending migrations in fsys to the database and returns how
+// many were applied. It is safe to run on every startup and from several
+// replicas at once.
+func Up(ctx context.Context, pool *pgxpool.Pool, fsys fs.FS, logger *slog.Logger) (int, error) {
+	migrations, err := Load(fsys)
+	if err != nil {
+		return 0, err
+	}
+	return run(ctx, &pgStore{pool: pool}, migrations, logger)
+}
+
+func run(ctx context.Context, s store, migrations []Migration, logger *slog.Logger) (int, error) {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	if err := s.init(ctx); err != nil {
+		return 0, fmt.Errorf("failed to create %s table: %w", TableName, err)
+	}
+
+	applied := 0
+	for _, m := range migrations {
+		ok, err := s.apply(ctx, m)
+		if err != nil {
+			return applied, fmt.Errorf("migration %d_%s failed: %w", m.Version, m.Name, err)
+		}
+		if !ok {
+			continue
+		}
+		applied++
+		logger.InfoContext(ctx, "applied migration",
+			"version", m.Version,
+			"name", m.Name,
+		)
+	}
+	return applied, nil
+}
+
+// pgStore keeps applied versions in a PostgreSQL table.
+type pgStore struct {
+	pool *pgxpool.Pool
+}
+
+func (s *pgStore) init(ctx context.Context) error {
+	return pgx.BeginFunc(ctx, s.pool, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, "SELECT pg_advisory_xact_lock($1)", lockKey); err != nil {
+			return err
+		}
+		_, err := tx.Exec(ctx, `CREATE TABLE IF NOT EXISTS `+TableName+` (
+			version    BIGINT PRIMARY KEY,
+			name       TEXT NOT NULL,
+			applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+		)`)
+		return err
+	})
+}
+
+func (s *pgStore) apply(ctx context.Context, m Migration) (bool, error) {
+	applied := false
+	err := pgx.BeginFunc(ctx, s.pool, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, "SELECT pg_advisory_xact_lock($1)", lockKey); err != nil {
+			return err
+		}
+
+		var exists bool
+		err := tx.QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM "+TableName+" WHERE version = $1)", m.Version).Scan(&exists)
+		if err != nil {
+			return err
+		}
+		if exists {
+			return nil
+		}
+
+		if _, err := tx.Exec(ctx, m.SQL); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, "INSERT INTO "+TableName+" (version, name) VALUES ($1, $2)", m.Version, m.Name); err != nil {
+			return err
+		}
+		applied = true
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return applied, nil
+}
@@ -0,0 +1,176 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"testing/fstest"
+
+	"github.com/sundayezeilo/urlshortener/db/migrations"
+)
+
+// memStore is an in-memory store standing in for a fresh database schema.
+type memStore struct {
+	versions map[int64]bool
+	executed []string
+	failOn   int64
+}
+
+func newMemStore() *memStore {
+	return &memStore{versions: make(map[int64]bool)}
+}
+
+func (s *memStore) init(context.Context) error { return nil }
+
+func (s *memStore) apply(_ context.Context, m Migration) (bool, error) {
+	if s.versions[m.Version] {
+		return false, nil
+	}
+	if m.Version == s.failOn {
+		return false, errors.New("syntax error")
+	}
+	s.versions[m.Version] = true
+	s.executed = append(s.executed, m.SQL)
+	return true, nil
+}
+
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func TestLoad(t *testing.T) {
+	fsys := fstest.MapFS{
+		"20260102000000_add_owner.up.sql":    {Data: []byte("ALTER TABLE links ADD COLUMN owner TEXT;")},
+		"20260102000000_add_owner.down.sql":  {Data: []byte("ALTER TABLE links DROP COLUMN owner;")},
+		"20260101000000_create_links.up.sql": {Data: []byte("CREATE TABLE links (id UUID);")},
+		"embed.go":                           {Data: []byte("package migrations")},
+	}
+
+	got, err := Load(fsys)
+	if err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+
+	want := []Migration{
+		{Version: 20260101000000, Name: "create_links", SQL: "CREATE TABLE links (id UUID);"},
+		{Version: 20260102000000, Name: "add_owner", SQL: "ALTER TABLE links ADD COLUMN owner TEXT;"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Load() = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Load()[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		fsys fstest.MapFS
+	}{
+		{
+			name: "non-numeric version",
+			fsys: fstest.MapFS{"first_create_links.up.sql": {}},
+		},
+		{
+			name: "duplicate version",
+			fsys: fstest.MapFS{
+				"20260101000000_create_links.up.sql":  {},
+				"20260101000000_create_clicks.up.sql": {},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Load(tt.fsys); err == nil {
+				t.Error("Load() expected error")
+			}
+		})
+	}
+}
+
+func TestLoad_EmbeddedMigrations(t *testing.T) {
+	got, err := Load(migrations.FS)
+	if err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+	if len(got) == 0 {
+		t.Fatal("Load() found no embedded migrations")
+	}
+	if got[0].Name != "create_links_table" {
+		t.Errorf("first migration = %q, want create_links_table", got[0].Name)
+	}
+}
+
+func TestRun(t *testing.T) {
+	ms := []Migration{
+		{Version: 1, Name: "create_links", SQL: "CREATE TABLE links (id UUID);"},
+		{Version: 2, Name: "add_owner", SQL: "ALTER TABLE links ADD COLUMN owner TEXT;"},
+	}
+
+	t.Run("applies every migration to a fresh schema", func(t *testing.T) {
+		s := newMemStore()
+
+		n, err := run(context.Background(), s, ms, testLogger())
+		if err != nil {
+			t.Fatalf("run() unexpected error: %v", err)
+		}
+		if n != 2 {
+			t.Errorf("applied = %d, want 2", n)
+		}
+		if len(s.executed) != 2 || s.executed[0] != ms[0].SQL || s.executed[1] != ms[1].SQL {
+			t.Errorf("executed = %q, want migrations in version order", s.executed)
+		}
+	})
+
+	t.Run("re-runs are idempotent", func(t *testing.T) {
+		s := newMemStore()
+		if _, err := run(context.Background(), s, ms, testLogger()); err != nil {
+			t.Fatalf("first run() unexpected error: %v", err)
+		}
+
+		n, err := run(context.Background(), s, ms, testLogger())
+		if err != nil {
+			t.Fatalf("second run() unexpected error: %v", err)
+		}
+		if n != 0 {
+			t.Errorf("applied on re-run = %d, want 0", n)
+		}
+		if len(s.executed) != 2 {
+			t.Errorf("executed %d statements, want 2", len(s.executed))
+		}
+	})
+
+	t.Run("applies only new migrations", func(t *testing.T) {
+		s := newMemStore()
+		if _, err := run(context.Background(), s, ms[:1], testLogger()); err != nil {
+			t.Fatalf("first run() unexpected error: %v", err)
+		}
+
+		n, err := run(context.Background(), s, ms, testLogger())
+		if err != nil {
+			t.Fatalf("second run() unexpected error: %v", err)
+		}
+		if n != 1 || s.executed[len(s.executed)-1] != ms[1].SQL {
+			t.Errorf("applied = %d, executed = %q; want only add_owner", n, s.executed)
+		}
+	})
+
+	t.Run("stops at the first failure", func(t *testing.T) {
+		s := newMemStore()
+		s.failOn = 1
+
+		n, err := run(context.Background(), s, ms, testLogger())
+		if err == nil {
+			t.Fatal("run() expected error")
+		}
+		if n != 0 || len(s.executed) != 0 {
+			t.Errorf("applied = %d, executed = %q; want nothing after the failure", n, s.executed)
+		}
+	})
+}
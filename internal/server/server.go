@@ -6,43 +6,134 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+	"net/netip"
 	"os"
 	"os/signal"
+	"strings"
+	"sync/atomic"
 	"syscall"
+	"time"
+
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
 
 	"github.com/sundayezeilo/urlshortener/internal/config"
+	"github.com/sundayezeilo/urlshortener/internal/errx"
+	"github.com/sundayezeilo/urlshortener/internal/health"
 	"github.com/sundayezeilo/urlshortener/internal/httpx"
 	"github.com/sundayezeilo/urlshortener/internal/shortener"
 )
 
 // Server represents the HTTP server with all dependencies.
 type Server struct {
-	config  *config.Config
-	logger  *slog.Logger
-	handler *shortener.Handler
-	server  *http.Server
+	config      *config.Config
+	logger      *slog.Logger
+	handler     *shortener.Handler
+	server      *http.Server
+	poolMonitor *health.PoolMonitor
+	keyspace    *shortener.KeyspaceMonitor
+	errorCounts *errx.Counts
+	caches      map[string]*shortener.MemoryCache
+	tracker     *shortener.ClickTracker
+	proxies     []netip.Prefix
+	draining    atomic.Bool
+}
+
+// Option configures optional Server dependencies.
+type Option func(*Server)
+
+// WithPoolMonitor exposes the monitor's latest pool stats on the readiness endpoint.
+func WithPoolMonitor(m *health.PoolMonitor) Option {
+	return func(s *Server) {
+		s.poolMonitor = m
+	}
+}
+
+// WithKeyspaceMonitor exposes the latest slug keyspace estimate on the
+// readiness endpoint. It is informational and never fails readiness.
+func WithKeyspaceMonitor(m *shortener.KeyspaceMonitor) Option {
+	return func(s *Server) {
+		s.keyspace = m
+	}
+}
+
+// WithErrorCounts exposes per-kind counts of the errors the handler has
+// written on the readiness endpoint. It never fails readiness.
+func WithErrorCounts(c *errx.Counts) Option {
+	return func(s *Server) {
+		s.errorCounts = c
+	}
+}
+
+// WithCacheStats exposes the hit, miss and eviction counters of cache under
+// name on the readiness endpoint, for tuning its size and TTL. It never
+// fails readiness.
+func WithCacheStats(name string, c *shortener.MemoryCache) Option {
+	return func(s *Server) {
+		if s.caches == nil {
+			s.caches = make(map[string]*shortener.MemoryCache)
+		}
+		s.caches[name] = c
+	}
+}
+
+// WithClickTracker exposes the tracker's dropped and inline click counts
+// on the readiness endpoint, so clicks lost to a full buffer show up. It
+// never fails readiness.
+func WithClickTracker(t *shortener.ClickTracker) Option {
+	return func(s *Server) {
+		s.tracker = t
+	}
+}
+
+// WithTrustedProxies resolves the client IP of requests relayed by one of
+// proxies from their forwarding headers, for logging, rate limits and
+// click tracking.
+func WithTrustedProxies(proxies []netip.Prefix) Option {
+	return func(s *Server) {
+		s.proxies = proxies
+	}
 }
 
 // New creates a new Server instance.
-func New(cfg *config.Config, logger *slog.Logger, handler *shortener.Handler) *Server {
-	return &Server{
+func New(cfg *config.Config, logger *slog.Logger, handler *shortener.Handler, opts ...Option) *Server {
+	s := &Server{
 		config:  cfg,
 		logger:  logger,
 		handler: handler,
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 // Start starts the HTTP server and blocks until shutdown.
 func (s *Server) Start(ctx context.Context) error {
-	mux := s.setupRoutes()
-	handler := s.applyMiddleware(mux)
+	// Listen for interrupt signals
+	shutdown := make(chan os.Signal, 1)
+	signal.Notify(shutdown, os.Interrupt, syscall.SIGTERM, syscall.SIGINT)
+	defer signal.Stop(shutdown)
+
+	return s.serve(ctx, shutdown)
+}
+
+// serve runs the HTTP server until it fails or a value arrives on shutdown.
+func (s *Server) serve(ctx context.Context, shutdown <-chan os.Signal) error {
 	s.server = &http.Server{
 		Addr:         fmt.Sprintf("%s:%s", s.config.Server.Host, s.config.Server.Port),
-		Handler:      handler,
+		Handler:      s.httpHandler(),
 		ReadTimeout:  s.config.Server.ReadTimeout,
 		WriteTimeout: s.config.Server.WriteTimeout,
 		IdleTimeout:  s.config.Server.IdleTimeout,
 	}
+	if s.config.Server.TLSEnabled() {
+		tlsConfig, err := s.config.Server.TLSConfig()
+		if err != nil {
+			return fmt.Errorf("server error: %w", err)
+		}
+		s.server.TLSConfig = tlsConfig
+	}
 
 	// Listen for errors from the server
 	serverErrors := make(chan error, 1)
@@ -52,14 +143,16 @@ func (s *Server) Start(ctx context.Context) error {
 		s.logger.Info("starting http server",
 			"addr", s.server.Addr,
 			"env", s.config.App.Environment,
+			"h2c", s.config.Server.H2C,
+			"tls", s.config.Server.TLSEnabled(),
 		)
+		if s.config.Server.TLSEnabled() {
+			serverErrors <- s.server.ListenAndServeTLS(s.config.Server.TLSCertFile, s.config.Server.TLSKeyFile)
+			return
+		}
 		serverErrors <- s.server.ListenAndServe()
 	}()
 
-	// Listen for interrupt signals
-	shutdown := make(chan os.Signal, 1)
-	signal.Notify(shutdown, os.Interrupt, syscall.SIGTERM, syscall.SIGINT)
-
 	select {
 	case err := <-serverErrors:
 		return fmt.Errorf("server error: %w", err)
@@ -67,6 +160,14 @@ func (s *Server) Start(ctx context.Context) error {
 	case sig := <-shutdown:
 		s.logger.Info("received shutdown signal", "signal", sig.String())
 
+		// Fail readiness first so load balancers stop routing new traffic
+		// here, then give them time to notice before we stop accepting.
+		s.draining.Store(true)
+		if delay := s.config.Server.PrestopDelay; delay > 0 {
+			s.logger.Info("draining before shutdown", "delay", delay.String())
+			time.Sleep(delay)
+		}
+
 		// Create context with timeout for shutdown
 		ctx, cancel := context.WithTimeout(context.Background(), s.config.Server.ShutdownTimeout)
 		defer cancel()
@@ -85,29 +186,172 @@ func (s *Server) Start(ctx context.Context) error {
 	}
 }
 
+// httpHandler returns the routes wrapped in middleware, additionally
+// accepting cleartext HTTP/2 when h2c is enabled.
+func (s *Server) httpHandler() http.Handler {
+	handler := s.applyMiddleware(s.setupRoutes())
+	if s.config.Server.H2C {
+		handler = h2c.NewHandler(handler, &http2.Server{IdleTimeout: s.config.Server.IdleTimeout})
+	}
+	return handler
+}
+
 // setupRoutes configures all HTTP routes.
-func (s *Server) setupRoutes() *http.ServeMux {
+func (s *Server) setupRoutes() http.Handler {
 	mux := http.NewServeMux()
 
-	// Health check endpoint
+	// Health check endpoints. GET patterns also match HEAD, which
+	// orchestrators use as a probe; the server drops the body.
 	mux.HandleFunc("GET /x/health", s.healthCheckHandler)
+	mux.HandleFunc("GET /x/ready", s.readinessHandler)
+
+	// Creation is open, but a valid API key namespaces the caller's slugs
+	tenantAuth := httpx.OptionalAPIKeyAuth(s.config.Server.APIKeys)
+	mux.Handle("POST /api/links", tenantAuth(http.HandlerFunc(s.handler.CreateLink)))
+	mux.Handle("POST /api/links/batch", tenantAuth(http.HandlerFunc(s.handler.CreateLinksBatch)))
+	mux.Handle("POST /api/slugs/availability", tenantAuth(http.HandlerFunc(s.handler.CheckSlugAvailability)))
+
+	// Admin endpoints can enumerate links, so they require an API key
+	adminAuth := httpx.APIKeyAuth(s.config.Server.APIKeys)
+	mux.Handle("GET /api/links", adminAuth(http.HandlerFunc(s.handler.ListLinks)))
+	mux.Handle("GET /api/links/by-url", adminAuth(http.HandlerFunc(s.handler.GetLinksByURL)))
+	mux.Handle("GET /api/links/id/{id}", adminAuth(http.HandlerFunc(s.handler.GetLinkByID)))
+	mux.Handle("GET /api/stats", adminAuth(http.HandlerFunc(s.handler.GetStats)))
+	mux.Handle("GET /api/stats/sources", adminAuth(http.HandlerFunc(s.handler.GetSourceStats)))
+	mux.Handle("DELETE /api/links/{slug}", adminAuth(http.HandlerFunc(s.handler.DeleteLink)))
+	mux.HandleFunc("GET /api/links/{slug}", s.handler.GetLink)
+
+	// Per-link sub-resources get their own mux behind a single pattern.
+	// Registered directly, GET /api/links/{slug}/metadata and
+	// GET /api/links/id/{id} would both match /api/links/id/metadata with
+	// neither more specific, and ServeMux rejects that; the literal id
+	// segment does win over /api/links/{slug}/{resource}.
+	links := http.NewServeMux()
+	links.Handle("GET /api/links/{slug}/metadata", adminAuth(http.HandlerFunc(s.handler.GetLinkMetadata)))
+	links.Handle("POST /api/links/{slug}/aliases", adminAuth(http.HandlerFunc(s.handler.AddLinkAlias)))
+	links.Handle("POST /api/links/{slug}/pause", adminAuth(http.HandlerFunc(s.handler.PauseLink)))
+	links.Handle("POST /api/links/{slug}/resume", adminAuth(http.HandlerFunc(s.handler.ResumeLink)))
+	links.Handle("GET /api/links/{slug}/timeseries", adminAuth(http.HandlerFunc(s.handler.GetLinkTimeSeries)))
+	links.HandleFunc("GET /api/links/{slug}/preview", s.handler.GetLinkPreview)
+	mux.Handle("/api/links/{slug}/{resource}", httpx.RouteErrors(links))
+
+	// The public routes are left out of API-only deployments, so every
+	// other path 404s from the mux instead of being resolved as a slug.
+	// POST is redirected only for links with a method-preserving status.
+	if !s.config.Server.ResolveDisabled {
+		mux.HandleFunc("GET /{$}", s.handler.Root)
+		resolve := s.resolveHandler()
+		mux.Handle("GET /{slug}", resolve)
+		mux.Handle("POST /{slug}", resolve)
+	}
 
-	mux.HandleFunc("POST /api/links", s.handler.CreateLink)
-	mux.HandleFunc("GET /{slug}", s.handler.ResolveLink)
+	// The resolved config helps debug deployments but is never exposed in
+	// production, even redacted
+	if s.config.App.Environment != "production" {
+		mux.HandleFunc("GET /x/debug/config", s.debugConfigHandler)
+	}
 
-	return mux
+	return httpx.RouteErrors(mux)
+}
+
+// resolveHandler returns the redirect handler, rate limited per client IP
+// and slug when configured.
+func (s *Server) resolveHandler() http.Handler {
+	var h http.Handler = http.HandlerFunc(s.handler.ResolveLink)
+
+	if s.config.Server.ResolveRateLimit > 0 {
+		limiter := httpx.NewSlidingWindowLimiter(s.config.Server.ResolveRateLimit, s.config.Server.ResolveRateWindow)
+		h = httpx.RateLimit(limiter, func(r *http.Request) string {
+			return httpx.ClientIP(r) + "|" + r.PathValue("slug")
+		})(h)
+	}
+
+	return h
+}
+
+// isResolve reports whether r is routed to the redirect handler: any
+// single-segment path other than the root, which setupRoutes registers as
+// "/{slug}". Resolves must keep working in maintenance mode, including
+// POSTs to links with a method-preserving redirect status.
+func (s *Server) isResolve(r *http.Request) bool {
+	if s.config.Server.ResolveDisabled {
+		return false
+	}
+	slug, ok := strings.CutPrefix(r.URL.Path, "/")
+	return ok && slug != "" && !strings.Contains(slug, "/")
 }
 
 // applyMiddleware wraps the handler with middleware in the correct order.
 func (s *Server) applyMiddleware(handler http.Handler) http.Handler {
 	return httpx.Chain(
-		httpx.Recovery(s.logger), // Outermost: catch panics
-		httpx.RequestID,          // Add request ID
-		httpx.Logger(s.logger),   // Log requests
-		httpx.CORS(nil),          // CORS headers (allow all in dev)
+		s.requestIDMiddleware(),                             // Outermost: add request ID
+		httpx.TrustedProxies(s.proxies),                     // Resolve the client IP behind proxies
+		s.errorFormatMiddleware(),                           // Select the error body format
+		httpx.PrettyJSON(s.config.Server.PrettyJSON),        // Indent JSON bodies for debugging
+		httpx.Recovery(s.logger),                            // Catch panics, quoting the request ID
+		s.loggerMiddleware(),                                // Log requests, flagging slow ones
+		s.bodyLogMiddleware(),                               // Log request bodies in development
+		httpx.ConcurrencyLimit(s.config.Server.MaxInFlight), // Shed load when saturated
+		httpx.MaintenanceWith(httpx.MaintenanceConfig{ // Reject writes during maintenance
+			Enabled:     s.config.Server.MaintenanceMode,
+			Message:     s.config.Server.MaintenanceMessage,
+			ExemptPaths: s.config.Server.MaintenanceExemptPaths,
+			Exempt:      s.isResolve,
+		}),
+		httpx.CORS(nil), // CORS headers (allow all in dev)
+		httpx.TrailingSlash( // Innermost: normalize API paths before routing
+			httpx.TrailingSlashPolicy(s.config.Server.TrailingSlash), "/api/",
+		),
 	)(handler)
 }
 
+// loggerMiddleware logs requests, at WARN once they exceed the configured
+// slow request threshold.
+func (s *Server) loggerMiddleware() httpx.Middleware {
+	return httpx.LoggerWith(httpx.LoggerConfig{
+		Logger:        s.logger,
+		SlowThreshold: s.config.Server.SlowRequestThreshold,
+	})
+}
+
+// bodyLogMiddleware logs request bodies when enabled, except in production
+// where bodies may hold personal data.
+func (s *Server) bodyLogMiddleware() httpx.Middleware {
+	if !s.config.Server.LogRequestBodies || s.config.App.Environment == "production" {
+		return func(next http.Handler) http.Handler { return next }
+	}
+	return httpx.BodyLogger(httpx.BodyLogConfig{
+		Logger:       s.logger,
+		MaxBytes:     s.config.Server.LogBodyMaxBytes,
+		RedactFields: s.config.Server.LogRedactFields,
+	})
+}
+
+// requestIDMiddleware builds the request ID middleware from config. An unset
+// header or source keeps the RequestID defaults, and an unset validation
+// accepts any incoming ID.
+func (s *Server) requestIDMiddleware() httpx.Middleware {
+	cfg := httpx.RequestIDConfig{Header: s.config.Server.RequestIDHeader}
+	if s.config.Server.RequestIDSource == "traceparent" {
+		cfg.Generator = httpx.TraceparentRequestID
+	}
+	switch s.config.Server.RequestIDValidation {
+	case "uuid":
+		cfg.Validator = httpx.UUIDRequestIDValidator
+	case "length":
+		cfg.Validator = httpx.MaxLengthRequestIDValidator(s.config.Server.RequestIDMaxLength)
+	}
+	return httpx.RequestIDWith(cfg)
+}
+
+// errorFormatMiddleware renders errors as RFC 7807 problems when the
+// "problem" error format is configured or the client's Accept header
+// prefers them. It sits inside the request ID
+// middleware so problems can name the request as their instance.
+func (s *Server) errorFormatMiddleware() httpx.Middleware {
+	return httpx.ProblemErrors(httpx.ErrorFormat(s.config.Server.ErrorFormat))
+}
+
 // healthCheckHandler handles health check requests.
 func (s *Server) healthCheckHandler(w http.ResponseWriter, r *http.Request) {
 	httpx.WriteJSON(w, http.StatusOK, map[string]string{
@@ -117,6 +361,62 @@ func (s *Server) healthCheckHandler(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// debugConfigHandler returns the loaded configuration with secrets redacted.
+func (s *Server) debugConfigHandler(w http.ResponseWriter, r *http.Request) {
+	httpx.WriteJSON(w, http.StatusOK, s.config.Redacted())
+}
+
+// readinessHandler reports whether the server can take traffic.
+// It fails while the server is draining for shutdown.
+// When a pool monitor is configured, its latest snapshot is included and an
+// unhealthy database makes the server report not ready. The latest keyspace
+// estimate, error counts, cache stats and click tracker counts are
+// included when configured.
+func (s *Server) readinessHandler(w http.ResponseWriter, r *http.Request) {
+	if s.draining.Load() {
+		httpx.WriteJSON(w, http.StatusServiceUnavailable, map[string]any{"status": "draining"})
+		return
+	}
+
+	resp := map[string]any{"status": "ready"}
+	status := http.StatusOK
+
+	if s.poolMonitor != nil {
+		snap, ok := s.poolMonitor.Snapshot()
+		if ok {
+			resp["database"] = snap
+		}
+		if !ok || !snap.Healthy {
+			resp["status"] = "unavailable"
+			status = http.StatusServiceUnavailable
+		}
+	}
+
+	if s.keyspace != nil {
+		if est, ok := s.keyspace.Estimate(); ok {
+			resp["keyspace"] = est
+		}
+	}
+
+	if s.errorCounts != nil {
+		resp["errors"] = s.errorCounts.Snapshot()
+	}
+
+	if len(s.caches) > 0 {
+		caches := make(map[string]shortener.CacheStats, len(s.caches))
+		for name, c := range s.caches {
+			caches[name] = c.Stats()
+		}
+		resp["caches"] = caches
+	}
+
+	if s.tracker != nil {
+		resp["click_tracking"] = s.tracker.Stats()
+	}
+
+	httpx.WriteJSON(w, status, resp)
+}
+
 // Shutdown gracefully shuts down the server.
 func (s *Server) Shutdown(ctx context.Context) error {
 	if s.server == nil {
@@ -0,0 +1,937 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/net/http2"
+
+	"github.com/sundayezeilo/urlshortener/internal/config"
+	"github.com/sundayezeilo/urlshortener/internal/errx"
+	"github.com/sundayezeilo/urlshortener/internal/health"
+	"github.com/sundayezeilo/urlshortener/internal/httpx"
+	"github.com/sundayezeilo/urlshortener/internal/shortener"
+)
+
+// stubPool implements health.Pool for testing.
+type stubPool struct {
+	pingErr error
+	stats   health.PoolStats
+}
+
+func (p *stubPool) Ping(ctx context.Context) error { return p.pingErr }
+func (p *stubPool) Stats() health.PoolStats        { return p.stats }
+
+// stubService implements shortener.Service, resolving every slug to
+// resolveURL with redirectStatus.
+type stubService struct {
+	resolveURL     string
+	redirectStatus int
+}
+
+func (s *stubService) Create(ctx context.Context, req shortener.CreateLinkRequest) (shortener.Link, error) {
+	return shortener.Link{OriginalURL: req.OriginalURL, Slug: "abc1234"}, nil
+}
+
+func (s *stubService) CreateBatch(ctx context.Context, reqs []shortener.CreateLinkRequest) ([]shortener.BatchResult, error) {
+	results := make([]shortener.BatchResult, len(reqs))
+	for i, req := range reqs {
+		results[i] = shortener.BatchResult{Index: i, Status: shortener.BatchCreated,
+			Link: shortener.Link{OriginalURL: req.OriginalURL, Slug: "abc1234"}}
+	}
+	return results, nil
+}
+
+func (s *stubService) GetBySlug(ctx context.Context, slug string) (shortener.Link, error) {
+	return shortener.Link{OriginalURL: s.resolveURL, Slug: slug}, nil
+}
+
+func (s *stubService) GetByID(ctx context.Context, id uuid.UUID) (shortener.Link, error) {
+	return shortener.Link{ID: id, OriginalURL: s.resolveURL, Slug: "abc1234"}, nil
+}
+
+func (s *stubService) GetMetadata(ctx context.Context, slug string) (shortener.LinkMetadata, error) {
+	return shortener.LinkMetadata{Link: shortener.Link{OriginalURL: s.resolveURL, Slug: slug}}, nil
+}
+
+func (s *stubService) List(ctx context.Context, req shortener.ListLinksRequest) (shortener.LinkPage, error) {
+	return shortener.LinkPage{}, nil
+}
+
+func (s *stubService) GetByURL(ctx context.Context, rawURL string) ([]shortener.Link, error) {
+	return []shortener.Link{{OriginalURL: rawURL, Slug: "abc1234"}}, nil
+}
+
+func (s *stubService) CountBySource(ctx context.Context) ([]shortener.SourceCount, error) {
+	return []shortener.SourceCount{{Source: shortener.SourceAPI, Links: 1}}, nil
+}
+
+func (s *stubService) CountClicksByCountry(ctx context.Context) ([]shortener.CountryCount, error) {
+	return []shortener.CountryCount{{Country: "DE", Clicks: 1}}, nil
+}
+
+func (s *stubService) TimeSeries(ctx context.Context, req shortener.TimeSeriesRequest) (shortener.TimeSeries, error) {
+	return shortener.TimeSeries{Slug: req.Slug, Bucket: shortener.BucketDay}, nil
+}
+
+func (s *stubService) Resolve(ctx context.Context, slug string) (shortener.Resolution, error) {
+	return shortener.Resolution{URL: s.resolveURL, RedirectStatus: s.redirectStatus}, nil
+}
+
+func (s *stubService) RedirectStatus(ctx context.Context, slug string) (int, error) {
+	return s.redirectStatus, nil
+}
+
+func (s *stubService) AddAlias(ctx context.Context, slug, alias string) (string, error) {
+	return alias, nil
+}
+
+func (s *stubService) CheckSlugs(ctx context.Context, principal string, slugs []string) (map[string]shortener.SlugAvailability, error) {
+	results := make(map[string]shortener.SlugAvailability, len(slugs))
+	for _, slug := range slugs {
+		results[slug] = shortener.SlugAvailability{Slug: slug, Status: shortener.SlugAvailable}
+	}
+	return results, nil
+}
+
+func (s *stubService) RecommendedSlugLength(ctx context.Context) (int, error) {
+	return shortener.DefaultSlugLength, nil
+}
+
+func (s *stubService) CheckSlugGenerator(ctx context.Context) error {
+	return nil
+}
+
+func (s *stubService) SetActive(ctx context.Context, slug string, active bool) (shortener.Link, error) {
+	return shortener.Link{OriginalURL: s.resolveURL, Slug: slug, Paused: !active}, nil
+}
+
+func (s *stubService) Delete(ctx context.Context, slug string) error { return nil }
+
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func testConfig() *config.Config {
+	return &config.Config{
+		App: config.AppConfig{Environment: "test", LogLevel: "error"},
+		Observability: config.ObservabilityConfig{
+			ServiceName:    "urlshortener-test",
+			ServiceVersion: "test",
+		},
+	}
+}
+
+func TestReadinessHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		pool       *stubPool
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "no monitor configured",
+			wantStatus: http.StatusOK,
+			wantBody:   "ready",
+		},
+		{
+			name:       "healthy pool",
+			pool:       &stubPool{stats: health.PoolStats{AcquiredConns: 1, TotalConns: 2, MaxConns: 4}},
+			wantStatus: http.StatusOK,
+			wantBody:   "ready",
+		},
+		{
+			name:       "unhealthy pool",
+			pool:       &stubPool{pingErr: errors.New("connection refused")},
+			wantStatus: http.StatusServiceUnavailable,
+			wantBody:   "unavailable",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var opts []Option
+			if tt.pool != nil {
+				m := health.NewPoolMonitor(health.PoolMonitorConfig{Pool: tt.pool, Logger: testLogger()})
+				m.Check(context.Background())
+				opts = append(opts, WithPoolMonitor(m))
+			}
+			srv := New(testConfig(), testLogger(), nil, opts...)
+
+			rr := httptest.NewRecorder()
+			srv.setupRoutes().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/x/ready", nil))
+
+			if rr.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rr.Code, tt.wantStatus)
+			}
+
+			var resp struct {
+				Status   string           `json:"status"`
+				Database *health.Snapshot `json:"database"`
+			}
+			if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if resp.Status != tt.wantBody {
+				t.Errorf("status field = %q, want %q", resp.Status, tt.wantBody)
+			}
+			if tt.pool != nil {
+				if resp.Database == nil {
+					t.Fatal("expected database snapshot in response")
+				}
+				if resp.Database.Stats != tt.pool.stats {
+					t.Errorf("database stats = %+v, want %+v", resp.Database.Stats, tt.pool.stats)
+				}
+			}
+		})
+	}
+}
+
+func TestReadinessHandler_ErrorCounts(t *testing.T) {
+	counts := &errx.Counts{}
+	counts.Add(errx.Conflict)
+	srv := New(testConfig(), testLogger(), nil, WithErrorCounts(counts))
+
+	rr := httptest.NewRecorder()
+	srv.setupRoutes().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/x/ready", nil))
+
+	var resp struct {
+		Errors map[string]uint64 `json:"errors"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.Errors[errx.Conflict.String()] != 1 {
+		t.Errorf("errors = %v, want one conflict", resp.Errors)
+	}
+}
+
+func TestReadinessHandler_CacheStats(t *testing.T) {
+	cache := shortener.NewMemoryCache(10, time.Minute, nil)
+	cache.Set("missing1", "")
+	cache.Get("missing1")
+	cache.Get("missing2")
+	srv := New(testConfig(), testLogger(), nil, WithCacheStats("not_found", cache))
+
+	rr := httptest.NewRecorder()
+	srv.setupRoutes().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/x/ready", nil))
+
+	var resp struct {
+		Caches map[string]shortener.CacheStats `json:"caches"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	want := shortener.CacheStats{Hits: 1, Misses: 1, Size: 1}
+	if got := resp.Caches["not_found"]; got != want {
+		t.Errorf("caches[not_found] = %+v, want %+v", got, want)
+	}
+}
+
+func TestReadinessHandler_ClickTracker(t *testing.T) {
+	tracker := shortener.NewClickTracker(shortener.ClickTrackerConfig{
+		BufferSize:     1,
+		OverflowPolicy: shortener.TrackingOverflowDrop,
+		Logger:         testLogger(),
+	})
+	for range 2 { // Not started, so the second click overflows the buffer
+		tracker.Track(context.Background(), func(context.Context) {})
+	}
+	srv := New(testConfig(), testLogger(), nil, WithClickTracker(tracker))
+
+	rr := httptest.NewRecorder()
+	srv.setupRoutes().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/x/ready", nil))
+
+	var resp struct {
+		ClickTracking shortener.ClickTrackerStats `json:"click_tracking"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.ClickTracking != (shortener.ClickTrackerStats{Dropped: 1}) {
+		t.Errorf("click_tracking = %+v, want one dropped click", resp.ClickTracking)
+	}
+}
+
+func TestHealthProbes_HEAD(t *testing.T) {
+	tests := []struct {
+		name       string
+		path       string
+		draining   bool
+		wantStatus int
+	}{
+		{name: "health", path: "/x/health", wantStatus: http.StatusOK},
+		{name: "ready", path: "/x/ready", wantStatus: http.StatusOK},
+		{name: "ready while draining", path: "/x/ready", draining: true, wantStatus: http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := New(testConfig(), testLogger(), nil)
+			srv.draining.Store(tt.draining)
+			ts := httptest.NewServer(srv.httpHandler())
+			defer ts.Close()
+
+			resp, err := http.Head(ts.URL + tt.path)
+			if err != nil {
+				t.Fatalf("HEAD %s failed: %v", tt.path, err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if got := resp.Header.Get("Content-Type"); got != "application/json" {
+				t.Errorf("Content-Type = %q, want application/json", got)
+			}
+			if resp.Header.Get(httpx.RequestIDHeader) == "" {
+				t.Errorf("missing %s header", httpx.RequestIDHeader)
+			}
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatalf("failed to read body: %v", err)
+			}
+			if len(body) != 0 {
+				t.Errorf("body = %q, want empty", body)
+			}
+		})
+	}
+}
+
+func TestRequestLog_RoutePattern(t *testing.T) {
+	var buf strings.Builder
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	srv := New(testConfig(), logger, nil)
+
+	rr := httptest.NewRecorder()
+	srv.httpHandler().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/x/health", nil))
+
+	var entry struct {
+		Msg   string `json:"msg"`
+		Route string `json:"route"`
+	}
+	for line := range strings.Lines(buf.String()) {
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("failed to decode log line %q: %v", line, err)
+		}
+		if entry.Msg == "http request" {
+			break
+		}
+	}
+	if entry.Route != "GET /x/health" {
+		t.Errorf("route = %q, want %q", entry.Route, "GET /x/health")
+	}
+}
+
+func TestServe_PrestopDelayFailsReadinessBeforeShutdown(t *testing.T) {
+	cfg := testConfig()
+	cfg.Server = config.ServerConfig{
+		Host:            "127.0.0.1",
+		Port:            "0",
+		ReadTimeout:     time.Second,
+		WriteTimeout:    time.Second,
+		IdleTimeout:     time.Second,
+		ShutdownTimeout: time.Second,
+		PrestopDelay:    100 * time.Millisecond,
+	}
+	srv := New(cfg, testLogger(), nil)
+
+	ready := func() int {
+		rr := httptest.NewRecorder()
+		srv.readinessHandler(rr, httptest.NewRequest(http.MethodGet, "/x/ready", nil))
+		return rr.Code
+	}
+
+	if got := ready(); got != http.StatusOK {
+		t.Fatalf("readiness before shutdown = %d, want %d", got, http.StatusOK)
+	}
+
+	shutdown := make(chan os.Signal, 1)
+	done := make(chan error, 1)
+	go func() { done <- srv.serve(context.Background(), shutdown) }()
+
+	shutdown <- syscall.SIGTERM
+
+	deadline := time.Now().Add(time.Second)
+	for ready() != http.StatusServiceUnavailable {
+		if time.Now().After(deadline) {
+			t.Fatal("readiness never flipped to unavailable")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	select {
+	case err := <-done:
+		t.Fatalf("server stopped before prestop delay elapsed: %v", err)
+	default:
+	}
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("serve() error = %v, want nil", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("server did not shut down")
+	}
+}
+
+func TestMaintenanceMode_BlocksCreateButResolves(t *testing.T) {
+	cfg := testConfig()
+	cfg.Server.MaintenanceMode = true
+
+	handler := shortener.NewHandler(shortener.HandlerConfig{
+		Service: &stubService{resolveURL: "https://example.com"},
+		Logger:  testLogger(),
+		BaseURL: "https://short.ly",
+	})
+	srv := New(cfg, testLogger(), handler)
+	h := srv.applyMiddleware(srv.setupRoutes())
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/api/links", strings.NewReader(`{"url":"https://example.com"}`)))
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Errorf("create status = %d, want %d", rr.Code, http.StatusServiceUnavailable)
+	}
+
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/abc1234", nil))
+	if rr.Code != http.StatusFound {
+		t.Errorf("resolve status = %d, want %d", rr.Code, http.StatusFound)
+	}
+	if got := rr.Header().Get("Location"); got != "https://example.com" {
+		t.Errorf("Location = %q, want %q", got, "https://example.com")
+	}
+}
+
+func TestMaintenanceMode_ResolvesMethodPreservingPOST(t *testing.T) {
+	cfg := testConfig()
+	cfg.Server.MaintenanceMode = true
+
+	handler := shortener.NewHandler(shortener.HandlerConfig{
+		Service: &stubService{resolveURL: "https://api.example.com/hook", redirectStatus: http.StatusPermanentRedirect},
+		Logger:  testLogger(),
+		BaseURL: "https://short.ly",
+	})
+	srv := New(cfg, testLogger(), handler)
+	h := srv.applyMiddleware(srv.setupRoutes())
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/abc1234", strings.NewReader(`{"event":"ping"}`)))
+	if rr.Code != http.StatusPermanentRedirect {
+		t.Errorf("resolve status = %d, want %d; body: %s", rr.Code, http.StatusPermanentRedirect, rr.Body.String())
+	}
+	if got := rr.Header().Get("Location"); got != "https://api.example.com/hook" {
+		t.Errorf("Location = %q, want %q", got, "https://api.example.com/hook")
+	}
+
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/api/links/abc1234/pause", nil))
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Errorf("pause status = %d, want %d", rr.Code, http.StatusServiceUnavailable)
+	}
+}
+
+func TestResolveRateLimit_TrustedProxies(t *testing.T) {
+	cfg := testConfig()
+	cfg.Server.ResolveRateLimit = 1
+	cfg.Server.ResolveRateWindow = time.Minute
+
+	handler := shortener.NewHandler(shortener.HandlerConfig{
+		Service: &stubService{resolveURL: "https://example.com"},
+		Logger:  testLogger(),
+		BaseURL: "https://short.ly",
+	})
+	srv := New(cfg, testLogger(), handler, WithTrustedProxies([]netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}))
+	h := srv.applyMiddleware(srv.setupRoutes())
+
+	resolve := func(peer, forwardedFor string) int {
+		req := httptest.NewRequest(http.MethodGet, "/abc1234", nil)
+		req.RemoteAddr = peer + ":4321"
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+		return rr.Code
+	}
+
+	// Clients behind the load balancer get their own limits
+	if code := resolve("10.0.0.5", "203.0.113.1"); code != http.StatusFound {
+		t.Errorf("first client status = %d, want %d", code, http.StatusFound)
+	}
+	if code := resolve("10.0.0.5", "203.0.113.2"); code != http.StatusFound {
+		t.Errorf("second client status = %d, want %d", code, http.StatusFound)
+	}
+	if code := resolve("10.0.0.6", "203.0.113.1"); code != http.StatusTooManyRequests {
+		t.Errorf("repeat client status = %d, want %d", code, http.StatusTooManyRequests)
+	}
+
+	// An untrusted peer can't dodge its limit with a forged header
+	if code := resolve("198.51.100.1", "203.0.113.3"); code != http.StatusFound {
+		t.Errorf("direct client status = %d, want %d", code, http.StatusFound)
+	}
+	if code := resolve("198.51.100.1", "203.0.113.4"); code != http.StatusTooManyRequests {
+		t.Errorf("forged header status = %d, want %d", code, http.StatusTooManyRequests)
+	}
+}
+
+func TestRootAndBrowserPaths(t *testing.T) {
+	tests := []struct {
+		name         string
+		rootRedirect string
+		path         string
+		wantStatus   int
+		wantLocation string
+	}{
+		{"root is a clean 404", "", "/", http.StatusNotFound, ""},
+		{"root redirects to landing page", "https://example.org/", "/", http.StatusFound, "https://example.org/"},
+		{"favicon is ignored", "", "/favicon.ico", http.StatusNotFound, ""},
+		{"real slug resolves", "", "/abc1234", http.StatusFound, "https://example.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var logs strings.Builder
+			handler := shortener.NewHandler(shortener.HandlerConfig{
+				Service:         &stubService{resolveURL: "https://example.com"},
+				Logger:          slog.New(slog.NewTextHandler(&logs, nil)),
+				BaseURL:         "https://short.ly",
+				RootRedirectURL: tt.rootRedirect,
+			})
+			srv := New(testConfig(), testLogger(), handler)
+
+			rr := httptest.NewRecorder()
+			srv.setupRoutes().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if rr.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rr.Code, tt.wantStatus)
+			}
+			if got := rr.Header().Get("Location"); got != tt.wantLocation {
+				t.Errorf("Location = %q, want %q", got, tt.wantLocation)
+			}
+			if strings.Contains(logs.String(), "level=WARN") {
+				t.Errorf("unexpected warning logged:\n%s", logs.String())
+			}
+		})
+	}
+}
+
+func TestMethodNotAllowed(t *testing.T) {
+	handler := shortener.NewHandler(shortener.HandlerConfig{
+		Service: &stubService{resolveURL: "https://example.com"},
+		Logger:  testLogger(),
+		BaseURL: "https://short.ly",
+	})
+	srv := New(testConfig(), testLogger(), handler)
+	h := srv.applyMiddleware(srv.setupRoutes())
+
+	tests := []struct {
+		method    string
+		path      string
+		wantAllow string
+	}{
+		{http.MethodPost, "/x/health", "GET, HEAD"},
+		{http.MethodPost, "/x/ready", "GET, HEAD"},
+		{http.MethodDelete, "/api/links", "GET, HEAD, POST"},
+		{http.MethodPut, "/api/links/batch", "DELETE, GET, HEAD, POST"},
+		{http.MethodPost, "/api/links/by-url", "DELETE, GET, HEAD"},
+		{http.MethodPost, "/api/stats/sources", "GET, HEAD"},
+		{http.MethodPost, "/api/links/abc1234/metadata", "GET, HEAD"},
+		{http.MethodPut, "/api/links/abc1234", "DELETE, GET, HEAD"},
+		{http.MethodPost, "/api/links/abc1234/timeseries", "GET, HEAD"},
+		{http.MethodPost, "/", "GET, HEAD"},
+		{http.MethodPut, "/abc1234", "GET, HEAD, POST"},
+		{http.MethodPost, "/x/debug/config", "GET, HEAD"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			h.ServeHTTP(rr, httptest.NewRequest(tt.method, tt.path, nil))
+
+			if rr.Code != http.StatusMethodNotAllowed {
+				t.Fatalf("status = %d, want %d", rr.Code, http.StatusMethodNotAllowed)
+			}
+			if got := rr.Header().Get("Allow"); got != tt.wantAllow {
+				t.Errorf("Allow = %q, want %q", got, tt.wantAllow)
+			}
+			var body httpx.ErrorResponse
+			if err := json.Unmarshal(rr.Body.Bytes(), &body); err != nil {
+				t.Fatalf("body is not a JSON error: %v: %s", err, rr.Body.String())
+			}
+			if body.Error != "method_not_allowed" {
+				t.Errorf("error = %q, want method_not_allowed", body.Error)
+			}
+		})
+	}
+}
+
+func TestTrailingSlash_APIRoutes(t *testing.T) {
+	routes := []struct {
+		method string
+		path   string
+		body   string
+	}{
+		{http.MethodPost, "/api/links", `{"url":"https://example.com"}`},
+		{http.MethodPost, "/api/links/batch", `{"links":[{"url":"https://example.com"}]}`},
+		{http.MethodPost, "/api/slugs/availability", `{"slugs":["spring-sale"]}`},
+		{http.MethodGet, "/api/links", ""},
+		{http.MethodGet, "/api/links/by-url?url=https%3A%2F%2Fexample.com", ""},
+		{http.MethodGet, "/api/links/id/9b2c1a6e-4f0d-4c3b-8a57-2f1e6d0c9a41", ""},
+		{http.MethodGet, "/api/stats", ""},
+		{http.MethodGet, "/api/stats/sources", ""},
+		{http.MethodGet, "/api/links/abc1234/metadata", ""},
+		{http.MethodPost, "/api/links/abc1234/aliases", `{"alias":"spring-sale"}`},
+		{http.MethodPost, "/api/links/abc1234/pause", ""},
+		{http.MethodPost, "/api/links/abc1234/resume", ""},
+		{http.MethodDelete, "/api/links/abc1234", ""},
+		{http.MethodGet, "/api/links/abc1234", ""},
+		{http.MethodGet, "/api/links/abc1234/timeseries", ""},
+		{http.MethodGet, "/api/links/abc1234/preview", ""},
+	}
+
+	newHandler := func(policy string) http.Handler {
+		cfg := testConfig()
+		cfg.Server.APIKeys = map[string]string{"secret": "ops"}
+		cfg.Server.TrailingSlash = policy
+		handler := shortener.NewHandler(shortener.HandlerConfig{
+			Service: &stubService{resolveURL: "https://example.com"},
+			Logger:  testLogger(),
+			BaseURL: "https://short.ly",
+		})
+		srv := New(cfg, testLogger(), handler)
+		return srv.applyMiddleware(srv.setupRoutes())
+	}
+	serve := func(h http.Handler, method, target, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set(httpx.APIKeyHeader, "secret")
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+		return rr
+	}
+	withSlash := func(target string) string {
+		path, query, _ := strings.Cut(target, "?")
+		if query == "" {
+			return path + "/"
+		}
+		return path + "/?" + query
+	}
+
+	strict, redirect, strip := newHandler("strict"), newHandler("redirect"), newHandler("strip")
+
+	for _, rt := range routes {
+		t.Run(rt.method+" "+rt.path, func(t *testing.T) {
+			want := serve(strip, rt.method, rt.path, rt.body).Code
+			if want == http.StatusNotFound || want == http.StatusMethodNotAllowed {
+				t.Fatalf("canonical status = %d, want a routed response", want)
+			}
+
+			if rr := serve(strict, rt.method, withSlash(rt.path), rt.body); rr.Code != http.StatusNotFound {
+				t.Errorf("strict status = %d, want %d", rr.Code, http.StatusNotFound)
+			}
+
+			rr := serve(redirect, rt.method, withSlash(rt.path), rt.body)
+			if rr.Code != http.StatusPermanentRedirect {
+				t.Errorf("redirect status = %d, want %d", rr.Code, http.StatusPermanentRedirect)
+			}
+			if got := rr.Header().Get("Location"); got != rt.path {
+				t.Errorf("redirect Location = %q, want %q", got, rt.path)
+			}
+
+			if rr := serve(strip, rt.method, withSlash(rt.path), rt.body); rr.Code != want {
+				t.Errorf("strip status = %d, want %d like the canonical path; body: %s", rr.Code, want, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestResolveDisabled(t *testing.T) {
+	cfg := testConfig()
+	cfg.Server.ResolveDisabled = true
+	handler := shortener.NewHandler(shortener.HandlerConfig{
+		Service: &stubService{resolveURL: "https://example.com"},
+		Logger:  testLogger(),
+		BaseURL: "https://short.ly",
+	})
+	routes := New(cfg, testLogger(), handler).setupRoutes()
+
+	tests := []struct {
+		method     string
+		path       string
+		body       string
+		wantStatus int
+	}{
+		{http.MethodGet, "/abc1234", "", http.StatusNotFound},
+		{http.MethodPost, "/abc1234", "", http.StatusNotFound},
+		{http.MethodGet, "/", "", http.StatusNotFound},
+		{http.MethodGet, "/api/links/abc1234", "", http.StatusOK},
+		{http.MethodPost, "/api/links", `{"url":"https://example.com"}`, http.StatusCreated},
+		{http.MethodGet, "/x/health", "", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			routes.ServeHTTP(rr, httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body)))
+
+			if rr.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d; body: %s", rr.Code, tt.wantStatus, rr.Body.String())
+			}
+			if loc := rr.Header().Get("Location"); tt.wantStatus == http.StatusNotFound && loc != "" {
+				t.Errorf("Location = %q, want no redirect", loc)
+			}
+		})
+	}
+}
+
+func TestLinksByURL_RequiresAPIKey(t *testing.T) {
+	cfg := testConfig()
+	cfg.Server.APIKeys = map[string]string{"secret": "ops"}
+
+	handler := shortener.NewHandler(shortener.HandlerConfig{
+		Service: &stubService{resolveURL: "https://example.com"},
+		Logger:  testLogger(),
+		BaseURL: "https://short.ly",
+	})
+	srv := New(cfg, testLogger(), handler)
+	h := srv.applyMiddleware(srv.setupRoutes())
+
+	target := "/api/links/by-url?url=https%3A%2F%2Fexample.com"
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, target, nil))
+	if rr.Code != http.StatusUnauthorized {
+		t.Errorf("status without key = %d, want %d", rr.Code, http.StatusUnauthorized)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	req.Header.Set(httpx.APIKeyHeader, "secret")
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Errorf("status with key = %d, want %d; body: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+}
+
+func TestListLinks_RequiresAPIKey(t *testing.T) {
+	cfg := testConfig()
+	cfg.Server.APIKeys = map[string]string{"secret": "ops"}
+
+	handler := shortener.NewHandler(shortener.HandlerConfig{
+		Service: &stubService{resolveURL: "https://example.com"},
+		Logger:  testLogger(),
+		BaseURL: "https://short.ly",
+	})
+	srv := New(cfg, testLogger(), handler)
+	h := srv.applyMiddleware(srv.setupRoutes())
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/links", nil))
+	if rr.Code != http.StatusUnauthorized {
+		t.Errorf("status without key = %d, want %d", rr.Code, http.StatusUnauthorized)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/links", nil)
+	req.Header.Set(httpx.APIKeyHeader, "secret")
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Errorf("status with key = %d, want %d; body: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+}
+
+func TestLinkByID_Route(t *testing.T) {
+	cfg := testConfig()
+	cfg.Server.APIKeys = map[string]string{"secret": "ops"}
+
+	handler := shortener.NewHandler(shortener.HandlerConfig{
+		Service: &stubService{resolveURL: "https://example.com"},
+		Logger:  testLogger(),
+		BaseURL: "https://short.ly",
+	})
+	srv := New(cfg, testLogger(), handler)
+	h := srv.applyMiddleware(srv.setupRoutes())
+
+	target := "/api/links/id/9b2c1a6e-4f0d-4c3b-8a57-2f1e6d0c9a41"
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, target, nil))
+	if rr.Code != http.StatusUnauthorized {
+		t.Errorf("status without key = %d, want %d", rr.Code, http.StatusUnauthorized)
+	}
+
+	tests := []struct {
+		path       string
+		wantStatus int
+	}{
+		{target, http.StatusOK},
+		{"/api/links/id/abc1234", http.StatusBadRequest},
+		{"/api/links/abc1234/metadata", http.StatusOK},
+		{"/api/links/abc1234/unknown", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+		req.Header.Set(httpx.APIKeyHeader, "secret")
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+		if rr.Code != tt.wantStatus {
+			t.Errorf("GET %s status = %d, want %d; body: %s", tt.path, rr.Code, tt.wantStatus, rr.Body.String())
+		}
+	}
+}
+
+func TestLinkMetadata_RequiresAPIKey(t *testing.T) {
+	cfg := testConfig()
+	cfg.Server.APIKeys = map[string]string{"secret": "ops"}
+
+	handler := shortener.NewHandler(shortener.HandlerConfig{
+		Service: &stubService{resolveURL: "https://example.com"},
+		Logger:  testLogger(),
+		BaseURL: "https://short.ly",
+	})
+	srv := New(cfg, testLogger(), handler)
+	h := srv.applyMiddleware(srv.setupRoutes())
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/links/abc1234/metadata", nil))
+	if rr.Code != http.StatusUnauthorized {
+		t.Errorf("status without key = %d, want %d", rr.Code, http.StatusUnauthorized)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/links/abc1234/metadata", nil)
+	req.Header.Set(httpx.APIKeyHeader, "secret")
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Errorf("status with key = %d, want %d; body: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+}
+
+func TestLinkTimeSeries_RequiresAPIKey(t *testing.T) {
+	cfg := testConfig()
+	cfg.Server.APIKeys = map[string]string{"secret": "ops"}
+
+	handler := shortener.NewHandler(shortener.HandlerConfig{
+		Service: &stubService{resolveURL: "https://example.com"},
+		Logger:  testLogger(),
+		BaseURL: "https://short.ly",
+	})
+	srv := New(cfg, testLogger(), handler)
+	h := srv.applyMiddleware(srv.setupRoutes())
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/links/abc1234/timeseries", nil))
+	if rr.Code != http.StatusUnauthorized {
+		t.Errorf("status without key = %d, want %d", rr.Code, http.StatusUnauthorized)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/links/abc1234/timeseries", nil)
+	req.Header.Set(httpx.APIKeyHeader, "secret")
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Errorf("status with key = %d, want %d; body: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+}
+
+func TestDebugConfig(t *testing.T) {
+	newHandler := func(env string) http.Handler {
+		cfg := testConfig()
+		cfg.App.Environment = env
+		cfg.Database.Password = "hunter2"
+
+		handler := shortener.NewHandler(shortener.HandlerConfig{
+			Service: &stubService{resolveURL: "https://example.com"},
+			Logger:  testLogger(),
+			BaseURL: "https://short.ly",
+		})
+		srv := New(cfg, testLogger(), handler)
+		return srv.applyMiddleware(srv.setupRoutes())
+	}
+
+	t.Run("serves the redacted config outside production", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		newHandler("development").ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/x/debug/config", nil))
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("status = %d, want %d", rr.Code, http.StatusOK)
+		}
+		if strings.Contains(rr.Body.String(), "hunter2") {
+			t.Errorf("response leaks the database password: %s", rr.Body.String())
+		}
+
+		var got config.Config
+		if err := json.NewDecoder(rr.Body).Decode(&got); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if got.Database.Password != config.RedactedValue {
+			t.Errorf("Database.Password = %q, want %q", got.Database.Password, config.RedactedValue)
+		}
+	})
+
+	t.Run("is unavailable in production", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		newHandler("production").ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/x/debug/config", nil))
+
+		if rr.Code != http.StatusNotFound {
+			t.Errorf("status = %d, want %d", rr.Code, http.StatusNotFound)
+		}
+	})
+}
+
+func TestH2C(t *testing.T) {
+	cfg := testConfig()
+	cfg.Server.H2C = true
+
+	handler := shortener.NewHandler(shortener.HandlerConfig{
+		Service: &stubService{resolveURL: "https://example.com"},
+		Logger:  testLogger(),
+		BaseURL: "https://short.ly",
+	})
+	ts := httptest.NewServer(New(cfg, testLogger(), handler).httpHandler())
+	defer ts.Close()
+
+	// Prior-knowledge h2c: speak HTTP/2 over a plain TCP connection.
+	client := &http.Client{Transport: &http2.Transport{
+		AllowHTTP: true,
+		DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, network, addr)
+		},
+	}}
+
+	resp, err := client.Get(ts.URL + "/x/health")
+	if err != nil {
+		t.Fatalf("h2c request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	if resp.ProtoMajor != 2 {
+		t.Errorf("proto = %s, want HTTP/2", resp.Proto)
+	}
+
+	// HTTP/1.1 clients keep working on the same listener.
+	resp, err = http.Get(ts.URL + "/x/health")
+	if err != nil {
+		t.Fatalf("HTTP/1.1 request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.ProtoMajor != 1 || resp.StatusCode != http.StatusOK {
+		t.Errorf("HTTP/1.1 response = %s %d, want HTTP/1.1 200", resp.Proto, resp.StatusCode)
+	}
+}
@@ -0,0 +1,34 @@
+package shortener
+
+import (
+	"context"
+)
+
+// accessCountReached handles a resolve that took link's access count to the
+// configured threshold. Each link crosses it once, so the warning is not
+// repeated per click; alerts_total counts crossings since startup. Pausing
+// is best-effort like click tracking: the redirect in progress still
+// succeeds, and later ones find the link paused.
+func (s *service) accessCountReached(ctx context.Context, link Link) {
+	attrs := []any{
+		"slug", link.Slug,
+		"access_count", link.AccessCount,
+		"threshold", s.accessCountThreshold,
+		"alerts_total", s.accessAlerts.Add(1),
+	}
+	if !s.autoPause {
+		s.logger.WarnContext(ctx, "link access count reached threshold, possible bot traffic", attrs...)
+		return
+	}
+
+	paused, err := s.repo.SetPaused(ctx, link.Slug, true)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to auto-pause link over access count threshold",
+			append(attrs, "error", err.Error())...)
+		return
+	}
+	s.invalidator.Invalidate(ctx, paused.Slug)
+	s.recordAudit(ctx, AuditUpdate, paused)
+	s.logger.WarnContext(ctx, "link access count reached threshold, possible bot traffic; link paused",
+		append(attrs, "paused", true)...)
+}
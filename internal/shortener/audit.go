@@ -0,0 +1,78 @@
+package shortener
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	db "github.com/sundayezeilo/urlshortener/internal/db/sqlc"
+)
+
+// AuditOp names a link mutation recorded in the audit log.
+type AuditOp string
+
+const (
+	AuditCreate  AuditOp = "create"
+	AuditUpdate  AuditOp = "update"
+	AuditDelete  AuditOp = "delete"
+	AuditRestore AuditOp = "restore"
+)
+
+// AuditEntry is one append-only record of a link mutation.
+type AuditEntry struct {
+	Op        AuditOp
+	Slug      string
+	Owner     string // Principal owning the link; empty for anonymous links
+	RequestID string // Request that caused the mutation, if known
+	At        time.Time
+}
+
+// AuditLogger records link mutations. Implementations must not fail the
+// mutation they record: it has already been committed by the time Record
+// is called.
+type AuditLogger interface {
+	Record(ctx context.Context, entry AuditEntry)
+}
+
+// nopAuditLogger discards every entry. It is the service default.
+type nopAuditLogger struct{}
+
+func (nopAuditLogger) Record(context.Context, AuditEntry) {}
+
+// auditQuerier is the subset of *db.Queries the database audit log needs.
+type auditQuerier interface {
+	InsertAuditEntry(ctx context.Context, arg db.InsertAuditEntryParams) error
+}
+
+type dbAuditLogger struct {
+	q      auditQuerier
+	logger *slog.Logger
+}
+
+// NewDBAuditLogger returns an AuditLogger that appends entries to the
+// audit_log table. Write failures are logged, not returned.
+func NewDBAuditLogger(q auditQuerier, logger *slog.Logger) AuditLogger {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &dbAuditLogger{q: q, logger: logger}
+}
+
+func (a *dbAuditLogger) Record(ctx context.Context, entry AuditEntry) {
+	err := a.q.InsertAuditEntry(ctx, db.InsertAuditEntryParams{
+		Op:        string(entry.Op),
+		Slug:      entry.Slug,
+		Owner:     pgtype.Text{String: entry.Owner, Valid: entry.Owner != ""},
+		RequestID: pgtype.Text{String: entry.RequestID, Valid: entry.RequestID != ""},
+		CreatedAt: pgtype.Timestamptz{Time: entry.At, Valid: true},
+	})
+	if err != nil {
+		a.logger.ErrorContext(ctx, "failed to write audit entry",
+			"op", entry.Op,
+			"slug", entry.Slug,
+			"error", err,
+		)
+	}
+}
@@ -0,0 +1,223 @@
+package shortener
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/sundayezeilo/urlshortener/internal/clock"
+	db "github.com/sundayezeilo/urlshortener/internal/db/sqlc"
+	"github.com/sundayezeilo/urlshortener/internal/errx"
+	"github.com/sundayezeilo/urlshortener/internal/httpx"
+)
+
+// recordingAuditLogger keeps every entry in memory.
+type recordingAuditLogger struct {
+	entries []AuditEntry
+}
+
+func (r *recordingAuditLogger) Record(_ context.Context, entry AuditEntry) {
+	r.entries = append(r.entries, entry)
+}
+
+// mockAuditQuerier implements auditQuerier for testing.
+type mockAuditQuerier struct {
+	insertFunc func(ctx context.Context, arg db.InsertAuditEntryParams) error
+}
+
+func (m *mockAuditQuerier) InsertAuditEntry(ctx context.Context, arg db.InsertAuditEntryParams) error {
+	if m.insertFunc != nil {
+		return m.insertFunc(ctx, arg)
+	}
+	return nil
+}
+
+func TestServiceAudit_MutationsEmitOneEntry(t *testing.T) {
+	tests := []struct {
+		name     string
+		mutate   func(ctx context.Context, svc Service) error
+		wantOp   AuditOp
+		wantSlug string
+	}{
+		{
+			name: "create with generated slug",
+			mutate: func(ctx context.Context, svc Service) error {
+				_, err := svc.Create(ctx, CreateLinkRequest{OriginalURL: "https://example.com", Principal: "alice"})
+				return err
+			},
+			wantOp:   AuditCreate,
+			wantSlug: "gen1234",
+		},
+		{
+			name: "create with custom slug",
+			mutate: func(ctx context.Context, svc Service) error {
+				_, err := svc.Create(ctx, CreateLinkRequest{OriginalURL: "https://example.com", CustomSlug: "my-link", Principal: "alice"})
+				return err
+			},
+			wantOp:   AuditCreate,
+			wantSlug: "my-link",
+		},
+		{
+			name: "delete",
+			mutate: func(ctx context.Context, svc Service) error {
+				return svc.Delete(ctx, "old-link")
+			},
+			wantOp:   AuditDelete,
+			wantSlug: "old-link",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			audit := &recordingAuditLogger{}
+			repo := &mockRepository{
+				deleteFunc: func(ctx context.Context, slug string) (Link, error) {
+					return Link{Slug: slug, Owner: "alice"}, nil
+				},
+			}
+			now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+			svc := NewService(repo, &ServiceConfig{
+				SlugGenerator: &mockSlugGenerator{slugs: []string{"gen1234"}},
+				AuditLogger:   audit,
+				Clock:         clock.NewFake(now),
+			})
+			ctx := httpx.WithRequestID(context.Background(), "req-42")
+
+			if err := tt.mutate(ctx, svc); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(audit.entries) != 1 {
+				t.Fatalf("audit entries = %d, want 1: %+v", len(audit.entries), audit.entries)
+			}
+			got := audit.entries[0]
+			if got.Op != tt.wantOp {
+				t.Errorf("Op = %q, want %q", got.Op, tt.wantOp)
+			}
+			if got.Slug != tt.wantSlug {
+				t.Errorf("Slug = %q, want %q", got.Slug, tt.wantSlug)
+			}
+			if got.Owner != "alice" {
+				t.Errorf("Owner = %q, want alice", got.Owner)
+			}
+			if got.RequestID != "req-42" {
+				t.Errorf("RequestID = %q, want req-42", got.RequestID)
+			}
+			if !got.At.Equal(now) {
+				t.Errorf("At = %v, want %v", got.At, now)
+			}
+		})
+	}
+}
+
+func TestServiceAudit_FailedMutationsEmitNothing(t *testing.T) {
+	audit := &recordingAuditLogger{}
+	repo := &mockRepository{
+		createFunc: func(ctx context.Context, link Link) (Link, error) {
+			return Link{}, errx.E("repo.Create", errx.Unavailable, errors.New("db down"))
+		},
+		deleteFunc: func(ctx context.Context, slug string) (Link, error) {
+			return Link{}, errx.E("repo.Delete", errx.NotFound, errors.New("not found"))
+		},
+	}
+	svc := NewService(repo, &ServiceConfig{AuditLogger: audit})
+	ctx := context.Background()
+
+	if _, err := svc.Create(ctx, CreateLinkRequest{OriginalURL: "https://example.com"}); err == nil {
+		t.Fatal("Create() expected error")
+	}
+	if err := svc.Delete(ctx, "missing"); err == nil {
+		t.Fatal("Delete() expected error")
+	}
+
+	if len(audit.entries) != 0 {
+		t.Errorf("audit entries = %+v, want none", audit.entries)
+	}
+}
+
+func TestServiceAudit_BatchEmitsPerCreatedRow(t *testing.T) {
+	audit := &recordingAuditLogger{}
+	svc := NewService(&mockRepository{}, &ServiceConfig{
+		AuditLogger:         audit,
+		DuplicateSlugPolicy: DuplicateSlugSkip,
+	})
+
+	_, err := svc.CreateBatch(context.Background(), []CreateLinkRequest{
+		{OriginalURL: "https://example.com/a", CustomSlug: "summer-sale"},
+		{OriginalURL: "https://example.com/b", CustomSlug: "summer-sale"},
+		{OriginalURL: "not a url"},
+	})
+	if err != nil {
+		t.Fatalf("CreateBatch() unexpected error: %v", err)
+	}
+
+	if len(audit.entries) != 1 || audit.entries[0].Slug != "summer-sale" {
+		t.Errorf("audit entries = %+v, want one for summer-sale", audit.entries)
+	}
+}
+
+func TestDBAuditLogger(t *testing.T) {
+	at := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+
+	t.Run("maps entry to row", func(t *testing.T) {
+		var got db.InsertAuditEntryParams
+		q := &mockAuditQuerier{
+			insertFunc: func(_ context.Context, arg db.InsertAuditEntryParams) error {
+				got = arg
+				return nil
+			},
+		}
+
+		NewDBAuditLogger(q, nil).Record(context.Background(), AuditEntry{
+			Op:        AuditDelete,
+			Slug:      "my-link",
+			Owner:     "alice",
+			RequestID: "req-42",
+			At:        at,
+		})
+
+		want := db.InsertAuditEntryParams{
+			Op:        "delete",
+			Slug:      "my-link",
+			Owner:     pgtype.Text{String: "alice", Valid: true},
+			RequestID: pgtype.Text{String: "req-42", Valid: true},
+			CreatedAt: pgtype.Timestamptz{Time: at, Valid: true},
+		}
+		if got != want {
+			t.Errorf("params=%+v want %+v", got, want)
+		}
+	})
+
+	t.Run("stores NULL for anonymous owner and missing request ID", func(t *testing.T) {
+		var got db.InsertAuditEntryParams
+		q := &mockAuditQuerier{
+			insertFunc: func(_ context.Context, arg db.InsertAuditEntryParams) error {
+				got = arg
+				return nil
+			},
+		}
+
+		NewDBAuditLogger(q, nil).Record(context.Background(), AuditEntry{Op: AuditCreate, Slug: "my-link", At: at})
+
+		if got.Owner.Valid || got.RequestID.Valid {
+			t.Errorf("owner=%+v request_id=%+v, want NULL", got.Owner, got.RequestID)
+		}
+	})
+
+	t.Run("swallows write failures", func(t *testing.T) {
+		q := &mockAuditQuerier{
+			insertFunc: func(_ context.Context, _ db.InsertAuditEntryParams) error {
+				return errors.New("connection reset")
+			},
+		}
+		logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+		// Must not panic or block; there is nothing to return.
+		NewDBAuditLogger(q, logger).Record(context.Background(), AuditEntry{Op: AuditCreate, Slug: "my-link", At: at})
+	})
+}
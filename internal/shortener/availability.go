@@ -0,0 +1,75 @@
+package shortener
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/sundayezeilo/urlshortener/internal/errx"
+)
+
+// SlugStatus is the outcome of checking one custom slug.
+type SlugStatus string
+
+const (
+	SlugAvailable SlugStatus = "available"
+	SlugTaken     SlugStatus = "taken"    // Used by a link or alias, live or soft-deleted
+	SlugInvalid   SlugStatus = "invalid"  // Rejected by the slug validator
+	SlugReserved  SlugStatus = "reserved" // Falls in another principal's namespace
+)
+
+// SlugAvailability is the availability of one requested custom slug.
+type SlugAvailability struct {
+	Slug    string // As Create would store it, under the caller's namespace
+	Status  SlugStatus
+	Message string // Why an invalid or reserved slug can't be used
+}
+
+// CheckSlugs reports, for each requested slug, whether Create would accept
+// it as principal's custom slug, looking up all the valid ones in a single
+// query. Results are keyed by the requested slug. Nothing is reserved: a
+// slug reported available may still be taken by the time it is created.
+// At most ServiceConfig.MaxBatchSize slugs may be checked at once.
+func (s *service) CheckSlugs(ctx context.Context, principal string, slugs []string) (map[string]SlugAvailability, error) {
+	const op = "shortener.service.CheckSlugs"
+
+	if len(slugs) == 0 {
+		return nil, errx.E(op, errx.Invalid, errors.New("slugs cannot be empty"))
+	}
+	if len(slugs) > s.maxBatchSize {
+		return nil, errx.E(op, errx.Invalid,
+			fmt.Errorf("request has %d slugs (maximum %d)", len(slugs), s.maxBatchSize))
+	}
+
+	prefix := s.slugPrefixes[principal]
+	results := make(map[string]SlugAvailability, len(slugs))
+	var lookup []string
+	for _, requested := range slugs {
+		if _, ok := results[requested]; ok {
+			continue
+		}
+
+		slug, err := s.namespacedCustomSlug(prefix, requested)
+		switch {
+		case err == nil:
+			results[requested] = SlugAvailability{Slug: slug, Status: SlugAvailable}
+			lookup = append(lookup, slug)
+		case errx.KindOf(err) == errx.Forbidden:
+			results[requested] = SlugAvailability{Slug: requested, Status: SlugReserved, Message: err.Error()}
+		default:
+			results[requested] = SlugAvailability{Slug: requested, Status: SlugInvalid, Message: err.Error()}
+		}
+	}
+
+	taken, err := s.repo.TakenSlugs(ctx, lookup)
+	if err != nil {
+		return nil, errx.E(op, errx.KindOf(err), err)
+	}
+	for requested, res := range results {
+		if res.Status == SlugAvailable && taken[res.Slug] {
+			res.Status = SlugTaken
+			results[requested] = res
+		}
+	}
+	return results, nil
+}
@@ -0,0 +1,163 @@
+package shortener
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/sundayezeilo/urlshortener/internal/errx"
+)
+
+const (
+	// DefaultMaxBatchSize bounds the number of links created by one
+	// CreateBatch call unless ServiceConfig.MaxBatchSize says otherwise.
+	DefaultMaxBatchSize = 100
+	// MaxBatchRowBytes is the request body budget per batch row: a batch
+	// body may be up to the batch size times this.
+	MaxBatchRowBytes = 16 << 10
+)
+
+// DuplicateSlugPolicy decides what CreateBatch does with a row whose custom
+// slug was already claimed by an earlier row of the same batch.
+type DuplicateSlugPolicy int
+
+const (
+	// DuplicateSlugFail fails the later row with a Conflict error.
+	DuplicateSlugFail DuplicateSlugPolicy = iota
+	// DuplicateSlugSkip leaves the later row out without an error.
+	DuplicateSlugSkip
+	// DuplicateSlugSuffix gives the later row the next free "-<n>" suffix,
+	// e.g. "promo" becomes "promo-2".
+	DuplicateSlugSuffix
+)
+
+// ParseDuplicateSlugPolicy maps a configuration name to a DuplicateSlugPolicy.
+func ParseDuplicateSlugPolicy(name string) (DuplicateSlugPolicy, error) {
+	switch name {
+	case "", "fail":
+		return DuplicateSlugFail, nil
+	case "skip":
+		return DuplicateSlugSkip, nil
+	case "suffix":
+		return DuplicateSlugSuffix, nil
+	default:
+		return 0, fmt.Errorf("unknown duplicate slug policy %q", name)
+	}
+}
+
+// BatchStatus is the outcome of one CreateBatch row.
+type BatchStatus string
+
+const (
+	BatchCreated BatchStatus = "created"
+	BatchSkipped BatchStatus = "skipped"
+	BatchFailed  BatchStatus = "failed"
+)
+
+// BatchResult reports what happened to one CreateBatch row.
+type BatchResult struct {
+	Index  int // Position of the row in the request
+	Status BatchStatus
+	Link   Link  // Set when Status is BatchCreated
+	Err    error // Set when Status is BatchFailed
+
+	// Reachable is set for created rows when a ReachabilityChecker is
+	// configured. An unreachable destination is still created.
+	Reachable *bool
+}
+
+// CreateBatch creates one link per request and reports the outcome of each
+// row in order. Rows fail independently; only an empty or oversized batch
+// fails the call as a whole.
+func (s *service) CreateBatch(ctx context.Context, reqs []CreateLinkRequest) ([]BatchResult, error) {
+	const op = "shortener.service.CreateBatch"
+
+	if len(reqs) == 0 {
+		return nil, errx.E(op, errx.Invalid, errors.New("batch cannot be empty"))
+	}
+	if len(reqs) > s.maxBatchSize {
+		return nil, errx.E(op, errx.Invalid,
+			fmt.Errorf("batch has %d links (maximum %d)", len(reqs), s.maxBatchSize))
+	}
+
+	results := make([]BatchResult, len(reqs))
+	claimed := make(map[string]int, len(reqs)) // slug key -> first row claiming it
+
+	for i, req := range reqs {
+		results[i].Index = i
+
+		req, err := req.trimmed()
+		if err != nil {
+			results[i].Status = BatchFailed
+			results[i].Err = errx.E(op, errx.Invalid, err)
+			continue
+		}
+		if req.CustomSlug != "" {
+			slug, err := s.batchCustomSlug(req, claimed)
+			if err != nil {
+				results[i].Status = BatchFailed
+				results[i].Err = errx.E(op, errx.KindOf(err), err)
+				continue
+			}
+			if slug == "" {
+				results[i].Status = BatchSkipped
+				continue
+			}
+			claimed[s.key(slug)] = i
+			req.CustomSlug = slug
+		}
+
+		link, err := s.Create(ctx, req)
+		if err != nil {
+			results[i].Status = BatchFailed
+			results[i].Err = err
+			continue
+		}
+		results[i].Status = BatchCreated
+		results[i].Link = link
+	}
+
+	s.checkReachability(ctx, results)
+
+	return results, nil
+}
+
+// batchCustomSlug returns the namespaced custom slug req should be created
+// with, applying the duplicate policy when an earlier row claimed it. An
+// empty slug with a nil error means the row is skipped.
+func (s *service) batchCustomSlug(req CreateLinkRequest, claimed map[string]int) (string, error) {
+	const op = "shortener.service.batchCustomSlug"
+
+	slug, err := s.namespacedCustomSlug(s.slugPrefixes[req.Principal], req.CustomSlug)
+	if err != nil {
+		return "", err
+	}
+
+	first, dup := claimed[s.key(slug)]
+	if !dup {
+		return slug, nil
+	}
+
+	switch s.duplicateSlugPolicy {
+	case DuplicateSlugSkip:
+		return "", nil
+	case DuplicateSlugSuffix:
+		base, next := splitSlugCounter(slug)
+		for n := next; n < next+s.maxBatchSize; n++ {
+			candidate := withSlugSuffix(base, strconv.Itoa(n), s.maxCustomSlugLength)
+			if _, taken := claimed[s.key(candidate)]; taken {
+				continue
+			}
+			if err := s.slugValidator.Validate(candidate); err != nil {
+				return "", errx.E(op, errx.Invalid, err)
+			}
+			return candidate, nil
+		}
+		return "", errx.E(op, errx.Conflict,
+			fmt.Errorf("no free suffix for slug %q in this batch", slug))
+	default:
+		return "", errx.E(op, errx.Conflict,
+			fmt.Errorf("slug %q is already used by row %d of this batch", slug, first))
+	}
+}
@@ -0,0 +1,198 @@
+package shortener
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/sundayezeilo/urlshortener/internal/errx"
+)
+
+func TestParseDuplicateSlugPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    DuplicateSlugPolicy
+		wantErr bool
+	}{
+		{"", DuplicateSlugFail, false},
+		{"fail", DuplicateSlugFail, false},
+		{"skip", DuplicateSlugSkip, false},
+		{"suffix", DuplicateSlugSuffix, false},
+		{"rename", 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseDuplicateSlugPolicy(tt.name)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseDuplicateSlugPolicy(%q) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseDuplicateSlugPolicy(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestServiceCreateBatch_DuplicateSlugPolicy(t *testing.T) {
+	// Rows 0 and 2 request the same slug; row 1 is unrelated.
+	batch := []CreateLinkRequest{
+		{OriginalURL: "https://example.com/a", CustomSlug: "summer-sale"},
+		{OriginalURL: "https://example.com/b", CustomSlug: "winter-sale"},
+		{OriginalURL: "https://example.com/c", CustomSlug: "summer-sale"},
+	}
+
+	tests := []struct {
+		name       string
+		policy     DuplicateSlugPolicy
+		wantStatus []BatchStatus
+		wantSlug   string // slug created for row 2, if any
+		wantKind   errx.Kind
+	}{
+		{
+			name:       "fail",
+			policy:     DuplicateSlugFail,
+			wantStatus: []BatchStatus{BatchCreated, BatchCreated, BatchFailed},
+			wantKind:   errx.Conflict,
+		},
+		{
+			name:       "skip",
+			policy:     DuplicateSlugSkip,
+			wantStatus: []BatchStatus{BatchCreated, BatchCreated, BatchSkipped},
+		},
+		{
+			name:       "suffix",
+			policy:     DuplicateSlugSuffix,
+			wantStatus: []BatchStatus{BatchCreated, BatchCreated, BatchCreated},
+			wantSlug:   "summer-sale-2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var created []string
+			repo := &mockRepository{
+				createFunc: func(ctx context.Context, link Link) (Link, error) {
+					created = append(created, link.Slug)
+					return link, nil
+				},
+			}
+			svc := NewService(repo, &ServiceConfig{DuplicateSlugPolicy: tt.policy})
+
+			results, err := svc.CreateBatch(context.Background(), batch)
+			if err != nil {
+				t.Fatalf("CreateBatch() unexpected error: %v", err)
+			}
+			if len(results) != len(batch) {
+				t.Fatalf("len(results) = %d, want %d", len(results), len(batch))
+			}
+
+			for i, res := range results {
+				if res.Index != i {
+					t.Errorf("results[%d].Index = %d", i, res.Index)
+				}
+				if res.Status != tt.wantStatus[i] {
+					t.Errorf("results[%d].Status = %q, want %q (err: %v)", i, res.Status, tt.wantStatus[i], res.Err)
+				}
+			}
+
+			last := results[2]
+			if tt.wantSlug != "" && last.Link.Slug != tt.wantSlug {
+				t.Errorf("row 2 slug = %q, want %q", last.Link.Slug, tt.wantSlug)
+			}
+			if errx.KindOf(last.Err) != tt.wantKind {
+				t.Errorf("row 2 KindOf(err) = %v, want %v", errx.KindOf(last.Err), tt.wantKind)
+			}
+
+			wantCreates := 0
+			for _, st := range tt.wantStatus {
+				if st == BatchCreated {
+					wantCreates++
+				}
+			}
+			if len(created) != wantCreates {
+				t.Errorf("repository creates = %v, want %d", created, wantCreates)
+			}
+		})
+	}
+}
+
+func TestServiceCreateBatch_SuffixSkipsClaimedSlugs(t *testing.T) {
+	svc := NewService(&mockRepository{}, &ServiceConfig{DuplicateSlugPolicy: DuplicateSlugSuffix})
+
+	results, err := svc.CreateBatch(context.Background(), []CreateLinkRequest{
+		{OriginalURL: "https://example.com/a", CustomSlug: "summer-sale"},
+		{OriginalURL: "https://example.com/b", CustomSlug: "summer-sale-2"},
+		{OriginalURL: "https://example.com/c", CustomSlug: "summer-sale"},
+		{OriginalURL: "https://example.com/d", CustomSlug: "summer-sale"},
+	})
+	if err != nil {
+		t.Fatalf("CreateBatch() unexpected error: %v", err)
+	}
+
+	want := []string{"summer-sale", "summer-sale-2", "summer-sale-3", "summer-sale-4"}
+	for i, res := range results {
+		if res.Status != BatchCreated || res.Link.Slug != want[i] {
+			t.Errorf("results[%d] = %q %q, want created %q (err: %v)", i, res.Status, res.Link.Slug, want[i], res.Err)
+		}
+	}
+}
+
+func TestServiceCreateBatch_RowsFailIndependently(t *testing.T) {
+	repo := &mockRepository{
+		createFunc: func(ctx context.Context, link Link) (Link, error) {
+			if link.Slug == "taken-slug" {
+				return Link{}, errx.E("repo.Create", errx.Conflict, errors.New("duplicate slug"))
+			}
+			return link, nil
+		},
+	}
+	svc := NewService(repo, nil)
+
+	results, err := svc.CreateBatch(context.Background(), []CreateLinkRequest{
+		{OriginalURL: "not a url"},
+		{OriginalURL: "https://example.com/b", CustomSlug: "taken-slug"},
+		{OriginalURL: "https://example.com/c"},
+	})
+	if err != nil {
+		t.Fatalf("CreateBatch() unexpected error: %v", err)
+	}
+
+	wantKinds := []errx.Kind{errx.Invalid, errx.Conflict, errx.Unknown}
+	for i, res := range results {
+		if errx.KindOf(res.Err) != wantKinds[i] {
+			t.Errorf("results[%d] KindOf(err) = %v, want %v", i, errx.KindOf(res.Err), wantKinds[i])
+		}
+	}
+	if results[2].Status != BatchCreated {
+		t.Errorf("results[2].Status = %q, want %q", results[2].Status, BatchCreated)
+	}
+}
+
+func TestServiceCreateBatch_ConfiguredMaxBatchSize(t *testing.T) {
+	svc := NewService(&mockRepository{}, &ServiceConfig{MaxBatchSize: 3})
+	ctx := context.Background()
+
+	if _, err := svc.CreateBatch(ctx, make([]CreateLinkRequest, 3)); err != nil {
+		t.Fatalf("batch of 3: unexpected error: %v", err)
+	}
+
+	_, err := svc.CreateBatch(ctx, make([]CreateLinkRequest, 4))
+	if errx.KindOf(err) != errx.Invalid {
+		t.Fatalf("batch of 4: KindOf(err) = %v, want %v", errx.KindOf(err), errx.Invalid)
+	}
+	if !strings.Contains(err.Error(), "maximum 3") {
+		t.Errorf("error = %q, want it to name the maximum", err)
+	}
+}
+
+func TestServiceCreateBatch_RejectsBatchSize(t *testing.T) {
+	svc := NewService(&mockRepository{}, nil)
+
+	for _, n := range []int{0, DefaultMaxBatchSize + 1} {
+		_, err := svc.CreateBatch(context.Background(), make([]CreateLinkRequest, n))
+		if errx.KindOf(err) != errx.Invalid {
+			t.Errorf("batch of %d: KindOf(err) = %v, want %v", n, errx.KindOf(err), errx.Invalid)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package shortener
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// beaconImage is a 1x1 transparent image served in place of a redirect.
+type beaconImage struct {
+	contentType string
+	body        []byte
+}
+
+// beaconImages maps resolve path suffixes to the pixel served for them.
+var beaconImages = map[string]beaconImage{
+	".gif": {
+		contentType: "image/gif",
+		body: []byte{
+			0x47, 0x49, 0x46, 0x38, 0x39, 0x61, 0x01, 0x00, 0x01, 0x00, 0x80, 0x00,
+			0x00, 0x00, 0x00, 0x00, 0xff, 0xff, 0xff, 0x21, 0xf9, 0x04, 0x01, 0x00,
+			0x00, 0x00, 0x00, 0x2c, 0x00, 0x00, 0x00, 0x00, 0x01, 0x00, 0x01, 0x00,
+			0x00, 0x02, 0x02, 0x44, 0x01, 0x00, 0x3b,
+		},
+	},
+	".png": {
+		contentType: "image/png",
+		body: []byte{
+			0x89, 0x50, 0x4e, 0x47, 0x0d, 0x0a, 0x1a, 0x0a, 0x00, 0x00, 0x00, 0x0d,
+			0x49, 0x48, 0x44, 0x52, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x01,
+			0x08, 0x06, 0x00, 0x00, 0x00, 0x1f, 0x15, 0xc4, 0x89, 0x00, 0x00, 0x00,
+			0x0b, 0x49, 0x44, 0x41, 0x54, 0x78, 0xda, 0x63, 0x60, 0x00, 0x02, 0x00,
+			0x00, 0x05, 0x00, 0x01, 0xe9, 0xfa, 0xdc, 0xd8, 0x00, 0x00, 0x00, 0x00,
+			0x49, 0x45, 0x4e, 0x44, 0xae, 0x42, 0x60, 0x82,
+		},
+	},
+}
+
+// splitBeaconSuffix strips a beacon suffix such as ".gif" from a resolve
+// path segment. It reports false when the segment has no known suffix.
+func splitBeaconSuffix(segment string) (string, beaconImage, bool) {
+	dot := strings.LastIndexByte(segment, '.')
+	if dot < 0 {
+		return segment, beaconImage{}, false
+	}
+	img, ok := beaconImages[strings.ToLower(segment[dot:])]
+	if !ok {
+		return segment, beaconImage{}, false
+	}
+	return segment[:dot], img, true
+}
+
+// writeBeacon serves img uncached, so every load reaches the server and
+// counts as a click.
+func writeBeacon(w http.ResponseWriter, img beaconImage) {
+	w.Header().Set("Content-Type", img.contentType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(img.body)))
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(img.body)
+}
@@ -0,0 +1,233 @@
+package shortener
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/sundayezeilo/urlshortener/internal/clock"
+	db "github.com/sundayezeilo/urlshortener/internal/db/sqlc"
+)
+
+// DefaultBreakerCooldown is how long an open circuit fast-fails before a
+// probe query is let through.
+const DefaultBreakerCooldown = 30 * time.Second
+
+// ErrCircuitOpen is returned without touching the database while the circuit
+// breaker is open. The repository reports it as errx.Unavailable.
+var ErrCircuitOpen = errors.New("database circuit breaker open")
+
+type breakerState int
+
+const (
+	breakerClosed breakerState = iota
+	breakerOpen
+	breakerHalfOpen
+)
+
+// circuitBreaker opens after threshold consecutive database failures and
+// fast-fails until cooldown has passed. It then half-opens, letting a single
+// probe through: success closes the circuit, failure reopens it.
+type circuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+	clock     clock.Clock
+
+	mu       sync.Mutex
+	state    breakerState
+	failures int
+	openedAt time.Time
+}
+
+func newCircuitBreaker(threshold int, cooldown time.Duration, clk clock.Clock) *circuitBreaker {
+	if cooldown <= 0 {
+		cooldown = DefaultBreakerCooldown
+	}
+	return &circuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		clock:     clk,
+	}
+}
+
+// allow reports whether a call may proceed, moving an open circuit to
+// half-open once the cooldown has elapsed.
+func (b *circuitBreaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case breakerOpen:
+		if b.clock.Now().Sub(b.openedAt) < b.cooldown {
+			return false
+		}
+		b.state = breakerHalfOpen
+		return true
+	case breakerHalfOpen:
+		return false // a probe is already in flight
+	default:
+		return true
+	}
+}
+
+// record updates the breaker with the outcome of an allowed call.
+func (b *circuitBreaker) record(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !isDatabaseFailure(err) {
+		b.state = breakerClosed
+		b.failures = 0
+		return
+	}
+
+	b.failures++
+	if b.state == breakerHalfOpen || b.failures >= b.threshold {
+		b.state = breakerOpen
+		b.openedAt = b.clock.Now()
+	}
+}
+
+// isDatabaseFailure reports whether err means the database is unhealthy.
+// Missing rows, constraint violations and caller cancellations are answers
+// from a working database, so they don't count against it.
+func isDatabaseFailure(err error) bool {
+	switch {
+	case err == nil:
+		return false
+	case errors.Is(err, pgx.ErrNoRows), isSlugUniqueViolation(err):
+		return false
+	case errors.Is(err, context.Canceled):
+		return false
+	default:
+		return true
+	}
+}
+
+// breakerCall runs fn through b, failing fast with ErrCircuitOpen when the
+// circuit is open.
+func breakerCall[T any](b *circuitBreaker, fn func() (T, error)) (T, error) {
+	if !b.allow() {
+		var zero T
+		return zero, ErrCircuitOpen
+	}
+	v, err := fn()
+	b.record(err)
+	return v, err
+}
+
+// breakerQuerier guards every query of the wrapped querier with a breaker.
+type breakerQuerier struct {
+	q querier
+	b *circuitBreaker
+}
+
+var _ querier = (*breakerQuerier)(nil)
+
+func (bq *breakerQuerier) CreateLink(ctx context.Context, arg db.CreateLinkParams) (db.Link, error) {
+	return breakerCall(bq.b, func() (db.Link, error) { return bq.q.CreateLink(ctx, arg) })
+}
+
+func (bq *breakerQuerier) CreateOrUpdateLink(ctx context.Context, arg db.CreateOrUpdateLinkParams) (db.CreateOrUpdateLinkRow, error) {
+	return breakerCall(bq.b, func() (db.CreateOrUpdateLinkRow, error) { return bq.q.CreateOrUpdateLink(ctx, arg) })
+}
+
+func (bq *breakerQuerier) GetLinkBySLug(ctx context.Context, slug string) (db.Link, error) {
+	return breakerCall(bq.b, func() (db.Link, error) { return bq.q.GetLinkBySLug(ctx, slug) })
+}
+
+func (bq *breakerQuerier) GetLinkByID(ctx context.Context, id uuid.UUID) (db.Link, error) {
+	return breakerCall(bq.b, func() (db.Link, error) { return bq.q.GetLinkByID(ctx, id) })
+}
+
+func (bq *breakerQuerier) ResolveAndTrackLink(ctx context.Context, arg db.ResolveAndTrackLinkParams) (db.Link, error) {
+	return breakerCall(bq.b, func() (db.Link, error) { return bq.q.ResolveAndTrackLink(ctx, arg) })
+}
+
+func (bq *breakerQuerier) DeleteLink(ctx context.Context, slug string) (db.Link, error) {
+	return breakerCall(bq.b, func() (db.Link, error) { return bq.q.DeleteLink(ctx, slug) })
+}
+
+func (bq *breakerQuerier) SetLinkPaused(ctx context.Context, arg db.SetLinkPausedParams) (db.Link, error) {
+	return breakerCall(bq.b, func() (db.Link, error) { return bq.q.SetLinkPaused(ctx, arg) })
+}
+
+func (bq *breakerQuerier) CountLinks(ctx context.Context) (int64, error) {
+	return breakerCall(bq.b, func() (int64, error) { return bq.q.CountLinks(ctx) })
+}
+
+func (bq *breakerQuerier) CountLinksByOwner(ctx context.Context, owner pgtype.Text) (int64, error) {
+	return breakerCall(bq.b, func() (int64, error) { return bq.q.CountLinksByOwner(ctx, owner) })
+}
+
+func (bq *breakerQuerier) CountLinksBySource(ctx context.Context) ([]db.CountLinksBySourceRow, error) {
+	return breakerCall(bq.b, func() ([]db.CountLinksBySourceRow, error) { return bq.q.CountLinksBySource(ctx) })
+}
+
+func (bq *breakerQuerier) CountLinksFiltered(ctx context.Context, arg db.CountLinksFilteredParams) (int64, error) {
+	return breakerCall(bq.b, func() (int64, error) { return bq.q.CountLinksFiltered(ctx, arg) })
+}
+
+func (bq *breakerQuerier) ListLinks(ctx context.Context, arg db.ListLinksParams) ([]db.Link, error) {
+	return breakerCall(bq.b, func() ([]db.Link, error) { return bq.q.ListLinks(ctx, arg) })
+}
+
+func (bq *breakerQuerier) GetLinksByURL(ctx context.Context, originalUrl string) ([]db.Link, error) {
+	return breakerCall(bq.b, func() ([]db.Link, error) { return bq.q.GetLinksByURL(ctx, originalUrl) })
+}
+
+func (bq *breakerQuerier) GetTakenSlugs(ctx context.Context, slugKeys []string) ([]string, error) {
+	return breakerCall(bq.b, func() ([]string, error) { return bq.q.GetTakenSlugs(ctx, slugKeys) })
+}
+
+func (bq *breakerQuerier) PurgeExpiredLinks(ctx context.Context, arg db.PurgeExpiredLinksParams) (int64, error) {
+	return breakerCall(bq.b, func() (int64, error) { return bq.q.PurgeExpiredLinks(ctx, arg) })
+}
+
+func (bq *breakerQuerier) PurgeDeletedLinks(ctx context.Context, arg db.PurgeDeletedLinksParams) (int64, error) {
+	return breakerCall(bq.b, func() (int64, error) { return bq.q.PurgeDeletedLinks(ctx, arg) })
+}
+
+func (bq *breakerQuerier) CountClicksByCountry(ctx context.Context) ([]db.CountClicksByCountryRow, error) {
+	return breakerCall(bq.b, func() ([]db.CountClicksByCountryRow, error) { return bq.q.CountClicksByCountry(ctx) })
+}
+
+func (bq *breakerQuerier) RecordClick(ctx context.Context, arg db.RecordClickParams) error {
+	_, err := breakerCall(bq.b, func() (struct{}, error) { return struct{}{}, bq.q.RecordClick(ctx, arg) })
+	return err
+}
+
+func (bq *breakerQuerier) InsertLinkCreator(ctx context.Context, arg db.InsertLinkCreatorParams) error {
+	_, err := breakerCall(bq.b, func() (struct{}, error) { return struct{}{}, bq.q.InsertLinkCreator(ctx, arg) })
+	return err
+}
+
+func (bq *breakerQuerier) GetLinkCreator(ctx context.Context, linkID uuid.UUID) (db.LinkCreator, error) {
+	return breakerCall(bq.b, func() (db.LinkCreator, error) { return bq.q.GetLinkCreator(ctx, linkID) })
+}
+
+func (bq *breakerQuerier) InsertLinkAlias(ctx context.Context, arg db.InsertLinkAliasParams) error {
+	_, err := breakerCall(bq.b, func() (struct{}, error) { return struct{}{}, bq.q.InsertLinkAlias(ctx, arg) })
+	return err
+}
+
+func (bq *breakerQuerier) ListLinkAliases(ctx context.Context, linkID uuid.UUID) ([]string, error) {
+	return breakerCall(bq.b, func() ([]string, error) { return bq.q.ListLinkAliases(ctx, linkID) })
+}
+
+func (bq *breakerQuerier) GetSlugByAlias(ctx context.Context, aliasKey string) (string, error) {
+	return breakerCall(bq.b, func() (string, error) { return bq.q.GetSlugByAlias(ctx, aliasKey) })
+}
+
+func (bq *breakerQuerier) GetClickTimeSeries(ctx context.Context, arg db.GetClickTimeSeriesParams) ([]db.GetClickTimeSeriesRow, error) {
+	return breakerCall(bq.b, func() ([]db.GetClickTimeSeriesRow, error) { return bq.q.GetClickTimeSeries(ctx, arg) })
+}
+
+func (bq *breakerQuerier) TrackUniqueVisitor(ctx context.Context, arg db.TrackUniqueVisitorParams) (int64, error) {
+	return breakerCall(bq.b, func() (int64, error) { return bq.q.TrackUniqueVisitor(ctx, arg) })
+}
@@ -0,0 +1,165 @@
+package shortener
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/sundayezeilo/urlshortener/internal/clock"
+	"github.com/sundayezeilo/urlshortener/internal/errx"
+)
+
+// newBreakerRepo returns a repository whose breaker uses a controllable clock
+// and a query func that reports whether the database was reached.
+func newBreakerRepo(threshold int, cooldown time.Duration, countErr *error) (Repository, *circuitBreaker, *int, *clock.Fake) {
+	calls := 0
+	clk := clock.NewFake(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	mock := &mockQueries{
+		countLinksFunc: func(ctx context.Context) (int64, error) {
+			calls++
+			return 42, *countErr
+		},
+	}
+	r := NewRepository(mock, &RepositoryConfig{
+		BreakerThreshold: threshold,
+		BreakerCooldown:  cooldown,
+		Clock:            clk,
+	}).(*repo)
+
+	b := r.q.(*breakerQuerier).b
+	return r, b, &calls, clk
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	dbDown := errors.New("connection refused")
+
+	t.Run("opens after consecutive failures and fails fast", func(t *testing.T) {
+		err := dbDown
+		r, _, calls, _ := newBreakerRepo(3, time.Minute, &err)
+
+		for i := range 3 {
+			if _, err := r.Count(context.Background()); errx.KindOf(err) != errx.Unavailable {
+				t.Fatalf("call %d: KindOf(err)=%v want %v", i, errx.KindOf(err), errx.Unavailable)
+			}
+		}
+		if *calls != 3 {
+			t.Fatalf("database calls=%d want 3", *calls)
+		}
+
+		_, err = r.Count(context.Background())
+		if !errors.Is(err, ErrCircuitOpen) {
+			t.Errorf("err=%v want ErrCircuitOpen", err)
+		}
+		if errx.KindOf(err) != errx.Unavailable {
+			t.Errorf("KindOf(err)=%v want %v", errx.KindOf(err), errx.Unavailable)
+		}
+		if *calls != 3 {
+			t.Errorf("database calls=%d want 3 while open", *calls)
+		}
+	})
+
+	t.Run("successful probe closes the circuit", func(t *testing.T) {
+		err := dbDown
+		r, _, calls, now := newBreakerRepo(2, time.Minute, &err)
+
+		r.Count(context.Background())
+		r.Count(context.Background())
+
+		now.Advance(time.Minute)
+		err = nil
+
+		n, err2 := r.Count(context.Background())
+		if err2 != nil || n != 42 {
+			t.Fatalf("probe Count()=(%d, %v) want (42, nil)", n, err2)
+		}
+		if _, err2 := r.Count(context.Background()); err2 != nil {
+			t.Errorf("Count() after recovery err=%v want nil", err2)
+		}
+		if *calls != 4 {
+			t.Errorf("database calls=%d want 4", *calls)
+		}
+	})
+
+	t.Run("failed probe reopens the circuit", func(t *testing.T) {
+		err := dbDown
+		r, _, calls, now := newBreakerRepo(2, time.Minute, &err)
+
+		r.Count(context.Background())
+		r.Count(context.Background())
+
+		now.Advance(time.Minute)
+		r.Count(context.Background()) // probe fails
+
+		if _, err := r.Count(context.Background()); !errors.Is(err, ErrCircuitOpen) {
+			t.Errorf("err=%v want ErrCircuitOpen after failed probe", err)
+		}
+		if *calls != 3 {
+			t.Errorf("database calls=%d want 3", *calls)
+		}
+	})
+
+	t.Run("only one probe at a time while half-open", func(t *testing.T) {
+		err := dbDown
+		_, b, _, now := newBreakerRepo(1, time.Minute, &err)
+
+		b.record(dbDown)
+		now.Advance(time.Minute)
+
+		if !b.allow() {
+			t.Fatal("first call after cooldown should be allowed as a probe")
+		}
+		if b.allow() {
+			t.Error("second call should fail fast while the probe is in flight")
+		}
+	})
+
+	t.Run("success resets the failure count", func(t *testing.T) {
+		err := dbDown
+		r, _, calls, _ := newBreakerRepo(2, time.Minute, &err)
+
+		r.Count(context.Background())
+		err = nil
+		r.Count(context.Background())
+		err = dbDown
+		r.Count(context.Background())
+
+		if _, err := r.Count(context.Background()); errors.Is(err, ErrCircuitOpen) {
+			t.Error("circuit opened without consecutive failures")
+		}
+		if *calls != 4 {
+			t.Errorf("database calls=%d want 4", *calls)
+		}
+	})
+}
+
+func TestIsDatabaseFailure(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"no rows", pgx.ErrNoRows, false},
+		{"caller canceled", context.Canceled, false},
+		{"connection error", errors.New("connection refused"), true},
+		{"deadline exceeded", context.DeadlineExceeded, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isDatabaseFailure(tt.err); got != tt.want {
+				t.Errorf("isDatabaseFailure(%v)=%v want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewRepository_BreakerDisabledByDefault(t *testing.T) {
+	r := NewRepository(&mockQueries{}, nil).(*repo)
+	if _, ok := r.q.(*breakerQuerier); ok {
+		t.Error("breaker should be disabled without a threshold")
+	}
+}
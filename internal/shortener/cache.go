@@ -0,0 +1,122 @@
+package shortener
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/sundayezeilo/urlshortener/internal/clock"
+)
+
+// CacheStats is a snapshot of a MemoryCache's counters, for tuning its size
+// and TTL.
+type CacheStats struct {
+	Hits      uint64 `json:"hits"`
+	Misses    uint64 `json:"misses"`    // Includes lookups of expired entries
+	Evictions uint64 `json:"evictions"` // Entries dropped to make room
+	Size      int    `json:"size"`
+}
+
+// MemoryCache is a size-bounded LRU of slug to destination URL whose
+// entries expire after a TTL. It is safe for concurrent use and implements
+// CacheInvalidator, so the service can evict slugs it deletes, and
+// SlugCache, so it can serve as ServiceConfig.NotFoundCache.
+type MemoryCache struct {
+	capacity int
+	ttl      time.Duration
+	clock    clock.Clock
+
+	mu      sync.Mutex
+	order   *list.List // Front is most recently used
+	entries map[string]*list.Element
+	stats   CacheStats
+}
+
+type cacheEntry struct {
+	slug      string
+	url       string
+	expiresAt time.Time
+}
+
+// NewMemoryCache returns a cache holding at most capacity entries for ttl
+// each. clk may be nil to use the wall clock.
+func NewMemoryCache(capacity int, ttl time.Duration, clk clock.Clock) *MemoryCache {
+	if clk == nil {
+		clk = clock.Real
+	}
+	return &MemoryCache{
+		capacity: max(capacity, 1),
+		ttl:      ttl,
+		clock:    clk,
+		order:    list.New(),
+		entries:  make(map[string]*list.Element),
+	}
+}
+
+// Get returns the cached URL for slug.
+func (c *MemoryCache) Get(slug string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[slug]
+	if !ok {
+		c.stats.Misses++
+		return "", false
+	}
+	e := el.Value.(*cacheEntry)
+	if !c.clock.Now().Before(e.expiresAt) {
+		c.remove(el)
+		c.stats.Misses++
+		return "", false
+	}
+	c.order.MoveToFront(el)
+	c.stats.Hits++
+	return e.url, true
+}
+
+// Set caches url for slug, evicting the least recently used entry when
+// the cache is full.
+func (c *MemoryCache) Set(slug, url string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expiresAt := c.clock.Now().Add(c.ttl)
+	if el, ok := c.entries[slug]; ok {
+		e := el.Value.(*cacheEntry)
+		e.url, e.expiresAt = url, expiresAt
+		c.order.MoveToFront(el)
+		return
+	}
+
+	if c.order.Len() >= c.capacity {
+		c.remove(c.order.Back())
+		c.stats.Evictions++
+	}
+	c.entries[slug] = c.order.PushFront(&cacheEntry{slug: slug, url: url, expiresAt: expiresAt})
+}
+
+// Invalidate drops slug from the cache.
+func (c *MemoryCache) Invalidate(_ context.Context, slug string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.entries[slug]; ok {
+		c.remove(el)
+	}
+}
+
+// Stats returns the current counters.
+func (c *MemoryCache) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	s := c.stats
+	s.Size = c.order.Len()
+	return s
+}
+
+func (c *MemoryCache) remove(el *list.Element) {
+	c.order.Remove(el)
+	delete(c.entries, el.Value.(*cacheEntry).slug)
+}
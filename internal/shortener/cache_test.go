@@ -0,0 +1,173 @@
+package shortener
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sundayezeilo/urlshortener/internal/clock"
+	"github.com/sundayezeilo/urlshortener/internal/errx"
+)
+
+func TestMemoryCache_Stats(t *testing.T) {
+	clk := clock.NewFake(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	c := NewMemoryCache(2, time.Minute, clk)
+
+	c.Get("a") // miss
+	c.Set("a", "https://a.example")
+	c.Get("a") // hit
+	c.Set("b", "https://b.example")
+	c.Get("a")                      // hit; b is now least recently used
+	c.Set("c", "https://c.example") // evicts b
+	c.Get("b")                      // miss
+	clk.Advance(time.Minute)
+	c.Get("c") // miss: expired
+
+	want := CacheStats{Hits: 2, Misses: 3, Evictions: 1, Size: 1}
+	if got := c.Stats(); got != want {
+		t.Errorf("Stats() = %+v, want %+v", got, want)
+	}
+}
+
+func TestMemoryCache_GetSet(t *testing.T) {
+	clk := clock.NewFake(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	c := NewMemoryCache(10, time.Minute, clk)
+
+	c.Set("abc1234", "https://example.com/old")
+	c.Set("abc1234", "https://example.com/new")
+	if got, ok := c.Get("abc1234"); !ok || got != "https://example.com/new" {
+		t.Errorf("Get() = %q, %v; want the latest URL", got, ok)
+	}
+
+	clk.Advance(59 * time.Second)
+	if _, ok := c.Get("abc1234"); !ok {
+		t.Error("entry expired before its TTL")
+	}
+
+	c.Invalidate(context.Background(), "abc1234")
+	if _, ok := c.Get("abc1234"); ok {
+		t.Error("entry still cached after Invalidate")
+	}
+	if got := c.Stats(); got.Size != 0 || got.Evictions != 0 {
+		t.Errorf("Stats() = %+v, want empty with no evictions", got)
+	}
+}
+
+func TestMemoryCache_Concurrent(t *testing.T) {
+	c := NewMemoryCache(8, time.Minute, nil)
+
+	var wg sync.WaitGroup
+	for g := range 8 {
+		wg.Go(func() {
+			for i := range 100 {
+				slug := fmt.Sprintf("s%d", (g+i)%16)
+				if _, ok := c.Get(slug); !ok {
+					c.Set(slug, "https://example.com/"+slug)
+				}
+			}
+		})
+	}
+	wg.Wait()
+
+	got := c.Stats()
+	if got.Hits+got.Misses != 800 {
+		t.Errorf("hits+misses = %d, want 800", got.Hits+got.Misses)
+	}
+	if got.Size > 8 {
+		t.Errorf("Size = %d, want at most 8", got.Size)
+	}
+}
+
+func TestServiceResolve_NotFoundCache(t *testing.T) {
+	newService := func(clk *clock.Fake, repo *mockRepository) Service {
+		return NewService(repo, &ServiceConfig{
+			NotFoundCache: NewMemoryCache(100, 5*time.Second, clk),
+		})
+	}
+	// countingRepo counts lookups and resolves only the slugs in links.
+	countingRepo := func(lookups *int, links map[string]Link) *mockRepository {
+		return &mockRepository{
+			resolveAndTrackFunc: func(_ context.Context, slug string) (Link, error) {
+				*lookups++
+				if link, ok := links[slug]; ok {
+					return link, nil
+				}
+				return Link{}, errx.E("repo.ResolveAndTrack", errx.NotFound, errors.New("not found"))
+			},
+			slugForAliasFunc: func(_ context.Context, alias string) (string, error) {
+				return "", errx.E("repo.SlugForAlias", errx.NotFound, errors.New("not found"))
+			},
+		}
+	}
+	ctx := context.Background()
+
+	t.Run("repeated misses within the TTL skip the repository", func(t *testing.T) {
+		clk := clock.NewFake(time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC))
+		var lookups int
+		svc := newService(clk, countingRepo(&lookups, nil))
+
+		for range 3 {
+			if _, err := svc.Resolve(ctx, "missing1"); errx.KindOf(err) != errx.NotFound {
+				t.Fatalf("Resolve() error kind = %v, want %v", errx.KindOf(err), errx.NotFound)
+			}
+		}
+		if lookups != 1 {
+			t.Errorf("repository lookups = %d, want 1", lookups)
+		}
+
+		clk.Advance(5 * time.Second)
+		if _, err := svc.Resolve(ctx, "missing1"); errx.KindOf(err) != errx.NotFound {
+			t.Fatalf("Resolve() after TTL error kind = %v, want %v", errx.KindOf(err), errx.NotFound)
+		}
+		if lookups != 2 {
+			t.Errorf("repository lookups after TTL = %d, want 2", lookups)
+		}
+	})
+
+	t.Run("creating the slug clears the cached miss", func(t *testing.T) {
+		clk := clock.NewFake(time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC))
+		var lookups int
+		links := map[string]Link{}
+		repo := countingRepo(&lookups, links)
+		repo.createFunc = func(_ context.Context, link Link) (Link, error) {
+			links[link.Slug] = link
+			return link, nil
+		}
+		svc := newService(clk, repo)
+
+		if _, err := svc.Resolve(ctx, "new-link"); errx.KindOf(err) != errx.NotFound {
+			t.Fatalf("Resolve() before create error kind = %v, want %v", errx.KindOf(err), errx.NotFound)
+		}
+		if _, err := svc.Create(ctx, CreateLinkRequest{OriginalURL: "https://example.com", CustomSlug: "new-link"}); err != nil {
+			t.Fatalf("Create() unexpected error: %v", err)
+		}
+
+		res, err := svc.Resolve(ctx, "new-link")
+		if err != nil {
+			t.Fatalf("Resolve() after create unexpected error: %v", err)
+		}
+		if res.URL != "https://example.com" {
+			t.Errorf("Resolve() URL = %q, want https://example.com", res.URL)
+		}
+	})
+
+	t.Run("errors other than not found are not cached", func(t *testing.T) {
+		clk := clock.NewFake(time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC))
+		var lookups int
+		svc := newService(clk, &mockRepository{
+			resolveAndTrackFunc: func(_ context.Context, slug string) (Link, error) {
+				lookups++
+				return Link{}, errx.E("repo.ResolveAndTrack", errx.Unavailable, errors.New("db down"))
+			},
+		})
+
+		svc.Resolve(ctx, "abc1234")
+		svc.Resolve(ctx, "abc1234")
+		if lookups != 2 {
+			t.Errorf("repository lookups = %d, want 2", lookups)
+		}
+	})
+}
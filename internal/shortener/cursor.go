@@ -0,0 +1,55 @@
+package shortener
+
+import (
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LinkCursor marks a position in the newest-first link listing.
+// Listing resumes with links strictly older than (CreatedAt, ID).
+type LinkCursor struct {
+	CreatedAt time.Time
+	ID        uuid.UUID
+}
+
+var errInvalidCursor = errors.New("invalid cursor")
+
+// cursorAfter returns the cursor that resumes listing after link.
+func cursorAfter(link Link) LinkCursor {
+	return LinkCursor{CreatedAt: link.CreatedAt, ID: link.ID}
+}
+
+// Encode returns the opaque string form handed to clients.
+// Timestamps are kept at microsecond precision to match Postgres.
+func (c LinkCursor) Encode() string {
+	raw := strconv.FormatInt(c.CreatedAt.UnixMicro(), 10) + "_" + c.ID.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeCursor parses a cursor produced by Encode.
+func decodeCursor(s string) (LinkCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return LinkCursor{}, errInvalidCursor
+	}
+
+	micros, id, ok := strings.Cut(string(raw), "_")
+	if !ok {
+		return LinkCursor{}, errInvalidCursor
+	}
+	usec, err := strconv.ParseInt(micros, 10, 64)
+	if err != nil {
+		return LinkCursor{}, errInvalidCursor
+	}
+	parsedID, err := uuid.Parse(id)
+	if err != nil {
+		return LinkCursor{}, errInvalidCursor
+	}
+
+	return LinkCursor{CreatedAt: time.UnixMicro(usec).UTC(), ID: parsedID}, nil
+}
@@ -0,0 +1,35 @@
+package shortener
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestLinkCursor_RoundTrip(t *testing.T) {
+	want := LinkCursor{
+		CreatedAt: time.Date(2025, 3, 4, 5, 6, 7, 123456000, time.UTC),
+		ID:        uuid.New(),
+	}
+
+	got, err := decodeCursor(want.Encode())
+	if err != nil {
+		t.Fatalf("decodeCursor() unexpected error: %v", err)
+	}
+	if !got.CreatedAt.Equal(want.CreatedAt) || got.ID != want.ID {
+		t.Errorf("decodeCursor() = %+v, want %+v", got, want)
+	}
+}
+
+func TestDecodeCursor_Invalid(t *testing.T) {
+	for _, s := range []string{
+		"!!!",
+		"bm8tc2VwYXJhdG9y",    // "no-separator"
+		"YWJjX25vdC1hLXV1aWQ", // "abc_not-a-uuid"
+	} {
+		if _, err := decodeCursor(s); err == nil {
+			t.Errorf("decodeCursor(%q) expected error, got nil", s)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package shortener
+
+import (
+	"net/url"
+	"strings"
+)
+
+// domainList matches destination hosts against configured domains. An entry
+// "example.com" matches that host only; "*.example.com" matches any of its
+// subdomains but not example.com itself.
+type domainList struct {
+	exact    map[string]bool
+	suffixes []string // ".example.com" for each wildcard entry
+}
+
+// newDomainList builds a domainList, ignoring blank entries. Matching is
+// case-insensitive and ignores a trailing dot.
+func newDomainList(entries []string) domainList {
+	l := domainList{exact: make(map[string]bool)}
+	for _, e := range entries {
+		e = normalizeDomain(e)
+		if suffix, ok := strings.CutPrefix(e, "*."); ok {
+			if suffix != "" {
+				l.suffixes = append(l.suffixes, "."+suffix)
+			}
+			continue
+		}
+		if e != "" {
+			l.exact[e] = true
+		}
+	}
+	return l
+}
+
+// empty reports whether the list has no entries.
+func (l domainList) empty() bool {
+	return len(l.exact) == 0 && len(l.suffixes) == 0
+}
+
+// matches reports whether host is on the list.
+func (l domainList) matches(host string) bool {
+	host = normalizeDomain(host)
+	if l.exact[host] {
+		return true
+	}
+	for _, suffix := range l.suffixes {
+		if strings.HasSuffix(host, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizeDomain(d string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(d)), ".")
+}
+
+// destinationHost returns the host name of an already validated URL.
+func destinationHost(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return u.Hostname()
+}
@@ -0,0 +1,31 @@
+package shortener
+
+import "testing"
+
+func TestDomainList(t *testing.T) {
+	l := newDomainList([]string{"Evil.com", "*.tracker.io", "trailing.org.", " ", "*."})
+
+	tests := []struct {
+		host string
+		want bool
+	}{
+		{"evil.com", true},
+		{"EVIL.COM", true},
+		{"evil.com.", true},
+		{"www.evil.com", false},
+		{"notevil.com", false},
+		{"ads.tracker.io", true},
+		{"a.b.tracker.io", true},
+		{"tracker.io", false},
+		{"eviltracker.io", false},
+		{"trailing.org", true},
+		{"example.com", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := l.matches(tt.host); got != tt.want {
+			t.Errorf("matches(%q) = %v, want %v", tt.host, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package shortener
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/netip"
+	"slices"
+	"strings"
+)
+
+// GeoResolver maps a client IP to a coarse location for click analytics.
+// It runs off the redirect path, but should still answer quickly.
+type GeoResolver interface {
+	// Country returns the ISO 3166-1 alpha-2 code for ip, or "" when
+	// unknown.
+	Country(ctx context.Context, ip netip.Addr) string
+}
+
+// geoNetwork is one network of a MaxMindGeoResolver.
+type geoNetwork struct {
+	prefix  netip.Prefix
+	country string
+}
+
+// MaxMindGeoResolver resolves countries from the MaxMind GeoLite2/GeoIP2
+// Country CSV databases, held in memory.
+type MaxMindGeoResolver struct {
+	networks []geoNetwork // Sorted by first address, non-overlapping
+}
+
+// LoadMaxMindCSV builds a MaxMindGeoResolver from a Country-Locations CSV
+// and one or more Country-Blocks CSVs (typically IPv4 and IPv6). Blocks
+// without a country fall back to the registered country.
+func LoadMaxMindCSV(locations io.Reader, blocks ...io.Reader) (*MaxMindGeoResolver, error) {
+	countries := make(map[string]string) // geoname_id -> country_iso_code
+	err := readCSV(locations, []string{"geoname_id", "country_iso_code"}, func(f []string) error {
+		if code := normalizeCountry(f[1]); code != "" {
+			countries[f[0]] = code
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read locations: %w", err)
+	}
+
+	g := &MaxMindGeoResolver{}
+	for _, b := range blocks {
+		err := readCSV(b, []string{"network", "geoname_id", "registered_country_geoname_id"}, func(f []string) error {
+			prefix, err := netip.ParsePrefix(f[0])
+			if err != nil {
+				return err
+			}
+			country := countries[f[1]]
+			if country == "" {
+				country = countries[f[2]]
+			}
+			if country != "" {
+				g.networks = append(g.networks, geoNetwork{prefix: prefix.Masked(), country: country})
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to read blocks: %w", err)
+		}
+	}
+
+	slices.SortFunc(g.networks, func(a, b geoNetwork) int {
+		return a.prefix.Addr().Compare(b.prefix.Addr())
+	})
+	return g, nil
+}
+
+// Country returns the country of the network containing ip.
+func (g *MaxMindGeoResolver) Country(_ context.Context, ip netip.Addr) string {
+	ip = ip.Unmap()
+	// The last network starting at or before ip is the only candidate.
+	i, found := slices.BinarySearchFunc(g.networks, ip, func(n geoNetwork, ip netip.Addr) int {
+		return n.prefix.Addr().Compare(ip)
+	})
+	if !found {
+		i--
+	}
+	if i < 0 || !g.networks[i].prefix.Contains(ip) {
+		return ""
+	}
+	return g.networks[i].country
+}
+
+// readCSV calls fn with the named columns of every record in r, in the
+// order of columns. The first record must be a header naming them.
+func readCSV(r io.Reader, columns []string, fn func(fields []string) error) error {
+	cr := csv.NewReader(r)
+	cr.ReuseRecord = true
+
+	header, err := cr.Read()
+	if err != nil {
+		return err
+	}
+	idx := make([]int, len(columns))
+	for i, name := range columns {
+		idx[i] = slices.Index(header, name)
+		if idx[i] < 0 {
+			return fmt.Errorf("missing column %q", name)
+		}
+	}
+
+	fields := make([]string, len(columns))
+	for {
+		rec, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		for i, j := range idx {
+			fields[i] = rec[j]
+		}
+		if err := fn(fields); err != nil {
+			line, _ := cr.FieldPos(0)
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+	}
+}
+
+// normalizeCountry upper-cases a two-letter country code, returning "" for
+// anything else.
+func normalizeCountry(code string) string {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if len(code) != 2 || code[0] < 'A' || code[0] > 'Z' || code[1] < 'A' || code[1] > 'Z' {
+		return ""
+	}
+	return code
+}
@@ -0,0 +1,133 @@
+package shortener
+
+import (
+	"context"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	testGeoLocations = `geoname_id,locale_code,continent_code,continent_name,country_iso_code,country_name,is_in_european_union
+2921044,en,EU,Europe,DE,Germany,1
+6252001,en,NA,"North America",US,"United States",0
+6255148,en,EU,Europe,,,0
+`
+	testGeoBlocksIPv4 = `network,geoname_id,registered_country_geoname_id,represented_country_geoname_id,is_anonymous_proxy,is_satellite_provider
+198.51.100.0/24,2921044,2921044,,0,0
+203.0.113.0/25,,6252001,,0,0
+192.0.2.0/24,6255148,,,0,0
+`
+	testGeoBlocksIPv6 = `network,geoname_id,registered_country_geoname_id,represented_country_geoname_id,is_anonymous_proxy,is_satellite_provider
+2001:db8::/32,6252001,6252001,,0,0
+`
+)
+
+func TestLoadMaxMindCSV(t *testing.T) {
+	geo, err := LoadMaxMindCSV(strings.NewReader(testGeoLocations),
+		strings.NewReader(testGeoBlocksIPv4), strings.NewReader(testGeoBlocksIPv6))
+	if err != nil {
+		t.Fatalf("LoadMaxMindCSV() unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		ip   string
+		want string
+	}{
+		{"198.51.100.7", "DE"},
+		{"198.51.100.255", "DE"},
+		{"::ffff:198.51.100.7", "DE"},
+		{"203.0.113.9", "US"}, // falls back to the registered country
+		{"203.0.113.200", ""}, // outside the /25
+		{"192.0.2.1", ""},     // continent only
+		{"2001:db8::1", "US"},
+		{"10.0.0.1", ""},
+		{"2001:db9::1", ""},
+	}
+
+	for _, tt := range tests {
+		if got := geo.Country(context.Background(), netip.MustParseAddr(tt.ip)); got != tt.want {
+			t.Errorf("Country(%s) = %q, want %q", tt.ip, got, tt.want)
+		}
+	}
+}
+
+func TestLoadMaxMindCSV_Invalid(t *testing.T) {
+	tests := []struct {
+		name      string
+		locations string
+		blocks    string
+	}{
+		{"locations missing column", "geoname_id,country_name\n1,Germany\n", testGeoBlocksIPv4},
+		{"blocks missing column", testGeoLocations, "network,geoname_id\n198.51.100.0/24,2921044\n"},
+		{"bad network", testGeoLocations, "network,geoname_id,registered_country_geoname_id\nnot-a-cidr,2921044,\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := LoadMaxMindCSV(strings.NewReader(tt.locations), strings.NewReader(tt.blocks)); err == nil {
+				t.Error("LoadMaxMindCSV() expected error")
+			}
+		})
+	}
+}
+
+// stubGeo maps IP strings to country codes.
+type stubGeo map[string]string
+
+func (g stubGeo) Country(_ context.Context, ip netip.Addr) string {
+	return g[ip.String()]
+}
+
+func TestServiceResolve_TagsClickCountry(t *testing.T) {
+	tests := []struct {
+		name    string
+		visitor *Visitor
+		want    string
+	}{
+		{"known IP", &Visitor{IP: "198.51.100.7"}, "DE"},
+		{"lower-case code is normalized", &Visitor{IP: "198.51.100.8"}, "FR"},
+		{"unknown IP", &Visitor{IP: "203.0.113.1"}, ""},
+		{"unparsable IP", &Visitor{IP: "unknown"}, ""},
+		{"no visitor", nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorded := make(chan ClickEvent, 1)
+			repo := &mockRepository{
+				resolveAndTrackFunc: func(ctx context.Context, slug string) (Link, error) {
+					return Link{ID: uuid.New(), OriginalURL: "https://example.com"}, nil
+				},
+				recordClickFunc: func(ctx context.Context, click ClickEvent) error {
+					recorded <- click
+					return nil
+				},
+			}
+			svc := NewService(repo, &ServiceConfig{
+				RecordClicks: true,
+				GeoResolver:  stubGeo{"198.51.100.7": "DE", "198.51.100.8": "fr"},
+			})
+
+			ctx := context.Background()
+			if tt.visitor != nil {
+				ctx = WithVisitor(ctx, *tt.visitor)
+			}
+			if _, err := svc.Resolve(ctx, "abc1234"); err != nil {
+				t.Fatalf("Resolve() unexpected error: %v", err)
+			}
+
+			select {
+			case click := <-recorded:
+				if click.Country != tt.want {
+					t.Errorf("click.Country = %q, want %q", click.Country, tt.want)
+				}
+			case <-time.After(time.Second):
+				t.Fatal("click was not recorded")
+			}
+		})
+	}
+}
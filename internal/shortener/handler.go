@@ -4,33 +4,248 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"html/template"
 	"log/slog"
+	"maps"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
 
 	"github.com/sundayezeilo/urlshortener/internal/errx"
 	"github.com/sundayezeilo/urlshortener/internal/httpx"
 )
 
+// LinkExpiredHeader is set to "true" on redirects for links past their
+// expiry that still resolve within the configured grace period.
+const LinkExpiredHeader = "X-Link-Expired"
+
 // HTTPCreateLinkRequest represents the JSON request body for creating a link.
 type HTTPCreateLinkRequest struct {
-	URL        string `json:"url"`
-	CustomSlug string `json:"custom_slug,omitempty"`
+	URL        string   `json:"url"`
+	CustomSlug string   `json:"custom_slug,omitempty"`
+	Source     string   `json:"source,omitempty"`
+	UTM        *HTTPUTM `json:"utm,omitempty"`
+
+	// RedirectStatus optionally overrides the server's redirect status for
+	// the link: 301, 302, 307 or 308.
+	RedirectStatus int `json:"redirect_status,omitempty"`
 }
 
-// CreateLinkResponse represents the JSON response for a created link.
-type CreateLinkResponse struct {
-	ID          string `json:"id"`
-	Slug        string `json:"slug"`
-	OriginalURL string `json:"original_url"`
-	ShortURL    string `json:"short_url"`
-	CreatedAt   string `json:"created_at"`
+// HTTPUTM is the JSON form of a link's UTM defaults.
+type HTTPUTM struct {
+	Source   string `json:"source,omitempty"`
+	Medium   string `json:"medium,omitempty"`
+	Campaign string `json:"campaign,omitempty"`
+}
+
+// utmParams converts optional JSON UTM defaults to UTMParams.
+func (u *HTTPUTM) utmParams() UTMParams {
+	if u == nil {
+		return UTMParams{}
+	}
+	return UTMParams{Source: u.Source, Medium: u.Medium, Campaign: u.Campaign}
+}
+
+// HTTPCreateBatchRequest represents the JSON request body for creating
+// several links at once.
+type HTTPCreateBatchRequest struct {
+	Links []HTTPCreateLinkRequest `json:"links"`
+}
+
+// HTTPSlugAvailabilityRequest represents the JSON request body for checking
+// several custom slugs at once.
+type HTTPSlugAvailabilityRequest struct {
+	Slugs []string `json:"slugs"`
+}
+
+// HTTPAddAliasRequest represents the JSON request body for adding an alias
+// to a link.
+type HTTPAddAliasRequest struct {
+	Alias string `json:"alias"`
+}
+
+// LinkResponse represents the JSON representation of a link.
+type LinkResponse struct {
+	ID                string   `json:"id"`
+	Slug              string   `json:"slug"`
+	OriginalURL       string   `json:"original_url"`
+	ShortURL          string   `json:"short_url"`
+	AccessCount       int64    `json:"access_count"`
+	UniqueAccessCount int64    `json:"unique_access_count"`
+	CreatedAt         string   `json:"created_at"`
+	UpdatedAt         string   `json:"updated_at"`
+	LastAccessedAt    *string  `json:"last_accessed_at,omitempty"`
+	ExpiresAt         *string  `json:"expires_at,omitempty"`
+	Source            string   `json:"source,omitempty"`
+	UTM               *HTTPUTM `json:"utm,omitempty"`
+	RedirectStatus    int      `json:"redirect_status,omitempty"`
+	Paused            bool     `json:"paused,omitempty"`
+}
+
+// ListLinksResponse represents the JSON response for a page of links.
+type ListLinksResponse struct {
+	Links []LinkResponse `json:"links"`
+	Page  PageInfo       `json:"page"`
+}
+
+// LinkMetadataResponse is the admin view of a link: the public fields plus
+// its owner and, when recorded, the creating client.
+type LinkMetadataResponse struct {
+	LinkResponse
+	Owner            string `json:"owner,omitempty"`
+	CreatedIP        string `json:"created_ip,omitempty"`
+	CreatedUserAgent string `json:"created_user_agent,omitempty"`
+}
+
+// LinksByURLResponse represents the JSON response for a reverse lookup by
+// destination URL.
+type LinksByURLResponse struct {
+	URL   string         `json:"url"`
+	Links []LinkResponse `json:"links"`
+}
+
+// TimeSeriesResponse represents the JSON response for a link's click time
+// series. Times are RFC 3339 in UTC; To is exclusive.
+type TimeSeriesResponse struct {
+	Slug   string            `json:"slug"`
+	Bucket string            `json:"bucket"`
+	From   string            `json:"from"`
+	To     string            `json:"to"`
+	Total  int64             `json:"total"`
+	Points []TimeSeriesPoint `json:"points"`
+}
+
+// TimeSeriesPoint is the click count of one bucket.
+type TimeSeriesPoint struct {
+	Start  string `json:"start"`
+	Clicks int64  `json:"clicks"`
+}
+
+// SlugAvailabilityResponse maps each requested slug to its availability.
+type SlugAvailabilityResponse struct {
+	Slugs map[string]SlugAvailabilityEntry `json:"slugs"`
+}
+
+// SlugAvailabilityEntry is the availability of one requested slug. Slug is
+// what a create would store, which differs from the requested slug when
+// the caller's namespace prefix is added. Status is one of "available",
+// "taken", "invalid" or "reserved".
+type SlugAvailabilityEntry struct {
+	Slug      string `json:"slug"`
+	Available bool   `json:"available"`
+	Valid     bool   `json:"valid"`
+	Status    string `json:"status"`
+	Message   string `json:"message,omitempty"`
+}
+
+// CreateBatchResponse represents the JSON response for a batch create.
+// Results are in request order, one per row.
+type CreateBatchResponse struct {
+	Results []BatchRowResponse `json:"results"`
+	Created int                `json:"created"`
+	Skipped int                `json:"skipped"`
+	Failed  int                `json:"failed"`
+
+	// Unreachable counts created rows whose destination failed the
+	// reachability check; only reported when the check is enabled.
+	Unreachable int `json:"unreachable,omitempty"`
 }
 
+// BatchRowResponse is the outcome of one batch row.
+type BatchRowResponse struct {
+	Index  int            `json:"index"`
+	Status string         `json:"status"`
+	Link   *LinkResponse  `json:"link,omitempty"`
+	Error  *BatchRowError `json:"error,omitempty"`
+
+	// Reachable is the destination check result for created rows, when
+	// the check is enabled.
+	Reachable *bool `json:"reachable,omitempty"`
+}
+
+// BatchRowError describes why a batch row failed.
+type BatchRowError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// SourceStatsResponse represents the JSON response for link counts by
+// creation source. Untagged links are reported under an empty source.
+type SourceStatsResponse struct {
+	Sources []SourceStat `json:"sources"`
+	Total   int64        `json:"total"`
+}
+
+// SourceStat is the number of live links created through one source.
+type SourceStat struct {
+	Source string `json:"source"`
+	Links  int64  `json:"links"`
+}
+
+// CountryStatsResponse represents the JSON response for click counts by
+// country. Clicks from unknown locations are reported under an empty
+// country.
+type CountryStatsResponse struct {
+	Countries []CountryStat `json:"countries"`
+	Total     int64         `json:"total"`
+}
+
+// CountryStat is the number of recorded clicks from one country.
+type CountryStat struct {
+	Country string `json:"country"`
+	Clicks  int64  `json:"clicks"`
+}
+
+// AliasResponse represents the JSON response for an added alias.
+type AliasResponse struct {
+	Slug     string `json:"slug"`
+	Alias    string `json:"alias"`
+	ShortURL string `json:"short_url"` // The short URL under the alias
+}
+
+// PreviewResponse is the OpenGraph metadata for a short link, so clients
+// unfurling the short URL can show a preview without following it.
+type PreviewResponse struct {
+	Title       string `json:"og:title"`
+	URL         string `json:"og:url"` // The short URL
+	Description string `json:"og:description"`
+}
+
+// PageInfo carries pagination state for list responses.
+// Pass NextCursor back as the cursor query parameter to fetch the next page.
+type PageInfo struct {
+	Limit      int    `json:"limit"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	HasMore    bool   `json:"has_more"`
+}
+
+// CreateLinkResponse represents the JSON response for a created link.
+// It carries the full link record so clients don't need a follow-up GET.
+type CreateLinkResponse = LinkResponse
+
 // Handler provides HTTP handlers for the URL shortener service.
 type Handler struct {
-	service Service
-	logger  *slog.Logger
-	baseURL string
+	service             Service
+	logger              *slog.Logger
+	baseURL             string
+	oversizedSlugStatus int
+	notFoundRedirectURL string
+	rootRedirectURL     string
+	ignoredPaths        map[string]bool
+	beacons             bool
+	forwardQueryParams  bool
+	redirectStatus      int
+	redirectCache       map[int]string
+	redactor            *httpx.Redactor
+	maxBatchBytes       int64
+	errorCodes          httpx.ErrorCodes
+	errorCounts         *errx.Counts
+	shortLinkHeader     bool
+	originalURIHeader   string
 }
 
 // HandlerConfig holds configuration for the handler.
@@ -38,6 +253,101 @@ type HandlerConfig struct {
 	Service Service
 	Logger  *slog.Logger
 	BaseURL string // Base URL for constructing short URLs (e.g., "https://short.ly")
+
+	// OversizedSlugStatus is returned for slugs longer than MaxCustomSlugLength:
+	// http.StatusBadRequest (default) or http.StatusRequestURITooLong.
+	OversizedSlugStatus int
+
+	// NotFoundRedirectURL, when set, sends resolves of unknown slugs to this
+	// URL with a 302 instead of returning a 404.
+	NotFoundRedirectURL string
+
+	// RootRedirectURL, when set, sends requests for "/" to this URL with a
+	// 302, e.g. a landing page. Otherwise "/" is a 404.
+	RootRedirectURL string
+
+	// IgnoredPaths are answered with a bare 404 and no handler logging, so
+	// browser auto-requests don't fill the logs with warnings
+	// (default: DefaultIgnoredPaths). Use an empty, non-nil slice to
+	// resolve them like any other slug.
+	IgnoredPaths []string
+
+	// RedactParams lists query parameters whose values are masked in logged
+	// URLs (default: httpx.DefaultRedactedParams). Use an empty, non-nil
+	// slice to log URLs verbatim.
+	RedactParams []string
+
+	// Beacons lets resolve paths end in ".gif" or ".png" for tracking
+	// pixels: the click is recorded and a 1x1 transparent image is served
+	// instead of a redirect. Unknown slugs get the image too, so embedded
+	// pixels never render broken. With the AlphanumDashUnderscoreDot
+	// charset, slugs ending in those suffixes can then no longer redirect.
+	Beacons bool
+
+	// ForwardQueryParams appends the resolve request's query parameters to
+	// the destination, e.g. "/promo?utm_source=x" redirects to
+	// "https://dest?utm_source=x". Parameters the stored destination
+	// already has win over incoming ones with the same name.
+	ForwardQueryParams bool
+
+	// RedirectStatus is sent for resolved links: 301, 302 (default), 307
+	// or 308.
+	RedirectStatus int
+	// RedirectCacheControl sets the Cache-Control header per redirect
+	// status; an empty value sends none. Statuses it leaves out use
+	// DefaultRedirectCacheControl.
+	RedirectCacheControl map[int]string
+
+	// MaxBatchSize is the service's ServiceConfig.MaxBatchSize. Batch
+	// request bodies may be up to MaxBatchRowBytes per row
+	// (default: DefaultMaxBatchSize).
+	MaxBatchSize int
+
+	// ErrorCodes overrides the error codes written for each errx.Kind,
+	// e.g. to namespace them. Kinds it leaves out, and codes specific to
+	// one endpoint such as "invalid_slug", are unchanged.
+	ErrorCodes httpx.ErrorCodes
+
+	// ErrorCounts, when set, counts each service error the handler writes
+	// by kind, including failed batch rows, so operators can alert on
+	// spikes in, say, Conflict or Unavailable.
+	ErrorCounts *errx.Counts
+
+	// ShortLinkHeader adds a `Link: <short URL>; rel="shortlink"` header
+	// to created links, next to the Location of the new resource.
+	ShortLinkHeader bool
+
+	// OriginalURIHeader, when set, names a header that resolve redirects
+	// echo the incoming request URI (path and query) in, e.g.
+	// "X-Original-Request-URI", for downstream logging.
+	OriginalURIHeader string
+}
+
+// DefaultIgnoredPaths are paths browsers and crawlers request on their own.
+var DefaultIgnoredPaths = []string{
+	"/favicon.ico",
+	"/robots.txt",
+	"/apple-touch-icon.png",
+	"/apple-touch-icon-precomposed.png",
+}
+
+// DefaultRedirectCacheControl lets browsers and CDNs keep permanent
+// redirects for a day and makes them revalidate temporary ones, so
+// retargeted or deleted links take effect immediately.
+var DefaultRedirectCacheControl = map[int]string{
+	http.StatusMovedPermanently:  "public, max-age=86400",
+	http.StatusFound:             "no-cache",
+	http.StatusTemporaryRedirect: "no-cache",
+	http.StatusPermanentRedirect: "public, max-age=86400",
+}
+
+// CacheControlMaxAge returns the Cache-Control value that lets a redirect be
+// cached for maxAge, or "no-cache" when maxAge is not positive.
+func CacheControlMaxAge(maxAge time.Duration) string {
+	if maxAge <= 0 {
+		return "no-cache"
+	}
+	return fmt.Sprintf("public, max-age=%d", int64(maxAge/time.Second))
 }
 
 // NewHandler creates a new Handler instance.
@@ -47,10 +357,56 @@ func NewHandler(cfg HandlerConfig) *Handler {
 		logger = slog.Default()
 	}
 
+	oversizedSlugStatus := cfg.OversizedSlugStatus
+	if oversizedSlugStatus != http.StatusRequestURITooLong {
+		oversizedSlugStatus = http.StatusBadRequest
+	}
+
+	redactParams := cfg.RedactParams
+	if redactParams == nil {
+		redactParams = httpx.DefaultRedactedParams
+	}
+
+	redirectStatus := cfg.RedirectStatus
+	if _, ok := DefaultRedirectCacheControl[redirectStatus]; !ok {
+		redirectStatus = http.StatusFound
+	}
+
+	redirectCache := maps.Clone(DefaultRedirectCacheControl)
+	maps.Copy(redirectCache, cfg.RedirectCacheControl)
+
+	ignoredPaths := cfg.IgnoredPaths
+	if ignoredPaths == nil {
+		ignoredPaths = DefaultIgnoredPaths
+	}
+	maxBatch := cfg.MaxBatchSize
+	if maxBatch <= 0 {
+		maxBatch = DefaultMaxBatchSize
+	}
+
+	ignored := make(map[string]bool, len(ignoredPaths))
+	for _, p := range ignoredPaths {
+		ignored[p] = true
+	}
+
 	return &Handler{
-		service: cfg.Service,
-		logger:  logger,
-		baseURL: cfg.BaseURL,
+		service:             cfg.Service,
+		logger:              logger,
+		baseURL:             cfg.BaseURL,
+		oversizedSlugStatus: oversizedSlugStatus,
+		notFoundRedirectURL: cfg.NotFoundRedirectURL,
+		rootRedirectURL:     cfg.RootRedirectURL,
+		ignoredPaths:        ignored,
+		beacons:             cfg.Beacons,
+		forwardQueryParams:  cfg.ForwardQueryParams,
+		redirectStatus:      redirectStatus,
+		redirectCache:       redirectCache,
+		redactor:            httpx.NewRedactor(redactParams),
+		maxBatchBytes:       int64(maxBatch) * MaxBatchRowBytes,
+		errorCodes:          cfg.ErrorCodes,
+		errorCounts:         cfg.ErrorCounts,
+		shortLinkHeader:     cfg.ShortLinkHeader,
+		originalURIHeader:   cfg.OriginalURIHeader,
 	}
 }
 
@@ -73,32 +429,47 @@ func (h *Handler) CreateLink(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Validate required fields
+	// Validate field shapes before the service call; all failures are
+	// reported together
 	if err := validateCreateRequest(req); err != nil {
 		logger.WarnContext(ctx, "request validation failed",
 			"error", err.Error(),
-			"url", req.URL,
+			"url", h.redactor.URL(req.URL),
 			"custom_slug", req.CustomSlug,
 		)
-		httpx.WriteError(w, http.StatusBadRequest, "validation_failed", err.Error(), nil)
+		var details any
+		var verr *httpx.ValidationError
+		if errors.As(err, &verr) {
+			details = verr.Fields
+		}
+		httpx.WriteError(w, http.StatusBadRequest, "validation_failed", err.Error(), details)
 		return
 	}
 
 	link, err := h.service.Create(ctx, CreateLinkRequest{
 		OriginalURL: req.URL,
 		CustomSlug:  req.CustomSlug,
+		Principal:   httpx.GetPrincipal(ctx),
+		Source:      req.Source,
+		Creator:     Creator{IP: httpx.ClientIP(r), UserAgent: r.UserAgent()},
+		UTM:         req.UTM.utmParams(),
+
+		RedirectStatus: req.RedirectStatus,
 	})
 	if err != nil {
 		h.handleCreateError(ctx, w, err)
 		return
 	}
 
-	resp := CreateLinkResponse{
-		ID:          link.ID.String(),
-		Slug:        link.Slug,
-		OriginalURL: link.OriginalURL,
-		ShortURL:    fmt.Sprintf("%s/%s", h.baseURL, link.Slug),
-		CreatedAt:   link.CreatedAt.Format(http.TimeFormat),
+	resp := toResponse(link, h.baseURL)
+
+	if link.Replayed {
+		logger.InfoContext(ctx, "link create replayed",
+			"link_id", link.ID.String(),
+			"slug", link.Slug,
+		)
+		httpx.WriteJSON(w, http.StatusOK, resp)
+		return
 	}
 
 	logger.InfoContext(ctx, "link created successfully",
@@ -107,12 +478,17 @@ func (h *Handler) CreateLink(w http.ResponseWriter, r *http.Request) {
 		"custom_slug", req.CustomSlug != "",
 	)
 
+	w.Header().Set("Location", "/api/links/"+url.PathEscape(link.Slug))
+	if h.shortLinkHeader {
+		w.Header().Set("Link", "<"+resp.ShortURL+`>; rel="shortlink"`)
+	}
 	httpx.WriteJSON(w, http.StatusCreated, resp)
 }
 
-// ResolveLink handles GET requests to resolve a slug and redirect to the original URL.
-// This increments the access count and updates tracking metadata.
-func (h *Handler) ResolveLink(w http.ResponseWriter, r *http.Request) {
+// CreateLinksBatch handles POST requests to create several links at once.
+// Rows succeed or fail independently, so the response is 200 with a
+// per-row outcome unless the batch as a whole is rejected.
+func (h *Handler) CreateLinksBatch(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
 	// Extract request ID for tracing
@@ -120,14 +496,84 @@ func (h *Handler) ResolveLink(w http.ResponseWriter, r *http.Request) {
 
 	logger := h.logger.With("request_id", requestID)
 
-	// Extract slug from URL path
-	slug := extractSlugFromPath(r.URL.Path)
-	if slug == "" {
-		logger.WarnContext(ctx, "missing slug in path")
-		httpx.WriteError(w, http.StatusBadRequest, "invalid_request", "slug is required", nil)
+	req, err := httpx.DecodeJSONWithLimit[HTTPCreateBatchRequest](r, h.maxBatchBytes)
+	if err != nil {
+		logger.WarnContext(ctx, "failed to decode request",
+			"error", err.Error(),
+		)
+		httpx.WriteError(w, http.StatusBadRequest, "invalid_request", err.Error(), nil)
+		return
+	}
+
+	principal := httpx.GetPrincipal(ctx)
+	creator := Creator{IP: httpx.ClientIP(r), UserAgent: r.UserAgent()}
+	reqs := make([]CreateLinkRequest, 0, len(req.Links))
+	for _, l := range req.Links {
+		reqs = append(reqs, CreateLinkRequest{
+			OriginalURL: l.URL,
+			CustomSlug:  l.CustomSlug,
+			Principal:   principal,
+			Source:      l.Source,
+			Creator:     creator,
+			UTM:         l.UTM.utmParams(),
+
+			RedirectStatus: l.RedirectStatus,
+		})
+	}
+
+	results, err := h.service.CreateBatch(ctx, reqs)
+	if err != nil {
+		h.handleCreateError(ctx, w, err)
 		return
 	}
 
+	resp := CreateBatchResponse{Results: make([]BatchRowResponse, 0, len(results))}
+	for _, res := range results {
+		row := BatchRowResponse{Index: res.Index, Status: string(res.Status)}
+		switch res.Status {
+		case BatchCreated:
+			link := toResponse(res.Link, h.baseURL)
+			row.Link = &link
+			row.Reachable = res.Reachable
+			resp.Created++
+			if res.Reachable != nil && !*res.Reachable {
+				resp.Unreachable++
+			}
+		case BatchSkipped:
+			resp.Skipped++
+		default:
+			row.Error = h.batchRowError(res.Err)
+			resp.Failed++
+		}
+		resp.Results = append(resp.Results, row)
+	}
+
+	logger.InfoContext(ctx, "link batch processed",
+		"rows", len(results),
+		"created", resp.Created,
+		"skipped", resp.Skipped,
+		"failed", resp.Failed,
+		"unreachable", resp.Unreachable,
+	)
+
+	httpx.WriteJSON(w, http.StatusOK, resp)
+}
+
+// GetLink handles GET requests for a link's metadata.
+// Unlike ResolveLink, it does not redirect or track access.
+func (h *Handler) GetLink(w http.ResponseWriter, r *http.Request) {
+	slug := r.PathValue("slug")
+	if h.rejectOversizedSlug(w, slug) {
+		return
+	}
+
+	ctx := r.Context()
+
+	// Extract request ID for tracing
+	requestID := httpx.GetRequestID(ctx)
+
+	logger := h.logger.With("request_id", requestID)
+
 	if err := validateSlugFormat(slug); err != nil {
 		logger.WarnContext(ctx, "invalid slug format",
 			"slug", slug,
@@ -137,91 +583,1157 @@ func (h *Handler) ResolveLink(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	originalURL, err := h.service.Resolve(ctx, slug)
+	link, err := h.service.GetBySlug(ctx, slug)
 	if err != nil {
-		h.handleResolveError(ctx, w, err, slug)
+		h.handleGetError(ctx, w, err, slug)
 		return
 	}
 
-	logger.InfoContext(ctx, "slug resolved successfully",
-		"slug", slug,
-		"original_url", originalURL,
-		"user_agent", r.UserAgent(),
-		"referer", r.Referer(),
-	)
-
-	http.Redirect(w, r, originalURL, http.StatusFound)
+	httpx.WriteJSON(w, http.StatusOK, toResponse(link, h.baseURL))
 }
 
-// handleCreateError handles errors from the Create service method.
-func (h *Handler) handleCreateError(ctx context.Context, w http.ResponseWriter, err error) {
-	kind := errx.KindOf(err)
-
-	logAttrs := []any{
-		"error", err.Error(),
-		"error_kind", kind,
-		"operation", errx.OpOf(err),
+// GetLinkMetadata handles GET requests for the admin view of a link. It
+// includes creator details, so it must only be routed behind admin auth.
+func (h *Handler) GetLinkMetadata(w http.ResponseWriter, r *http.Request) {
+	slug := r.PathValue("slug")
+	if h.rejectOversizedSlug(w, slug) {
+		return
 	}
 
-	switch kind {
-	case errx.Conflict:
-		h.logger.WarnContext(ctx, "slug conflict", logAttrs...)
-		httpx.WriteError(w, http.StatusConflict, "conflict",
-			"This slug is already taken",
-			map[string]string{
-				"hint": "Try a different custom slug or let us generate one for you",
-			})
+	ctx := r.Context()
 
-	case errx.Invalid:
-		h.logger.WarnContext(ctx, "invalid link request", logAttrs...)
-		httpx.WriteError(w, http.StatusBadRequest, "invalid_input", err.Error(), nil)
+	// Extract request ID for tracing
+	requestID := httpx.GetRequestID(ctx)
 
-	case errx.Unavailable:
-		h.logger.ErrorContext(ctx, "service unavailable", logAttrs...)
-		httpx.WriteError(w, http.StatusServiceUnavailable, "unavailable",
-			"Unable to create short link at this time. Please try again.", nil)
+	logger := h.logger.With("request_id", requestID)
 
-	default:
-		h.logger.ErrorContext(ctx, "unexpected error creating link", logAttrs...)
-		httpx.WriteError(w, http.StatusInternalServerError, "internal_error",
-			"Unable to create short link at this time. Please try again.", nil)
+	if err := validateSlugFormat(slug); err != nil {
+		logger.WarnContext(ctx, "invalid slug format",
+			"slug", slug,
+			"error", err.Error(),
+		)
+		httpx.WriteError(w, http.StatusBadRequest, "invalid_slug", err.Error(), nil)
+		return
+	}
+
+	meta, err := h.service.GetMetadata(ctx, slug)
+	if err != nil {
+		h.handleGetError(ctx, w, err, slug)
+		return
+	}
+
+	resp := LinkMetadataResponse{
+		LinkResponse: toResponse(meta.Link, h.baseURL),
+		Owner:        meta.Link.Owner,
+	}
+	if meta.Creator != nil {
+		resp.CreatedIP = meta.Creator.IP
+		resp.CreatedUserAgent = meta.Creator.UserAgent
 	}
+	httpx.WriteJSON(w, http.StatusOK, resp)
 }
 
-// handleResolveError handles errors from the Resolve service method.
-func (h *Handler) handleResolveError(ctx context.Context, w http.ResponseWriter, err error, slug string) {
-	kind := errx.KindOf(err)
+// GetLinkByID handles GET requests for the admin view of the link with the
+// ID given in the id path value, as returned on create. Like
+// GetLinkMetadata, it must only be routed behind admin auth.
+func (h *Handler) GetLinkByID(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
 
-	logAttrs := []any{
-		"error", err.Error(),
-		"error_kind", kind,
-		"operation", errx.OpOf(err),
-		"slug", slug,
+	// Extract request ID for tracing
+	requestID := httpx.GetRequestID(ctx)
+
+	logger := h.logger.With("request_id", requestID)
+
+	rawID := r.PathValue("id")
+	if rawID == "" {
+		logger.WarnContext(ctx, "missing link id")
+		httpx.WriteError(w, http.StatusBadRequest, "invalid_request", "id is required", nil)
+		return
+	}
+	id, err := uuid.Parse(rawID)
+	if err != nil {
+		logger.WarnContext(ctx, "invalid link id",
+			"id", rawID,
+			"error", err.Error(),
+		)
+		httpx.WriteError(w, http.StatusBadRequest, "invalid_request", "id must be a UUID", nil)
+		return
 	}
 
-	switch kind {
-	case errx.NotFound:
-		h.logger.WarnContext(ctx, "slug not found", logAttrs...)
-		httpx.WriteError(w, http.StatusNotFound, "not_found",
-			"short link doesn't exist", nil)
+	link, err := h.service.GetByID(ctx, id)
+	if err != nil {
+		h.handleGetByIDError(ctx, w, err, id)
+		return
+	}
 
-	case errx.Invalid:
-		h.logger.WarnContext(ctx, "invalid slug", logAttrs...)
+	httpx.WriteJSON(w, http.StatusOK, LinkMetadataResponse{
+		LinkResponse: toResponse(link, h.baseURL),
+		Owner:        link.Owner,
+	})
+}
+
+// GetLinkPreview handles GET requests for a link's OpenGraph metadata. It
+// serves <meta> tags to clients preferring text/html and JSON otherwise.
+// Links store no title or description, so both are derived from the
+// destination.
+func (h *Handler) GetLinkPreview(w http.ResponseWriter, r *http.Request) {
+	slug := r.PathValue("slug")
+	if h.rejectOversizedSlug(w, slug) {
+		return
+	}
+
+	ctx := r.Context()
+
+	// Extract request ID for tracing
+	requestID := httpx.GetRequestID(ctx)
+
+	logger := h.logger.With("request_id", requestID)
+
+	if err := validateSlugFormat(slug); err != nil {
+		logger.WarnContext(ctx, "invalid slug format",
+			"slug", slug,
+			"error", err.Error(),
+		)
 		httpx.WriteError(w, http.StatusBadRequest, "invalid_slug", err.Error(), nil)
+		return
+	}
 
-	default:
-		h.logger.ErrorContext(ctx, "unexpected error resolving link", logAttrs...)
-		httpx.WriteError(w, http.StatusInternalServerError, "internal_error",
-			"Unable to resolve this link at this time", nil)
+	link, err := h.service.GetBySlug(ctx, slug)
+	if err != nil {
+		h.handleGetError(ctx, w, err, slug)
+		return
+	}
+
+	preview := PreviewResponse{
+		Title:       destinationHost(link.OriginalURL),
+		URL:         buildShortURL(h.baseURL, "", link.Slug),
+		Description: "Short link to " + link.OriginalURL,
+	}
+
+	w.Header().Add("Vary", "Accept")
+	if httpx.Negotiate(r, previewMediaTypes) != "text/html" {
+		httpx.WriteJSON(w, http.StatusOK, preview)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	if err := previewTemplate.Execute(w, preview); err != nil {
+		logger.ErrorContext(ctx, "failed to write preview", "slug", slug, "error", err)
 	}
 }
 
-// validateCreateRequest validates the HTTPCreateLinkRequest.
-func validateCreateRequest(req HTTPCreateLinkRequest) error {
-	if req.URL == "" {
-		return errors.New("url is required")
+// previewMediaTypes are the representations GetLinkPreview offers, the
+// default first.
+var previewMediaTypes = []string{"application/json", "text/html"}
+
+var previewTemplate = template.Must(template.New("preview").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<meta property="og:title" content="{{.Title}}">
+<meta property="og:url" content="{{.URL}}">
+<meta property="og:description" content="{{.Description}}">
+</head>
+</html>
+`))
+
+// AddLinkAlias handles POST requests adding an alias to the link with the
+// path slug. The link then resolves under both.
+func (h *Handler) AddLinkAlias(w http.ResponseWriter, r *http.Request) {
+	slug := r.PathValue("slug")
+	if h.rejectOversizedSlug(w, slug) {
+		return
 	}
-	return nil
+
+	ctx := r.Context()
+
+	// Extract request ID for tracing
+	requestID := httpx.GetRequestID(ctx)
+
+	logger := h.logger.With("request_id", requestID)
+
+	if err := validateSlugFormat(slug); err != nil {
+		logger.WarnContext(ctx, "invalid slug format",
+			"slug", slug,
+			"error", err.Error(),
+		)
+		httpx.WriteError(w, http.StatusBadRequest, "invalid_slug", err.Error(), nil)
+		return
+	}
+
+	req, err := httpx.DecodeJSON[HTTPAddAliasRequest](r)
+	if err != nil {
+		logger.WarnContext(ctx, "failed to decode request",
+			"error", err.Error(),
+		)
+		httpx.WriteError(w, http.StatusBadRequest, "invalid_request", err.Error(), nil)
+		return
+	}
+
+	alias, err := h.service.AddAlias(ctx, slug, req.Alias)
+	if err != nil {
+		h.handleAliasError(ctx, w, err, slug)
+		return
+	}
+
+	logger.InfoContext(ctx, "link alias added",
+		"slug", slug,
+		"alias", alias,
+	)
+
+	httpx.WriteJSON(w, http.StatusCreated, AliasResponse{
+		Slug:     slug,
+		Alias:    alias,
+		ShortURL: buildShortURL(h.baseURL, "", alias),
+	})
+}
+
+// DeleteLink handles DELETE requests for the link with the path slug. The
+// link is soft-deleted and stops resolving at once.
+func (h *Handler) DeleteLink(w http.ResponseWriter, r *http.Request) {
+	slug := r.PathValue("slug")
+	if h.rejectOversizedSlug(w, slug) {
+		return
+	}
+
+	ctx := r.Context()
+
+	// Extract request ID for tracing
+	requestID := httpx.GetRequestID(ctx)
+
+	logger := h.logger.With("request_id", requestID)
+
+	if err := validateSlugFormat(slug); err != nil {
+		logger.WarnContext(ctx, "invalid slug format",
+			"slug", slug,
+			"error", err.Error(),
+		)
+		httpx.WriteError(w, http.StatusBadRequest, "invalid_slug", err.Error(), nil)
+		return
+	}
+
+	if err := h.service.Delete(ctx, slug); err != nil {
+		h.handleGetError(ctx, w, err, slug)
+		return
+	}
+
+	logger.InfoContext(ctx, "link deleted", "slug", slug)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// PauseLink handles POST requests pausing the link with the path slug. It
+// stops resolving at once but keeps its stats and can be resumed.
+func (h *Handler) PauseLink(w http.ResponseWriter, r *http.Request) {
+	h.setLinkActive(w, r, false)
+}
+
+// ResumeLink handles POST requests resuming a paused link.
+func (h *Handler) ResumeLink(w http.ResponseWriter, r *http.Request) {
+	h.setLinkActive(w, r, true)
+}
+
+func (h *Handler) setLinkActive(w http.ResponseWriter, r *http.Request, active bool) {
+	slug := r.PathValue("slug")
+	if h.rejectOversizedSlug(w, slug) {
+		return
+	}
+
+	ctx := r.Context()
+
+	// Extract request ID for tracing
+	requestID := httpx.GetRequestID(ctx)
+
+	logger := h.logger.With("request_id", requestID)
+
+	if err := validateSlugFormat(slug); err != nil {
+		logger.WarnContext(ctx, "invalid slug format",
+			"slug", slug,
+			"error", err.Error(),
+		)
+		httpx.WriteError(w, http.StatusBadRequest, "invalid_slug", err.Error(), nil)
+		return
+	}
+
+	link, err := h.service.SetActive(ctx, slug, active)
+	if err != nil {
+		h.handleGetError(ctx, w, err, slug)
+		return
+	}
+
+	logger.InfoContext(ctx, "link active state changed", "slug", slug, "active", active)
+	httpx.WriteJSON(w, http.StatusOK, toResponse(link, h.baseURL))
+}
+
+// CheckSlugAvailability handles POST requests checking whether several
+// custom slugs are free, in one round trip for bulk UIs. Slugs are checked
+// in the caller's namespace, as CreateLink would store them.
+func (h *Handler) CheckSlugAvailability(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Extract request ID for tracing
+	requestID := httpx.GetRequestID(ctx)
+
+	logger := h.logger.With("request_id", requestID)
+
+	req, err := httpx.DecodeJSON[HTTPSlugAvailabilityRequest](r)
+	if err != nil {
+		logger.WarnContext(ctx, "failed to decode request",
+			"error", err.Error(),
+		)
+		httpx.WriteError(w, http.StatusBadRequest, "invalid_request", err.Error(), nil)
+		return
+	}
+
+	results, err := h.service.CheckSlugs(ctx, httpx.GetPrincipal(ctx), req.Slugs)
+	if err != nil {
+		h.handleSlugAvailabilityError(ctx, w, err)
+		return
+	}
+
+	resp := SlugAvailabilityResponse{Slugs: make(map[string]SlugAvailabilityEntry, len(results))}
+	for requested, res := range results {
+		resp.Slugs[requested] = SlugAvailabilityEntry{
+			Slug:      res.Slug,
+			Available: res.Status == SlugAvailable,
+			Valid:     res.Status != SlugInvalid,
+			Status:    string(res.Status),
+			Message:   res.Message,
+		}
+	}
+
+	httpx.WriteJSON(w, http.StatusOK, resp)
+}
+
+// ListLinks handles GET requests for a page of links, newest first. It is
+// an admin endpoint: the page spans every owner.
+// It accepts optional limit and cursor query parameters.
+func (h *Handler) ListLinks(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Extract request ID for tracing
+	requestID := httpx.GetRequestID(ctx)
+
+	logger := h.logger.With("request_id", requestID)
+
+	query := r.URL.Query()
+
+	var limit int
+	if raw := query.Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 {
+			logger.WarnContext(ctx, "invalid limit", "limit", raw)
+			httpx.WriteError(w, http.StatusBadRequest, "invalid_request",
+				"limit must be a positive integer", nil)
+			return
+		}
+		limit = n
+	}
+
+	page, err := h.service.List(ctx, ListLinksRequest{
+		Limit:  limit,
+		Cursor: query.Get("cursor"),
+	})
+	if err != nil {
+		h.handleListError(ctx, w, err)
+		return
+	}
+
+	links := make([]LinkResponse, 0, len(page.Links))
+	for _, link := range page.Links {
+		links = append(links, toResponse(link, h.baseURL))
+	}
+
+	httpx.WriteJSON(w, http.StatusOK, ListLinksResponse{
+		Links: links,
+		Page: PageInfo{
+			Limit:      page.Limit,
+			NextCursor: page.NextCursor,
+			HasMore:    page.HasMore,
+		},
+	})
+}
+
+// GetLinksByURL handles GET requests for every link pointing at the
+// destination given in the url query parameter.
+func (h *Handler) GetLinksByURL(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Extract request ID for tracing
+	requestID := httpx.GetRequestID(ctx)
+
+	logger := h.logger.With("request_id", requestID)
+
+	rawURL := r.URL.Query().Get("url")
+	if rawURL == "" {
+		logger.WarnContext(ctx, "missing url query parameter")
+		httpx.WriteError(w, http.StatusBadRequest, "invalid_request", "url is required", nil)
+		return
+	}
+
+	found, err := h.service.GetByURL(ctx, rawURL)
+	if err != nil {
+		h.handleGetByURLError(ctx, w, err)
+		return
+	}
+
+	links := make([]LinkResponse, 0, len(found))
+	for _, link := range found {
+		links = append(links, toResponse(link, h.baseURL))
+	}
+
+	logger.InfoContext(ctx, "links looked up by url",
+		"principal", httpx.GetPrincipal(ctx),
+		"matches", len(links),
+	)
+
+	httpx.WriteJSON(w, http.StatusOK, LinksByURLResponse{
+		URL:   found[0].OriginalURL,
+		Links: links,
+	})
+}
+
+// GetSourceStats handles GET requests for the number of live links per
+// creation source.
+func (h *Handler) GetSourceStats(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	counts, err := h.service.CountBySource(ctx)
+	if err != nil {
+		h.handleStatsError(ctx, w, err, "failed to count links by source")
+		return
+	}
+
+	resp := SourceStatsResponse{Sources: make([]SourceStat, 0, len(counts))}
+	for _, c := range counts {
+		resp.Sources = append(resp.Sources, SourceStat{Source: c.Source, Links: c.Links})
+		resp.Total += c.Links
+	}
+
+	httpx.WriteJSON(w, http.StatusOK, resp)
+}
+
+// GetStats handles GET requests for aggregate stats grouped by the "group"
+// query parameter: "country" counts recorded clicks per country, "source"
+// is the same as GetSourceStats.
+func (h *Handler) GetStats(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	switch group := r.URL.Query().Get("group"); group {
+	case "source":
+		h.GetSourceStats(w, r)
+		return
+	case "country":
+	default:
+		httpx.WriteError(w, http.StatusBadRequest, "invalid_group",
+			fmt.Sprintf("group must be %q or %q", "country", "source"), nil)
+		return
+	}
+
+	counts, err := h.service.CountClicksByCountry(ctx)
+	if err != nil {
+		h.handleStatsError(ctx, w, err, "failed to count clicks by country")
+		return
+	}
+
+	resp := CountryStatsResponse{Countries: make([]CountryStat, 0, len(counts))}
+	for _, c := range counts {
+		resp.Countries = append(resp.Countries, CountryStat{Country: c.Country, Clicks: c.Clicks})
+		resp.Total += c.Clicks
+	}
+
+	httpx.WriteJSON(w, http.StatusOK, resp)
+}
+
+// GetLinkTimeSeries handles GET requests for a link's clicks bucketed over
+// time. It accepts optional bucket (hour or day) and RFC 3339 from and to
+// query parameters. Like GetLinkMetadata, it must only be routed behind
+// admin auth.
+func (h *Handler) GetLinkTimeSeries(w http.ResponseWriter, r *http.Request) {
+	slug := r.PathValue("slug")
+	if h.rejectOversizedSlug(w, slug) {
+		return
+	}
+
+	ctx := r.Context()
+
+	// Extract request ID for tracing
+	requestID := httpx.GetRequestID(ctx)
+
+	logger := h.logger.With("request_id", requestID)
+
+	query := r.URL.Query()
+
+	bucket, err := ParseTimeBucket(query.Get("bucket"))
+	if err != nil {
+		logger.WarnContext(ctx, "invalid bucket", "bucket", query.Get("bucket"))
+		httpx.WriteError(w, http.StatusBadRequest, "invalid_request", err.Error(), nil)
+		return
+	}
+
+	var from, to time.Time
+	for _, p := range []struct {
+		name string
+		dst  *time.Time
+	}{{"from", &from}, {"to", &to}} {
+		raw := query.Get(p.name)
+		if raw == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			logger.WarnContext(ctx, "invalid time range", p.name, raw)
+			httpx.WriteError(w, http.StatusBadRequest, "invalid_request",
+				p.name+" must be an RFC 3339 timestamp", nil)
+			return
+		}
+		*p.dst = t
+	}
+
+	series, err := h.service.TimeSeries(ctx, TimeSeriesRequest{
+		Slug:   slug,
+		Bucket: bucket,
+		From:   from,
+		To:     to,
+	})
+	if err != nil {
+		h.handleTimeSeriesError(ctx, w, err, slug)
+		return
+	}
+
+	points := make([]TimeSeriesPoint, 0, len(series.Buckets))
+	for _, b := range series.Buckets {
+		points = append(points, TimeSeriesPoint{
+			Start:  b.Start.Format(time.RFC3339),
+			Clicks: b.Clicks,
+		})
+	}
+
+	httpx.WriteJSON(w, http.StatusOK, TimeSeriesResponse{
+		Slug:   series.Slug,
+		Bucket: string(series.Bucket),
+		From:   series.From.Format(time.RFC3339),
+		To:     series.To.Format(time.RFC3339),
+		Total:  series.Total,
+		Points: points,
+	})
+}
+
+// Root handles GET requests for "/": a redirect to RootRedirectURL when
+// configured, otherwise a 404.
+func (h *Handler) Root(w http.ResponseWriter, r *http.Request) {
+	if h.rootRedirectURL != "" {
+		http.Redirect(w, r, h.rootRedirectURL, http.StatusFound)
+		return
+	}
+	httpx.WriteError(w, http.StatusNotFound, h.errorCodes.Code(errx.NotFound), "not found", nil)
+}
+
+// ResolveLink handles GET requests to resolve a slug and redirect to the original URL.
+// This increments the access count and updates tracking metadata.
+// Other methods, such as POST, are redirected only for links configured
+// with a method-preserving status (307 or 308); others answer 405.
+func (h *Handler) ResolveLink(w http.ResponseWriter, r *http.Request) {
+	if h.ignoredPaths[r.URL.Path] {
+		httpx.WriteError(w, http.StatusNotFound, h.errorCodes.Code(errx.NotFound), "not found", nil)
+		return
+	}
+
+	// Extract slug from URL path
+	slug := extractSlugFromPath(r.URL.Path)
+	if slug == "" {
+		h.Root(w, r)
+		return
+	}
+
+	var beacon beaconImage
+	isBeacon := false
+	if h.beacons {
+		slug, beacon, isBeacon = splitBeaconSuffix(slug)
+	}
+
+	if h.rejectOversizedSlug(w, slug) {
+		return
+	}
+
+	ctx := r.Context()
+
+	// Extract request ID for tracing
+	requestID := httpx.GetRequestID(ctx)
+
+	logger := h.logger.With("request_id", requestID)
+
+	if slug == "" {
+		logger.WarnContext(ctx, "missing slug in path")
+		httpx.WriteError(w, http.StatusBadRequest, "invalid_request", "slug is required", nil)
+		return
+	}
+
+	if err := validateSlugFormat(slug); err != nil {
+		logger.WarnContext(ctx, "invalid slug format",
+			"slug", slug,
+			"error", err.Error(),
+		)
+		httpx.WriteError(w, http.StatusBadRequest, "invalid_slug", err.Error(), nil)
+		return
+	}
+
+	// Refuse methods the link won't redirect before Resolve counts a visit
+	if !isBeacon && !isSafeMethod(r.Method) {
+		linkStatus, err := h.service.RedirectStatus(ctx, slug)
+		if status := h.effectiveRedirectStatus(linkStatus); err == nil && !PreservesMethod(status) {
+			h.writeMethodNotAllowed(ctx, w, logger, slug, r.Method, status)
+			return
+		}
+	}
+
+	ctx = WithVisitor(ctx, Visitor{IP: httpx.ClientIP(r), UserAgent: r.UserAgent()})
+
+	res, err := h.service.Resolve(ctx, slug)
+	if isBeacon && errx.KindOf(err) == errx.NotFound {
+		logger.WarnContext(ctx, "beacon for unknown slug", "slug", slug)
+		writeBeacon(w, beacon)
+		return
+	}
+	if err != nil {
+		h.handleResolveError(ctx, w, err, slug)
+		return
+	}
+
+	if isBeacon {
+		logger.InfoContext(ctx, "beacon served",
+			"slug", slug,
+			"user_agent", r.UserAgent(),
+			"referer", h.redactor.URL(r.Referer()),
+		)
+		writeBeacon(w, beacon)
+		return
+	}
+
+	status := h.effectiveRedirectStatus(res.RedirectStatus)
+
+	// The link may have changed since the check above
+	if !isSafeMethod(r.Method) && !PreservesMethod(status) {
+		h.writeMethodNotAllowed(ctx, w, logger, slug, r.Method, status)
+		return
+	}
+
+	logger.InfoContext(ctx, "slug resolved successfully",
+		"slug", slug,
+		"original_url", h.redactor.URL(res.URL),
+		"redirect_status", status,
+		"user_agent", r.UserAgent(),
+		"referer", h.redactor.URL(r.Referer()),
+	)
+
+	target := res.URL
+	if h.forwardQueryParams {
+		target = forwardQuery(res.URL, r.URL.RawQuery)
+	}
+
+	if res.Expired {
+		// Don't let caches outlive the grace window
+		logger.WarnContext(ctx, "expired link resolved within grace period", "slug", slug)
+		w.Header().Set(LinkExpiredHeader, "true")
+		w.Header().Set("Cache-Control", "no-store")
+	} else if cc := h.redirectCache[status]; cc != "" {
+		w.Header().Set("Cache-Control", cc)
+	}
+	if h.originalURIHeader != "" {
+		w.Header().Set(h.originalURIHeader, originalRequestURI(r))
+	}
+	http.Redirect(w, r, target, status)
+}
+
+// isSafeMethod reports whether method is one every redirect repeats.
+func isSafeMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead
+}
+
+// effectiveRedirectStatus is the status a link with the given override
+// redirects with.
+func (h *Handler) effectiveRedirectStatus(linkStatus int) int {
+	if linkStatus != 0 {
+		return linkStatus
+	}
+	return h.redirectStatus
+}
+
+// writeMethodNotAllowed refuses a request whose method a redirect with
+// status would not preserve.
+func (h *Handler) writeMethodNotAllowed(ctx context.Context, w http.ResponseWriter, logger *slog.Logger, slug, method string, status int) {
+	logger.WarnContext(ctx, "method not allowed for link",
+		"slug", slug,
+		"method", method,
+		"redirect_status", status,
+	)
+	w.Header().Set("Allow", "GET, HEAD")
+	httpx.WriteError(w, http.StatusMethodNotAllowed, "method_not_allowed",
+		"link only redirects GET and HEAD requests", nil)
+}
+
+// originalRequestURI returns the request URI as the client sent it, before
+// any middleware rewrote the path.
+func originalRequestURI(r *http.Request) string {
+	if r.RequestURI != "" {
+		return r.RequestURI
+	}
+	return r.URL.RequestURI()
+}
+
+// handleSlugAvailabilityError handles errors from the CheckSlugs service
+// method.
+func (h *Handler) handleSlugAvailabilityError(ctx context.Context, w http.ResponseWriter, err error) {
+	kind := h.errorKind(err)
+
+	logAttrs := []any{
+		"error", err.Error(),
+		"error_kind", kind,
+		"operation", errx.OpOf(err),
+	}
+
+	switch kind {
+	case errx.Invalid:
+		h.logger.WarnContext(ctx, "invalid slug availability request", logAttrs...)
+		httpx.WriteError(w, http.StatusBadRequest, "invalid_request", err.Error(), nil)
+
+	case errx.Unavailable, errx.Timeout:
+		h.logger.ErrorContext(ctx, "service unavailable", logAttrs...)
+		httpx.WriteError(w, http.StatusServiceUnavailable, h.errorCodes.Code(errx.Unavailable),
+			"Unable to check slugs at this time. Please try again.", nil)
+
+	default:
+		h.logger.ErrorContext(ctx, "unexpected error checking slugs", logAttrs...)
+		httpx.WriteError(w, http.StatusInternalServerError, h.errorCodes.Code(errx.Internal),
+			"Unable to check slugs at this time", nil)
+	}
+}
+
+// handleStatsError handles errors from the aggregate stats service methods.
+func (h *Handler) handleStatsError(ctx context.Context, w http.ResponseWriter, err error, msg string) {
+	kind := h.errorKind(err)
+	h.logger.ErrorContext(ctx, msg,
+		"error", err.Error(),
+		"error_kind", kind,
+		"operation", errx.OpOf(err),
+	)
+	status, code := http.StatusInternalServerError, h.errorCodes.Code(errx.Internal)
+	if kind == errx.Unavailable {
+		status, code = http.StatusServiceUnavailable, h.errorCodes.Code(errx.Unavailable)
+	}
+	httpx.WriteError(w, status, code, "Unable to load link stats at this time. Please try again.", nil)
+}
+
+// handleListError handles errors from the List service method.
+func (h *Handler) handleListError(ctx context.Context, w http.ResponseWriter, err error) {
+	kind := h.errorKind(err)
+
+	logAttrs := []any{
+		"error", err.Error(),
+		"error_kind", kind,
+		"operation", errx.OpOf(err),
+	}
+
+	switch kind {
+	case errx.Invalid:
+		h.logger.WarnContext(ctx, "invalid list request", logAttrs...)
+		httpx.WriteError(w, http.StatusBadRequest, "invalid_cursor", err.Error(), nil)
+
+	case errx.Unavailable, errx.Timeout:
+		h.logger.ErrorContext(ctx, "service unavailable", logAttrs...)
+		httpx.WriteError(w, http.StatusServiceUnavailable, h.errorCodes.Code(errx.Unavailable),
+			"Unable to list links at this time. Please try again.", nil)
+
+	default:
+		h.logger.ErrorContext(ctx, "unexpected error listing links", logAttrs...)
+		httpx.WriteError(w, http.StatusInternalServerError, h.errorCodes.Code(errx.Internal),
+			"Unable to list links at this time", nil)
+	}
+}
+
+// handleGetByURLError handles errors from the GetByURL service method.
+func (h *Handler) handleGetByURLError(ctx context.Context, w http.ResponseWriter, err error) {
+	kind := h.errorKind(err)
+
+	logAttrs := []any{
+		"error", err.Error(),
+		"error_kind", kind,
+		"operation", errx.OpOf(err),
+	}
+
+	switch kind {
+	case errx.NotFound:
+		h.logger.WarnContext(ctx, "no links for url", logAttrs...)
+		httpx.WriteError(w, http.StatusNotFound, h.errorCodes.Code(errx.NotFound),
+			"no short links point at this url", nil)
+
+	case errx.Invalid:
+		h.logger.WarnContext(ctx, "invalid url", logAttrs...)
+		httpx.WriteError(w, http.StatusBadRequest, "invalid_url", err.Error(), nil)
+
+	case errx.Unavailable, errx.Timeout:
+		h.logger.ErrorContext(ctx, "service unavailable", logAttrs...)
+		httpx.WriteError(w, http.StatusServiceUnavailable, h.errorCodes.Code(errx.Unavailable),
+			"Unable to look up links at this time. Please try again.", nil)
+
+	default:
+		h.logger.ErrorContext(ctx, "unexpected error looking up links", logAttrs...)
+		httpx.WriteError(w, http.StatusInternalServerError, h.errorCodes.Code(errx.Internal),
+			"Unable to look up links at this time", nil)
+	}
+}
+
+// handleTimeSeriesError handles errors from the TimeSeries service method.
+func (h *Handler) handleTimeSeriesError(ctx context.Context, w http.ResponseWriter, err error, slug string) {
+	kind := h.errorKind(err)
+
+	logAttrs := []any{
+		"error", err.Error(),
+		"error_kind", kind,
+		"operation", errx.OpOf(err),
+		"slug", slug,
+	}
+
+	switch kind {
+	case errx.NotFound:
+		h.logger.WarnContext(ctx, "slug not found", logAttrs...)
+		httpx.WriteError(w, http.StatusNotFound, h.errorCodes.Code(errx.NotFound),
+			"short link doesn't exist", nil)
+
+	case errx.Invalid:
+		h.logger.WarnContext(ctx, "invalid time series request", logAttrs...)
+		httpx.WriteError(w, http.StatusBadRequest, "invalid_request", err.Error(), nil)
+
+	case errx.Unavailable, errx.Timeout:
+		h.logger.ErrorContext(ctx, "service unavailable", logAttrs...)
+		httpx.WriteError(w, http.StatusServiceUnavailable, h.errorCodes.Code(errx.Unavailable),
+			"Unable to fetch the time series at this time. Please try again.", nil)
+
+	default:
+		h.logger.ErrorContext(ctx, "unexpected error fetching time series", logAttrs...)
+		httpx.WriteError(w, http.StatusInternalServerError, h.errorCodes.Code(errx.Internal),
+			"Unable to fetch the time series at this time", nil)
+	}
+}
+
+// rejectOversizedSlug writes an error and returns true when slug exceeds
+// MaxCustomSlugLength. It runs before any logging so pathological paths are turned
+// away without building log attributes or echoing the slug back.
+func (h *Handler) rejectOversizedSlug(w http.ResponseWriter, slug string) bool {
+	if len(slug) <= MaxCustomSlugLength {
+		return false
+	}
+
+	code := "invalid_slug"
+	if h.oversizedSlugStatus == http.StatusRequestURITooLong {
+		code = "uri_too_long"
+	}
+	httpx.WriteError(w, h.oversizedSlugStatus, code, "invalid link", nil)
+	return true
+}
+
+// errorKind returns the kind of a service error about to be written,
+// counting it in the configured ErrorCounts.
+func (h *Handler) errorKind(err error) errx.Kind {
+	kind := errx.KindOf(err)
+	if h.errorCounts != nil {
+		h.errorCounts.Add(kind)
+	}
+	return kind
+}
+
+// batchRowError maps a failed batch row to the error codes CreateLink uses.
+func (h *Handler) batchRowError(err error) *BatchRowError {
+	switch h.errorKind(err) {
+	case errx.Conflict:
+		return &BatchRowError{Code: h.errorCodes.Code(errx.Conflict), Message: err.Error()}
+	case errx.Invalid:
+		return &BatchRowError{Code: h.errorCodes.Code(errx.Invalid), Message: err.Error()}
+	case errx.Forbidden:
+		return &BatchRowError{Code: h.errorCodes.Code(errx.Forbidden), Message: err.Error()}
+	case errx.QuotaExceeded:
+		return &BatchRowError{Code: h.errorCodes.Code(errx.QuotaExceeded), Message: "This API key has reached its link limit"}
+	case errx.Unavailable, errx.Timeout:
+		return &BatchRowError{Code: h.errorCodes.Code(errx.Unavailable),
+			Message: "Unable to create short link at this time. Please try again."}
+	default:
+		return &BatchRowError{Code: h.errorCodes.Code(errx.Internal),
+			Message: "Unable to create short link at this time. Please try again."}
+	}
+}
+
+// handleCreateError handles errors from the Create service method.
+func (h *Handler) handleCreateError(ctx context.Context, w http.ResponseWriter, err error) {
+	kind := h.errorKind(err)
+
+	logAttrs := []any{
+		"error", err.Error(),
+		"error_kind", kind,
+		"operation", errx.OpOf(err),
+	}
+
+	switch kind {
+	case errx.Conflict:
+		h.logger.WarnContext(ctx, "slug conflict", logAttrs...)
+		details := map[string]any{
+			"hint": "Try a different custom slug or let us generate one for you",
+		}
+		var taken *SlugTakenError
+		if errors.As(err, &taken) && len(taken.Suggestions) > 0 {
+			details["suggestions"] = taken.Suggestions
+		}
+		httpx.WriteError(w, http.StatusConflict, h.errorCodes.Code(errx.Conflict),
+			"This slug is already taken", details)
+
+	case errx.Invalid:
+		h.logger.WarnContext(ctx, "invalid link request", logAttrs...)
+		httpx.WriteError(w, http.StatusBadRequest, h.errorCodes.Code(errx.Invalid), err.Error(), nil)
+
+	case errx.Forbidden:
+		h.logger.WarnContext(ctx, "create forbidden by policy", logAttrs...)
+		httpx.WriteError(w, http.StatusForbidden, h.errorCodes.Code(errx.Forbidden), err.Error(), nil)
+
+	case errx.QuotaExceeded:
+		h.logger.WarnContext(ctx, "link quota exceeded", logAttrs...)
+		httpx.WriteError(w, http.StatusForbidden, h.errorCodes.Code(errx.QuotaExceeded),
+			"This API key has reached its link limit",
+			map[string]string{
+				"hint": "Delete unused links or ask for a higher limit",
+			})
+
+	case errx.Unavailable, errx.Timeout:
+		h.logger.ErrorContext(ctx, "service unavailable", logAttrs...)
+		httpx.WriteError(w, http.StatusServiceUnavailable, h.errorCodes.Code(errx.Unavailable),
+			"Unable to create short link at this time. Please try again.", nil)
+
+	default:
+		h.logger.ErrorContext(ctx, "unexpected error creating link", logAttrs...)
+		httpx.WriteError(w, http.StatusInternalServerError, h.errorCodes.Code(errx.Internal),
+			"Unable to create short link at this time. Please try again.", nil)
+	}
+}
+
+// handleAliasError handles errors from the AddAlias service method.
+func (h *Handler) handleAliasError(ctx context.Context, w http.ResponseWriter, err error, slug string) {
+	kind := h.errorKind(err)
+
+	logAttrs := []any{
+		"error", err.Error(),
+		"error_kind", kind,
+		"operation", errx.OpOf(err),
+		"slug", slug,
+	}
+
+	switch kind {
+	case errx.NotFound:
+		h.logger.WarnContext(ctx, "slug not found", logAttrs...)
+		httpx.WriteError(w, http.StatusNotFound, h.errorCodes.Code(errx.NotFound),
+			"short link doesn't exist", nil)
+
+	case errx.Conflict:
+		h.logger.WarnContext(ctx, "alias conflict", logAttrs...)
+		httpx.WriteError(w, http.StatusConflict, h.errorCodes.Code(errx.Conflict),
+			"This alias is already taken", nil)
+
+	case errx.Invalid:
+		h.logger.WarnContext(ctx, "invalid alias", logAttrs...)
+		httpx.WriteError(w, http.StatusBadRequest, h.errorCodes.Code(errx.Invalid), err.Error(), nil)
+
+	case errx.Forbidden:
+		h.logger.WarnContext(ctx, "alias forbidden by policy", logAttrs...)
+		httpx.WriteError(w, http.StatusForbidden, h.errorCodes.Code(errx.Forbidden), err.Error(), nil)
+
+	case errx.Unavailable, errx.Timeout:
+		h.logger.ErrorContext(ctx, "service unavailable", logAttrs...)
+		httpx.WriteError(w, http.StatusServiceUnavailable, h.errorCodes.Code(errx.Unavailable),
+			"Unable to add this alias at this time. Please try again.", nil)
+
+	default:
+		h.logger.ErrorContext(ctx, "unexpected error adding alias", logAttrs...)
+		httpx.WriteError(w, http.StatusInternalServerError, h.errorCodes.Code(errx.Internal),
+			"Unable to add this alias at this time", nil)
+	}
+}
+
+// handleResolveError handles errors from the Resolve service method.
+func (h *Handler) handleResolveError(ctx context.Context, w http.ResponseWriter, err error, slug string) {
+	kind := h.errorKind(err)
+
+	logAttrs := []any{
+		"error", err.Error(),
+		"error_kind", kind,
+		"operation", errx.OpOf(err),
+		"slug", slug,
+	}
+
+	switch kind {
+	case errx.NotFound:
+		h.logger.WarnContext(ctx, "slug not found", logAttrs...)
+		if h.notFoundRedirectURL != "" {
+			w.Header().Set("Location", h.notFoundRedirectURL)
+			w.WriteHeader(http.StatusFound)
+			return
+		}
+		httpx.WriteError(w, http.StatusNotFound, h.errorCodes.Code(errx.NotFound),
+			"short link doesn't exist", nil)
+
+	case errx.Invalid:
+		h.logger.WarnContext(ctx, "invalid slug", logAttrs...)
+		httpx.WriteError(w, http.StatusBadRequest, "invalid_slug", err.Error(), nil)
+
+	default:
+		h.logger.ErrorContext(ctx, "unexpected error resolving link", logAttrs...)
+		httpx.WriteError(w, http.StatusInternalServerError, h.errorCodes.Code(errx.Internal),
+			"Unable to resolve this link at this time", nil)
+	}
+}
+
+// handleGetError handles errors from the GetBySlug service method.
+func (h *Handler) handleGetError(ctx context.Context, w http.ResponseWriter, err error, slug string) {
+	kind := h.errorKind(err)
+
+	logAttrs := []any{
+		"error", err.Error(),
+		"error_kind", kind,
+		"operation", errx.OpOf(err),
+		"slug", slug,
+	}
+
+	switch kind {
+	case errx.NotFound:
+		h.logger.WarnContext(ctx, "slug not found", logAttrs...)
+		httpx.WriteError(w, http.StatusNotFound, h.errorCodes.Code(errx.NotFound),
+			"short link doesn't exist", nil)
+
+	case errx.Invalid:
+		h.logger.WarnContext(ctx, "invalid slug", logAttrs...)
+		httpx.WriteError(w, http.StatusBadRequest, "invalid_slug", err.Error(), nil)
+
+	case errx.Unavailable, errx.Timeout:
+		h.logger.ErrorContext(ctx, "service unavailable", logAttrs...)
+		httpx.WriteError(w, http.StatusServiceUnavailable, h.errorCodes.Code(errx.Unavailable),
+			"Unable to fetch this link at this time. Please try again.", nil)
+
+	default:
+		h.logger.ErrorContext(ctx, "unexpected error fetching link", logAttrs...)
+		httpx.WriteError(w, http.StatusInternalServerError, h.errorCodes.Code(errx.Internal),
+			"Unable to fetch this link at this time", nil)
+	}
+}
+
+// handleGetByIDError handles errors from the GetByID service method.
+func (h *Handler) handleGetByIDError(ctx context.Context, w http.ResponseWriter, err error, id uuid.UUID) {
+	kind := h.errorKind(err)
+
+	logAttrs := []any{
+		"error", err.Error(),
+		"error_kind", kind,
+		"operation", errx.OpOf(err),
+		"id", id.String(),
+	}
+
+	switch kind {
+	case errx.NotFound:
+		h.logger.WarnContext(ctx, "link id not found", logAttrs...)
+		httpx.WriteError(w, http.StatusNotFound, h.errorCodes.Code(errx.NotFound),
+			"short link doesn't exist", nil)
+
+	case errx.Invalid:
+		h.logger.WarnContext(ctx, "invalid link id", logAttrs...)
+		httpx.WriteError(w, http.StatusBadRequest, "invalid_request", err.Error(), nil)
+
+	case errx.Unavailable, errx.Timeout:
+		h.logger.ErrorContext(ctx, "service unavailable", logAttrs...)
+		httpx.WriteError(w, http.StatusServiceUnavailable, h.errorCodes.Code(errx.Unavailable),
+			"Unable to fetch this link at this time. Please try again.", nil)
+
+	default:
+		h.logger.ErrorContext(ctx, "unexpected error fetching link by id", logAttrs...)
+		httpx.WriteError(w, http.StatusInternalServerError, h.errorCodes.Code(errx.Internal),
+			"Unable to fetch this link at this time", nil)
+	}
+}
+
+// toResponse maps a domain Link to its JSON representation.
+// Optional timestamps are omitted when unset.
+func toResponse(link Link, baseURL string) LinkResponse {
+	var utm *HTTPUTM
+	if link.UTM != (UTMParams{}) {
+		utm = &HTTPUTM{Source: link.UTM.Source, Medium: link.UTM.Medium, Campaign: link.UTM.Campaign}
+	}
+	return LinkResponse{
+		ID:                link.ID.String(),
+		Slug:              link.Slug,
+		OriginalURL:       link.OriginalURL,
+		ShortURL:          buildShortURL(baseURL, "", link.Slug),
+		AccessCount:       link.AccessCount,
+		UniqueAccessCount: link.UniqueAccessCount,
+		CreatedAt:         link.CreatedAt.Format(http.TimeFormat),
+		UpdatedAt:         link.UpdatedAt.Format(http.TimeFormat),
+		LastAccessedAt:    formatTimePtr(link.LastAccessedAt),
+		ExpiresAt:         formatTimePtr(link.ExpiresAt),
+		Source:            link.Source,
+		UTM:               utm,
+		RedirectStatus:    link.RedirectStatus,
+		Paused:            link.Paused,
+	}
+}
+
+// buildShortURL joins baseURL, an optional path prefix, and slug with
+// exactly one slash between each, whether or not baseURL ends in a slash
+// or carries a path of its own, e.g. "https://example.com/go/".
+func buildShortURL(baseURL, prefix, slug string) string {
+	parts := []string{strings.TrimRight(baseURL, "/")}
+	if prefix = strings.Trim(prefix, "/"); prefix != "" {
+		parts = append(parts, prefix)
+	}
+	return strings.Join(append(parts, slug), "/")
+}
+
+// formatTimePtr formats an optional timestamp, returning nil when unset.
+func formatTimePtr(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	s := t.Format(http.TimeFormat)
+	return &s
+}
+
+// validateCreateRequest checks the shape of an HTTPCreateLinkRequest and
+// returns every failing field in an *httpx.ValidationError. The service still applies
+// the full URL and slug rules; this only rejects what can never be valid.
+func validateCreateRequest(req HTTPCreateLinkRequest) error {
+	var errs httpx.FieldErrors
+
+	// The service trims surrounding whitespace, so check what it will see
+	rawURL := strings.TrimSpace(req.URL)
+	switch {
+	case rawURL == "":
+		errs.Add("url", "is required")
+	case len(rawURL) > MaxURLLength:
+		errs.Add("url", fmt.Sprintf("is too long (maximum %d characters)", MaxURLLength))
+	}
+
+	if slug := strings.TrimSpace(req.CustomSlug); slug == "" && req.CustomSlug != "" {
+		errs.Add("custom_slug", "must not be blank")
+	} else if slug != "" {
+		// The configured limit may be lower; the service enforces it.
+		if len(slug) > MaxCustomSlugLength {
+			errs.Add("custom_slug", fmt.Sprintf("is too long (maximum %d characters)", MaxCustomSlugLength))
+		}
+		// The most permissive charset; the configured one is enforced later.
+		if strings.IndexFunc(slug, func(c rune) bool {
+			return !AlphanumDashUnderscoreDot.allows(c)
+		}) >= 0 {
+			errs.Add("custom_slug", "may only contain letters, digits, dash, underscore, and dot")
+		}
+	}
+
+	if !validSource(req.Source) {
+		errs.Add("source", "must be one of: "+strings.Join(Sources, ", "))
+	}
+
+	return errs.Err()
 }
 
 // validateSlugFormat performs basic slug format validation for HTTP layer.
@@ -231,7 +1743,7 @@ func validateSlugFormat(slug string) error {
 		return errors.New("invalid link")
 	}
 
-	if len(slug) > MaxSlugLength {
+	if len(slug) > MaxCustomSlugLength {
 		return errors.New("invalid link")
 	}
 	return nil
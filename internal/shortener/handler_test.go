@@ -0,0 +1,2421 @@
+package shortener
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"image"
+	"image/gif"
+	"image/png"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/sundayezeilo/urlshortener/internal/clock"
+	"github.com/sundayezeilo/urlshortener/internal/errx"
+	"github.com/sundayezeilo/urlshortener/internal/httpx"
+)
+
+/***************
+ * Mocks
+ ***************/
+
+// mockService implements Service interface for handler testing.
+type mockService struct {
+	createFunc    func(ctx context.Context, req CreateLinkRequest) (Link, error)
+	batchFunc     func(ctx context.Context, reqs []CreateLinkRequest) ([]BatchResult, error)
+	getBySlugFunc func(ctx context.Context, slug string) (Link, error)
+	getByIDFunc   func(ctx context.Context, id uuid.UUID) (Link, error)
+	listFunc      func(ctx context.Context, req ListLinksRequest) (LinkPage, error)
+	getByURLFunc  func(ctx context.Context, rawURL string) ([]Link, error)
+	sourcesFunc   func(ctx context.Context) ([]SourceCount, error)
+	countriesFunc func(ctx context.Context) ([]CountryCount, error)
+	seriesFunc    func(ctx context.Context, req TimeSeriesRequest) (TimeSeries, error)
+	resolveFunc   func(ctx context.Context, slug string) (Resolution, error)
+	statusFunc    func(ctx context.Context, slug string) (int, error)
+	deleteFunc    func(ctx context.Context, slug string) error
+	aliasFunc     func(ctx context.Context, slug, alias string) (string, error)
+	slugsFunc     func(ctx context.Context, principal string, slugs []string) (map[string]SlugAvailability, error)
+	activeFunc    func(ctx context.Context, slug string, active bool) (Link, error)
+	metadataFunc  func(ctx context.Context, slug string) (LinkMetadata, error)
+}
+
+func (m *mockService) Create(ctx context.Context, req CreateLinkRequest) (Link, error) {
+	if m.createFunc != nil {
+		return m.createFunc(ctx, req)
+	}
+	return Link{}, errors.New("not implemented")
+}
+
+func (m *mockService) CreateBatch(ctx context.Context, reqs []CreateLinkRequest) ([]BatchResult, error) {
+	if m.batchFunc != nil {
+		return m.batchFunc(ctx, reqs)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *mockService) CountBySource(ctx context.Context) ([]SourceCount, error) {
+	if m.sourcesFunc != nil {
+		return m.sourcesFunc(ctx)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *mockService) CountClicksByCountry(ctx context.Context) ([]CountryCount, error) {
+	if m.countriesFunc != nil {
+		return m.countriesFunc(ctx)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *mockService) GetBySlug(ctx context.Context, slug string) (Link, error) {
+	if m.getBySlugFunc != nil {
+		return m.getBySlugFunc(ctx, slug)
+	}
+	return Link{}, errx.E("service.GetBySlug", errx.NotFound, errors.New("not found"))
+}
+
+func (m *mockService) GetByID(ctx context.Context, id uuid.UUID) (Link, error) {
+	if m.getByIDFunc != nil {
+		return m.getByIDFunc(ctx, id)
+	}
+	return Link{}, errx.E("service.GetByID", errx.NotFound, errors.New("not found"))
+}
+
+func (m *mockService) GetMetadata(ctx context.Context, slug string) (LinkMetadata, error) {
+	if m.metadataFunc != nil {
+		return m.metadataFunc(ctx, slug)
+	}
+	return LinkMetadata{}, errx.E("service.GetMetadata", errx.NotFound, errors.New("not found"))
+}
+
+func (m *mockService) List(ctx context.Context, req ListLinksRequest) (LinkPage, error) {
+	if m.listFunc != nil {
+		return m.listFunc(ctx, req)
+	}
+	return LinkPage{}, nil
+}
+
+func (m *mockService) GetByURL(ctx context.Context, rawURL string) ([]Link, error) {
+	if m.getByURLFunc != nil {
+		return m.getByURLFunc(ctx, rawURL)
+	}
+	return nil, errx.E("service.GetByURL", errx.NotFound, errors.New("not found"))
+}
+
+func (m *mockService) TimeSeries(ctx context.Context, req TimeSeriesRequest) (TimeSeries, error) {
+	if m.seriesFunc != nil {
+		return m.seriesFunc(ctx, req)
+	}
+	return TimeSeries{}, errx.E("service.TimeSeries", errx.NotFound, errors.New("not found"))
+}
+
+func (m *mockService) Resolve(ctx context.Context, slug string) (Resolution, error) {
+	if m.resolveFunc != nil {
+		return m.resolveFunc(ctx, slug)
+	}
+	return Resolution{}, errx.E("service.Resolve", errx.NotFound, errors.New("not found"))
+}
+
+func (m *mockService) RedirectStatus(ctx context.Context, slug string) (int, error) {
+	if m.statusFunc != nil {
+		return m.statusFunc(ctx, slug)
+	}
+	return 0, errx.E("service.RedirectStatus", errx.NotFound, errors.New("not found"))
+}
+
+func (m *mockService) AddAlias(ctx context.Context, slug, alias string) (string, error) {
+	if m.aliasFunc != nil {
+		return m.aliasFunc(ctx, slug, alias)
+	}
+	return alias, nil
+}
+
+func (m *mockService) CheckSlugs(ctx context.Context, principal string, slugs []string) (map[string]SlugAvailability, error) {
+	if m.slugsFunc != nil {
+		return m.slugsFunc(ctx, principal, slugs)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *mockService) RecommendedSlugLength(ctx context.Context) (int, error) {
+	return DefaultSlugLength, nil
+}
+
+func (m *mockService) CheckSlugGenerator(ctx context.Context) error {
+	return nil
+}
+
+func (m *mockService) SetActive(ctx context.Context, slug string, active bool) (Link, error) {
+	if m.activeFunc != nil {
+		return m.activeFunc(ctx, slug, active)
+	}
+	return Link{}, errx.E("service.SetActive", errx.NotFound, errors.New("not found"))
+}
+
+func (m *mockService) Delete(ctx context.Context, slug string) error {
+	if m.deleteFunc != nil {
+		return m.deleteFunc(ctx, slug)
+	}
+	return nil
+}
+
+/***************
+ * Helpers
+ ***************/
+
+const testBaseURL = "https://short.ly"
+
+func newTestHandler(svc Service) *Handler {
+	return NewHandler(HandlerConfig{
+		Service: svc,
+		Logger:  slog.New(slog.NewTextHandler(io.Discard, nil)),
+		BaseURL: testBaseURL,
+	})
+}
+
+func sampleLink() Link {
+	created := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	return Link{
+		ID:          uuid.MustParse("0194a9f0-0000-7000-8000-000000000001"),
+		OriginalURL: "https://example.com/page",
+		Slug:        "abc1234",
+		AccessCount: 5,
+		CreatedAt:   created,
+		UpdatedAt:   created.Add(time.Hour),
+	}
+}
+
+/***************
+ * Response Mapping Tests
+ ***************/
+
+func TestToResponse(t *testing.T) {
+	t.Run("maps all fields", func(t *testing.T) {
+		link := sampleLink()
+		accessed := link.CreatedAt.Add(2 * time.Hour)
+		link.LastAccessedAt = &accessed
+
+		resp := toResponse(link, testBaseURL)
+
+		if resp.ID != link.ID.String() {
+			t.Errorf("ID = %q, want %q", resp.ID, link.ID.String())
+		}
+		if resp.Slug != "abc1234" {
+			t.Errorf("Slug = %q, want %q", resp.Slug, "abc1234")
+		}
+		if resp.OriginalURL != "https://example.com/page" {
+			t.Errorf("OriginalURL = %q, want %q", resp.OriginalURL, "https://example.com/page")
+		}
+		if resp.ShortURL != "https://short.ly/abc1234" {
+			t.Errorf("ShortURL = %q, want %q", resp.ShortURL, "https://short.ly/abc1234")
+		}
+		if resp.AccessCount != 5 {
+			t.Errorf("AccessCount = %d, want 5", resp.AccessCount)
+		}
+		if resp.CreatedAt != "Thu, 02 Jan 2025 03:04:05 GMT" {
+			t.Errorf("CreatedAt = %q, want %q", resp.CreatedAt, "Thu, 02 Jan 2025 03:04:05 GMT")
+		}
+		if resp.UpdatedAt != "Thu, 02 Jan 2025 04:04:05 GMT" {
+			t.Errorf("UpdatedAt = %q, want %q", resp.UpdatedAt, "Thu, 02 Jan 2025 04:04:05 GMT")
+		}
+		if resp.LastAccessedAt == nil || *resp.LastAccessedAt != "Thu, 02 Jan 2025 05:04:05 GMT" {
+			t.Errorf("LastAccessedAt = %v, want %q", resp.LastAccessedAt, "Thu, 02 Jan 2025 05:04:05 GMT")
+		}
+	})
+
+	t.Run("omits unset optional times", func(t *testing.T) {
+		resp := toResponse(sampleLink(), testBaseURL)
+		if resp.LastAccessedAt != nil {
+			t.Errorf("LastAccessedAt = %q, want nil", *resp.LastAccessedAt)
+		}
+
+		body, err := json.Marshal(resp)
+		if err != nil {
+			t.Fatalf("failed to marshal response: %v", err)
+		}
+		var fields map[string]any
+		if err := json.Unmarshal(body, &fields); err != nil {
+			t.Fatalf("failed to unmarshal response: %v", err)
+		}
+		if _, ok := fields["last_accessed_at"]; ok {
+			t.Error("expected last_accessed_at to be omitted from JSON")
+		}
+	})
+}
+
+func TestBuildShortURL(t *testing.T) {
+	tests := []struct {
+		baseURL, prefix, slug string
+		want                  string
+	}{
+		{"https://short.ly", "", "abc1234", "https://short.ly/abc1234"},
+		{"https://short.ly/", "", "abc1234", "https://short.ly/abc1234"},
+		{"https://example.com/go", "", "abc1234", "https://example.com/go/abc1234"},
+		{"https://example.com/go/", "", "abc1234", "https://example.com/go/abc1234"},
+		{"https://short.ly", "s", "abc1234", "https://short.ly/s/abc1234"},
+		{"https://short.ly/", "/s/", "abc1234", "https://short.ly/s/abc1234"},
+		{"https://example.com/go/", "/links/s", "abc1234", "https://example.com/go/links/s/abc1234"},
+	}
+	for _, tt := range tests {
+		if got := buildShortURL(tt.baseURL, tt.prefix, tt.slug); got != tt.want {
+			t.Errorf("buildShortURL(%q, %q, %q) = %q, want %q", tt.baseURL, tt.prefix, tt.slug, got, tt.want)
+		}
+	}
+}
+
+/***************
+ * Handler Tests
+ ***************/
+
+func TestHandlerCreateLink_ReturnsFullRecord(t *testing.T) {
+	h := newTestHandler(&mockService{
+		createFunc: func(ctx context.Context, req CreateLinkRequest) (Link, error) {
+			return sampleLink(), nil
+		},
+	})
+
+	body, _ := json.Marshal(map[string]string{"url": "https://example.com/page"})
+	rr := httptest.NewRecorder()
+	h.CreateLink(rr, httptest.NewRequest(http.MethodPost, "/api/links", bytes.NewReader(body)))
+
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want %d; body: %s", rr.Code, http.StatusCreated, rr.Body.String())
+	}
+
+	var resp map[string]any
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	for _, field := range []string{"id", "slug", "original_url", "short_url", "access_count", "created_at", "updated_at"} {
+		if _, ok := resp[field]; !ok {
+			t.Errorf("expected field %q in response", field)
+		}
+	}
+}
+
+func TestHandlerCreateLink_LocationAndLinkHeaders(t *testing.T) {
+	svc := &mockService{
+		createFunc: func(ctx context.Context, req CreateLinkRequest) (Link, error) {
+			return sampleLink(), nil
+		},
+	}
+	create := func(h *Handler) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(map[string]string{"url": "https://example.com/page"})
+		rr := httptest.NewRecorder()
+		h.CreateLink(rr, httptest.NewRequest(http.MethodPost, "/api/links", bytes.NewReader(body)))
+		if rr.Code != http.StatusCreated {
+			t.Fatalf("status = %d, want %d; body: %s", rr.Code, http.StatusCreated, rr.Body.String())
+		}
+		return rr
+	}
+
+	t.Run("location only by default", func(t *testing.T) {
+		rr := create(newTestHandler(svc))
+
+		if got := rr.Header().Get("Location"); got != "/api/links/abc1234" {
+			t.Errorf("Location = %q, want /api/links/abc1234", got)
+		}
+		if got := rr.Header().Get("Link"); got != "" {
+			t.Errorf("Link = %q, want none", got)
+		}
+	})
+
+	t.Run("link header when enabled", func(t *testing.T) {
+		rr := create(NewHandler(HandlerConfig{
+			Service:         svc,
+			Logger:          slog.New(slog.NewTextHandler(io.Discard, nil)),
+			BaseURL:         testBaseURL,
+			ShortLinkHeader: true,
+		}))
+
+		if got := rr.Header().Get("Location"); got != "/api/links/abc1234" {
+			t.Errorf("Location = %q, want /api/links/abc1234", got)
+		}
+		if got, want := rr.Header().Get("Link"), `<https://short.ly/abc1234>; rel="shortlink"`; got != want {
+			t.Errorf("Link = %q, want %q", got, want)
+		}
+	})
+}
+
+func TestHandlerCreateLink_ShortURLIsWellFormed(t *testing.T) {
+	h := newTestHandler(&mockService{
+		createFunc: func(ctx context.Context, req CreateLinkRequest) (Link, error) {
+			return sampleLink(), nil
+		},
+	})
+
+	body, _ := json.Marshal(map[string]string{"url": "https://example.com/page"})
+	rr := httptest.NewRecorder()
+	h.CreateLink(rr, httptest.NewRequest(http.MethodPost, "/api/links", bytes.NewReader(body)))
+
+	var resp CreateLinkResponse
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	u, err := url.Parse(resp.ShortURL)
+	if err != nil {
+		t.Fatalf("short_url %q does not parse: %v", resp.ShortURL, err)
+	}
+	if !u.IsAbs() || u.Host != "short.ly" {
+		t.Errorf("short_url %q is not an absolute URL on the base host", resp.ShortURL)
+	}
+	if u.Path != "/"+sampleLink().Slug {
+		t.Errorf("short_url path = %q, want %q", u.Path, "/"+sampleLink().Slug)
+	}
+}
+
+func TestHandlerCreateLink_FieldErrors(t *testing.T) {
+	h := newTestHandler(&mockService{
+		createFunc: func(ctx context.Context, req CreateLinkRequest) (Link, error) {
+			t.Fatal("service should not be called for an invalid body")
+			return Link{}, nil
+		},
+	})
+
+	tests := []struct {
+		name       string
+		body       map[string]string
+		wantFields []string
+	}{
+		{
+			name:       "missing url and bad slug",
+			body:       map[string]string{"custom_slug": "has space!"},
+			wantFields: []string{"url", "custom_slug"},
+		},
+		{
+			name: "oversized url and slug",
+			body: map[string]string{
+				"url":         "https://example.com/" + strings.Repeat("a", MaxURLLength),
+				"custom_slug": strings.Repeat("b", MaxCustomSlugLength+1),
+			},
+			wantFields: []string{"url", "custom_slug"},
+		},
+		{
+			name: "slug both too long and invalid",
+			body: map[string]string{
+				"url":         "https://example.com",
+				"custom_slug": strings.Repeat("/", MaxCustomSlugLength+1),
+			},
+			wantFields: []string{"custom_slug", "custom_slug"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, _ := json.Marshal(tt.body)
+			rr := httptest.NewRecorder()
+			h.CreateLink(rr, httptest.NewRequest(http.MethodPost, "/api/links", bytes.NewReader(body)))
+
+			if rr.Code != http.StatusBadRequest {
+				t.Fatalf("status = %d, want %d; body: %s", rr.Code, http.StatusBadRequest, rr.Body.String())
+			}
+
+			var resp struct {
+				Error   string             `json:"error"`
+				Details []httpx.FieldError `json:"details"`
+			}
+			if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if resp.Error != "validation_failed" {
+				t.Errorf("error = %q, want %q", resp.Error, "validation_failed")
+			}
+			if len(resp.Details) != len(tt.wantFields) {
+				t.Fatalf("details = %+v, want fields %v", resp.Details, tt.wantFields)
+			}
+			for i, field := range tt.wantFields {
+				if resp.Details[i].Field != field || resp.Details[i].Message == "" {
+					t.Errorf("details[%d] = %+v, want a %q error", i, resp.Details[i], field)
+				}
+			}
+		})
+	}
+}
+
+func TestHandlerCreateLink_Namespaces(t *testing.T) {
+	h := newTestHandler(NewService(&mockRepository{}, &ServiceConfig{
+		SlugPrefixes: map[string]string{"acme": "acme"},
+	}))
+
+	create := func(principal string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(map[string]string{"url": "https://example.com", "custom_slug": "acme-summer-sale"})
+		req := httptest.NewRequest(http.MethodPost, "/api/links", bytes.NewReader(body))
+		if principal != "" {
+			req = req.WithContext(httpx.WithPrincipal(req.Context(), principal))
+		}
+		rr := httptest.NewRecorder()
+		h.CreateLink(rr, req)
+		return rr
+	}
+
+	if rr := create("acme"); rr.Code != http.StatusCreated {
+		t.Errorf("owner status = %d, want %d; body: %s", rr.Code, http.StatusCreated, rr.Body.String())
+	}
+	if rr := create(""); rr.Code != http.StatusForbidden {
+		t.Errorf("anonymous status = %d, want %d; body: %s", rr.Code, http.StatusForbidden, rr.Body.String())
+	}
+}
+
+func TestHandlerCreateLink_ConflictSuggestions(t *testing.T) {
+	existing := []string{"summer-sale", "summer-sale-2"}
+	h := newTestHandler(NewService(takenRepo(existing...), nil))
+
+	body, _ := json.Marshal(map[string]string{"url": "https://example.com", "custom_slug": "summer-sale"})
+	rr := httptest.NewRecorder()
+	h.CreateLink(rr, httptest.NewRequest(http.MethodPost, "/api/links", bytes.NewReader(body)))
+
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("status = %d, want %d; body: %s", rr.Code, http.StatusConflict, rr.Body.String())
+	}
+
+	var resp struct {
+		Details struct {
+			Suggestions []string `json:"suggestions"`
+		} `json:"details"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	got := resp.Details.Suggestions
+	if len(got) != DefaultSlugSuggestions {
+		t.Fatalf("suggestions = %v, want %d", got, DefaultSlugSuggestions)
+	}
+	validator := NewDefaultSlugValidator()
+	for _, slug := range got {
+		if err := validator.Validate(slug); err != nil {
+			t.Errorf("suggestion %q fails validation: %v", slug, err)
+		}
+		if slices.Contains(existing, slug) {
+			t.Errorf("suggestion %q is already taken", slug)
+		}
+	}
+}
+
+func TestHandlerCreateLinksBatch(t *testing.T) {
+	h := newTestHandler(NewService(&mockRepository{}, &ServiceConfig{
+		DuplicateSlugPolicy: DuplicateSlugSkip,
+	}))
+
+	body, _ := json.Marshal(map[string]any{"links": []map[string]string{
+		{"url": "https://example.com/a", "custom_slug": "summer-sale"},
+		{"url": "https://example.com/b", "custom_slug": "summer-sale"},
+		{"url": "ftp://example.com/c"},
+	}})
+	rr := httptest.NewRecorder()
+	h.CreateLinksBatch(rr, httptest.NewRequest(http.MethodPost, "/api/links/batch", bytes.NewReader(body)))
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d; body: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+
+	var resp CreateBatchResponse
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.Created != 1 || resp.Skipped != 1 || resp.Failed != 1 || len(resp.Results) != 3 {
+		t.Fatalf("response = %+v, want 1 created, 1 skipped, 1 failed", resp)
+	}
+	if r := resp.Results[0]; r.Status != "created" || r.Link == nil || r.Link.Slug != "summer-sale" {
+		t.Errorf("results[0] = %+v, want created summer-sale", r)
+	}
+	if r := resp.Results[1]; r.Status != "skipped" || r.Link != nil || r.Error != nil {
+		t.Errorf("results[1] = %+v, want skipped without link or error", r)
+	}
+	if r := resp.Results[2]; r.Status != "failed" || r.Error == nil || r.Error.Code != "invalid_input" {
+		t.Errorf("results[2] = %+v, want failed with invalid_input", r)
+	}
+}
+
+// stubReachability reports every destination in unreachable as down.
+type stubReachability struct {
+	unreachable map[string]bool
+}
+
+func (s stubReachability) Reachable(_ context.Context, rawURL string) bool {
+	return !s.unreachable[rawURL]
+}
+
+func TestHandlerCreateLinksBatch_Reachability(t *testing.T) {
+	h := newTestHandler(NewService(&mockRepository{}, &ServiceConfig{
+		ReachabilityChecker: stubReachability{unreachable: map[string]bool{"https://example.com/gone": true}},
+	}))
+
+	body := `{"links":[{"url":"https://example.com/a"},{"url":"https://example.com/gone"},{"url":"ftp://example.com/c"}]}`
+	rr := httptest.NewRecorder()
+	h.CreateLinksBatch(rr, httptest.NewRequest(http.MethodPost, "/api/links/batch", strings.NewReader(body)))
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d; body: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+
+	var resp CreateBatchResponse
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.Created != 2 || resp.Failed != 1 || resp.Unreachable != 1 {
+		t.Fatalf("response = %+v, want 2 created, 1 failed, 1 unreachable", resp)
+	}
+	if r := resp.Results[0]; r.Reachable == nil || !*r.Reachable {
+		t.Errorf("results[0].Reachable = %v, want true", r.Reachable)
+	}
+	if r := resp.Results[1]; r.Status != "created" || r.Reachable == nil || *r.Reachable {
+		t.Errorf("results[1] = %+v, want created and unreachable", r)
+	}
+	if r := resp.Results[2]; r.Reachable != nil {
+		t.Errorf("results[2].Reachable = %v, want unset for a failed row", *r.Reachable)
+	}
+}
+
+func TestHandlerCreateLinksBatch_EmptyIsBadRequest(t *testing.T) {
+	h := newTestHandler(NewService(&mockRepository{}, nil))
+
+	rr := httptest.NewRecorder()
+	h.CreateLinksBatch(rr, httptest.NewRequest(http.MethodPost, "/api/links/batch",
+		strings.NewReader(`{"links":[]}`)))
+
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d; body: %s", rr.Code, http.StatusBadRequest, rr.Body.String())
+	}
+}
+
+func TestHandlerCreateLinksBatch_Limits(t *testing.T) {
+	// batchBody returns a batch of n rows whose URLs are urlLen long.
+	batchBody := func(n, urlLen int) io.Reader {
+		links := make([]HTTPCreateLinkRequest, n)
+		for i := range links {
+			links[i].URL = "https://example.com/" + strings.Repeat("a", urlLen)
+		}
+		body, _ := json.Marshal(HTTPCreateBatchRequest{Links: links})
+		return bytes.NewReader(body)
+	}
+	newHandler := func(maxBatch int) *Handler {
+		return NewHandler(HandlerConfig{
+			Service:      NewService(&mockRepository{}, &ServiceConfig{MaxBatchSize: maxBatch}),
+			Logger:       slog.New(slog.NewTextHandler(io.Discard, nil)),
+			BaseURL:      testBaseURL,
+			MaxBatchSize: maxBatch,
+		})
+	}
+
+	tests := []struct {
+		name        string
+		maxBatch    int
+		body        io.Reader
+		wantStatus  int
+		wantMessage string
+	}{
+		{name: "at the limit", maxBatch: 2, body: batchBody(2, 10), wantStatus: http.StatusOK},
+		{name: "too many rows", maxBatch: 2, body: batchBody(3, 10), wantStatus: http.StatusBadRequest, wantMessage: "maximum 2"},
+		{name: "body over the row budget", maxBatch: 2, body: batchBody(2, 20<<10), wantStatus: http.StatusBadRequest, wantMessage: "max 32768 bytes"},
+		// Larger than the 1MB single-request limit, within 100 rows' budget.
+		{name: "default limit allows large batches", body: batchBody(90, 12<<10), wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			newHandler(tt.maxBatch).CreateLinksBatch(rr, httptest.NewRequest(http.MethodPost, "/api/links/batch", tt.body))
+
+			if rr.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d; body: %s", rr.Code, tt.wantStatus, rr.Body.String())
+			}
+			if tt.wantMessage == "" {
+				return
+			}
+			var resp httpx.ErrorResponse
+			if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if !strings.Contains(resp.Message, tt.wantMessage) {
+				t.Errorf("message = %q, want it to contain %q", resp.Message, tt.wantMessage)
+			}
+		})
+	}
+}
+
+func TestHandlerCheckSlugAvailability(t *testing.T) {
+	repo := NewMemoryRepository()
+	if _, err := repo.Create(context.Background(), Link{OriginalURL: "https://example.com", Slug: "spring-sale"}); err != nil {
+		t.Fatalf("Create() unexpected error: %v", err)
+	}
+	h := newTestHandler(NewService(repo, &ServiceConfig{MaxBatchSize: 3}))
+
+	check := func(body string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		h.CheckSlugAvailability(rr, httptest.NewRequest(http.MethodPost, "/api/slugs/availability", strings.NewReader(body)))
+		return rr
+	}
+
+	rr := check(`{"slugs":["spring-sale","autumn-sale","no"]}`)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d; body: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+
+	var resp SlugAvailabilityResponse
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	want := map[string]SlugAvailabilityEntry{
+		"spring-sale": {Slug: "spring-sale", Available: false, Valid: true, Status: "taken"},
+		"autumn-sale": {Slug: "autumn-sale", Available: true, Valid: true, Status: "available"},
+	}
+	if len(resp.Slugs) != 3 {
+		t.Fatalf("slugs = %+v, want 3 entries", resp.Slugs)
+	}
+	for slug, entry := range want {
+		if resp.Slugs[slug] != entry {
+			t.Errorf("slugs[%q] = %+v, want %+v", slug, resp.Slugs[slug], entry)
+		}
+	}
+	if e := resp.Slugs["no"]; e.Available || e.Valid || e.Status != "invalid" || e.Message == "" {
+		t.Errorf(`slugs["no"] = %+v, want invalid with a message`, e)
+	}
+
+	for _, body := range []string{`{"slugs":[]}`, `{"slugs":["a1234567","b1234567","c1234567","d1234567"]}`} {
+		if rr := check(body); rr.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want %d", body, rr.Code, http.StatusBadRequest)
+		}
+	}
+}
+
+func TestHandlerCaseInsensitiveSlugs(t *testing.T) {
+	repo := newMemoryRepo(clock.Real)
+	repo.caseInsensitive = true
+	h := newTestHandler(NewService(repo, &ServiceConfig{CaseInsensitiveSlugs: true}))
+
+	create := func(slug string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(map[string]string{"url": "https://example.com", "custom_slug": slug})
+		rr := httptest.NewRecorder()
+		h.CreateLink(rr, httptest.NewRequest(http.MethodPost, "/api/links", bytes.NewReader(body)))
+		return rr
+	}
+
+	rr := create("MyLink1")
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want %d; body: %s", rr.Code, http.StatusCreated, rr.Body.String())
+	}
+	var resp LinkResponse
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.Slug != "MyLink1" || !strings.HasSuffix(resp.ShortURL, "/MyLink1") {
+		t.Errorf("slug = %q, short_url = %q; want the created case preserved", resp.Slug, resp.ShortURL)
+	}
+
+	if rr := create("mylink1"); rr.Code != http.StatusConflict {
+		t.Errorf("case variant: status = %d, want %d", rr.Code, http.StatusConflict)
+	}
+
+	rr = httptest.NewRecorder()
+	h.ResolveLink(rr, httptest.NewRequest(http.MethodGet, "/MYLINK1", nil))
+	if rr.Code != http.StatusFound || rr.Header().Get("Location") != "https://example.com" {
+		t.Errorf("resolve: status = %d, Location = %q; want a redirect to the link", rr.Code, rr.Header().Get("Location"))
+	}
+	req := httptest.NewRequest(http.MethodPost, "/api/links/MyLink1/aliases", strings.NewReader(`{"alias":"mylink1"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.SetPathValue("slug", "MyLink1")
+	rr = httptest.NewRecorder()
+	h.AddLinkAlias(rr, req)
+	if rr.Code != http.StatusConflict {
+		t.Errorf("case variant alias: status = %d, want %d", rr.Code, http.StatusConflict)
+	}
+}
+
+func TestHandlerCreateLink_DefaultLinkTTL(t *testing.T) {
+	clk := clock.NewFake(time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC))
+
+	create := func(ttl time.Duration) LinkResponse {
+		t.Helper()
+		h := newTestHandler(NewService(newMemoryRepo(clk), &ServiceConfig{DefaultLinkTTL: ttl, Clock: clk}))
+		rr := httptest.NewRecorder()
+		h.CreateLink(rr, httptest.NewRequest(http.MethodPost, "/api/links", strings.NewReader(`{"url":"https://example.com"}`)))
+		if rr.Code != http.StatusCreated {
+			t.Fatalf("status = %d, want %d; body: %s", rr.Code, http.StatusCreated, rr.Body.String())
+		}
+		var resp LinkResponse
+		if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		return resp
+	}
+
+	resp := create(24 * time.Hour)
+	want := clk.Now().Add(24 * time.Hour).Format(http.TimeFormat)
+	if resp.ExpiresAt == nil || *resp.ExpiresAt != want {
+		t.Errorf("expires_at = %v, want %q", resp.ExpiresAt, want)
+	}
+
+	if resp := create(0); resp.ExpiresAt != nil {
+		t.Errorf("expires_at = %q, want none without a default TTL", *resp.ExpiresAt)
+	}
+}
+
+func TestHandlerCreateLink_Source(t *testing.T) {
+	h := newTestHandler(NewService(&mockRepository{}, nil))
+
+	create := func(source string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(map[string]string{"url": "https://example.com", "source": source})
+		rr := httptest.NewRecorder()
+		h.CreateLink(rr, httptest.NewRequest(http.MethodPost, "/api/links", bytes.NewReader(body)))
+		return rr
+	}
+
+	rr := create(SourceDashboard)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want %d; body: %s", rr.Code, http.StatusCreated, rr.Body.String())
+	}
+	var resp LinkResponse
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.Source != SourceDashboard {
+		t.Errorf("source = %q, want %q", resp.Source, SourceDashboard)
+	}
+
+	rr = create("carrier-pigeon")
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("invalid source status = %d, want %d; body: %s", rr.Code, http.StatusBadRequest, rr.Body.String())
+	}
+	var errResp struct {
+		Details []httpx.FieldError `json:"details"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&errResp); err != nil {
+		t.Fatalf("failed to decode error response: %v", err)
+	}
+	if len(errResp.Details) != 1 || errResp.Details[0].Field != "source" {
+		t.Errorf("details = %+v, want a source field error", errResp.Details)
+	}
+}
+
+func TestHandlerCreateLink_QuotaExceeded(t *testing.T) {
+	svc := &mockService{
+		createFunc: func(ctx context.Context, req CreateLinkRequest) (Link, error) {
+			return Link{}, errx.E("service.Create", errx.QuotaExceeded, errors.New("link limit of 3 reached"))
+		},
+	}
+	h := newTestHandler(svc)
+
+	body, _ := json.Marshal(map[string]string{"url": "https://example.com"})
+	rr := httptest.NewRecorder()
+	h.CreateLink(rr, httptest.NewRequest(http.MethodPost, "/api/links", bytes.NewReader(body)))
+
+	if rr.Code != http.StatusForbidden {
+		t.Fatalf("status = %d, want %d; body: %s", rr.Code, http.StatusForbidden, rr.Body.String())
+	}
+	var resp struct {
+		Error string `json:"error"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.Error != "quota_exceeded" {
+		t.Errorf("error = %q, want quota_exceeded", resp.Error)
+	}
+}
+
+func TestHandler_ErrorCodes(t *testing.T) {
+	h := NewHandler(HandlerConfig{
+		Service: &mockService{},
+		Logger:  slog.New(slog.NewTextHandler(io.Discard, nil)),
+		BaseURL: testBaseURL,
+		ErrorCodes: httpx.ErrorCodes{
+			errx.NotFound: "urlshortener.not_found",
+			errx.Invalid:  "urlshortener.invalid_input",
+		},
+	})
+
+	decodeCode := func(t *testing.T, rr *httptest.ResponseRecorder) string {
+		t.Helper()
+		var resp httpx.ErrorResponse
+		if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		return resp.Error
+	}
+
+	t.Run("overridden kind", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/api/links/missing-link", nil)
+		req.SetPathValue("slug", "missing-link")
+		h.GetLink(rr, req)
+
+		if rr.Code != http.StatusNotFound {
+			t.Fatalf("status = %d, want %d; body: %s", rr.Code, http.StatusNotFound, rr.Body.String())
+		}
+		if code := decodeCode(t, rr); code != "urlshortener.not_found" {
+			t.Errorf("error = %q, want urlshortener.not_found", code)
+		}
+	})
+
+	t.Run("default for unlisted kind", func(t *testing.T) {
+		h.service = &mockService{
+			createFunc: func(ctx context.Context, req CreateLinkRequest) (Link, error) {
+				return Link{}, errx.E("service.Create", errx.Conflict, errors.New("slug taken"))
+			},
+		}
+		body, _ := json.Marshal(map[string]string{"url": "https://example.com"})
+		rr := httptest.NewRecorder()
+		h.CreateLink(rr, httptest.NewRequest(http.MethodPost, "/api/links", bytes.NewReader(body)))
+
+		if code := decodeCode(t, rr); code != "conflict" {
+			t.Errorf("error = %q, want conflict", code)
+		}
+	})
+
+	t.Run("batch rows", func(t *testing.T) {
+		h.service = NewService(&mockRepository{}, &ServiceConfig{})
+		body, _ := json.Marshal(map[string]any{"links": []map[string]string{{"url": "ftp://example.com"}}})
+		rr := httptest.NewRecorder()
+		h.CreateLinksBatch(rr, httptest.NewRequest(http.MethodPost, "/api/links/batch", bytes.NewReader(body)))
+
+		var resp CreateBatchResponse
+		if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if len(resp.Results) != 1 || resp.Results[0].Error == nil || resp.Results[0].Error.Code != "urlshortener.invalid_input" {
+			t.Errorf("results = %+v, want one failed with urlshortener.invalid_input", resp.Results)
+		}
+	})
+}
+
+func TestHandler_ErrorCounts(t *testing.T) {
+	counts := &errx.Counts{}
+	h := NewHandler(HandlerConfig{
+		Service: &mockService{
+			createFunc: func(ctx context.Context, req CreateLinkRequest) (Link, error) {
+				return Link{}, errx.E("service.Create", errx.Conflict, errors.New("slug taken"))
+			},
+			getBySlugFunc: func(ctx context.Context, slug string) (Link, error) {
+				return Link{}, errx.E("service.GetBySlug", errx.Unavailable, errors.New("database is down"))
+			},
+		},
+		Logger:      slog.New(slog.NewTextHandler(io.Discard, nil)),
+		BaseURL:     testBaseURL,
+		ErrorCounts: counts,
+	})
+
+	for range 2 {
+		body, _ := json.Marshal(map[string]string{"url": "https://example.com"})
+		h.CreateLink(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/api/links", bytes.NewReader(body)))
+	}
+	req := httptest.NewRequest(http.MethodGet, "/api/links/summer-sale", nil)
+	req.SetPathValue("slug", "summer-sale")
+	h.GetLink(httptest.NewRecorder(), req)
+
+	if got := counts.Get(errx.Conflict); got != 2 {
+		t.Errorf("conflict count = %d, want 2", got)
+	}
+	if got := counts.Get(errx.Unavailable); got != 1 {
+		t.Errorf("unavailable count = %d, want 1", got)
+	}
+	if got := counts.Get(errx.NotFound); got != 0 {
+		t.Errorf("not found count = %d, want 0", got)
+	}
+}
+
+func TestHandlerCreateLink_IdempotentStatus(t *testing.T) {
+	tests := []struct {
+		name       string
+		result     Link
+		err        error
+		wantStatus int
+	}{
+		{"new link", Link{Slug: "summer-sale"}, nil, http.StatusCreated},
+		{"replayed link", Link{Slug: "summer-sale", Replayed: true}, nil, http.StatusOK},
+		{"conflict", Link{}, errx.E("service.Create", errx.Conflict, errors.New("slug taken")), http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &mockService{
+				createFunc: func(ctx context.Context, req CreateLinkRequest) (Link, error) {
+					return tt.result, tt.err
+				},
+			}
+			h := newTestHandler(svc)
+
+			body, _ := json.Marshal(map[string]string{"url": "https://example.com", "custom_slug": "summer-sale"})
+			rr := httptest.NewRecorder()
+			h.CreateLink(rr, httptest.NewRequest(http.MethodPost, "/api/links", bytes.NewReader(body)))
+
+			if rr.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d; body: %s", rr.Code, tt.wantStatus, rr.Body.String())
+			}
+			if tt.err == nil {
+				var resp LinkResponse
+				if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+					t.Fatalf("failed to decode response: %v", err)
+				}
+				if resp.Slug != "summer-sale" {
+					t.Errorf("Slug = %q, want summer-sale", resp.Slug)
+				}
+			}
+		})
+	}
+}
+
+func TestHandlerCreateLink_CreatorMetadata(t *testing.T) {
+	var got Creator
+	svc := &mockService{
+		createFunc: func(ctx context.Context, req CreateLinkRequest) (Link, error) {
+			got = req.Creator
+			return sampleLink(), nil
+		},
+	}
+	h := newTestHandler(svc)
+
+	body, _ := json.Marshal(map[string]string{"url": "https://example.com"})
+	req := httptest.NewRequest(http.MethodPost, "/api/links", bytes.NewReader(body))
+	req.RemoteAddr = "203.0.113.7:51234"
+	req.Header.Set("User-Agent", "curl/8.5.0")
+	rr := httptest.NewRecorder()
+	h.CreateLink(rr, req)
+
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want %d; body: %s", rr.Code, http.StatusCreated, rr.Body.String())
+	}
+	if want := (Creator{IP: "203.0.113.7", UserAgent: "curl/8.5.0"}); got != want {
+		t.Errorf("Creator = %+v, want %+v", got, want)
+	}
+	for _, field := range []string{"created_ip", "created_user_agent", "owner"} {
+		if strings.Contains(rr.Body.String(), field) {
+			t.Errorf("public response exposes %s: %s", field, rr.Body.String())
+		}
+	}
+}
+
+func TestHandlerCreateLink_UTM(t *testing.T) {
+	var got UTMParams
+	svc := &mockService{
+		createFunc: func(ctx context.Context, req CreateLinkRequest) (Link, error) {
+			got = req.UTM
+			link := sampleLink()
+			link.UTM = req.UTM
+			return link, nil
+		},
+	}
+	h := newTestHandler(svc)
+
+	body := `{"url":"https://example.com","utm":{"source":"newsletter","campaign":"fall"}}`
+	rr := httptest.NewRecorder()
+	h.CreateLink(rr, httptest.NewRequest(http.MethodPost, "/api/links", strings.NewReader(body)))
+
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want %d; body: %s", rr.Code, http.StatusCreated, rr.Body.String())
+	}
+	if want := (UTMParams{Source: "newsletter", Campaign: "fall"}); got != want {
+		t.Errorf("UTM = %+v, want %+v", got, want)
+	}
+	var resp LinkResponse
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.UTM == nil || resp.UTM.Source != "newsletter" || resp.UTM.Campaign != "fall" || resp.UTM.Medium != "" {
+		t.Errorf("response utm = %+v, want source and campaign", resp.UTM)
+	}
+}
+
+func TestHandlerGetLinkMetadata(t *testing.T) {
+	link := sampleLink()
+	link.Owner = "alice"
+	svc := &mockService{
+		metadataFunc: func(ctx context.Context, slug string) (LinkMetadata, error) {
+			return LinkMetadata{Link: link, Creator: &Creator{IP: "203.0.113.7", UserAgent: "curl/8.5.0"}}, nil
+		},
+	}
+	h := newTestHandler(svc)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/links/abc1234/metadata", nil)
+	req.SetPathValue("slug", "abc1234")
+	rr := httptest.NewRecorder()
+	h.GetLinkMetadata(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d; body: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+	var resp LinkMetadataResponse
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.Slug != link.Slug || resp.Owner != "alice" {
+		t.Errorf("response = %+v, want the link owned by alice", resp)
+	}
+	if resp.CreatedIP != "203.0.113.7" || resp.CreatedUserAgent != "curl/8.5.0" {
+		t.Errorf("creator = %q / %q, want the recorded client", resp.CreatedIP, resp.CreatedUserAgent)
+	}
+}
+
+func TestHandlerGetLinkByID(t *testing.T) {
+	link := sampleLink()
+	link.Owner = "alice"
+	h := newTestHandler(&mockService{
+		getByIDFunc: func(ctx context.Context, id uuid.UUID) (Link, error) {
+			if id != link.ID {
+				return Link{}, errx.E("service.GetByID", errx.NotFound, errors.New("not found"))
+			}
+			return link, nil
+		},
+	})
+
+	tests := []struct {
+		name       string
+		id         string
+		wantStatus int
+	}{
+		{"valid id", link.ID.String(), http.StatusOK},
+		{"missing link", uuid.NewString(), http.StatusNotFound},
+		{"malformed id", "abc1234", http.StatusBadRequest},
+		{"missing id", "", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/links/id/"+tt.id, nil)
+			req.SetPathValue("id", tt.id)
+			rr := httptest.NewRecorder()
+			h.GetLinkByID(rr, req)
+
+			if rr.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d; body: %s", rr.Code, tt.wantStatus, rr.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var resp LinkMetadataResponse
+			if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if resp.ID != link.ID.String() || resp.Slug != link.Slug || resp.Owner != "alice" {
+				t.Errorf("response = %+v, want the link owned by alice", resp)
+			}
+		})
+	}
+}
+
+func TestHandlerDeleteLink(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{name: "deleted", wantStatus: http.StatusNoContent},
+		{
+			name:       "unknown link",
+			err:        errx.E("service.Delete", errx.NotFound, errors.New("not found")),
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "database down",
+			err:        errx.E("service.Delete", errx.Unavailable, errors.New("db down")),
+			wantStatus: http.StatusServiceUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var deleted string
+			h := newTestHandler(&mockService{
+				deleteFunc: func(ctx context.Context, slug string) error {
+					deleted = slug
+					return tt.err
+				},
+			})
+
+			req := httptest.NewRequest(http.MethodDelete, "/api/links/abc1234", nil)
+			req.SetPathValue("slug", "abc1234")
+			rr := httptest.NewRecorder()
+			h.DeleteLink(rr, req)
+
+			if rr.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d; body: %s", rr.Code, tt.wantStatus, rr.Body.String())
+			}
+			if deleted != "abc1234" {
+				t.Errorf("deleted slug = %q, want abc1234", deleted)
+			}
+		})
+	}
+}
+
+func TestHandlerPauseResumeLink(t *testing.T) {
+	svc := &mockService{
+		activeFunc: func(ctx context.Context, slug string, active bool) (Link, error) {
+			if slug != "abc1234" {
+				return Link{}, errx.E("service.SetActive", errx.NotFound, errors.New("not found"))
+			}
+			link := sampleLink()
+			link.Paused = !active
+			return link, nil
+		},
+	}
+	h := newTestHandler(svc)
+
+	tests := []struct {
+		name       string
+		serve      http.HandlerFunc
+		slug       string
+		wantStatus int
+		wantPaused bool
+	}{
+		{"pause", h.PauseLink, "abc1234", http.StatusOK, true},
+		{"resume", h.ResumeLink, "abc1234", http.StatusOK, false},
+		{"unknown slug", h.PauseLink, "missing-link", http.StatusNotFound, false},
+		{"empty slug", h.ResumeLink, "", http.StatusBadRequest, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/links/x/pause", nil)
+			req.SetPathValue("slug", tt.slug)
+			rr := httptest.NewRecorder()
+			tt.serve(rr, req)
+
+			if rr.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d; body: %s", rr.Code, tt.wantStatus, rr.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var resp LinkResponse
+			if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if resp.Paused != tt.wantPaused || resp.AccessCount != 5 {
+				t.Errorf("response = %+v, want paused=%v with access_count kept", resp, tt.wantPaused)
+			}
+		})
+	}
+}
+
+func TestHandlerAddLinkAlias(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		err        error
+		wantStatus int
+	}{
+		{name: "added", body: `{"alias":"old-name"}`, wantStatus: http.StatusCreated},
+		{name: "malformed body", body: `{"alias":`, wantStatus: http.StatusBadRequest},
+		{
+			name:       "alias taken",
+			body:       `{"alias":"taken-name"}`,
+			err:        errx.E("service.AddAlias", errx.Conflict, errors.New("duplicate key")),
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name:       "unknown link",
+			body:       `{"alias":"old-name"}`,
+			err:        errx.E("service.AddAlias", errx.NotFound, errors.New("not found")),
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHandler(&mockService{
+				aliasFunc: func(ctx context.Context, slug, alias string) (string, error) {
+					if tt.err != nil {
+						return "", tt.err
+					}
+					return alias, nil
+				},
+			})
+
+			req := httptest.NewRequest(http.MethodPost, "/api/links/abc1234/aliases", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			req.SetPathValue("slug", "abc1234")
+			rr := httptest.NewRecorder()
+			h.AddLinkAlias(rr, req)
+
+			if rr.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d; body: %s", rr.Code, tt.wantStatus, rr.Body.String())
+			}
+			if tt.wantStatus != http.StatusCreated {
+				return
+			}
+			var resp AliasResponse
+			if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if resp.Slug != "abc1234" || resp.Alias != "old-name" || !strings.HasSuffix(resp.ShortURL, "/old-name") {
+				t.Errorf("response = %+v, want alias old-name of abc1234", resp)
+			}
+		})
+	}
+}
+
+func TestHandlerGetSourceStats(t *testing.T) {
+	h := newTestHandler(&mockService{
+		sourcesFunc: func(ctx context.Context) ([]SourceCount, error) {
+			return []SourceCount{{SourceAPI, 7}, {SourceImport, 3}, {"", 1}}, nil
+		},
+	})
+
+	rr := httptest.NewRecorder()
+	h.GetSourceStats(rr, httptest.NewRequest(http.MethodGet, "/api/stats/sources", nil))
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d; body: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+	var resp SourceStatsResponse
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	want := []SourceStat{{SourceAPI, 7}, {SourceImport, 3}, {"", 1}}
+	if !slices.Equal(resp.Sources, want) || resp.Total != 11 {
+		t.Errorf("response = %+v, want sources %v with total 11", resp, want)
+	}
+}
+
+func TestHandlerGetStats(t *testing.T) {
+	h := newTestHandler(&mockService{
+		countriesFunc: func(ctx context.Context) ([]CountryCount, error) {
+			return []CountryCount{{"DE", 7}, {"US", 3}, {"", 1}}, nil
+		},
+		sourcesFunc: func(ctx context.Context) ([]SourceCount, error) {
+			return []SourceCount{{SourceAPI, 2}}, nil
+		},
+	})
+
+	t.Run("group by country", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		h.GetStats(rr, httptest.NewRequest(http.MethodGet, "/api/stats?group=country", nil))
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("status = %d, want %d; body: %s", rr.Code, http.StatusOK, rr.Body.String())
+		}
+		var resp CountryStatsResponse
+		if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		want := []CountryStat{{"DE", 7}, {"US", 3}, {"", 1}}
+		if !slices.Equal(resp.Countries, want) || resp.Total != 11 {
+			t.Errorf("response = %+v, want countries %v with total 11", resp, want)
+		}
+	})
+
+	t.Run("group by source", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		h.GetStats(rr, httptest.NewRequest(http.MethodGet, "/api/stats?group=source", nil))
+
+		var resp SourceStatsResponse
+		if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if resp.Total != 2 {
+			t.Errorf("response = %+v, want source stats", resp)
+		}
+	})
+
+	for _, query := range []string{"", "?group=city"} {
+		t.Run("rejects group "+query, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			h.GetStats(rr, httptest.NewRequest(http.MethodGet, "/api/stats"+query, nil))
+
+			if rr.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want %d", rr.Code, http.StatusBadRequest)
+			}
+		})
+	}
+}
+
+func TestHandlerCreateLink_ShortCustomSlugIsBadRequest(t *testing.T) {
+	repo := &mockRepository{
+		createFunc: func(ctx context.Context, link Link) (Link, error) {
+			t.Fatal("repository should not be called for a short custom slug")
+			return Link{}, nil
+		},
+	}
+	h := newTestHandler(NewService(repo, nil))
+
+	body, _ := json.Marshal(map[string]string{"url": "https://example.com", "custom_slug": "abc"})
+	rr := httptest.NewRecorder()
+	h.CreateLink(rr, httptest.NewRequest(http.MethodPost, "/api/links", bytes.NewReader(body)))
+
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d; body: %s", rr.Code, http.StatusBadRequest, rr.Body.String())
+	}
+}
+
+func TestHandlerGetLink(t *testing.T) {
+	tests := []struct {
+		name       string
+		slug       string
+		getErr     error
+		wantStatus int
+	}{
+		{"existing link", "abc1234", nil, http.StatusOK},
+		{"missing link", "missing1", errx.E("service.GetBySlug", errx.NotFound, errors.New("not found")), http.StatusNotFound},
+		{"repository unavailable", "abc1234", errx.E("service.GetBySlug", errx.Unavailable, errors.New("db down")), http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotSlug string
+			h := newTestHandler(&mockService{
+				getBySlugFunc: func(ctx context.Context, slug string) (Link, error) {
+					gotSlug = slug
+					if tt.getErr != nil {
+						return Link{}, tt.getErr
+					}
+					return sampleLink(), nil
+				},
+			})
+
+			mux := http.NewServeMux()
+			mux.HandleFunc("GET /api/links/{slug}", h.GetLink)
+
+			rr := httptest.NewRecorder()
+			mux.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/links/"+tt.slug, nil))
+
+			if rr.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rr.Code, tt.wantStatus)
+			}
+			if gotSlug != tt.slug {
+				t.Errorf("service called with slug %q, want %q", gotSlug, tt.slug)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var resp LinkResponse
+			if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if resp != toResponse(sampleLink(), testBaseURL) {
+				t.Errorf("response = %+v, want %+v", resp, toResponse(sampleLink(), testBaseURL))
+			}
+		})
+	}
+}
+
+func TestHandlerResolveLink_OversizedSlug(t *testing.T) {
+	longSlug := strings.Repeat("a", 100_000)
+
+	tests := []struct {
+		name       string
+		status     int
+		wantStatus int
+		wantCode   string
+	}{
+		{"default is bad request", 0, http.StatusBadRequest, "invalid_slug"},
+		{"configured uri too long", http.StatusRequestURITooLong, http.StatusRequestURITooLong, "uri_too_long"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var logs bytes.Buffer
+			h := NewHandler(HandlerConfig{
+				Service: &mockService{
+					resolveFunc: func(ctx context.Context, slug string) (Resolution, error) {
+						t.Fatal("service should not be called for an oversized slug")
+						return Resolution{}, nil
+					},
+				},
+				Logger:              slog.New(slog.NewTextHandler(&logs, nil)),
+				BaseURL:             testBaseURL,
+				OversizedSlugStatus: tt.status,
+			})
+
+			rr := httptest.NewRecorder()
+			h.ResolveLink(rr, httptest.NewRequest(http.MethodGet, "/"+longSlug, nil))
+
+			if rr.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rr.Code, tt.wantStatus)
+			}
+			if strings.Contains(rr.Body.String(), longSlug) {
+				t.Error("response echoes the oversized slug")
+			}
+			var resp httpx.ErrorResponse
+			if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if resp.Error != tt.wantCode {
+				t.Errorf("error code = %q, want %q", resp.Error, tt.wantCode)
+			}
+			if logs.Len() != 0 {
+				t.Errorf("expected no log output, got %d bytes", logs.Len())
+			}
+		})
+	}
+}
+
+func TestHandlerGetLink_OversizedSlug(t *testing.T) {
+	h := newTestHandler(&mockService{
+		getBySlugFunc: func(ctx context.Context, slug string) (Link, error) {
+			t.Fatal("service should not be called for an oversized slug")
+			return Link{}, nil
+		},
+	})
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/links/{slug}", h.GetLink)
+
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/links/"+strings.Repeat("a", MaxCustomSlugLength+1), nil))
+
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d", rr.Code, http.StatusBadRequest)
+	}
+}
+
+func TestHandlerListLinks(t *testing.T) {
+	t.Run("wraps links with page metadata", func(t *testing.T) {
+		var gotReq ListLinksRequest
+		h := newTestHandler(&mockService{
+			listFunc: func(ctx context.Context, req ListLinksRequest) (LinkPage, error) {
+				gotReq = req
+				return LinkPage{
+					Links:      []Link{sampleLink()},
+					Limit:      1,
+					NextCursor: "next",
+					HasMore:    true,
+				}, nil
+			},
+		})
+
+		rr := httptest.NewRecorder()
+		h.ListLinks(rr, httptest.NewRequest(http.MethodGet, "/api/links?limit=1&cursor=prev", nil))
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("status = %d, want %d; body: %s", rr.Code, http.StatusOK, rr.Body.String())
+		}
+		if gotReq.Limit != 1 || gotReq.Cursor != "prev" {
+			t.Errorf("service request = %+v, want limit 1 and cursor %q", gotReq, "prev")
+		}
+
+		var resp ListLinksResponse
+		if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if len(resp.Links) != 1 || resp.Links[0] != toResponse(sampleLink(), testBaseURL) {
+			t.Errorf("links = %+v, want the sample link", resp.Links)
+		}
+		want := PageInfo{Limit: 1, NextCursor: "next", HasMore: true}
+		if resp.Page != want {
+			t.Errorf("page = %+v, want %+v", resp.Page, want)
+		}
+	})
+
+	t.Run("reports clamped limit", func(t *testing.T) {
+		repo := NewMemoryRepository()
+		for _, slug := range []string{"first12", "second1", "third12"} {
+			if _, err := repo.Create(context.Background(), Link{OriginalURL: "https://example.com", Slug: slug}); err != nil {
+				t.Fatalf("Create(%q) unexpected error: %v", slug, err)
+			}
+		}
+		h := newTestHandler(NewService(repo, &ServiceConfig{ListMaxLimit: 2}))
+
+		rr := httptest.NewRecorder()
+		h.ListLinks(rr, httptest.NewRequest(http.MethodGet, "/api/links?limit=500", nil))
+
+		var resp ListLinksResponse
+		if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if len(resp.Links) != 2 || resp.Page.Limit != 2 || !resp.Page.HasMore {
+			t.Errorf("got %d links with page %+v, want 2 links, limit 2 and more", len(resp.Links), resp.Page)
+		}
+	})
+
+	t.Run("empty page encodes links as array", func(t *testing.T) {
+		h := newTestHandler(&mockService{})
+
+		rr := httptest.NewRecorder()
+		h.ListLinks(rr, httptest.NewRequest(http.MethodGet, "/api/links", nil))
+
+		if !strings.Contains(rr.Body.String(), `"links":[]`) {
+			t.Errorf("body = %s, want empty links array", rr.Body.String())
+		}
+	})
+
+	tests := []struct {
+		name       string
+		query      string
+		listErr    error
+		wantStatus int
+	}{
+		{"non-numeric limit", "?limit=ten", nil, http.StatusBadRequest},
+		{"negative limit", "?limit=-1", nil, http.StatusBadRequest},
+		{"invalid cursor", "?cursor=bogus", errx.E("service.List", errx.Invalid, errors.New("invalid cursor")), http.StatusBadRequest},
+		{"repository unavailable", "", errx.E("service.List", errx.Unavailable, errors.New("db down")), http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHandler(&mockService{
+				listFunc: func(ctx context.Context, req ListLinksRequest) (LinkPage, error) {
+					return LinkPage{}, tt.listErr
+				},
+			})
+
+			rr := httptest.NewRecorder()
+			h.ListLinks(rr, httptest.NewRequest(http.MethodGet, "/api/links"+tt.query, nil))
+
+			if rr.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rr.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestHandlerGetLinksByURL(t *testing.T) {
+	t.Run("lists every slug for the url", func(t *testing.T) {
+		first, second := sampleLink(), sampleLink()
+		second.ID = uuid.MustParse("0194a9f0-0000-7000-8000-000000000002")
+		second.Slug = "xyz9876"
+
+		var gotURL string
+		h := newTestHandler(&mockService{
+			getByURLFunc: func(ctx context.Context, rawURL string) ([]Link, error) {
+				gotURL = rawURL
+				return []Link{first, second}, nil
+			},
+		})
+
+		target := "https://EXAMPLE.com/page"
+		rr := httptest.NewRecorder()
+		h.GetLinksByURL(rr, httptest.NewRequest(http.MethodGet,
+			"/api/links/by-url?url="+url.QueryEscape(target), nil))
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("status = %d, want %d; body: %s", rr.Code, http.StatusOK, rr.Body.String())
+		}
+		if gotURL != target {
+			t.Errorf("service url = %q, want %q", gotURL, target)
+		}
+
+		var resp LinksByURLResponse
+		if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if resp.URL != first.OriginalURL {
+			t.Errorf("url = %q, want normalized %q", resp.URL, first.OriginalURL)
+		}
+		if len(resp.Links) != 2 ||
+			resp.Links[0] != toResponse(first, testBaseURL) ||
+			resp.Links[1] != toResponse(second, testBaseURL) {
+			t.Errorf("links = %+v, want both sample links", resp.Links)
+		}
+	})
+
+	t.Run("no match is a JSON 404", func(t *testing.T) {
+		h := newTestHandler(&mockService{})
+
+		rr := httptest.NewRecorder()
+		h.GetLinksByURL(rr, httptest.NewRequest(http.MethodGet,
+			"/api/links/by-url?url="+url.QueryEscape("https://example.com/none"), nil))
+
+		if rr.Code != http.StatusNotFound {
+			t.Fatalf("status = %d, want %d", rr.Code, http.StatusNotFound)
+		}
+		var body httpx.ErrorResponse
+		if err := json.NewDecoder(rr.Body).Decode(&body); err != nil {
+			t.Fatalf("failed to decode error: %v", err)
+		}
+		if body.Error != "not_found" {
+			t.Errorf("error code = %q, want %q", body.Error, "not_found")
+		}
+	})
+
+	tests := []struct {
+		name       string
+		query      string
+		svcErr     error
+		wantStatus int
+	}{
+		{"missing url", "", nil, http.StatusBadRequest},
+		{"invalid url", "?url=ftp%3A%2F%2Fexample.com", errx.E("service.GetByURL", errx.Invalid, errors.New("url scheme must be http or https")), http.StatusBadRequest},
+		{"repository unavailable", "?url=https%3A%2F%2Fexample.com", errx.E("service.GetByURL", errx.Unavailable, errors.New("db down")), http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHandler(&mockService{
+				getByURLFunc: func(ctx context.Context, rawURL string) ([]Link, error) {
+					return nil, tt.svcErr
+				},
+			})
+
+			rr := httptest.NewRecorder()
+			h.GetLinksByURL(rr, httptest.NewRequest(http.MethodGet, "/api/links/by-url"+tt.query, nil))
+
+			if rr.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rr.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestHandlerResolveLink_ClickCarriesRequestID(t *testing.T) {
+	var got ClickEvent
+	repo := &mockRepository{
+		resolveAndTrackFunc: func(ctx context.Context, slug string) (Link, error) {
+			return Link{ID: uuid.New(), OriginalURL: "https://example.com"}, nil
+		},
+		recordClickFunc: func(ctx context.Context, click ClickEvent) error {
+			got = click
+			return nil
+		},
+	}
+	h := newTestHandler(NewService(repo, &ServiceConfig{
+		RecordClicks:          true,
+		RecordClickRequestIDs: true,
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/abc1234", nil)
+	req = req.WithContext(httpx.WithRequestID(req.Context(), "req-7f3a"))
+	rr := httptest.NewRecorder()
+	h.ResolveLink(rr, req)
+
+	if rr.Code != http.StatusFound {
+		t.Fatalf("status = %d, want %d; body: %s", rr.Code, http.StatusFound, rr.Body.String())
+	}
+	if got.RequestID != "req-7f3a" {
+		t.Errorf("recorded click request ID = %q, want %q", got.RequestID, "req-7f3a")
+	}
+}
+
+func TestHandlerResolveLink_Beacon(t *testing.T) {
+	tests := []struct {
+		path            string
+		wantContentType string
+		decode          func(io.Reader) (image.Image, error)
+	}{
+		{"/abc1234.gif", "image/gif", gif.Decode},
+		{"/abc1234.png", "image/png", png.Decode},
+		{"/abc1234.GIF", "image/gif", gif.Decode},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			var resolved []string
+			repo := &mockRepository{
+				resolveAndTrackFunc: func(ctx context.Context, slug string) (Link, error) {
+					resolved = append(resolved, slug)
+					return Link{ID: uuid.New(), Slug: slug, OriginalURL: "https://example.com"}, nil
+				},
+			}
+			h := NewHandler(HandlerConfig{
+				Service: NewService(repo, nil),
+				Logger:  slog.New(slog.NewTextHandler(io.Discard, nil)),
+				Beacons: true,
+			})
+
+			rr := httptest.NewRecorder()
+			h.ResolveLink(rr, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if rr.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d; body: %s", rr.Code, http.StatusOK, rr.Body.String())
+			}
+			if got := rr.Header().Get("Content-Type"); got != tt.wantContentType {
+				t.Errorf("Content-Type = %q, want %q", got, tt.wantContentType)
+			}
+			if got := rr.Header().Get("Location"); got != "" {
+				t.Errorf("Location = %q, want none", got)
+			}
+			img, err := tt.decode(rr.Body)
+			if err != nil {
+				t.Fatalf("failed to decode beacon image: %v", err)
+			}
+			if b := img.Bounds(); b.Dx() != 1 || b.Dy() != 1 {
+				t.Errorf("image size = %dx%d, want 1x1", b.Dx(), b.Dy())
+			}
+			if _, _, _, a := img.At(0, 0).RGBA(); a != 0 {
+				t.Errorf("pixel alpha = %d, want transparent", a)
+			}
+			if len(resolved) != 1 || resolved[0] != "abc1234" {
+				t.Errorf("resolved slugs = %q, want one click on abc1234", resolved)
+			}
+		})
+	}
+}
+
+func TestHandlerResolveLink_BeaconUnknownSlug(t *testing.T) {
+	h := NewHandler(HandlerConfig{
+		Service: NewService(&mockRepository{}, nil),
+		Logger:  slog.New(slog.NewTextHandler(io.Discard, nil)),
+		Beacons: true,
+	})
+
+	rr := httptest.NewRecorder()
+	h.ResolveLink(rr, httptest.NewRequest(http.MethodGet, "/missing.gif", nil))
+
+	if rr.Code != http.StatusOK || rr.Header().Get("Content-Type") != "image/gif" {
+		t.Errorf("response = %d %q, want 200 image/gif", rr.Code, rr.Header().Get("Content-Type"))
+	}
+}
+
+func TestHandlerResolveLink_IgnoredPathsOptOut(t *testing.T) {
+	var resolved string
+	h := NewHandler(HandlerConfig{
+		Service: &mockService{
+			resolveFunc: func(ctx context.Context, slug string) (Resolution, error) {
+				resolved = slug
+				return Resolution{URL: "https://example.com/icon"}, nil
+			},
+		},
+		Logger:       slog.New(slog.NewTextHandler(io.Discard, nil)),
+		IgnoredPaths: []string{},
+	})
+
+	rr := httptest.NewRecorder()
+	h.ResolveLink(rr, httptest.NewRequest(http.MethodGet, "/favicon.ico", nil))
+	if rr.Code != http.StatusFound || resolved != "favicon.ico" {
+		t.Errorf("status = %d, resolved %q; want favicon.ico resolved as a slug", rr.Code, resolved)
+	}
+}
+
+func TestHandlerResolveLink_BeaconsDisabled(t *testing.T) {
+	var resolved string
+	svc := &mockService{
+		resolveFunc: func(ctx context.Context, slug string) (Resolution, error) {
+			resolved = slug
+			return Resolution{URL: "https://example.com"}, nil
+		},
+	}
+	h := newTestHandler(svc)
+
+	rr := httptest.NewRecorder()
+	h.ResolveLink(rr, httptest.NewRequest(http.MethodGet, "/abc1234.gif", nil))
+
+	if rr.Code != http.StatusFound {
+		t.Errorf("status = %d, want %d", rr.Code, http.StatusFound)
+	}
+	if resolved != "abc1234.gif" {
+		t.Errorf("resolved slug = %q, want the suffix kept", resolved)
+	}
+}
+
+func TestHandlerResolveLink_ForwardQueryParams(t *testing.T) {
+	tests := []struct {
+		name         string
+		forward      bool
+		destination  string
+		target       string
+		wantLocation string
+	}{
+		{
+			name:         "forwards incoming params",
+			forward:      true,
+			destination:  "https://example.com/landing",
+			target:       "/promo123?utm_source=x&utm_medium=email",
+			wantLocation: "https://example.com/landing?utm_medium=email&utm_source=x",
+		},
+		{
+			name:         "appends to stored params",
+			forward:      true,
+			destination:  "https://example.com/landing?ref=abc#top",
+			target:       "/promo123?utm_source=x",
+			wantLocation: "https://example.com/landing?ref=abc&utm_source=x#top",
+		},
+		{
+			name:         "stored params take precedence",
+			forward:      true,
+			destination:  "https://example.com/landing?ref=partner&utm_source=site",
+			target:       "/promo123?ref=attacker&utm_source=x&utm_campaign=fall",
+			wantLocation: "https://example.com/landing?ref=partner&utm_source=site&utm_campaign=fall",
+		},
+		{
+			name:         "no query leaves destination untouched",
+			forward:      true,
+			destination:  "https://example.com/landing?ref=abc",
+			target:       "/promo123",
+			wantLocation: "https://example.com/landing?ref=abc",
+		},
+		{
+			name:         "disabled drops incoming params",
+			forward:      false,
+			destination:  "https://example.com/landing",
+			target:       "/promo123?utm_source=x",
+			wantLocation: "https://example.com/landing",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHandler(HandlerConfig{
+				Service: &mockService{
+					resolveFunc: func(ctx context.Context, slug string) (Resolution, error) {
+						return Resolution{URL: tt.destination}, nil
+					},
+				},
+				Logger:             slog.New(slog.NewTextHandler(io.Discard, nil)),
+				BaseURL:            "https://short.ly",
+				ForwardQueryParams: tt.forward,
+			})
+
+			rr := httptest.NewRecorder()
+			h.ResolveLink(rr, httptest.NewRequest(http.MethodGet, tt.target, nil))
+
+			if rr.Code != http.StatusFound {
+				t.Fatalf("status = %d, want %d", rr.Code, http.StatusFound)
+			}
+			if got := rr.Header().Get("Location"); got != tt.wantLocation {
+				t.Errorf("Location = %q, want %q", got, tt.wantLocation)
+			}
+		})
+	}
+}
+
+func TestHandlerResolveLink_ExpiryGrace(t *testing.T) {
+	ctx := context.Background()
+	clk := clock.NewFake(time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC))
+	repo := newMemoryRepo(clk)
+	if _, err := repo.Create(ctx, Link{OriginalURL: "https://example.com/old", Slug: "migrate1"}); err != nil {
+		t.Fatalf("Create() unexpected error: %v", err)
+	}
+	expiresAt := clk.Now().Add(time.Hour)
+	repo.links[repo.slugs["migrate1"]].ExpiresAt = &expiresAt
+	h := newTestHandler(NewService(repo, &ServiceConfig{ExpiryGrace: 24 * time.Hour, Clock: clk}))
+
+	resolve := func() *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		h.ResolveLink(rr, httptest.NewRequest(http.MethodGet, "/migrate1", nil))
+		return rr
+	}
+
+	rr := resolve()
+	if rr.Code != http.StatusFound || rr.Header().Get(LinkExpiredHeader) != "" {
+		t.Fatalf("before expiry: status = %d, %s = %q; want %d without the header",
+			rr.Code, LinkExpiredHeader, rr.Header().Get(LinkExpiredHeader), http.StatusFound)
+	}
+
+	clk.Advance(2 * time.Hour)
+	rr = resolve()
+	if rr.Code != http.StatusFound || rr.Header().Get("Location") != "https://example.com/old" {
+		t.Fatalf("within grace: status = %d, Location = %q; want a redirect to the link", rr.Code, rr.Header().Get("Location"))
+	}
+	if got := rr.Header().Get(LinkExpiredHeader); got != "true" {
+		t.Errorf("within grace: %s = %q, want true", LinkExpiredHeader, got)
+	}
+	if got := rr.Header().Get("Cache-Control"); got != "no-store" {
+		t.Errorf("within grace: Cache-Control = %q, want no-store", got)
+	}
+
+	clk.Advance(24 * time.Hour)
+	if rr = resolve(); rr.Code != http.StatusNotFound {
+		t.Errorf("after grace: status = %d, want %d", rr.Code, http.StatusNotFound)
+	}
+}
+
+func TestHandlerResolveLink_OriginalURIHeader(t *testing.T) {
+	const header = "X-Original-Request-URI"
+
+	tests := []struct {
+		name   string
+		header string
+		want   string
+	}{
+		{"enabled", header, "/promo123?utm_source=x&ref=a%20b"},
+		{"disabled", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHandler(HandlerConfig{
+				Service: &mockService{
+					resolveFunc: func(ctx context.Context, slug string) (Resolution, error) {
+						return Resolution{URL: "https://example.com/landing"}, nil
+					},
+				},
+				Logger:            slog.New(slog.NewTextHandler(io.Discard, nil)),
+				BaseURL:           "https://short.ly",
+				OriginalURIHeader: tt.header,
+			})
+
+			rr := httptest.NewRecorder()
+			h.ResolveLink(rr, httptest.NewRequest(http.MethodGet, "/promo123?utm_source=x&ref=a%20b", nil))
+
+			if rr.Code != http.StatusFound {
+				t.Fatalf("status = %d, want %d", rr.Code, http.StatusFound)
+			}
+			if got := rr.Header().Get(header); got != tt.want {
+				t.Errorf("%s = %q, want %q", header, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandlerResolveLink_CacheControl(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		overrides map[int]string
+		want      string
+	}{
+		{"default is 302 no-cache", 0, nil, "no-cache"},
+		{"301 cached for a day", http.StatusMovedPermanently, nil, "public, max-age=86400"},
+		{"307 no-cache", http.StatusTemporaryRedirect, nil, "no-cache"},
+		{"308 cached for a day", http.StatusPermanentRedirect, nil, "public, max-age=86400"},
+		{"configured 301", http.StatusMovedPermanently, map[int]string{301: "public, max-age=3600"}, "public, max-age=3600"},
+		{"configured 302", http.StatusFound, map[int]string{302: "private, max-age=60"}, "private, max-age=60"},
+		{"override for another status", http.StatusFound, map[int]string{301: "public, max-age=3600"}, "no-cache"},
+		{"empty value sends none", http.StatusFound, map[int]string{302: ""}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHandler(HandlerConfig{
+				Service: &mockService{resolveFunc: func(ctx context.Context, slug string) (Resolution, error) {
+					return Resolution{URL: "https://example.com"}, nil
+				}},
+				Logger:               slog.New(slog.NewTextHandler(io.Discard, nil)),
+				RedirectStatus:       tt.status,
+				RedirectCacheControl: tt.overrides,
+			})
+
+			rr := httptest.NewRecorder()
+			h.ResolveLink(rr, httptest.NewRequest(http.MethodGet, "/abc1234", nil))
+
+			wantStatus := tt.status
+			if wantStatus == 0 {
+				wantStatus = http.StatusFound
+			}
+			if rr.Code != wantStatus {
+				t.Errorf("status = %d, want %d", rr.Code, wantStatus)
+			}
+			if got := rr.Header().Get("Cache-Control"); got != tt.want {
+				t.Errorf("Cache-Control = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandlerResolveLink_PerLinkRedirectStatus(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		linkStatus int
+		wantStatus int
+	}{
+		{"link 308 on GET", http.MethodGet, http.StatusPermanentRedirect, http.StatusPermanentRedirect},
+		{"link 308 on POST", http.MethodPost, http.StatusPermanentRedirect, http.StatusPermanentRedirect},
+		{"link 307 on POST", http.MethodPost, http.StatusTemporaryRedirect, http.StatusTemporaryRedirect},
+		{"link 301 on GET", http.MethodGet, http.StatusMovedPermanently, http.StatusMovedPermanently},
+		{"server default on GET", http.MethodGet, 0, http.StatusFound},
+		{"server default on POST", http.MethodPost, 0, http.StatusMethodNotAllowed},
+		{"link 301 on POST", http.MethodPost, http.StatusMovedPermanently, http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHandler(&mockService{
+				resolveFunc: func(ctx context.Context, slug string) (Resolution, error) {
+					return Resolution{URL: "https://api.example.com/hook", RedirectStatus: tt.linkStatus}, nil
+				},
+			})
+
+			rr := httptest.NewRecorder()
+			h.ResolveLink(rr, httptest.NewRequest(tt.method, "/abc1234", strings.NewReader(`{"event":"ping"}`)))
+
+			if rr.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d; body: %s", rr.Code, tt.wantStatus, rr.Body.String())
+			}
+			if tt.wantStatus == http.StatusMethodNotAllowed {
+				if got := rr.Header().Get("Allow"); got != "GET, HEAD" {
+					t.Errorf("Allow = %q, want %q", got, "GET, HEAD")
+				}
+				if got := rr.Header().Get("Location"); got != "" {
+					t.Errorf("Location = %q, want none", got)
+				}
+				return
+			}
+			if got := rr.Header().Get("Location"); got != "https://api.example.com/hook" {
+				t.Errorf("Location = %q, want https://api.example.com/hook", got)
+			}
+			if got, want := rr.Header().Get("Cache-Control"), DefaultRedirectCacheControl[tt.wantStatus]; got != want {
+				t.Errorf("Cache-Control = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestHandlerResolveLink_MethodNotAllowedNotCounted(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryRepository()
+	link, err := repo.Create(ctx, Link{OriginalURL: "https://example.com", Slug: "webhook1"})
+	if err != nil {
+		t.Fatalf("Create() unexpected error: %v", err)
+	}
+	if err := repo.AddAlias(ctx, link.ID, "webhook2"); err != nil {
+		t.Fatalf("AddAlias() unexpected error: %v", err)
+	}
+	h := newTestHandler(NewService(repo, nil))
+
+	for _, path := range []string{"/webhook1", "/webhook2"} {
+		rr := httptest.NewRecorder()
+		h.ResolveLink(rr, httptest.NewRequest(http.MethodPost, path, strings.NewReader(`{"event":"ping"}`)))
+		if rr.Code != http.StatusMethodNotAllowed {
+			t.Fatalf("POST %s: status = %d, want %d", path, rr.Code, http.StatusMethodNotAllowed)
+		}
+	}
+
+	got, err := repo.GetBySlug(ctx, "webhook1")
+	if err != nil {
+		t.Fatalf("GetBySlug() unexpected error: %v", err)
+	}
+	if got.AccessCount != 0 {
+		t.Errorf("access count = %d after refused requests, want 0", got.AccessCount)
+	}
+}
+
+func TestHandlerCreateLink_RedirectStatus(t *testing.T) {
+	var got int
+	svc := &mockService{
+		createFunc: func(ctx context.Context, req CreateLinkRequest) (Link, error) {
+			got = req.RedirectStatus
+			link := sampleLink()
+			link.RedirectStatus = req.RedirectStatus
+			return link, nil
+		},
+	}
+	h := newTestHandler(svc)
+
+	rr := httptest.NewRecorder()
+	h.CreateLink(rr, httptest.NewRequest(http.MethodPost, "/api/links",
+		strings.NewReader(`{"url":"https://api.example.com/hook","redirect_status":308}`)))
+
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want %d; body: %s", rr.Code, http.StatusCreated, rr.Body.String())
+	}
+	if got != http.StatusPermanentRedirect {
+		t.Errorf("RedirectStatus = %d, want 308", got)
+	}
+	var resp LinkResponse
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.RedirectStatus != http.StatusPermanentRedirect {
+		t.Errorf("response redirect_status = %d, want 308", resp.RedirectStatus)
+	}
+}
+
+func TestCacheControlMaxAge(t *testing.T) {
+	tests := []struct {
+		maxAge time.Duration
+		want   string
+	}{
+		{0, "no-cache"},
+		{-time.Second, "no-cache"},
+		{90 * time.Second, "public, max-age=90"},
+		{24 * time.Hour, "public, max-age=86400"},
+	}
+
+	for _, tt := range tests {
+		if got := CacheControlMaxAge(tt.maxAge); got != tt.want {
+			t.Errorf("CacheControlMaxAge(%v) = %q, want %q", tt.maxAge, got, tt.want)
+		}
+	}
+}
+
+func TestHandlerGetLinkTimeSeries(t *testing.T) {
+	t.Run("returns bucketed points", func(t *testing.T) {
+		from := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+		var gotReq TimeSeriesRequest
+		h := newTestHandler(&mockService{
+			seriesFunc: func(ctx context.Context, req TimeSeriesRequest) (TimeSeries, error) {
+				gotReq = req
+				return TimeSeries{
+					Slug:   req.Slug,
+					Bucket: BucketHour,
+					From:   from,
+					To:     from.Add(2 * time.Hour),
+					Buckets: []ClickBucket{
+						{Start: from, Clicks: 3},
+						{Start: from.Add(time.Hour), Clicks: 0},
+					},
+					Total: 3,
+				}, nil
+			},
+		})
+
+		req := httptest.NewRequest(http.MethodGet,
+			"/api/links/abc1234/timeseries?bucket=hour&from=2025-03-01T00:00:00Z&to=2025-03-01T02:00:00Z", nil)
+		req.SetPathValue("slug", "abc1234")
+		rr := httptest.NewRecorder()
+		h.GetLinkTimeSeries(rr, req)
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("status = %d, want %d; body: %s", rr.Code, http.StatusOK, rr.Body.String())
+		}
+		if gotReq.Slug != "abc1234" || gotReq.Bucket != BucketHour ||
+			!gotReq.From.Equal(from) || !gotReq.To.Equal(from.Add(2*time.Hour)) {
+			t.Errorf("service request = %+v", gotReq)
+		}
+
+		var resp TimeSeriesResponse
+		if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		want := TimeSeriesResponse{
+			Slug:   "abc1234",
+			Bucket: "hour",
+			From:   "2025-03-01T00:00:00Z",
+			To:     "2025-03-01T02:00:00Z",
+			Total:  3,
+			Points: []TimeSeriesPoint{
+				{Start: "2025-03-01T00:00:00Z", Clicks: 3},
+				{Start: "2025-03-01T01:00:00Z", Clicks: 0},
+			},
+		}
+		if resp.Slug != want.Slug || resp.Bucket != want.Bucket || resp.From != want.From ||
+			resp.To != want.To || resp.Total != want.Total || len(resp.Points) != len(want.Points) {
+			t.Fatalf("response = %+v, want %+v", resp, want)
+		}
+		for i := range want.Points {
+			if resp.Points[i] != want.Points[i] {
+				t.Errorf("points[%d] = %+v, want %+v", i, resp.Points[i], want.Points[i])
+			}
+		}
+	})
+
+	tests := []struct {
+		name       string
+		query      string
+		svcErr     error
+		wantStatus int
+	}{
+		{"unknown bucket", "?bucket=week", nil, http.StatusBadRequest},
+		{"malformed from", "?from=yesterday", nil, http.StatusBadRequest},
+		{"malformed to", "?to=2025-03-01", nil, http.StatusBadRequest},
+		{"range rejected by service", "?from=2025-03-02T00:00:00Z&to=2025-03-01T00:00:00Z",
+			errx.E("service.TimeSeries", errx.Invalid, errors.New("from must be before to")), http.StatusBadRequest},
+		{"unknown slug", "", errx.E("service.TimeSeries", errx.NotFound, errors.New("not found")), http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHandler(&mockService{
+				seriesFunc: func(ctx context.Context, req TimeSeriesRequest) (TimeSeries, error) {
+					if tt.svcErr == nil {
+						t.Fatal("service should not be called")
+					}
+					return TimeSeries{}, tt.svcErr
+				},
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/api/links/abc1234/timeseries"+tt.query, nil)
+			req.SetPathValue("slug", "abc1234")
+			rr := httptest.NewRecorder()
+			h.GetLinkTimeSeries(rr, req)
+
+			if rr.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d; body: %s", rr.Code, tt.wantStatus, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandlerResolveLink_RedactsLoggedURLs(t *testing.T) {
+	const target = "https://example.com/cb?id=7&token=s3cret&lang=en"
+
+	resolve := func(redact []string) string {
+		var logs bytes.Buffer
+		h := NewHandler(HandlerConfig{
+			Service: &mockService{
+				resolveFunc: func(ctx context.Context, slug string) (Resolution, error) {
+					return Resolution{URL: target}, nil
+				},
+			},
+			Logger:       slog.New(slog.NewJSONHandler(&logs, nil)),
+			BaseURL:      testBaseURL,
+			RedactParams: redact,
+		})
+
+		req := httptest.NewRequest(http.MethodGet, "/abc1234", nil)
+		req.Header.Set("Referer", "https://ref.example/?sig=abc&q=go")
+		rr := httptest.NewRecorder()
+		h.ResolveLink(rr, req)
+
+		if rr.Code != http.StatusFound {
+			t.Fatalf("status = %d, want %d", rr.Code, http.StatusFound)
+		}
+		if got := rr.Header().Get("Location"); got != target {
+			t.Errorf("Location = %q, want the unredacted %q", got, target)
+		}
+		return logs.String()
+	}
+
+	t.Run("default params are masked", func(t *testing.T) {
+		logs := resolve(nil)
+
+		if strings.Contains(logs, "s3cret") || strings.Contains(logs, "sig=abc") {
+			t.Errorf("logs leak a sensitive value: %s", logs)
+		}
+		for _, want := range []string{
+			"https://example.com/cb?id=7\u0026token=REDACTED\u0026lang=en",
+			"https://ref.example/?sig=REDACTED\u0026q=go",
+		} {
+			if !strings.Contains(logs, want) {
+				t.Errorf("logs missing %s: %s", want, logs)
+			}
+		}
+	})
+
+	t.Run("configured params replace the defaults", func(t *testing.T) {
+		logs := resolve([]string{"lang"})
+
+		if !strings.Contains(logs, "token=s3cret") {
+			t.Errorf("token should be logged when not configured: %s", logs)
+		}
+		if !strings.Contains(logs, "lang=REDACTED") {
+			t.Errorf("lang should be masked: %s", logs)
+		}
+	})
+}
+
+func TestHandlerResolveLink_NotFound(t *testing.T) {
+	notFound := &mockService{
+		resolveFunc: func(ctx context.Context, slug string) (Resolution, error) {
+			return Resolution{}, errx.E("service.Resolve", errx.NotFound, errors.New("not found"))
+		},
+	}
+
+	t.Run("redirects to fallback when configured", func(t *testing.T) {
+		h := NewHandler(HandlerConfig{
+			Service:             notFound,
+			Logger:              slog.New(slog.NewTextHandler(io.Discard, nil)),
+			BaseURL:             testBaseURL,
+			NotFoundRedirectURL: "https://example.com/",
+		})
+
+		rr := httptest.NewRecorder()
+		h.ResolveLink(rr, httptest.NewRequest(http.MethodGet, "/missing1", nil))
+
+		if rr.Code != http.StatusFound {
+			t.Fatalf("status = %d, want %d", rr.Code, http.StatusFound)
+		}
+		if got := rr.Header().Get("Location"); got != "https://example.com/" {
+			t.Errorf("Location = %q, want %q", got, "https://example.com/")
+		}
+	})
+
+	t.Run("returns JSON 404 without fallback", func(t *testing.T) {
+		h := newTestHandler(notFound)
+
+		rr := httptest.NewRecorder()
+		h.ResolveLink(rr, httptest.NewRequest(http.MethodGet, "/missing1", nil))
+
+		if rr.Code != http.StatusNotFound {
+			t.Fatalf("status = %d, want %d", rr.Code, http.StatusNotFound)
+		}
+		if ct := rr.Header().Get("Content-Type"); ct != "application/json" {
+			t.Errorf("Content-Type = %q, want application/json", ct)
+		}
+		if rr.Header().Get("Location") != "" {
+			t.Error("unexpected Location header without fallback")
+		}
+	})
+
+	t.Run("fallback does not apply to other errors", func(t *testing.T) {
+		h := NewHandler(HandlerConfig{
+			Service: &mockService{
+				resolveFunc: func(ctx context.Context, slug string) (Resolution, error) {
+					return Resolution{}, errx.E("service.Resolve", errx.Unavailable, errors.New("db down"))
+				},
+			},
+			Logger:              slog.New(slog.NewTextHandler(io.Discard, nil)),
+			NotFoundRedirectURL: "https://example.com/",
+		})
+
+		rr := httptest.NewRecorder()
+		h.ResolveLink(rr, httptest.NewRequest(http.MethodGet, "/abc1234", nil))
+
+		if rr.Code != http.StatusInternalServerError {
+			t.Errorf("status = %d, want %d", rr.Code, http.StatusInternalServerError)
+		}
+	})
+}
+
+func TestHandlerGetLinkPreview(t *testing.T) {
+	h := newTestHandler(&mockService{
+		getBySlugFunc: func(ctx context.Context, slug string) (Link, error) {
+			link := sampleLink()
+			link.OriginalURL = `https://example.com/page?q="a"&b=<c>`
+			return link, nil
+		},
+	})
+
+	t.Run("JSON by default", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/links/abc1234/preview", nil)
+		req.SetPathValue("slug", "abc1234")
+		rr := httptest.NewRecorder()
+		h.GetLinkPreview(rr, req)
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("status = %d, want %d; body: %s", rr.Code, http.StatusOK, rr.Body.String())
+		}
+		if ct := rr.Header().Get("Content-Type"); ct != "application/json" {
+			t.Errorf("Content-Type = %q, want application/json", ct)
+		}
+		var resp PreviewResponse
+		if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		want := PreviewResponse{
+			Title:       "example.com",
+			URL:         testBaseURL + "/abc1234",
+			Description: `Short link to https://example.com/page?q="a"&b=<c>`,
+		}
+		if resp != want {
+			t.Errorf("response = %+v, want %+v", resp, want)
+		}
+	})
+
+	t.Run("HTML meta tags when preferred", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/links/abc1234/preview", nil)
+		req.Header.Set("Accept", "text/html,application/xhtml+xml,*/*;q=0.8")
+		req.SetPathValue("slug", "abc1234")
+		rr := httptest.NewRecorder()
+		h.GetLinkPreview(rr, req)
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("status = %d, want %d; body: %s", rr.Code, http.StatusOK, rr.Body.String())
+		}
+		if ct := rr.Header().Get("Content-Type"); ct != "text/html; charset=utf-8" {
+			t.Errorf("Content-Type = %q, want text/html", ct)
+		}
+		if vary := rr.Header().Get("Vary"); vary != "Accept" {
+			t.Errorf("Vary = %q, want Accept", vary)
+		}
+		body := rr.Body.String()
+		for _, tag := range []string{
+			`<meta property="og:title" content="example.com">`,
+			`<meta property="og:url" content="` + testBaseURL + `/abc1234">`,
+			`<meta property="og:description" content="Short link to https://example.com/page?q=&#34;a&#34;&amp;b=&lt;c&gt;">`,
+		} {
+			if !strings.Contains(body, tag) {
+				t.Errorf("body missing %s:\n%s", tag, body)
+			}
+		}
+	})
+
+	t.Run("unknown link", func(t *testing.T) {
+		h := newTestHandler(&mockService{
+			getBySlugFunc: func(ctx context.Context, slug string) (Link, error) {
+				return Link{}, errx.E("service.GetBySlug", errx.NotFound, errors.New("not found"))
+			},
+		})
+		req := httptest.NewRequest(http.MethodGet, "/api/links/missing/preview", nil)
+		req.Header.Set("Accept", "text/html")
+		req.SetPathValue("slug", "missing")
+		rr := httptest.NewRecorder()
+		h.GetLinkPreview(rr, req)
+
+		if rr.Code != http.StatusNotFound {
+			t.Errorf("status = %d, want %d", rr.Code, http.StatusNotFound)
+		}
+	})
+}
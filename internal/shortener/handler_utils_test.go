@@ -2,6 +2,7 @@ package shortener
 
 import (
 	"errors"
+	"strings"
 	"testing"
 )
 
@@ -26,6 +27,22 @@ func TestValidateCreateRequest(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "custom slug longer than generated slugs",
+			req: HTTPCreateLinkRequest{
+				URL:        "https://example.com",
+				CustomSlug: strings.Repeat("a", MaxSlugLength+1),
+			},
+			wantErr: false,
+		},
+		{
+			name: "custom slug over schema maximum",
+			req: HTTPCreateLinkRequest{
+				URL:        "https://example.com",
+				CustomSlug: strings.Repeat("a", MaxCustomSlugLength+1),
+			},
+			wantErr: true,
+		},
 		{
 			name: "empty URL",
 			req: HTTPCreateLinkRequest{
@@ -38,7 +55,31 @@ func TestValidateCreateRequest(t *testing.T) {
 			req: HTTPCreateLinkRequest{
 				URL: "   ",
 			},
-			wantErr: false, // validateCreateRequest only checks if empty, not trimmed
+			wantErr: true,
+		},
+		{
+			name: "custom slug with surrounding whitespace",
+			req: HTTPCreateLinkRequest{
+				URL:        " https://example.com\n",
+				CustomSlug: " my-slug ",
+			},
+			wantErr: false,
+		},
+		{
+			name: "whitespace only custom slug",
+			req: HTTPCreateLinkRequest{
+				URL:        "https://example.com",
+				CustomSlug: " \t ",
+			},
+			wantErr: true,
+		},
+		{
+			name: "custom slug with inner whitespace",
+			req: HTTPCreateLinkRequest{
+				URL:        "https://example.com",
+				CustomSlug: " my slug ",
+			},
+			wantErr: true,
 		},
 	}
 
@@ -86,12 +127,12 @@ func TestValidateSlugFormat(t *testing.T) {
 		},
 		{
 			name:    "slug at max length",
-			slug:    "a234567890123456789012345678901234567890123456789012345678901234", // 64 chars
+			slug:    strings.Repeat("a", MaxCustomSlugLength),
 			wantErr: false,
 		},
 		{
 			name:    "slug exceeds max length",
-			slug:    "a2345678901234567890123456789012345678901234567890123456789012345", // 65 chars
+			slug:    strings.Repeat("a", MaxCustomSlugLength+1),
 			wantErr: true,
 		},
 		{
@@ -216,7 +257,7 @@ func TestExtractSlugFromPath_RealWorldExamples(t *testing.T) {
 // Test edge cases
 func TestValidateSlugFormat_EdgeCases(t *testing.T) {
 	// Test exactly at boundary
-	maxLengthSlug := make([]byte, MaxSlugLength)
+	maxLengthSlug := make([]byte, MaxCustomSlugLength)
 	for i := range maxLengthSlug {
 		maxLengthSlug[i] = 'a'
 	}
@@ -227,7 +268,7 @@ func TestValidateSlugFormat_EdgeCases(t *testing.T) {
 	}
 
 	// Test one over boundary
-	overMaxSlug := make([]byte, MaxSlugLength+1)
+	overMaxSlug := make([]byte, MaxCustomSlugLength+1)
 	for i := range overMaxSlug {
 		overMaxSlug[i] = 'a'
 	}
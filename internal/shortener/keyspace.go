@@ -0,0 +1,221 @@
+package shortener
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/sundayezeilo/urlshortener/internal/clock"
+)
+
+const (
+	// DefaultKeyspaceCheckInterval is how often the keyspace is re-checked.
+	DefaultKeyspaceCheckInterval = time.Hour
+	// DefaultKeyspaceWarnFraction warns once stored links fill 1% of the
+	// generated slug keyspace, i.e. one generated slug in a hundred collides.
+	DefaultKeyspaceWarnFraction = 0.01
+	// DefaultKeyspaceAlphabet is the base62 alphabet size.
+	DefaultKeyspaceAlphabet = 62
+)
+
+// KeyspaceEstimate relates the stored link population to the number of
+// slugs the generator can produce at the current length.
+type KeyspaceEstimate struct {
+	Alphabet   int     `json:"alphabet"`
+	SlugLength int     `json:"slug_length"`
+	Links      int64   `json:"links"`
+	Keyspace   float64 `json:"keyspace"` // Alphabet^SlugLength
+	// Utilization is Links/Keyspace, which is also the chance that a single
+	// generated slug collides with a stored one.
+	Utilization float64   `json:"utilization"`
+	CheckedAt   time.Time `json:"checked_at"`
+}
+
+// EstimateKeyspace computes the keyspace utilization for links stored
+// under slugs of length drawn from an alphabet of the given size.
+func EstimateKeyspace(alphabet, length int, links int64) KeyspaceEstimate {
+	keyspace := math.Pow(float64(alphabet), float64(length))
+	return KeyspaceEstimate{
+		Alphabet:    alphabet,
+		SlugLength:  length,
+		Links:       links,
+		Keyspace:    keyspace,
+		Utilization: float64(links) / keyspace,
+	}
+}
+
+// KeyspaceMonitorConfig holds configuration for the keyspace monitor.
+type KeyspaceMonitorConfig struct {
+	Repo     Repository
+	Interval time.Duration // Time between checks (default: DefaultKeyspaceCheckInterval)
+	Logger   *slog.Logger
+
+	// Alphabet is the number of characters generated slugs draw from
+	// (default: DefaultKeyspaceAlphabet).
+	Alphabet int
+	// SlugLength and SlugLengthThresholds mirror the service settings so
+	// the estimate uses the length new slugs are generated at
+	// (default: DefaultSlugLength).
+	SlugLength           int
+	SlugLengthThresholds []SlugLengthThreshold
+	// WarnFraction is the utilization at which a check logs a warning
+	// (default: DefaultKeyspaceWarnFraction).
+	WarnFraction float64
+
+	// Clock stamps estimates (default: clock.Real).
+	Clock clock.Clock
+}
+
+// KeyspaceMonitor periodically estimates how full the generated slug
+// keyspace is and warns before collisions make creates fail. It is safe for
+// concurrent use.
+type KeyspaceMonitor struct {
+	repo         Repository
+	interval     time.Duration
+	alphabet     int
+	slugLength   int
+	thresholds   []SlugLengthThreshold
+	warnFraction float64
+	logger       *slog.Logger
+	clock        clock.Clock
+
+	mu     sync.RWMutex
+	latest KeyspaceEstimate
+	seen   bool
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewKeyspaceMonitor creates a new KeyspaceMonitor. Call Start to begin
+// checking.
+func NewKeyspaceMonitor(cfg KeyspaceMonitorConfig) *KeyspaceMonitor {
+	interval := cfg.Interval
+	if interval <= 0 {
+		interval = DefaultKeyspaceCheckInterval
+	}
+
+	alphabet := cfg.Alphabet
+	if alphabet < 2 {
+		alphabet = DefaultKeyspaceAlphabet
+	}
+
+	slugLength := cfg.SlugLength
+	if slugLength < MinSlugLength || slugLength > MaxSlugLength {
+		slugLength = DefaultSlugLength
+	}
+
+	warnFraction := cfg.WarnFraction
+	if warnFraction <= 0 || warnFraction > 1 {
+		warnFraction = DefaultKeyspaceWarnFraction
+	}
+
+	logger := cfg.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	clk := cfg.Clock
+	if clk == nil {
+		clk = clock.Real
+	}
+
+	return &KeyspaceMonitor{
+		repo:         cfg.Repo,
+		interval:     interval,
+		alphabet:     alphabet,
+		slugLength:   slugLength,
+		thresholds:   cfg.SlugLengthThresholds,
+		warnFraction: warnFraction,
+		logger:       logger,
+		clock:        clk,
+	}
+}
+
+// Start runs an initial check and then keeps checking in the background
+// until Stop is called or ctx is cancelled. Calling Start twice is a no-op.
+func (m *KeyspaceMonitor) Start(ctx context.Context) {
+	m.mu.Lock()
+	if m.done != nil {
+		m.mu.Unlock()
+		return
+	}
+	ctx, m.cancel = context.WithCancel(ctx)
+	m.done = make(chan struct{})
+	m.mu.Unlock()
+
+	_, _ = m.Check(ctx)
+
+	go func() {
+		defer close(m.done)
+
+		ticker := time.NewTicker(m.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				_, _ = m.Check(ctx)
+			}
+		}
+	}()
+}
+
+// Stop halts background checks and waits for the monitor goroutine to exit.
+func (m *KeyspaceMonitor) Stop() {
+	m.mu.RLock()
+	cancel, done := m.cancel, m.done
+	m.mu.RUnlock()
+
+	if cancel == nil {
+		return
+	}
+	cancel()
+	<-done
+}
+
+// Check counts the stored links, records the estimate, and logs a warning
+// when utilization has reached the warn fraction.
+func (m *KeyspaceMonitor) Check(ctx context.Context) (KeyspaceEstimate, error) {
+	count, err := m.repo.Count(ctx)
+	if err != nil {
+		m.logger.ErrorContext(ctx, "failed to count links for keyspace estimate",
+			"error", err.Error(),
+		)
+		return KeyspaceEstimate{}, err
+	}
+
+	est := EstimateKeyspace(m.alphabet, slugLengthForCount(m.slugLength, m.thresholds, count), count)
+	est.CheckedAt = m.clock.Now()
+
+	m.mu.Lock()
+	m.latest = est
+	m.seen = true
+	m.mu.Unlock()
+
+	logAttrs := []any{
+		"links", est.Links,
+		"slug_length", est.SlugLength,
+		"alphabet", est.Alphabet,
+		"utilization", est.Utilization,
+	}
+	if est.Utilization >= m.warnFraction {
+		m.logger.WarnContext(ctx, "generated slug keyspace is filling up; raise the slug length",
+			append(logAttrs, "warn_fraction", m.warnFraction)...)
+	} else {
+		m.logger.DebugContext(ctx, "slug keyspace estimate", logAttrs...)
+	}
+	return est, nil
+}
+
+// Estimate returns the latest recorded estimate. The boolean is false if no
+// check has succeeded yet.
+func (m *KeyspaceMonitor) Estimate() (KeyspaceEstimate, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.latest, m.seen
+}
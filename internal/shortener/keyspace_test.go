@@ -0,0 +1,126 @@
+package shortener
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sundayezeilo/urlshortener/internal/clock"
+	"github.com/sundayezeilo/urlshortener/internal/errx"
+)
+
+func TestEstimateKeyspace(t *testing.T) {
+	tests := []struct {
+		name         string
+		alphabet     int
+		length       int
+		links        int64
+		wantKeyspace float64
+		wantUtil     float64
+	}{
+		{"empty base62", 62, 7, 0, 3521614606208, 0},
+		{"1% of base62 length 7", 62, 7, 35216146062, 3521614606208, 0.01},
+		{"base32 length 7", 32, 7, 343597383, 34359738368, 0.01},
+		{"base62 length 8", 62, 8, 218340105584, 218340105584896, 0.001},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := EstimateKeyspace(tt.alphabet, tt.length, tt.links)
+			if got.Keyspace != tt.wantKeyspace {
+				t.Errorf("Keyspace = %v, want %v", got.Keyspace, tt.wantKeyspace)
+			}
+			if math.Abs(got.Utilization-tt.wantUtil) > 1e-9 {
+				t.Errorf("Utilization = %v, want %v", got.Utilization, tt.wantUtil)
+			}
+		})
+	}
+}
+
+func TestKeyspaceMonitor_Check(t *testing.T) {
+	// 62^7 * 0.01, rounded up so utilization is at or past the threshold.
+	const atThreshold = 35216146063
+
+	tests := []struct {
+		name     string
+		links    int64
+		wantWarn bool
+	}{
+		{"below threshold", atThreshold - 1000, false},
+		{"at threshold", atThreshold, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var logs strings.Builder
+			now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+			m := NewKeyspaceMonitor(KeyspaceMonitorConfig{
+				Repo: &mockRepository{
+					countFunc: func(ctx context.Context) (int64, error) { return tt.links, nil },
+				},
+				Logger:       slog.New(slog.NewTextHandler(&logs, nil)),
+				WarnFraction: 0.01,
+				Clock:        clock.NewFake(now),
+			})
+
+			est, err := m.Check(context.Background())
+			if err != nil {
+				t.Fatalf("Check() unexpected error: %v", err)
+			}
+			if est.Links != tt.links || est.SlugLength != DefaultSlugLength || est.Alphabet != 62 {
+				t.Errorf("estimate = %+v", est)
+			}
+			if !est.CheckedAt.Equal(now) {
+				t.Errorf("CheckedAt = %v, want %v", est.CheckedAt, now)
+			}
+			if warned := strings.Contains(logs.String(), "level=WARN"); warned != tt.wantWarn {
+				t.Errorf("warned = %v, want %v; logs:\n%s", warned, tt.wantWarn, logs.String())
+			}
+			if latest, ok := m.Estimate(); !ok || latest != est {
+				t.Errorf("Estimate() = %+v, %v; want the checked estimate", latest, ok)
+			}
+		})
+	}
+}
+
+func TestKeyspaceMonitor_UsesThresholdLength(t *testing.T) {
+	m := NewKeyspaceMonitor(KeyspaceMonitorConfig{
+		Repo: &mockRepository{
+			countFunc: func(ctx context.Context) (int64, error) { return 1_000_000, nil },
+		},
+		Logger:               slog.New(slog.NewTextHandler(io.Discard, nil)),
+		Alphabet:             32,
+		SlugLengthThresholds: []SlugLengthThreshold{{MinLinks: 100_000, Length: 9}},
+	})
+
+	est, err := m.Check(context.Background())
+	if err != nil {
+		t.Fatalf("Check() unexpected error: %v", err)
+	}
+	if est.SlugLength != 9 || est.Alphabet != 32 {
+		t.Errorf("slug length/alphabet = %d/%d, want 9/32", est.SlugLength, est.Alphabet)
+	}
+}
+
+func TestKeyspaceMonitor_CountFailure(t *testing.T) {
+	m := NewKeyspaceMonitor(KeyspaceMonitorConfig{
+		Repo: &mockRepository{
+			countFunc: func(ctx context.Context) (int64, error) {
+				return 0, errx.E("repo.Count", errx.Unavailable, errors.New("db down"))
+			},
+		},
+		Logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+	})
+
+	if _, err := m.Check(context.Background()); err == nil {
+		t.Fatal("Check() expected error")
+	}
+	if _, ok := m.Estimate(); ok {
+		t.Error("Estimate() reported an estimate after a failed check")
+	}
+}
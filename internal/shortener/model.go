@@ -1,7 +1,14 @@
 package shortener
 
 import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
 )
@@ -14,5 +21,209 @@ type Link struct {
 	CreatedAt      time.Time
 	UpdatedAt      time.Time
 	LastAccessedAt *time.Time
-	DeletedAt      *time.Time
+	ExpiresAt      *time.Time // Links stop resolving after this time
+	DeletedAt      *time.Time // Set by soft delete; purged after retention
+
+	// UniqueAccessCount counts distinct daily visitors; it only grows while
+	// unique visitor tracking is enabled.
+	UniqueAccessCount int64
+
+	// Source is the channel the link was created through, one of Sources,
+	// or empty when untagged.
+	Source string
+	// Owner is the principal that created the link, empty for anonymous
+	// links. It is what MaxLinksPerOwner counts against.
+	Owner string
+	// UTM holds campaign defaults added to the destination on resolve.
+	UTM UTMParams
+	// RedirectStatus overrides the server's redirect status for this link,
+	// one of RedirectStatuses; 0 uses the server default.
+	RedirectStatus int
+	// Paused links don't resolve until resumed; unlike deleted ones they
+	// keep their place in listings and stats.
+	Paused bool
+
+	// Replayed is set by Create when it returned an identical existing link
+	// instead of creating one (see ServiceConfig.IdempotentCreate). It is
+	// not stored.
+	Replayed bool
+}
+
+// Destination returns the URL a resolve of the link redirects to: the
+// original URL plus any UTM defaults it doesn't already set.
+func (l Link) Destination() string {
+	return appendMissingParams(l.OriginalURL, l.UTM.values())
+}
+
+// UTMParams are per-link campaign tags. Each non-empty field is appended to
+// the destination at resolve time unless the stored URL already carries
+// that parameter, so tags written into the URL itself always win.
+type UTMParams struct {
+	Source   string // utm_source
+	Medium   string // utm_medium
+	Campaign string // utm_campaign
+}
+
+// MaxUTMValueLength bounds each UTM parameter value.
+const MaxUTMValueLength = 128
+
+// validate rejects overlong values and control characters.
+func (u UTMParams) validate() error {
+	for _, p := range []struct{ name, value string }{
+		{"utm_source", u.Source},
+		{"utm_medium", u.Medium},
+		{"utm_campaign", u.Campaign},
+	} {
+		if len(p.value) > MaxUTMValueLength {
+			return fmt.Errorf("%s is too long (maximum %d characters)", p.name, MaxUTMValueLength)
+		}
+		if strings.IndexFunc(p.value, unicode.IsControl) >= 0 || !utf8.ValidString(p.value) {
+			return fmt.Errorf("%s contains invalid characters", p.name)
+		}
+	}
+	return nil
+}
+
+// values returns the non-empty parameters.
+func (u UTMParams) values() url.Values {
+	v := url.Values{}
+	if u.Source != "" {
+		v.Set("utm_source", u.Source)
+	}
+	if u.Medium != "" {
+		v.Set("utm_medium", u.Medium)
+	}
+	if u.Campaign != "" {
+		v.Set("utm_campaign", u.Campaign)
+	}
+	return v
+}
+
+// Creator identifies the client that created a link, for abuse
+// investigation. It is only stored with ServiceConfig.RecordCreators and
+// only shown on the admin metadata view.
+type Creator struct {
+	IP        string
+	UserAgent string
+}
+
+// MaxCreatorUserAgentLength caps the stored creator user agent; longer
+// values are truncated.
+const MaxCreatorUserAgentLength = 512
+
+// Link creation sources.
+const (
+	SourceAPI       = "api"
+	SourceDashboard = "dashboard"
+	SourceImport    = "import"
+)
+
+// Sources lists the accepted link creation sources.
+var Sources = []string{SourceAPI, SourceDashboard, SourceImport}
+
+// validSource reports whether source is empty or one of Sources.
+func validSource(source string) bool {
+	return source == "" || slices.Contains(Sources, source)
+}
+
+// RedirectStatuses lists the redirect statuses a link may be configured
+// with. 307 and 308 tell clients to repeat the request method and body.
+var RedirectStatuses = []int{
+	http.StatusMovedPermanently,
+	http.StatusFound,
+	http.StatusTemporaryRedirect,
+	http.StatusPermanentRedirect,
+}
+
+// validRedirectStatus reports whether status is 0 or one of RedirectStatuses.
+func validRedirectStatus(status int) bool {
+	return status == 0 || slices.Contains(RedirectStatuses, status)
+}
+
+// PreservesMethod reports whether clients repeat the original request
+// method and body when following a redirect with status.
+func PreservesMethod(status int) bool {
+	return status == http.StatusTemporaryRedirect || status == http.StatusPermanentRedirect
+}
+
+// SourceCount is the number of live links created through one source. An
+// empty Source counts untagged links.
+type SourceCount struct {
+	Source string
+	Links  int64
+}
+
+// LinkStatus selects links by lifecycle state.
+type LinkStatus string
+
+const (
+	// LinkStatusAny matches links in every state.
+	LinkStatusAny LinkStatus = ""
+	// LinkStatusActive matches links that still resolve: not deleted, not
+	// paused and not expired.
+	LinkStatusActive LinkStatus = "active"
+	// LinkStatusPaused matches paused links that are not deleted.
+	LinkStatusPaused LinkStatus = "paused"
+	// LinkStatusExpired matches links past their expiry that are not
+	// deleted.
+	LinkStatusExpired LinkStatus = "expired"
+	// LinkStatusDeleted matches soft-deleted links awaiting purge.
+	LinkStatusDeleted LinkStatus = "deleted"
+)
+
+// CountFilter selects the links Repository.CountLinks counts. Zero fields
+// don't filter.
+type CountFilter struct {
+	Status LinkStatus
+	Owner  string // Principal that created the link
+}
+
+// TimeBucket is the granularity of a click time series.
+type TimeBucket string
+
+const (
+	BucketHour TimeBucket = "hour"
+	BucketDay  TimeBucket = "day"
+)
+
+// ParseTimeBucket maps a query value to a TimeBucket. An empty value means BucketDay.
+func ParseTimeBucket(s string) (TimeBucket, error) {
+	switch TimeBucket(s) {
+	case "", BucketDay:
+		return BucketDay, nil
+	case BucketHour:
+		return BucketHour, nil
+	default:
+		return "", fmt.Errorf("bucket must be %q or %q", BucketHour, BucketDay)
+	}
+}
+
+// Duration returns the width of one bucket.
+func (b TimeBucket) Duration() time.Duration {
+	if b == BucketHour {
+		return time.Hour
+	}
+	return 24 * time.Hour
+}
+
+// ClickEvent is one resolution of a link. RequestID is empty unless request
+// IDs are recorded; Country is empty unless a GeoResolver knows the client.
+type ClickEvent struct {
+	LinkID    uuid.UUID
+	RequestID string
+	Country   string // ISO 3166-1 alpha-2, e.g. "DE"
+}
+
+// CountryCount is the number of recorded clicks from one country. An empty
+// Country counts clicks whose country is unknown.
+type CountryCount struct {
+	Country string
+	Clicks  int64
+}
+
+// ClickBucket counts the resolutions of a link in [Start, Start+bucket),
+// with Start in UTC.
+type ClickBucket struct {
+	Start  time.Time
+	Clicks int64
 }
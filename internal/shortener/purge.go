@@ -0,0 +1,198 @@
+package shortener
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/sundayezeilo/urlshortener/internal/clock"
+)
+
+const (
+	DefaultPurgeInterval         = time.Hour
+	DefaultPurgeExpiredGrace     = 24 * time.Hour
+	DefaultPurgeDeletedRetention = 30 * 24 * time.Hour
+	DefaultPurgeBatchSize        = 500
+)
+
+// PurgerConfig holds configuration for the purge sweeper.
+type PurgerConfig struct {
+	Repo     Repository
+	Interval time.Duration // Time between sweeps (default: DefaultPurgeInterval)
+	Logger   *slog.Logger
+
+	// ExpiredGrace keeps expired links around for this long after expires_at
+	// (default: DefaultPurgeExpiredGrace).
+	ExpiredGrace time.Duration
+	// DeletedRetention keeps soft-deleted links for this long after deleted_at
+	// (default: DefaultPurgeDeletedRetention).
+	DeletedRetention time.Duration
+	// BatchSize caps the rows removed per DELETE so a sweep never holds long
+	// locks (default: DefaultPurgeBatchSize).
+	BatchSize int
+
+	// Clock decides which links are past their window (default: clock.Real).
+	Clock clock.Clock
+}
+
+// PurgeResult reports how many links a sweep removed.
+type PurgeResult struct {
+	Expired int64
+	Deleted int64
+}
+
+// Purger periodically hard-deletes expired and soft-deleted links once they
+// are past their grace or retention window.
+type Purger struct {
+	repo             Repository
+	interval         time.Duration
+	expiredGrace     time.Duration
+	deletedRetention time.Duration
+	batchSize        int
+	logger           *slog.Logger
+	clock            clock.Clock
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewPurger creates a new Purger. Call Start to begin sweeping.
+func NewPurger(cfg PurgerConfig) *Purger {
+	interval := cfg.Interval
+	if interval <= 0 {
+		interval = DefaultPurgeInterval
+	}
+
+	expiredGrace := cfg.ExpiredGrace
+	if expiredGrace <= 0 {
+		expiredGrace = DefaultPurgeExpiredGrace
+	}
+
+	deletedRetention := cfg.DeletedRetention
+	if deletedRetention <= 0 {
+		deletedRetention = DefaultPurgeDeletedRetention
+	}
+
+	batchSize := cfg.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultPurgeBatchSize
+	}
+
+	logger := cfg.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	clk := cfg.Clock
+	if clk == nil {
+		clk = clock.Real
+	}
+
+	return &Purger{
+		repo:             cfg.Repo,
+		interval:         interval,
+		expiredGrace:     expiredGrace,
+		deletedRetention: deletedRetention,
+		batchSize:        batchSize,
+		logger:           logger,
+		clock:            clk,
+	}
+}
+
+// Start sweeps in the background every interval until Stop is called or ctx
+// is cancelled. Calling Start twice is a no-op.
+func (p *Purger) Start(ctx context.Context) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.done != nil {
+		return
+	}
+	ctx, p.cancel = context.WithCancel(ctx)
+	p.done = make(chan struct{})
+
+	go func() {
+		defer close(p.done)
+
+		ticker := time.NewTicker(p.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				_, _ = p.RunOnce(ctx)
+			}
+		}
+	}()
+}
+
+// Stop halts background sweeps and waits for the sweeper goroutine to exit.
+func (p *Purger) Stop() {
+	p.mu.Lock()
+	cancel, done := p.cancel, p.done
+	p.mu.Unlock()
+
+	if cancel == nil {
+		return
+	}
+	cancel()
+	<-done
+}
+
+// RunOnce performs a single sweep, deleting in batches until no eligible
+// rows remain, and logs the counts purged.
+func (p *Purger) RunOnce(ctx context.Context) (PurgeResult, error) {
+	now := p.clock.Now()
+
+	var res PurgeResult
+	var err error
+
+	res.Expired, err = p.drain(ctx, p.repo.PurgeExpired, now.Add(-p.expiredGrace))
+	if err != nil {
+		p.logger.ErrorContext(ctx, "failed to purge expired links",
+			"error", err.Error(),
+			"purged", res.Expired,
+		)
+		return res, err
+	}
+
+	res.Deleted, err = p.drain(ctx, p.repo.PurgeDeleted, now.Add(-p.deletedRetention))
+	if err != nil {
+		p.logger.ErrorContext(ctx, "failed to purge deleted links",
+			"error", err.Error(),
+			"purged", res.Deleted,
+		)
+		return res, err
+	}
+
+	if res.Expired > 0 || res.Deleted > 0 {
+		p.logger.InfoContext(ctx, "purged links",
+			"expired", res.Expired,
+			"deleted", res.Deleted,
+		)
+	}
+	return res, nil
+}
+
+// drain calls purge with the cutoff until a batch comes back short.
+func (p *Purger) drain(
+	ctx context.Context,
+	purge func(ctx context.Context, before time.Time, limit int) (int64, error),
+	before time.Time,
+) (int64, error) {
+	var total int64
+	for {
+		n, err := purge(ctx, before, p.batchSize)
+		total += n
+		if err != nil {
+			return total, err
+		}
+		if n < int64(p.batchSize) || ctx.Err() != nil {
+			return total, nil
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package shortener
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/sundayezeilo/urlshortener/internal/clock"
+	"github.com/sundayezeilo/urlshortener/internal/errx"
+)
+
+// purgeRepo is an in-memory store whose purge methods apply the same
+// predicates as the PurgeExpiredLinks and PurgeDeletedLinks queries.
+type purgeRepo struct {
+	mockRepository
+	links map[string]Link
+	calls int
+}
+
+func newPurgeRepo(links ...Link) *purgeRepo {
+	r := &purgeRepo{links: make(map[string]Link)}
+	for _, l := range links {
+		r.links[l.Slug] = l
+	}
+
+	purge := func(match func(Link) bool, limit int) int64 {
+		r.calls++
+		var n int64
+		for slug, l := range r.links {
+			if n == int64(limit) {
+				break
+			}
+			if match(l) {
+				delete(r.links, slug)
+				n++
+			}
+		}
+		return n
+	}
+	r.purgeExpiredFunc = func(ctx context.Context, before time.Time, limit int) (int64, error) {
+		return purge(func(l Link) bool { return l.ExpiresAt != nil && l.ExpiresAt.Before(before) }, limit), nil
+	}
+	r.purgeDeletedFunc = func(ctx context.Context, before time.Time, limit int) (int64, error) {
+		return purge(func(l Link) bool { return l.DeletedAt != nil && l.DeletedAt.Before(before) }, limit), nil
+	}
+	return r
+}
+
+func at(t time.Time) *time.Time { return &t }
+
+func TestPurger_RunOnce_OnlyPurgesEligibleLinks(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	repo := newPurgeRepo(
+		Link{Slug: "expired-past-grace", ExpiresAt: at(now.Add(-48 * time.Hour))},
+		Link{Slug: "expired-in-grace", ExpiresAt: at(now.Add(-time.Hour))},
+		Link{Slug: "expires-later", ExpiresAt: at(now.Add(time.Hour))},
+		Link{Slug: "deleted-past-retention", DeletedAt: at(now.Add(-31 * 24 * time.Hour))},
+		Link{Slug: "deleted-in-retention", DeletedAt: at(now.Add(-24 * time.Hour))},
+		Link{Slug: "active"},
+	)
+
+	p := NewPurger(PurgerConfig{
+		Repo:             repo,
+		ExpiredGrace:     24 * time.Hour,
+		DeletedRetention: 30 * 24 * time.Hour,
+		Logger:           slog.New(slog.NewTextHandler(io.Discard, nil)),
+		Clock:            clock.NewFake(now),
+	})
+
+	res, err := p.RunOnce(context.Background())
+	if err != nil {
+		t.Fatalf("RunOnce() unexpected error: %v", err)
+	}
+	if res != (PurgeResult{Expired: 1, Deleted: 1}) {
+		t.Errorf("RunOnce() = %+v, want 1 expired and 1 deleted", res)
+	}
+
+	for _, slug := range []string{"expired-past-grace", "deleted-past-retention"} {
+		if _, ok := repo.links[slug]; ok {
+			t.Errorf("%q should have been purged", slug)
+		}
+	}
+	for _, slug := range []string{"expired-in-grace", "expires-later", "deleted-in-retention", "active"} {
+		if _, ok := repo.links[slug]; !ok {
+			t.Errorf("%q should have been kept", slug)
+		}
+	}
+}
+
+func TestPurger_RunOnce_DrainsInBatches(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	var links []Link
+	for _, slug := range []string{"a", "b", "c", "d", "e"} {
+		links = append(links, Link{Slug: slug, DeletedAt: at(now.Add(-365 * 24 * time.Hour))})
+	}
+	repo := newPurgeRepo(links...)
+
+	p := NewPurger(PurgerConfig{
+		Repo:      repo,
+		BatchSize: 2,
+		Logger:    slog.New(slog.NewTextHandler(io.Discard, nil)),
+		Clock:     clock.NewFake(now),
+	})
+
+	res, err := p.RunOnce(context.Background())
+	if err != nil {
+		t.Fatalf("RunOnce() unexpected error: %v", err)
+	}
+	if res.Deleted != 5 {
+		t.Errorf("Deleted = %d, want 5", res.Deleted)
+	}
+	if len(repo.links) != 0 {
+		t.Errorf("%d links left, want 0", len(repo.links))
+	}
+	// One expired batch (empty) plus three deleted batches: 2 + 2 + 1.
+	if repo.calls != 4 {
+		t.Errorf("purge calls = %d, want 4", repo.calls)
+	}
+}
+
+func TestPurger_RunOnce_StopsOnError(t *testing.T) {
+	p := NewPurger(PurgerConfig{
+		Repo: &mockRepository{
+			purgeExpiredFunc: func(ctx context.Context, before time.Time, limit int) (int64, error) {
+				return 0, errx.E("repo.PurgeExpired", errx.Unavailable, errors.New("db down"))
+			},
+			purgeDeletedFunc: func(ctx context.Context, before time.Time, limit int) (int64, error) {
+				t.Error("deleted links should not be purged after an expired purge failure")
+				return 0, nil
+			},
+		},
+		Logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+	})
+
+	if _, err := p.RunOnce(context.Background()); errx.KindOf(err) != errx.Unavailable {
+		t.Errorf("RunOnce() error kind = %v, want %v", errx.KindOf(err), errx.Unavailable)
+	}
+}
+
+func TestPurger_StartStop(t *testing.T) {
+	swept := make(chan struct{}, 1)
+	p := NewPurger(PurgerConfig{
+		Repo: &mockRepository{
+			purgeExpiredFunc: func(ctx context.Context, before time.Time, limit int) (int64, error) {
+				select {
+				case swept <- struct{}{}:
+				default:
+				}
+				return 0, nil
+			},
+		},
+		Interval: 5 * time.Millisecond,
+		Logger:   slog.New(slog.NewTextHandler(io.Discard, nil)),
+	})
+
+	p.Stop() // no-op before Start
+
+	p.Start(context.Background())
+	select {
+	case <-swept:
+	case <-time.After(time.Second):
+		t.Fatal("purger never swept")
+	}
+	p.Stop()
+}
@@ -0,0 +1,50 @@
+package shortener
+
+import (
+	"net/url"
+	"strings"
+)
+
+// forwardQuery adds the parameters of an incoming query string to a
+// destination URL. Parameters the destination already sets take precedence:
+// an incoming key that the stored URL also has is dropped, so a short link
+// can't be used to override e.g. an affiliate ID baked into its target.
+// Malformed incoming pairs are skipped.
+func forwardQuery(destination, rawQuery string) string {
+	if rawQuery == "" {
+		return destination
+	}
+	incoming, _ := url.ParseQuery(rawQuery)
+	return appendMissingParams(destination, incoming)
+}
+
+// appendMissingParams appends the params whose keys destination's query
+// doesn't already have. The destination's own query is kept byte for byte.
+func appendMissingParams(destination string, params url.Values) string {
+	if len(params) == 0 {
+		return destination
+	}
+
+	u, err := url.Parse(destination)
+	if err != nil {
+		return destination
+	}
+	stored, _ := url.ParseQuery(u.RawQuery)
+
+	extra := url.Values{}
+	for key, values := range params {
+		if _, ok := stored[key]; !ok {
+			extra[key] = values
+		}
+	}
+	if len(extra) == 0 {
+		return destination
+	}
+
+	if u.RawQuery == "" {
+		u.RawQuery = extra.Encode()
+	} else {
+		u.RawQuery = strings.TrimSuffix(u.RawQuery, "&") + "&" + extra.Encode()
+	}
+	return u.String()
+}
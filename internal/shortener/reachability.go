@@ -0,0 +1,103 @@
+package shortener
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	// DefaultReachabilityTimeout bounds a single reachability check.
+	DefaultReachabilityTimeout = 3 * time.Second
+
+	// reachabilityConcurrency caps the checks one batch runs at a time.
+	reachabilityConcurrency = 8
+)
+
+// ReachabilityChecker reports whether a destination URL currently answers.
+// A false result is informational: it never fails the create.
+type ReachabilityChecker interface {
+	Reachable(ctx context.Context, rawURL string) bool
+}
+
+// httpReachabilityChecker sends a HEAD request to the destination.
+type httpReachabilityChecker struct {
+	client  *http.Client
+	timeout time.Duration
+}
+
+// NewHTTPReachabilityChecker returns a ReachabilityChecker that sends a HEAD
+// request to each destination, without following redirects, and gives up
+// after timeout (default: DefaultReachabilityTimeout). Connections to
+// loopback, private and link-local addresses are refused.
+func NewHTTPReachabilityChecker(timeout time.Duration) ReachabilityChecker {
+	return newHTTPReachabilityChecker(timeout, denyPrivateAddresses)
+}
+
+func newHTTPReachabilityChecker(timeout time.Duration, control func(network, address string, c syscall.RawConn) error) *httpReachabilityChecker {
+	if timeout <= 0 {
+		timeout = DefaultReachabilityTimeout
+	}
+	dialer := &net.Dialer{Timeout: timeout, Control: control}
+	return &httpReachabilityChecker{
+		client: &http.Client{
+			Transport: &http.Transport{
+				DialContext:           dialer.DialContext,
+				TLSHandshakeTimeout:   timeout,
+				ResponseHeaderTimeout: timeout,
+				DisableKeepAlives:     true,
+			},
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		timeout: timeout,
+	}
+}
+
+// Reachable reports whether the destination answered with a success or
+// redirect status. 405 also counts: the server is up but rejects HEAD.
+func (c *httpReachabilityChecker) Reachable(ctx context.Context, rawURL string) bool {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, rawURL, nil)
+	if err != nil {
+		return false
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return false
+	}
+	_ = resp.Body.Close()
+
+	return resp.StatusCode < http.StatusBadRequest || resp.StatusCode == http.StatusMethodNotAllowed
+}
+
+// checkReachability fills in Reachable for every created row, running a
+// bounded number of checks concurrently.
+func (s *service) checkReachability(ctx context.Context, results []BatchResult) {
+	if s.reachability == nil {
+		return
+	}
+
+	sem := make(chan struct{}, reachabilityConcurrency)
+	var wg sync.WaitGroup
+	for i := range results {
+		if results[i].Status != BatchCreated {
+			continue
+		}
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(res *BatchResult) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			reachable := s.reachability.Reachable(ctx, res.Link.OriginalURL)
+			res.Reachable = &reachable
+		}(&results[i])
+	}
+	wg.Wait()
+}
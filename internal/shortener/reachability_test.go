@@ -0,0 +1,133 @@
+package shortener
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// newReachabilityServer serves 200 on /ok, 404 on /missing and hangs past
+// any test timeout on /slow.
+func newReachabilityServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ok":
+			w.WriteHeader(http.StatusOK)
+		case "/slow":
+			select {
+			case <-r.Context().Done():
+			case <-time.After(5 * time.Second):
+			}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestHTTPReachabilityChecker(t *testing.T) {
+	srv := newReachabilityServer(t)
+	// The test server listens on loopback, so the address guard is off.
+	checker := newHTTPReachabilityChecker(200*time.Millisecond, nil)
+
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"/ok", true},
+		{"/missing", false},
+		{"/slow", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := checker.Reachable(context.Background(), srv.URL+tt.path); got != tt.want {
+				t.Errorf("Reachable(%s) = %v, want %v", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHTTPReachabilityChecker_RefusesPrivateAddresses(t *testing.T) {
+	srv := newReachabilityServer(t)
+	checker := NewHTTPReachabilityChecker(time.Second)
+
+	if checker.Reachable(context.Background(), srv.URL+"/ok") {
+		t.Error("Reachable() = true for a loopback destination, want false")
+	}
+}
+
+func TestPublicAddr(t *testing.T) {
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{"93.184.216.34", true},
+		{"2606:2800:220:1::", true},
+		{"127.0.0.1", false},
+		{"10.1.2.3", false},
+		{"192.168.0.1", false},
+		{"169.254.169.254", false},
+		{"::1", false},
+		{"fd00::1", false},
+		{"0.0.0.0", false},
+	}
+
+	for _, tt := range tests {
+		if got := publicAddr(netip.MustParseAddr(tt.addr)); got != tt.want {
+			t.Errorf("publicAddr(%s) = %v, want %v", tt.addr, got, tt.want)
+		}
+	}
+}
+
+func TestServiceCreateBatch_Reachability(t *testing.T) {
+	srv := newReachabilityServer(t)
+
+	t.Run("flags each created row", func(t *testing.T) {
+		svc := NewService(&mockRepository{}, &ServiceConfig{
+			ReachabilityChecker: newHTTPReachabilityChecker(200*time.Millisecond, nil),
+		})
+
+		results, err := svc.CreateBatch(context.Background(), []CreateLinkRequest{
+			{OriginalURL: srv.URL + "/ok"},
+			{OriginalURL: srv.URL + "/missing"},
+			{OriginalURL: srv.URL + "/slow"},
+			{OriginalURL: "not a url"},
+		})
+		if err != nil {
+			t.Fatalf("CreateBatch() unexpected error: %v", err)
+		}
+
+		want := []string{"true", "false", "false", "unset"}
+		for i, res := range results {
+			if i < 3 && res.Status != BatchCreated {
+				t.Errorf("results[%d].Status = %q, want created despite reachability", i, res.Status)
+			}
+			got := "unset"
+			if res.Reachable != nil {
+				got = strconv.FormatBool(*res.Reachable)
+			}
+			if got != want[i] {
+				t.Errorf("results[%d].Reachable = %s, want %s", i, got, want[i])
+			}
+		}
+	})
+
+	t.Run("unset when disabled", func(t *testing.T) {
+		svc := NewService(&mockRepository{}, nil)
+
+		results, err := svc.CreateBatch(context.Background(), []CreateLinkRequest{{OriginalURL: srv.URL + "/ok"}})
+		if err != nil {
+			t.Fatalf("CreateBatch() unexpected error: %v", err)
+		}
+		if results[0].Reachable != nil {
+			t.Errorf("Reachable = %v, want unset", *results[0].Reachable)
+		}
+	})
+}
@@ -1,6 +1,12 @@
 package shortener
 
-import "context"
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
 
 // Repository defines the persistence operations for Link entities.
 // It abstracts the underlying data store and is responsible for
@@ -8,7 +14,85 @@ import "context"
 // tracking access-related metadata.
 type Repository interface {
 	Create(ctx context.Context, link Link) (Link, error)
+	// CreateOrUpdate inserts link or, if a live link already has its slug,
+	// atomically points that link at link.OriginalURL. It reports whether
+	// link was inserted.
+	CreateOrUpdate(ctx context.Context, link Link) (Link, bool, error)
 	GetBySlug(ctx context.Context, slug string) (Link, error)
-	ResolveAndTrack(ctx context.Context, slug string) (Link, error)
-	Delete(ctx context.Context, slug string) error
+	// GetByID returns the live link with id.
+	GetByID(ctx context.Context, id uuid.UUID) (Link, error)
+	// ResolveAndTrack counts an access to the live, unpaused link with slug
+	// and returns it. A link past its expiry still resolves for
+	// expiryGrace after it.
+	ResolveAndTrack(ctx context.Context, slug string, expiryGrace time.Duration) (Link, error)
+	// Delete soft-deletes the live link with slug and returns it.
+	Delete(ctx context.Context, slug string) (Link, error)
+	// SetPaused pauses or resumes the live link with slug and returns it.
+	// Paused links are skipped by ResolveAndTrack.
+	SetPaused(ctx context.Context, slug string, paused bool) (Link, error)
+	Count(ctx context.Context) (int64, error)
+	// CountByOwner returns the number of live links created by owner.
+	CountByOwner(ctx context.Context, owner string) (int64, error)
+	// CountLinks returns the number of links matching filter.
+	CountLinks(ctx context.Context, filter CountFilter) (int64, error)
+	// CountBySource returns the number of live links per creation source,
+	// largest first.
+	CountBySource(ctx context.Context) ([]SourceCount, error)
+
+	// List returns up to limit links, newest first, starting after the
+	// cursor when it is non-nil.
+	List(ctx context.Context, after *LinkCursor, limit int) ([]Link, error)
+	// ListByURL returns every live link pointing at originalURL, newest first.
+	ListByURL(ctx context.Context, originalURL string) ([]Link, error)
+	// TakenSlugs reports which of slugs are already in use, including by
+	// soft-deleted links. With case-insensitive slugs, a slug differing
+	// only in case from a link's slug is taken.
+	TakenSlugs(ctx context.Context, slugs []string) (map[string]bool, error)
+
+	// TrackUniqueVisitor records fingerprint as a visitor of the link and
+	// increments its unique access count the first time it is seen.
+	// It reports whether the visitor was new.
+	TrackUniqueVisitor(ctx context.Context, linkID uuid.UUID, fingerprint string) (bool, error)
+
+	// RecordClick stores a click event for the link's time series.
+	RecordClick(ctx context.Context, click ClickEvent) error
+	// CountClicksByCountry returns the number of recorded clicks per
+	// country, largest first.
+	CountClicksByCountry(ctx context.Context) ([]CountryCount, error)
+
+	// SaveCreator stores the client that created the link.
+	SaveCreator(ctx context.Context, linkID uuid.UUID, c Creator) error
+	// GetCreator returns the stored creator of the link, failing with
+	// errx.NotFound when none was recorded.
+	GetCreator(ctx context.Context, linkID uuid.UUID) (Creator, error)
+
+	// AddAlias makes alias an additional slug of the link, failing with
+	// errx.Conflict when alias is already a slug or alias.
+	AddAlias(ctx context.Context, linkID uuid.UUID, alias string) error
+	// SlugForAlias returns the primary slug of the link alias points at,
+	// failing with errx.NotFound for an unknown alias.
+	SlugForAlias(ctx context.Context, alias string) (string, error)
+	// Aliases returns the aliases of the link, live or soft-deleted.
+	Aliases(ctx context.Context, linkID uuid.UUID) ([]string, error)
+	// ClickTimeSeries returns the link's clicks per bucket for every bucket
+	// from the one containing from up to to, including empty ones.
+	ClickTimeSeries(ctx context.Context, linkID uuid.UUID, bucket TimeBucket, from, to time.Time) ([]ClickBucket, error)
+
+	// PurgeExpired hard-deletes up to limit links that expired before the
+	// given time and returns how many were removed.
+	PurgeExpired(ctx context.Context, before time.Time, limit int) (int64, error)
+	// PurgeDeleted hard-deletes up to limit links soft-deleted before the
+	// given time and returns how many were removed.
+	PurgeDeleted(ctx context.Context, before time.Time, limit int) (int64, error)
+}
+
+// slugKey returns the key a link's slug is unique under and looked up by:
+// the slug itself, or its lowercase form when slugs are case-insensitive,
+// so "MyLink" and "mylink" collide while the link keeps the case it was
+// created with. Aliases are keyed the same way.
+func slugKey(slug string, caseInsensitive bool) string {
+	if caseInsensitive {
+		return strings.ToLower(slug)
+	}
+	return slug
 }
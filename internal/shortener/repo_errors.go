@@ -6,11 +6,17 @@ import (
 	"github.com/jackc/pgx/v5/pgconn"
 )
 
+// isSlugUniqueViolation reports whether err is a clash in the shared slug
+// and alias namespace. The link_aliases triggers report a clash between
+// the two tables as a links_slug_unique violation.
 func isSlugUniqueViolation(err error) bool {
 	var pgErr *pgconn.PgError
 	if !errors.As(err, &pgErr) {
 		return false
 	}
-	return pgErr.Code == "23505" &&
-		pgErr.ConstraintName == "links_slug_unique"
+	switch pgErr.ConstraintName {
+	case "links_slug_unique", "link_aliases_pkey", "link_aliases_alias_key_unique":
+		return pgErr.Code == "23505"
+	}
+	return false
 }
@@ -0,0 +1,565 @@
+package shortener
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"errors"
+	"math"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/sundayezeilo/urlshortener/idgen"
+	"github.com/sundayezeilo/urlshortener/internal/clock"
+	"github.com/sundayezeilo/urlshortener/internal/errx"
+)
+
+var (
+	errMemoryNoLink     = errors.New("link not found")
+	errMemorySlugTaken  = errors.New("slug already taken")
+	errMemoryLinkID     = errors.New("link id does not exist")
+	errMemoryNoCreator  = errors.New("creator not found")
+	errMemoryHasCreator = errors.New("creator already recorded")
+	errMemoryNoAlias    = errors.New("alias not found")
+)
+
+// memoryClick is one recorded click event.
+type memoryClick struct {
+	linkID    uuid.UUID
+	country   string
+	clickedAt time.Time
+}
+
+// memoryAlias is an alias as given and the link it points at.
+type memoryAlias struct {
+	alias  string
+	linkID uuid.UUID
+}
+
+// memoryRepo is a Repository kept in process memory. It mirrors the
+// Postgres schema's behaviour: slugs and aliases share one namespace,
+// soft-deleted links keep their slug until purged, and purging a link
+// removes its aliases, visitors, clicks and creator with it. Failures a
+// foreign key would report, such as tracking an unknown link ID, fail with
+// errx.Unavailable as they do against the database.
+type memoryRepo struct {
+	ids             idgen.Generator
+	clock           clock.Clock
+	caseInsensitive bool // See RepositoryConfig.CaseInsensitiveSlugs
+
+	mu       sync.Mutex
+	links    map[uuid.UUID]*Link
+	slugs    map[string]uuid.UUID   // Every link's slug key, soft-deleted ones included
+	aliases  map[string]memoryAlias // By alias key
+	visitors map[uuid.UUID]map[string]bool
+	clicks   []memoryClick
+	creators map[uuid.UUID]Creator
+}
+
+// NewMemoryRepository returns a thread-safe, in-memory Repository for
+// tests, local development and demos. Nothing survives a restart.
+func NewMemoryRepository() Repository {
+	return newMemoryRepo(clock.Real)
+}
+
+func newMemoryRepo(clk clock.Clock) *memoryRepo {
+	return &memoryRepo{
+		ids:      idgen.NewV7(idgen.WithRetries(1)),
+		clock:    clk,
+		links:    make(map[uuid.UUID]*Link),
+		slugs:    make(map[string]uuid.UUID),
+		aliases:  make(map[string]memoryAlias),
+		visitors: make(map[uuid.UUID]map[string]bool),
+		creators: make(map[uuid.UUID]Creator),
+	}
+}
+
+// key returns the slug key slug is stored and looked up under.
+func (r *memoryRepo) key(slug string) string {
+	return slugKey(slug, r.caseInsensitive)
+}
+
+// live returns the link with slug unless it is missing or soft-deleted.
+func (r *memoryRepo) live(slug string) (*Link, bool) {
+	id, ok := r.slugs[r.key(slug)]
+	if !ok {
+		return nil, false
+	}
+	link := r.links[id]
+	return link, link.DeletedAt == nil
+}
+
+// expired reports whether link is past its expiry at now.
+func expired(link *Link, now time.Time) bool {
+	return link.ExpiresAt != nil && !link.ExpiresAt.After(now)
+}
+
+func (r *memoryRepo) Create(_ context.Context, link Link) (Link, error) {
+	const op = "shortener.memoryRepo.Create"
+
+	if link.ID == uuid.Nil {
+		id, err := r.ids.Generate()
+		if err != nil {
+			return Link{}, errx.E(op, errx.Internal, err)
+		}
+		link.ID = id
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.insert(op, link)
+}
+
+func (r *memoryRepo) CreateOrUpdate(_ context.Context, link Link) (Link, bool, error) {
+	const op = "shortener.memoryRepo.CreateOrUpdate"
+
+	if link.ID == uuid.Nil {
+		id, err := r.ids.Generate()
+		if err != nil {
+			return Link{}, false, errx.E(op, errx.Internal, err)
+		}
+		link.ID = id
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if existing, ok := r.live(link.Slug); ok {
+		existing.OriginalURL = link.OriginalURL
+		existing.UpdatedAt = r.clock.Now()
+		return *existing, false, nil
+	}
+	created, err := r.insert(op, link)
+	return created, err == nil, err
+}
+
+// insert stores link under a free slug. r.mu must be held.
+func (r *memoryRepo) insert(op string, link Link) (Link, error) {
+	if _, ok := r.slugs[r.key(link.Slug)]; ok {
+		return Link{}, errx.E(op, errx.Conflict, errMemorySlugTaken)
+	}
+	if _, ok := r.aliases[r.key(link.Slug)]; ok {
+		return Link{}, errx.E(op, errx.Conflict, errMemorySlugTaken)
+	}
+
+	// Store only what CreateLink inserts; the rest starts at its default.
+	now := r.clock.Now()
+	stored := &Link{
+		ID:             link.ID,
+		OriginalURL:    link.OriginalURL,
+		Slug:           link.Slug,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+		Source:         link.Source,
+		Owner:          link.Owner,
+		UTM:            link.UTM,
+		RedirectStatus: link.RedirectStatus,
+		ExpiresAt:      link.ExpiresAt,
+	}
+	r.links[stored.ID] = stored
+	r.slugs[r.key(stored.Slug)] = stored.ID
+	return *stored, nil
+}
+
+func (r *memoryRepo) GetBySlug(_ context.Context, slug string) (Link, error) {
+	const op = "shortener.memoryRepo.GetBySlug"
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	link, ok := r.live(slug)
+	if !ok {
+		return Link{}, errx.E(op, errx.NotFound, errMemoryNoLink)
+	}
+	return *link, nil
+}
+
+func (r *memoryRepo) GetByID(_ context.Context, id uuid.UUID) (Link, error) {
+	const op = "shortener.memoryRepo.GetByID"
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	link, ok := r.links[id]
+	if !ok || link.DeletedAt != nil {
+		return Link{}, errx.E(op, errx.NotFound, errMemoryNoLink)
+	}
+	return *link, nil
+}
+
+func (r *memoryRepo) ResolveAndTrack(_ context.Context, slug string, expiryGrace time.Duration) (Link, error) {
+	const op = "shortener.memoryRepo.ResolveAndTrack"
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.clock.Now()
+	link, ok := r.live(slug)
+	if !ok || link.Paused || expired(link, now.Add(-expiryGrace)) {
+		return Link{}, errx.E(op, errx.NotFound, errMemoryNoLink)
+	}
+	if link.AccessCount < math.MaxInt64 {
+		link.AccessCount++
+	}
+	link.LastAccessedAt = &now
+	link.UpdatedAt = now
+	return *link, nil
+}
+
+func (r *memoryRepo) Delete(_ context.Context, slug string) (Link, error) {
+	const op = "shortener.memoryRepo.Delete"
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	link, ok := r.live(slug)
+	if !ok {
+		return Link{}, errx.E(op, errx.NotFound, errMemoryNoLink)
+	}
+	now := r.clock.Now()
+	link.DeletedAt = &now
+	link.UpdatedAt = now
+	return *link, nil
+}
+
+func (r *memoryRepo) SetPaused(_ context.Context, slug string, paused bool) (Link, error) {
+	const op = "shortener.memoryRepo.SetPaused"
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	link, ok := r.live(slug)
+	if !ok {
+		return Link{}, errx.E(op, errx.NotFound, errMemoryNoLink)
+	}
+	if link.Paused != paused {
+		link.Paused = paused
+		link.UpdatedAt = r.clock.Now()
+	}
+	return *link, nil
+}
+
+func (r *memoryRepo) Count(context.Context) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return int64(len(r.links)), nil
+}
+
+func (r *memoryRepo) CountByOwner(_ context.Context, owner string) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var n int64
+	for _, link := range r.links {
+		if link.DeletedAt == nil && link.Owner == owner {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (r *memoryRepo) CountLinks(_ context.Context, filter CountFilter) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.clock.Now()
+	var n int64
+	for _, link := range r.links {
+		if filter.Owner != "" && link.Owner != filter.Owner {
+			continue
+		}
+		live := link.DeletedAt == nil
+		match := true
+		switch filter.Status {
+		case LinkStatusActive:
+			match = live && !link.Paused && !expired(link, now)
+		case LinkStatusPaused:
+			match = live && link.Paused
+		case LinkStatusExpired:
+			match = live && expired(link, now)
+		case LinkStatusDeleted:
+			match = !live
+		}
+		if match {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (r *memoryRepo) CountBySource(context.Context) ([]SourceCount, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	bySource := make(map[string]int64)
+	for _, link := range r.links {
+		if link.DeletedAt == nil {
+			bySource[link.Source]++
+		}
+	}
+
+	counts := make([]SourceCount, 0, len(bySource))
+	for source, n := range bySource {
+		counts = append(counts, SourceCount{Source: source, Links: n})
+	}
+	slices.SortFunc(counts, func(a, b SourceCount) int {
+		return cmp.Or(cmp.Compare(b.Links, a.Links), cmp.Compare(a.Source, b.Source))
+	})
+	return counts, nil
+}
+
+// newestFirst orders links like the ListLinks query: by creation time,
+// then ID, descending.
+func newestFirst(a, b Link) int {
+	return cmp.Or(b.CreatedAt.Compare(a.CreatedAt), bytes.Compare(b.ID[:], a.ID[:]))
+}
+
+func (r *memoryRepo) List(_ context.Context, after *LinkCursor, limit int) ([]Link, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var links []Link
+	for _, link := range r.links {
+		if link.DeletedAt != nil {
+			continue
+		}
+		if after != nil && newestFirst(*link, Link{CreatedAt: after.CreatedAt, ID: after.ID}) <= 0 {
+			continue
+		}
+		links = append(links, *link)
+	}
+	slices.SortFunc(links, newestFirst)
+	if len(links) > limit {
+		links = links[:limit]
+	}
+	return links, nil
+}
+
+func (r *memoryRepo) ListByURL(_ context.Context, originalURL string) ([]Link, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var links []Link
+	for _, link := range r.links {
+		if link.DeletedAt == nil && link.OriginalURL == originalURL {
+			links = append(links, *link)
+		}
+	}
+	slices.SortFunc(links, newestFirst)
+	return links, nil
+}
+
+func (r *memoryRepo) TakenSlugs(_ context.Context, slugs []string) (map[string]bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	taken := make(map[string]bool)
+	for _, slug := range slugs {
+		_, isSlug := r.slugs[r.key(slug)]
+		_, isAlias := r.aliases[r.key(slug)]
+		if isSlug || isAlias {
+			taken[slug] = true
+		}
+	}
+	return taken, nil
+}
+
+func (r *memoryRepo) TrackUniqueVisitor(_ context.Context, linkID uuid.UUID, fingerprint string) (bool, error) {
+	const op = "shortener.memoryRepo.TrackUniqueVisitor"
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	link, ok := r.links[linkID]
+	if !ok {
+		return false, errx.E(op, errx.Unavailable, errMemoryLinkID)
+	}
+	if r.visitors[linkID][fingerprint] {
+		return false, nil
+	}
+	if r.visitors[linkID] == nil {
+		r.visitors[linkID] = make(map[string]bool)
+	}
+	r.visitors[linkID][fingerprint] = true
+	link.UniqueAccessCount++
+	link.UpdatedAt = r.clock.Now()
+	return true, nil
+}
+
+func (r *memoryRepo) RecordClick(_ context.Context, click ClickEvent) error {
+	const op = "shortener.memoryRepo.RecordClick"
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.links[click.LinkID]; !ok {
+		return errx.E(op, errx.Unavailable, errMemoryLinkID)
+	}
+	r.clicks = append(r.clicks, memoryClick{
+		linkID:    click.LinkID,
+		country:   click.Country,
+		clickedAt: r.clock.Now(),
+	})
+	return nil
+}
+
+func (r *memoryRepo) CountClicksByCountry(context.Context) ([]CountryCount, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	byCountry := make(map[string]int64)
+	for _, c := range r.clicks {
+		byCountry[c.country]++
+	}
+
+	counts := make([]CountryCount, 0, len(byCountry))
+	for country, n := range byCountry {
+		counts = append(counts, CountryCount{Country: country, Clicks: n})
+	}
+	slices.SortFunc(counts, func(a, b CountryCount) int {
+		return cmp.Or(cmp.Compare(b.Clicks, a.Clicks), cmp.Compare(a.Country, b.Country))
+	})
+	return counts, nil
+}
+
+func (r *memoryRepo) SaveCreator(_ context.Context, linkID uuid.UUID, c Creator) error {
+	const op = "shortener.memoryRepo.SaveCreator"
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.links[linkID]; !ok {
+		return errx.E(op, errx.Unavailable, errMemoryLinkID)
+	}
+	if _, ok := r.creators[linkID]; ok {
+		return errx.E(op, errx.Unavailable, errMemoryHasCreator)
+	}
+	r.creators[linkID] = c
+	return nil
+}
+
+func (r *memoryRepo) GetCreator(_ context.Context, linkID uuid.UUID) (Creator, error) {
+	const op = "shortener.memoryRepo.GetCreator"
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c, ok := r.creators[linkID]
+	if !ok {
+		return Creator{}, errx.E(op, errx.NotFound, errMemoryNoCreator)
+	}
+	return c, nil
+}
+
+func (r *memoryRepo) AddAlias(_ context.Context, linkID uuid.UUID, alias string) error {
+	const op = "shortener.memoryRepo.AddAlias"
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.slugs[r.key(alias)]; ok {
+		return errx.E(op, errx.Conflict, errMemorySlugTaken)
+	}
+	if _, ok := r.aliases[r.key(alias)]; ok {
+		return errx.E(op, errx.Conflict, errMemorySlugTaken)
+	}
+	if _, ok := r.links[linkID]; !ok {
+		return errx.E(op, errx.Unavailable, errMemoryLinkID)
+	}
+	r.aliases[r.key(alias)] = memoryAlias{alias: alias, linkID: linkID}
+	return nil
+}
+
+func (r *memoryRepo) SlugForAlias(_ context.Context, alias string) (string, error) {
+	const op = "shortener.memoryRepo.SlugForAlias"
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	a, ok := r.aliases[r.key(alias)]
+	if !ok {
+		return "", errx.E(op, errx.NotFound, errMemoryNoAlias)
+	}
+	return r.links[a.linkID].Slug, nil
+}
+
+func (r *memoryRepo) Aliases(_ context.Context, linkID uuid.UUID) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var aliases []string
+	for _, a := range r.aliases {
+		if a.linkID == linkID {
+			aliases = append(aliases, a.alias)
+		}
+	}
+	slices.Sort(aliases)
+	return aliases, nil
+}
+
+func (r *memoryRepo) ClickTimeSeries(_ context.Context, linkID uuid.UUID, bucket TimeBucket, from, to time.Time) ([]ClickBucket, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	step := bucket.Duration()
+	counts := make(map[int64]int64)
+	for _, c := range r.clicks {
+		if c.linkID == linkID && !c.clickedAt.Before(from) && c.clickedAt.Before(to) {
+			counts[c.clickedAt.UTC().Truncate(step).Unix()]++
+		}
+	}
+	return fillBuckets(counts, bucket, from, to), nil
+}
+
+func (r *memoryRepo) PurgeExpired(_ context.Context, before time.Time, limit int) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.purge(limit, func(link *Link) bool {
+		return link.ExpiresAt != nil && link.ExpiresAt.Before(before)
+	}), nil
+}
+
+func (r *memoryRepo) PurgeDeleted(_ context.Context, before time.Time, limit int) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.purge(limit, func(link *Link) bool {
+		return link.DeletedAt != nil && link.DeletedAt.Before(before)
+	}), nil
+}
+
+// purge hard-deletes up to limit links matching match, along with
+// everything that references them. The caller must hold r.mu.
+func (r *memoryRepo) purge(limit int, match func(*Link) bool) int64 {
+	removed := make(map[uuid.UUID]bool)
+	for id, link := range r.links {
+		if len(removed) >= limit {
+			break
+		}
+		if !match(link) {
+			continue
+		}
+		removed[id] = true
+		delete(r.links, id)
+		delete(r.slugs, r.key(link.Slug))
+		delete(r.visitors, id)
+		delete(r.creators, id)
+	}
+	if len(removed) == 0 {
+		return 0
+	}
+
+	for key, a := range r.aliases {
+		if removed[a.linkID] {
+			delete(r.aliases, key)
+		}
+	}
+	r.clicks = slices.DeleteFunc(r.clicks, func(c memoryClick) bool { return removed[c.linkID] })
+	return int64(len(removed))
+}
@@ -0,0 +1,301 @@
+package shortener
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/sundayezeilo/urlshortener/internal/clock"
+	"github.com/sundayezeilo/urlshortener/internal/errx"
+)
+
+func TestMemoryRepoCreate(t *testing.T) {
+	ctx := context.Background()
+	r := newMemoryRepo(clock.NewFake(time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)))
+
+	link, err := r.Create(ctx, Link{OriginalURL: "https://example.com", Slug: "abc1234", Owner: "alice"})
+	if err != nil {
+		t.Fatalf("Create() unexpected error: %v", err)
+	}
+	if link.ID == uuid.Nil {
+		t.Error("Create() should generate an ID")
+	}
+	if !link.CreatedAt.Equal(r.clock.Now()) || link.Owner != "alice" {
+		t.Errorf("Create() = %+v, want created now for alice", link)
+	}
+
+	if _, err := r.Create(ctx, Link{OriginalURL: "https://example.org", Slug: "abc1234"}); errx.KindOf(err) != errx.Conflict {
+		t.Errorf("Create() duplicate slug error kind = %v, want Conflict", errx.KindOf(err))
+	}
+
+	if err := r.AddAlias(ctx, link.ID, "alias12"); err != nil {
+		t.Fatalf("AddAlias() unexpected error: %v", err)
+	}
+	if _, err := r.Create(ctx, Link{OriginalURL: "https://example.org", Slug: "alias12"}); errx.KindOf(err) != errx.Conflict {
+		t.Errorf("Create() alias slug error kind = %v, want Conflict", errx.KindOf(err))
+	}
+
+	// A soft-deleted link keeps its slug until purged.
+	if _, err := r.Delete(ctx, "abc1234"); err != nil {
+		t.Fatalf("Delete() unexpected error: %v", err)
+	}
+	if _, err := r.Create(ctx, Link{OriginalURL: "https://example.org", Slug: "abc1234"}); errx.KindOf(err) != errx.Conflict {
+		t.Errorf("Create() deleted slug error kind = %v, want Conflict", errx.KindOf(err))
+	}
+}
+
+func TestMemoryRepoCreateOrUpdate(t *testing.T) {
+	ctx := context.Background()
+	r := NewMemoryRepository()
+
+	created, inserted, err := r.CreateOrUpdate(ctx, Link{OriginalURL: "https://example.com/a", Slug: "abc1234"})
+	if err != nil || !inserted {
+		t.Fatalf("CreateOrUpdate() = %v, %v; want inserted", inserted, err)
+	}
+
+	updated, inserted, err := r.CreateOrUpdate(ctx, Link{OriginalURL: "https://example.com/b", Slug: "abc1234"})
+	if err != nil || inserted {
+		t.Fatalf("CreateOrUpdate() = %v, %v; want updated", inserted, err)
+	}
+	if updated.ID != created.ID || updated.OriginalURL != "https://example.com/b" {
+		t.Errorf("CreateOrUpdate() = %+v, want %v pointed at /b", updated, created.ID)
+	}
+
+	if _, err := r.Delete(ctx, "abc1234"); err != nil {
+		t.Fatalf("Delete() unexpected error: %v", err)
+	}
+	if _, _, err := r.CreateOrUpdate(ctx, Link{OriginalURL: "https://example.com/c", Slug: "abc1234"}); errx.KindOf(err) != errx.Conflict {
+		t.Errorf("CreateOrUpdate() deleted slug error kind = %v, want Conflict", errx.KindOf(err))
+	}
+}
+
+func TestMemoryRepoGetBySlug(t *testing.T) {
+	ctx := context.Background()
+	r := NewMemoryRepository()
+
+	created, err := r.Create(ctx, Link{OriginalURL: "https://example.com", Slug: "abc1234"})
+	if err != nil {
+		t.Fatalf("Create() unexpected error: %v", err)
+	}
+
+	got, err := r.GetBySlug(ctx, "abc1234")
+	if err != nil {
+		t.Fatalf("GetBySlug() unexpected error: %v", err)
+	}
+	if got.ID != created.ID || got.OriginalURL != "https://example.com" {
+		t.Errorf("GetBySlug() = %+v, want %+v", got, created)
+	}
+
+	if _, err := r.GetBySlug(ctx, "missing"); errx.KindOf(err) != errx.NotFound {
+		t.Errorf("GetBySlug() unknown slug error kind = %v, want NotFound", errx.KindOf(err))
+	}
+
+	if _, err := r.Delete(ctx, "abc1234"); err != nil {
+		t.Fatalf("Delete() unexpected error: %v", err)
+	}
+	if _, err := r.GetBySlug(ctx, "abc1234"); errx.KindOf(err) != errx.NotFound {
+		t.Errorf("GetBySlug() deleted slug error kind = %v, want NotFound", errx.KindOf(err))
+	}
+}
+
+func TestMemoryRepoCaseInsensitiveSlugs(t *testing.T) {
+	ctx := context.Background()
+	r := newMemoryRepo(clock.Real)
+	r.caseInsensitive = true
+
+	created, err := r.Create(ctx, Link{OriginalURL: "https://example.com", Slug: "MyLink1"})
+	if err != nil {
+		t.Fatalf("Create() unexpected error: %v", err)
+	}
+	if _, err := r.Create(ctx, Link{OriginalURL: "https://example.org", Slug: "mylink1"}); errx.KindOf(err) != errx.Conflict {
+		t.Errorf("Create() case variant error kind = %v, want Conflict", errx.KindOf(err))
+	}
+
+	got, err := r.GetBySlug(ctx, "MYLINK1")
+	if err != nil {
+		t.Fatalf("GetBySlug() unexpected error: %v", err)
+	}
+	if got.ID != created.ID || got.Slug != "MyLink1" {
+		t.Errorf("GetBySlug() = %v %q, want %v with the created case", got.ID, got.Slug, created.ID)
+	}
+
+	taken, err := r.TakenSlugs(ctx, []string{"myLINK1", "other12"})
+	if err != nil {
+		t.Fatalf("TakenSlugs() unexpected error: %v", err)
+	}
+	if !taken["myLINK1"] || taken["other12"] {
+		t.Errorf("TakenSlugs() = %v, want only myLINK1 taken", taken)
+	}
+
+	// Aliases share the folded namespace in both directions.
+	if err := r.AddAlias(ctx, created.ID, "mylink1"); errx.KindOf(err) != errx.Conflict {
+		t.Errorf("AddAlias() case variant of a slug error kind = %v, want Conflict", errx.KindOf(err))
+	}
+	if err := r.AddAlias(ctx, created.ID, "Promo12"); err != nil {
+		t.Fatalf("AddAlias() unexpected error: %v", err)
+	}
+	if _, err := r.Create(ctx, Link{OriginalURL: "https://example.org", Slug: "PROMO12"}); errx.KindOf(err) != errx.Conflict {
+		t.Errorf("Create() case variant of an alias error kind = %v, want Conflict", errx.KindOf(err))
+	}
+	if slug, err := r.SlugForAlias(ctx, "promo12"); err != nil || slug != "MyLink1" {
+		t.Errorf("SlugForAlias() = %q, %v; want MyLink1", slug, err)
+	}
+}
+
+func TestMemoryRepoResolveAndTrack(t *testing.T) {
+	ctx := context.Background()
+	clk := clock.NewFake(time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC))
+	r := newMemoryRepo(clk)
+
+	for _, slug := range []string{"live123", "paused1", "expired", "deleted"} {
+		if _, err := r.Create(ctx, Link{OriginalURL: "https://example.com", Slug: slug}); err != nil {
+			t.Fatalf("Create(%q) unexpected error: %v", slug, err)
+		}
+	}
+	if _, err := r.SetPaused(ctx, "paused1", true); err != nil {
+		t.Fatalf("SetPaused() unexpected error: %v", err)
+	}
+	past := clk.Now().Add(-time.Minute)
+	r.links[r.slugs["expired"]].ExpiresAt = &past
+	if _, err := r.Delete(ctx, "deleted"); err != nil {
+		t.Fatalf("Delete() unexpected error: %v", err)
+	}
+
+	clk.Advance(time.Hour)
+	link, err := r.ResolveAndTrack(ctx, "live123", 0)
+	if err != nil {
+		t.Fatalf("ResolveAndTrack() unexpected error: %v", err)
+	}
+	if link.AccessCount != 1 || link.LastAccessedAt == nil || !link.LastAccessedAt.Equal(clk.Now()) {
+		t.Errorf("ResolveAndTrack() = %+v, want one access tracked now", link)
+	}
+
+	// The count saturates instead of overflowing.
+	r.links[r.slugs["live123"]].AccessCount = math.MaxInt64
+	if link, err := r.ResolveAndTrack(ctx, "live123", 0); err != nil || link.AccessCount != math.MaxInt64 {
+		t.Errorf("ResolveAndTrack() at max = %d, %v; want %d", link.AccessCount, err, int64(math.MaxInt64))
+	}
+
+	for _, slug := range []string{"paused1", "expired", "deleted", "missing"} {
+		if _, err := r.ResolveAndTrack(ctx, slug, 0); errx.KindOf(err) != errx.NotFound {
+			t.Errorf("ResolveAndTrack(%q) error kind = %v, want NotFound", slug, errx.KindOf(err))
+		}
+	}
+}
+
+func TestMemoryRepoDelete(t *testing.T) {
+	ctx := context.Background()
+	r := NewMemoryRepository()
+
+	if _, err := r.Create(ctx, Link{OriginalURL: "https://example.com", Slug: "abc1234"}); err != nil {
+		t.Fatalf("Create() unexpected error: %v", err)
+	}
+
+	link, err := r.Delete(ctx, "abc1234")
+	if err != nil {
+		t.Fatalf("Delete() unexpected error: %v", err)
+	}
+	if link.DeletedAt == nil {
+		t.Error("Delete() should set DeletedAt")
+	}
+
+	if _, err := r.Delete(ctx, "abc1234"); errx.KindOf(err) != errx.NotFound {
+		t.Errorf("Delete() twice error kind = %v, want NotFound", errx.KindOf(err))
+	}
+	if n, _ := r.CountLinks(ctx, CountFilter{Status: LinkStatusDeleted}); n != 1 {
+		t.Errorf("CountLinks(deleted) = %d, want 1", n)
+	}
+}
+
+func TestMemoryRepoPurge(t *testing.T) {
+	ctx := context.Background()
+	clk := clock.NewFake(time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC))
+	r := newMemoryRepo(clk)
+
+	link, err := r.Create(ctx, Link{OriginalURL: "https://example.com", Slug: "abc1234"})
+	if err != nil {
+		t.Fatalf("Create() unexpected error: %v", err)
+	}
+	if err := r.AddAlias(ctx, link.ID, "alias12"); err != nil {
+		t.Fatalf("AddAlias() unexpected error: %v", err)
+	}
+	if err := r.RecordClick(ctx, ClickEvent{LinkID: link.ID}); err != nil {
+		t.Fatalf("RecordClick() unexpected error: %v", err)
+	}
+	if _, err := r.Delete(ctx, "abc1234"); err != nil {
+		t.Fatalf("Delete() unexpected error: %v", err)
+	}
+
+	n, err := r.PurgeDeleted(ctx, clk.Now().Add(time.Second), 10)
+	if err != nil || n != 1 {
+		t.Fatalf("PurgeDeleted() = %d, %v, want 1", n, err)
+	}
+
+	if _, err := r.SlugForAlias(ctx, "alias12"); errx.KindOf(err) != errx.NotFound {
+		t.Errorf("SlugForAlias() after purge error kind = %v, want NotFound", errx.KindOf(err))
+	}
+	if counts, _ := r.CountClicksByCountry(ctx); len(counts) != 0 {
+		t.Errorf("CountClicksByCountry() after purge = %v, want none", counts)
+	}
+	if _, err := r.Create(ctx, Link{OriginalURL: "https://example.org", Slug: "abc1234"}); err != nil {
+		t.Errorf("Create() purged slug unexpected error: %v", err)
+	}
+}
+
+func TestMemoryRepoList(t *testing.T) {
+	ctx := context.Background()
+	clk := clock.NewFake(time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC))
+	r := newMemoryRepo(clk)
+
+	for _, slug := range []string{"first12", "second1", "third12"} {
+		if _, err := r.Create(ctx, Link{OriginalURL: "https://example.com", Slug: slug}); err != nil {
+			t.Fatalf("Create(%q) unexpected error: %v", slug, err)
+		}
+		clk.Advance(time.Second)
+	}
+
+	page, err := r.List(ctx, nil, 2)
+	if err != nil {
+		t.Fatalf("List() unexpected error: %v", err)
+	}
+	if len(page) != 2 || page[0].Slug != "third12" || page[1].Slug != "second1" {
+		t.Fatalf("List() first page = %v, want third12, second1", page)
+	}
+
+	cursor := cursorAfter(page[1])
+	page, err = r.List(ctx, &cursor, 2)
+	if err != nil {
+		t.Fatalf("List() unexpected error: %v", err)
+	}
+	if len(page) != 1 || page[0].Slug != "first12" {
+		t.Errorf("List() second page = %v, want first12", page)
+	}
+}
+
+func TestMemoryRepoWithService(t *testing.T) {
+	ctx := context.Background()
+	svc := NewService(NewMemoryRepository(), &ServiceConfig{})
+
+	link, err := svc.Create(ctx, CreateLinkRequest{OriginalURL: "https://example.com/page"})
+	if err != nil {
+		t.Fatalf("Create() unexpected error: %v", err)
+	}
+
+	res, err := svc.Resolve(ctx, link.Slug)
+	if err != nil {
+		t.Fatalf("Resolve() unexpected error: %v", err)
+	}
+	if res.URL != "https://example.com/page" {
+		t.Errorf("Resolve() URL = %q, want https://example.com/page", res.URL)
+	}
+
+	if err := svc.Delete(ctx, link.Slug); err != nil {
+		t.Fatalf("Delete() unexpected error: %v", err)
+	}
+	if _, err := svc.Resolve(ctx, link.Slug); errx.KindOf(err) != errx.NotFound {
+		t.Errorf("Resolve() deleted link error kind = %v, want NotFound", errx.KindOf(err))
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 
 	"github.com/sundayezeilo/urlshortener/idgen"
+	"github.com/sundayezeilo/urlshortener/internal/clock"
 	db "github.com/sundayezeilo/urlshortener/internal/db/sqlc"
 	"github.com/sundayezeilo/urlshortener/internal/errx"
 )
@@ -18,19 +19,67 @@ import (
 // querier is an internal interface that abstracts *db.Queries
 type querier interface {
 	CreateLink(ctx context.Context, arg db.CreateLinkParams) (db.Link, error)
+	CreateOrUpdateLink(ctx context.Context, arg db.CreateOrUpdateLinkParams) (db.CreateOrUpdateLinkRow, error)
 	GetLinkBySLug(ctx context.Context, slug string) (db.Link, error)
-	ResolveAndTrackLink(ctx context.Context, slug string) (db.Link, error)
-	DeleteLink(ctx context.Context, slug string) error
+	GetLinkByID(ctx context.Context, id uuid.UUID) (db.Link, error)
+	ResolveAndTrackLink(ctx context.Context, arg db.ResolveAndTrackLinkParams) (db.Link, error)
+	DeleteLink(ctx context.Context, slug string) (db.Link, error)
+	SetLinkPaused(ctx context.Context, arg db.SetLinkPausedParams) (db.Link, error)
+	CountLinks(ctx context.Context) (int64, error)
+	CountLinksByOwner(ctx context.Context, owner pgtype.Text) (int64, error)
+	CountLinksBySource(ctx context.Context) ([]db.CountLinksBySourceRow, error)
+	CountLinksFiltered(ctx context.Context, arg db.CountLinksFilteredParams) (int64, error)
+	ListLinks(ctx context.Context, arg db.ListLinksParams) ([]db.Link, error)
+	GetLinksByURL(ctx context.Context, originalUrl string) ([]db.Link, error)
+	GetTakenSlugs(ctx context.Context, slugKeys []string) ([]string, error)
+	PurgeExpiredLinks(ctx context.Context, arg db.PurgeExpiredLinksParams) (int64, error)
+	PurgeDeletedLinks(ctx context.Context, arg db.PurgeDeletedLinksParams) (int64, error)
+	TrackUniqueVisitor(ctx context.Context, arg db.TrackUniqueVisitorParams) (int64, error)
+	RecordClick(ctx context.Context, arg db.RecordClickParams) error
+	CountClicksByCountry(ctx context.Context) ([]db.CountClicksByCountryRow, error)
+	InsertLinkCreator(ctx context.Context, arg db.InsertLinkCreatorParams) error
+	GetLinkCreator(ctx context.Context, linkID uuid.UUID) (db.LinkCreator, error)
+	InsertLinkAlias(ctx context.Context, arg db.InsertLinkAliasParams) error
+	GetSlugByAlias(ctx context.Context, aliasKey string) (string, error)
+	ListLinkAliases(ctx context.Context, linkID uuid.UUID) ([]string, error)
+	GetClickTimeSeries(ctx context.Context, arg db.GetClickTimeSeriesParams) ([]db.GetClickTimeSeriesRow, error)
 }
 
 type repo struct {
-	q   querier
-	ids idgen.Generator
+	q               querier
+	ids             idgen.Generator
+	clock           clock.Clock
+	caseInsensitive bool
 }
 
 // RepositoryConfig holds configuration for the repository
 type RepositoryConfig struct {
 	IDGenerator idgen.Generator
+
+	// BreakerThreshold is the number of consecutive database failures that
+	// open the circuit breaker; 0 disables it. While open, queries fail
+	// immediately with errx.Unavailable for BreakerCooldown
+	// (default: DefaultBreakerCooldown) before a single probe is allowed.
+	BreakerThreshold int
+	BreakerCooldown  time.Duration
+
+	// QueryTimeout bounds each query; one that runs longer fails with
+	// errx.Timeout. 0 leaves queries bounded only by the caller's context.
+	QueryTimeout time.Duration
+
+	// Clock decides which links have expired and drives the breaker
+	// cooldown (default: clock.Real).
+	Clock clock.Clock
+
+	// CaseInsensitiveSlugs makes slugs and aliases unique regardless of
+	// case and finds links by any casing of either, while keeping the case
+	// they were created with. It decides the slug_key and alias_key stored,
+	// so switching it on an existing database needs the keys rewritten, or
+	// the startup self-check fails when SELF_CHECK_SLUG_KEYS is set:
+	// UPDATE links SET slug_key = lower(slug) and UPDATE link_aliases SET
+	// alias_key = lower(alias) (or the plain values to switch back), which
+	// fail while names differing only in case exist.
+	CaseInsensitiveSlugs bool
 }
 
 // NewRepository creates a new Repository implementation
@@ -44,12 +93,32 @@ func NewRepository(q querier, config *RepositoryConfig) Repository {
 		config.IDGenerator = idgen.NewV7(idgen.WithRetries(1))
 	}
 
+	clk := config.Clock
+	if clk == nil {
+		clk = clock.Real
+	}
+
+	// The breaker wraps the timeout so slow queries count as failures.
+	if config.QueryTimeout > 0 {
+		q = &timeoutQuerier{q: q, timeout: config.QueryTimeout}
+	}
+	if config.BreakerThreshold > 0 {
+		q = &breakerQuerier{q: q, b: newCircuitBreaker(config.BreakerThreshold, config.BreakerCooldown, clk)}
+	}
+
 	return &repo{
-		q:   q,
-		ids: config.IDGenerator,
+		q:               q,
+		ids:             config.IDGenerator,
+		clock:           clk,
+		caseInsensitive: config.CaseInsensitiveSlugs,
 	}
 }
 
+// key returns the slug_key slug is stored and looked up under.
+func (r *repo) key(slug string) string {
+	return slugKey(slug, r.caseInsensitive)
+}
+
 func mustTime(ts pgtype.Timestamptz, field string) (time.Time, error) {
 	if !ts.Valid {
 		return time.Time{}, fmt.Errorf("%s unexpectedly NULL", field)
@@ -65,6 +134,13 @@ func timePtr(ts pgtype.Timestamptz) *time.Time {
 	return &t
 }
 
+func timestamptz(t *time.Time) pgtype.Timestamptz {
+	if t == nil {
+		return pgtype.Timestamptz{}
+	}
+	return pgtype.Timestamptz{Time: *t, Valid: true}
+}
+
 func toDomainLink(x db.Link) (Link, error) {
 	createdAt, err := mustTime(x.CreatedAt, "created_at")
 	if err != nil {
@@ -76,16 +152,40 @@ func toDomainLink(x db.Link) (Link, error) {
 	}
 
 	return Link{
-		ID:             x.ID,
-		OriginalURL:    x.OriginalUrl,
-		Slug:           x.Slug,
-		AccessCount:    x.AccessCount,
-		CreatedAt:      createdAt,
-		UpdatedAt:      updatedAt,
-		LastAccessedAt: timePtr(x.LastAccessedAt),
+		ID:                x.ID,
+		OriginalURL:       x.OriginalUrl,
+		Slug:              x.Slug,
+		AccessCount:       x.AccessCount,
+		UniqueAccessCount: x.UniqueAccessCount,
+		CreatedAt:         createdAt,
+		UpdatedAt:         updatedAt,
+		LastAccessedAt:    timePtr(x.LastAccessedAt),
+		ExpiresAt:         timePtr(x.ExpiresAt),
+		DeletedAt:         timePtr(x.DeletedAt),
+		Source:            x.Source.String,
+		Owner:             x.Owner.String,
+		UTM: UTMParams{
+			Source:   x.UtmSource.String,
+			Medium:   x.UtmMedium.String,
+			Campaign: x.UtmCampaign.String,
+		},
+		RedirectStatus: int(x.RedirectStatus.Int16),
+		Paused:         x.Paused,
 	}, nil
 }
 
+func toDomainLinks(op string, rows []db.Link) ([]Link, error) {
+	links := make([]Link, 0, len(rows))
+	for _, row := range rows {
+		link, err := toDomainLink(row)
+		if err != nil {
+			return nil, errx.E(op, errx.Internal, err)
+		}
+		links = append(links, link)
+	}
+	return links, nil
+}
+
 func mapRepoError(op string, err error) error {
 	switch {
 	case errors.Is(err, pgx.ErrNoRows):
@@ -94,6 +194,9 @@ func mapRepoError(op string, err error) error {
 	case isSlugUniqueViolation(err):
 		return errx.E(op, errx.Conflict, err)
 
+	case errors.Is(err, context.DeadlineExceeded):
+		return errx.E(op, errx.Timeout, err)
+
 	default:
 		return errx.E(op, errx.Unavailable, err)
 	}
@@ -111,11 +214,7 @@ func (r *repo) Create(ctx context.Context, link Link) (Link, error) {
 		link.ID = id
 	}
 
-	row, err := r.q.CreateLink(ctx, db.CreateLinkParams{
-		ID:          link.ID,
-		OriginalUrl: link.OriginalURL,
-		Slug:        link.Slug,
-	})
+	row, err := r.q.CreateLink(ctx, r.createLinkParams(link))
 	if err != nil {
 		return Link{}, mapRepoError(op, err)
 	}
@@ -123,30 +222,389 @@ func (r *repo) Create(ctx context.Context, link Link) (Link, error) {
 	return toDomainLink(row)
 }
 
+// CreateOrUpdate inserts link, or if a live link already has its slug,
+// points that link at link.OriginalURL, in one atomic statement. It reports
+// whether link was inserted. A slug held by a soft-deleted link or an
+// alias fails with errx.Conflict.
+func (r *repo) CreateOrUpdate(ctx context.Context, link Link) (Link, bool, error) {
+	const op = "shortener.repo.CreateOrUpdate"
+
+	if link.ID == uuid.Nil {
+		id, err := r.ids.Generate()
+		if err != nil {
+			return Link{}, false, errx.E(op, errx.Internal, err)
+		}
+		link.ID = id
+	}
+
+	row, err := r.q.CreateOrUpdateLink(ctx, db.CreateOrUpdateLinkParams(r.createLinkParams(link)))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Link{}, false, errx.E(op, errx.Conflict, errors.New("slug is held by a deleted link"))
+	}
+	if err != nil {
+		return Link{}, false, mapRepoError(op, err)
+	}
+
+	stored, err := toDomainLink(db.Link{
+		ID:                row.ID,
+		OriginalUrl:       row.OriginalUrl,
+		Slug:              row.Slug,
+		AccessCount:       row.AccessCount,
+		CreatedAt:         row.CreatedAt,
+		UpdatedAt:         row.UpdatedAt,
+		LastAccessedAt:    row.LastAccessedAt,
+		UniqueAccessCount: row.UniqueAccessCount,
+		ExpiresAt:         row.ExpiresAt,
+		DeletedAt:         row.DeletedAt,
+		Source:            row.Source,
+		Owner:             row.Owner,
+		UtmSource:         row.UtmSource,
+		UtmMedium:         row.UtmMedium,
+		UtmCampaign:       row.UtmCampaign,
+		RedirectStatus:    row.RedirectStatus,
+		Paused:            row.Paused,
+	})
+	if err != nil {
+		return Link{}, false, err
+	}
+	return stored, row.Inserted, nil
+}
+
+// createLinkParams maps the columns of link that a create inserts.
+func (r *repo) createLinkParams(link Link) db.CreateLinkParams {
+	return db.CreateLinkParams{
+		ID:          link.ID,
+		OriginalUrl: link.OriginalURL,
+		Slug:        link.Slug,
+		SlugKey:     r.key(link.Slug),
+		Source:      pgtype.Text{String: link.Source, Valid: link.Source != ""},
+		Owner:       pgtype.Text{String: link.Owner, Valid: link.Owner != ""},
+		UtmSource:   pgtype.Text{String: link.UTM.Source, Valid: link.UTM.Source != ""},
+		UtmMedium:   pgtype.Text{String: link.UTM.Medium, Valid: link.UTM.Medium != ""},
+		UtmCampaign: pgtype.Text{String: link.UTM.Campaign, Valid: link.UTM.Campaign != ""},
+
+		RedirectStatus: pgtype.Int2{Int16: int16(link.RedirectStatus), Valid: link.RedirectStatus != 0},
+		ExpiresAt:      timestamptz(link.ExpiresAt),
+	}
+}
+
 func (r *repo) GetBySlug(ctx context.Context, slug string) (Link, error) {
 	const op = "shortener.repo.GetBySlug"
 
-	row, err := r.q.GetLinkBySLug(ctx, slug)
+	row, err := r.q.GetLinkBySLug(ctx, r.key(slug))
 	if err != nil {
 		return Link{}, mapRepoError(op, err)
 	}
 	return toDomainLink(row)
 }
 
-func (r *repo) ResolveAndTrack(ctx context.Context, slug string) (Link, error) {
+func (r *repo) GetByID(ctx context.Context, id uuid.UUID) (Link, error) {
+	const op = "shortener.repo.GetByID"
+
+	row, err := r.q.GetLinkByID(ctx, id)
+	if err != nil {
+		return Link{}, mapRepoError(op, err)
+	}
+	return toDomainLink(row)
+}
+
+func (r *repo) ResolveAndTrack(ctx context.Context, slug string, expiryGrace time.Duration) (Link, error) {
 	const op = "shortener.repo.ResolveAndTrack"
 
-	row, err := r.q.ResolveAndTrackLink(ctx, slug)
+	now := r.clock.Now()
+	row, err := r.q.ResolveAndTrackLink(ctx, db.ResolveAndTrackLinkParams{
+		Now:          pgtype.Timestamptz{Time: now, Valid: true},
+		SlugKey:      r.key(slug),
+		ExpiresAfter: pgtype.Timestamptz{Time: now.Add(-expiryGrace), Valid: true},
+	})
 	if err != nil {
 		return Link{}, mapRepoError(op, err)
 	}
 	return toDomainLink(row)
 }
 
-func (r *repo) Delete(ctx context.Context, slug string) error {
+func (r *repo) Delete(ctx context.Context, slug string) (Link, error) {
 	const op = "shortener.repo.Delete"
-	if err := r.q.DeleteLink(ctx, slug); err != nil {
+
+	row, err := r.q.DeleteLink(ctx, r.key(slug))
+	if err != nil {
+		return Link{}, mapRepoError(op, err)
+	}
+	return toDomainLink(row)
+}
+
+func (r *repo) SetPaused(ctx context.Context, slug string, paused bool) (Link, error) {
+	const op = "shortener.repo.SetPaused"
+
+	row, err := r.q.SetLinkPaused(ctx, db.SetLinkPausedParams{Paused: paused, SlugKey: r.key(slug)})
+	if err != nil {
+		return Link{}, mapRepoError(op, err)
+	}
+	return toDomainLink(row)
+}
+
+func (r *repo) Count(ctx context.Context) (int64, error) {
+	const op = "shortener.repo.Count"
+
+	n, err := r.q.CountLinks(ctx)
+	if err != nil {
+		return 0, mapRepoError(op, err)
+	}
+	return n, nil
+}
+
+func (r *repo) CountByOwner(ctx context.Context, owner string) (int64, error) {
+	const op = "shortener.repo.CountByOwner"
+
+	n, err := r.q.CountLinksByOwner(ctx, pgtype.Text{String: owner, Valid: true})
+	if err != nil {
+		return 0, mapRepoError(op, err)
+	}
+	return n, nil
+}
+
+func (r *repo) CountLinks(ctx context.Context, filter CountFilter) (int64, error) {
+	const op = "shortener.repo.CountLinks"
+
+	n, err := r.q.CountLinksFiltered(ctx, db.CountLinksFilteredParams{
+		Owner:  pgtype.Text{String: filter.Owner, Valid: filter.Owner != ""},
+		Status: string(filter.Status),
+		Now:    pgtype.Timestamptz{Time: r.clock.Now(), Valid: true},
+	})
+	if err != nil {
+		return 0, mapRepoError(op, err)
+	}
+	return n, nil
+}
+
+func (r *repo) CountBySource(ctx context.Context) ([]SourceCount, error) {
+	const op = "shortener.repo.CountBySource"
+
+	rows, err := r.q.CountLinksBySource(ctx)
+	if err != nil {
+		return nil, mapRepoError(op, err)
+	}
+
+	counts := make([]SourceCount, 0, len(rows))
+	for _, row := range rows {
+		counts = append(counts, SourceCount{Source: row.Source, Links: row.Links})
+	}
+	return counts, nil
+}
+
+func (r *repo) List(ctx context.Context, after *LinkCursor, limit int) ([]Link, error) {
+	const op = "shortener.repo.List"
+
+	params := db.ListLinksParams{RowLimit: int32(limit)}
+	if after != nil {
+		params.CursorCreatedAt = pgtype.Timestamptz{Time: after.CreatedAt, Valid: true}
+		params.CursorID = pgtype.UUID{Bytes: after.ID, Valid: true}
+	}
+
+	rows, err := r.q.ListLinks(ctx, params)
+	if err != nil {
+		return nil, mapRepoError(op, err)
+	}
+	return toDomainLinks(op, rows)
+}
+
+func (r *repo) ListByURL(ctx context.Context, originalURL string) ([]Link, error) {
+	const op = "shortener.repo.ListByURL"
+
+	rows, err := r.q.GetLinksByURL(ctx, originalURL)
+	if err != nil {
+		return nil, mapRepoError(op, err)
+	}
+	return toDomainLinks(op, rows)
+}
+
+func (r *repo) TakenSlugs(ctx context.Context, slugs []string) (map[string]bool, error) {
+	const op = "shortener.repo.TakenSlugs"
+
+	if len(slugs) == 0 {
+		return map[string]bool{}, nil
+	}
+
+	keys := make([]string, len(slugs))
+	for i, slug := range slugs {
+		keys[i] = r.key(slug)
+	}
+
+	rows, err := r.q.GetTakenSlugs(ctx, keys)
+	if err != nil {
+		return nil, mapRepoError(op, err)
+	}
+
+	// Rows are slug or alias keys; map them back to the requested slugs
+	found := make(map[string]bool, len(rows))
+	for _, row := range rows {
+		found[row] = true
+	}
+	taken := make(map[string]bool, len(rows))
+	for i, slug := range slugs {
+		if found[keys[i]] {
+			taken[slug] = true
+		}
+	}
+	return taken, nil
+}
+
+func (r *repo) TrackUniqueVisitor(ctx context.Context, linkID uuid.UUID, fingerprint string) (bool, error) {
+	const op = "shortener.repo.TrackUniqueVisitor"
+
+	n, err := r.q.TrackUniqueVisitor(ctx, db.TrackUniqueVisitorParams{
+		LinkID:      linkID,
+		Fingerprint: fingerprint,
+	})
+	if err != nil {
+		return false, mapRepoError(op, err)
+	}
+	return n > 0, nil
+}
+
+func (r *repo) RecordClick(ctx context.Context, click ClickEvent) error {
+	const op = "shortener.repo.RecordClick"
+
+	err := r.q.RecordClick(ctx, db.RecordClickParams{
+		LinkID:    click.LinkID,
+		RequestID: pgtype.Text{String: click.RequestID, Valid: click.RequestID != ""},
+		Country:   pgtype.Text{String: click.Country, Valid: click.Country != ""},
+	})
+	if err != nil {
+		return mapRepoError(op, err)
+	}
+	return nil
+}
+
+func (r *repo) CountClicksByCountry(ctx context.Context) ([]CountryCount, error) {
+	const op = "shortener.repo.CountClicksByCountry"
+
+	rows, err := r.q.CountClicksByCountry(ctx)
+	if err != nil {
+		return nil, mapRepoError(op, err)
+	}
+
+	counts := make([]CountryCount, 0, len(rows))
+	for _, row := range rows {
+		counts = append(counts, CountryCount{Country: row.Country, Clicks: row.Clicks})
+	}
+	return counts, nil
+}
+
+func (r *repo) SaveCreator(ctx context.Context, linkID uuid.UUID, c Creator) error {
+	const op = "shortener.repo.SaveCreator"
+
+	err := r.q.InsertLinkCreator(ctx, db.InsertLinkCreatorParams{
+		LinkID:           linkID,
+		CreatedIp:        pgtype.Text{String: c.IP, Valid: c.IP != ""},
+		CreatedUserAgent: pgtype.Text{String: c.UserAgent, Valid: c.UserAgent != ""},
+	})
+	if err != nil {
 		return mapRepoError(op, err)
 	}
 	return nil
 }
+
+func (r *repo) GetCreator(ctx context.Context, linkID uuid.UUID) (Creator, error) {
+	const op = "shortener.repo.GetCreator"
+
+	row, err := r.q.GetLinkCreator(ctx, linkID)
+	if err != nil {
+		return Creator{}, mapRepoError(op, err)
+	}
+	return Creator{IP: row.CreatedIp.String, UserAgent: row.CreatedUserAgent.String}, nil
+}
+
+func (r *repo) AddAlias(ctx context.Context, linkID uuid.UUID, alias string) error {
+	const op = "shortener.repo.AddAlias"
+
+	err := r.q.InsertLinkAlias(ctx, db.InsertLinkAliasParams{Alias: alias, LinkID: linkID, AliasKey: r.key(alias)})
+	if err != nil {
+		return mapRepoError(op, err)
+	}
+	return nil
+}
+
+func (r *repo) SlugForAlias(ctx context.Context, alias string) (string, error) {
+	const op = "shortener.repo.SlugForAlias"
+
+	slug, err := r.q.GetSlugByAlias(ctx, r.key(alias))
+	if err != nil {
+		return "", mapRepoError(op, err)
+	}
+	return slug, nil
+}
+
+func (r *repo) Aliases(ctx context.Context, linkID uuid.UUID) ([]string, error) {
+	const op = "shortener.repo.Aliases"
+
+	aliases, err := r.q.ListLinkAliases(ctx, linkID)
+	if err != nil {
+		return nil, mapRepoError(op, err)
+	}
+	return aliases, nil
+}
+
+func (r *repo) ClickTimeSeries(ctx context.Context, linkID uuid.UUID, bucket TimeBucket, from, to time.Time) ([]ClickBucket, error) {
+	const op = "shortener.repo.ClickTimeSeries"
+
+	rows, err := r.q.GetClickTimeSeries(ctx, db.GetClickTimeSeriesParams{
+		Bucket:   string(bucket),
+		LinkID:   linkID,
+		FromTime: pgtype.Timestamptz{Time: from, Valid: true},
+		ToTime:   pgtype.Timestamptz{Time: to, Valid: true},
+	})
+	if err != nil {
+		return nil, mapRepoError(op, err)
+	}
+
+	counts := make(map[int64]int64, len(rows))
+	for _, row := range rows {
+		start, err := mustTime(row.BucketStart, "bucket_start")
+		if err != nil {
+			return nil, errx.E(op, errx.Internal, err)
+		}
+		counts[start.Unix()] += row.Clicks
+	}
+	return fillBuckets(counts, bucket, from, to), nil
+}
+
+// fillBuckets lays out one bucket per step from the bucket containing from
+// up to to, taking counts by bucket start (Unix seconds) and zero otherwise.
+// UTC days and hours are whole multiples of their width since the zero
+// time, so Truncate matches Postgres' date_trunc in UTC.
+func fillBuckets(counts map[int64]int64, bucket TimeBucket, from, to time.Time) []ClickBucket {
+	step := bucket.Duration()
+
+	var buckets []ClickBucket
+	for start := from.UTC().Truncate(step); start.Before(to); start = start.Add(step) {
+		buckets = append(buckets, ClickBucket{Start: start, Clicks: counts[start.Unix()]})
+	}
+	return buckets
+}
+
+func (r *repo) PurgeExpired(ctx context.Context, before time.Time, limit int) (int64, error) {
+	const op = "shortener.repo.PurgeExpired"
+
+	n, err := r.q.PurgeExpiredLinks(ctx, db.PurgeExpiredLinksParams{
+		ExpiredBefore: pgtype.Timestamptz{Time: before, Valid: true},
+		BatchSize:     int32(limit),
+	})
+	if err != nil {
+		return 0, mapRepoError(op, err)
+	}
+	return n, nil
+}
+
+func (r *repo) PurgeDeleted(ctx context.Context, before time.Time, limit int) (int64, error) {
+	const op = "shortener.repo.PurgeDeleted"
+
+	n, err := r.q.PurgeDeletedLinks(ctx, db.PurgeDeletedLinksParams{
+		DeletedBefore: pgtype.Timestamptz{Time: before, Valid: true},
+		BatchSize:     int32(limit),
+	})
+	if err != nil {
+		return 0, mapRepoError(op, err)
+	}
+	return n, nil
+}
@@ -3,6 +3,8 @@ package shortener
 import (
 	"context"
 	"errors"
+	"maps"
+	"slices"
 	"testing"
 	"time"
 
@@ -11,6 +13,7 @@ import (
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgtype"
 
+	"github.com/sundayezeilo/urlshortener/internal/clock"
 	db "github.com/sundayezeilo/urlshortener/internal/db/sqlc"
 	"github.com/sundayezeilo/urlshortener/internal/errx"
 )
@@ -22,9 +25,30 @@ import (
 // mockQueries implements the querier interface for testing.
 type mockQueries struct {
 	createLinkFunc      func(ctx context.Context, params db.CreateLinkParams) (db.Link, error)
+	upsertLinkFunc      func(ctx context.Context, params db.CreateOrUpdateLinkParams) (db.CreateOrUpdateLinkRow, error)
 	getLinkBySlugFunc   func(ctx context.Context, slug string) (db.Link, error)
-	resolveAndTrackFunc func(ctx context.Context, slug string) (db.Link, error)
-	deleteLinkFunc      func(ctx context.Context, slug string) error
+	getLinkByIDFunc     func(ctx context.Context, id uuid.UUID) (db.Link, error)
+	resolveAndTrackFunc func(ctx context.Context, arg db.ResolveAndTrackLinkParams) (db.Link, error)
+	deleteLinkFunc      func(ctx context.Context, slug string) (db.Link, error)
+	setPausedFunc       func(ctx context.Context, arg db.SetLinkPausedParams) (db.Link, error)
+	countLinksFunc      func(ctx context.Context) (int64, error)
+	countBySourceFunc   func(ctx context.Context) ([]db.CountLinksBySourceRow, error)
+	countByOwnerFunc    func(ctx context.Context, owner pgtype.Text) (int64, error)
+	countFilteredFunc   func(ctx context.Context, arg db.CountLinksFilteredParams) (int64, error)
+	trackVisitorFunc    func(ctx context.Context, arg db.TrackUniqueVisitorParams) (int64, error)
+	listLinksFunc       func(ctx context.Context, arg db.ListLinksParams) ([]db.Link, error)
+	getLinksByURLFunc   func(ctx context.Context, originalUrl string) ([]db.Link, error)
+	getTakenSlugsFunc   func(ctx context.Context, slugKeys []string) ([]string, error)
+	recordClickFunc     func(ctx context.Context, arg db.RecordClickParams) error
+	countByCountryFunc  func(ctx context.Context) ([]db.CountClicksByCountryRow, error)
+	insertCreatorFunc   func(ctx context.Context, arg db.InsertLinkCreatorParams) error
+	getCreatorFunc      func(ctx context.Context, linkID uuid.UUID) (db.LinkCreator, error)
+	insertAliasFunc     func(ctx context.Context, arg db.InsertLinkAliasParams) error
+	slugByAliasFunc     func(ctx context.Context, alias string) (string, error)
+	listAliasesFunc     func(ctx context.Context, linkID uuid.UUID) ([]string, error)
+	clickSeriesFunc     func(ctx context.Context, arg db.GetClickTimeSeriesParams) ([]db.GetClickTimeSeriesRow, error)
+	purgeExpiredFunc    func(ctx context.Context, arg db.PurgeExpiredLinksParams) (int64, error)
+	purgeDeletedFunc    func(ctx context.Context, arg db.PurgeDeletedLinksParams) (int64, error)
 }
 
 func (m *mockQueries) CreateLink(ctx context.Context, params db.CreateLinkParams) (db.Link, error) {
@@ -34,6 +58,13 @@ func (m *mockQueries) CreateLink(ctx context.Context, params db.CreateLinkParams
 	return db.Link{}, nil
 }
 
+func (m *mockQueries) CreateOrUpdateLink(ctx context.Context, params db.CreateOrUpdateLinkParams) (db.CreateOrUpdateLinkRow, error) {
+	if m.upsertLinkFunc != nil {
+		return m.upsertLinkFunc(ctx, params)
+	}
+	return db.CreateOrUpdateLinkRow{}, nil
+}
+
 func (m *mockQueries) GetLinkBySLug(ctx context.Context, slug string) (db.Link, error) {
 	if m.getLinkBySlugFunc != nil {
 		return m.getLinkBySlugFunc(ctx, slug)
@@ -41,20 +72,160 @@ func (m *mockQueries) GetLinkBySLug(ctx context.Context, slug string) (db.Link,
 	return db.Link{}, nil
 }
 
-func (m *mockQueries) ResolveAndTrackLink(ctx context.Context, slug string) (db.Link, error) {
+func (m *mockQueries) GetLinkByID(ctx context.Context, id uuid.UUID) (db.Link, error) {
+	if m.getLinkByIDFunc != nil {
+		return m.getLinkByIDFunc(ctx, id)
+	}
+	return db.Link{}, nil
+}
+
+func (m *mockQueries) ResolveAndTrackLink(ctx context.Context, arg db.ResolveAndTrackLinkParams) (db.Link, error) {
 	if m.resolveAndTrackFunc != nil {
-		return m.resolveAndTrackFunc(ctx, slug)
+		return m.resolveAndTrackFunc(ctx, arg)
 	}
 	return db.Link{}, nil
 }
 
-func (m *mockQueries) DeleteLink(ctx context.Context, slug string) error {
+func (m *mockQueries) DeleteLink(ctx context.Context, slug string) (db.Link, error) {
 	if m.deleteLinkFunc != nil {
 		return m.deleteLinkFunc(ctx, slug)
 	}
+	return db.Link{}, nil
+}
+
+func (m *mockQueries) SetLinkPaused(ctx context.Context, arg db.SetLinkPausedParams) (db.Link, error) {
+	if m.setPausedFunc != nil {
+		return m.setPausedFunc(ctx, arg)
+	}
+	return db.Link{}, nil
+}
+
+func (m *mockQueries) CountLinks(ctx context.Context) (int64, error) {
+	if m.countLinksFunc != nil {
+		return m.countLinksFunc(ctx)
+	}
+	return 0, nil
+}
+
+func (m *mockQueries) CountLinksByOwner(ctx context.Context, owner pgtype.Text) (int64, error) {
+	if m.countByOwnerFunc != nil {
+		return m.countByOwnerFunc(ctx, owner)
+	}
+	return 0, nil
+}
+
+func (m *mockQueries) CountLinksFiltered(ctx context.Context, arg db.CountLinksFilteredParams) (int64, error) {
+	if m.countFilteredFunc != nil {
+		return m.countFilteredFunc(ctx, arg)
+	}
+	return 0, nil
+}
+
+func (m *mockQueries) CountLinksBySource(ctx context.Context) ([]db.CountLinksBySourceRow, error) {
+	if m.countBySourceFunc != nil {
+		return m.countBySourceFunc(ctx)
+	}
+	return nil, nil
+}
+
+func (m *mockQueries) TrackUniqueVisitor(ctx context.Context, arg db.TrackUniqueVisitorParams) (int64, error) {
+	if m.trackVisitorFunc != nil {
+		return m.trackVisitorFunc(ctx, arg)
+	}
+	return 0, nil
+}
+
+func (m *mockQueries) ListLinks(ctx context.Context, arg db.ListLinksParams) ([]db.Link, error) {
+	if m.listLinksFunc != nil {
+		return m.listLinksFunc(ctx, arg)
+	}
+	return nil, nil
+}
+
+func (m *mockQueries) GetLinksByURL(ctx context.Context, originalUrl string) ([]db.Link, error) {
+	if m.getLinksByURLFunc != nil {
+		return m.getLinksByURLFunc(ctx, originalUrl)
+	}
+	return nil, nil
+}
+
+func (m *mockQueries) GetTakenSlugs(ctx context.Context, slugKeys []string) ([]string, error) {
+	if m.getTakenSlugsFunc != nil {
+		return m.getTakenSlugsFunc(ctx, slugKeys)
+	}
+	return nil, nil
+}
+
+func (m *mockQueries) RecordClick(ctx context.Context, arg db.RecordClickParams) error {
+	if m.recordClickFunc != nil {
+		return m.recordClickFunc(ctx, arg)
+	}
+	return nil
+}
+
+func (m *mockQueries) CountClicksByCountry(ctx context.Context) ([]db.CountClicksByCountryRow, error) {
+	if m.countByCountryFunc != nil {
+		return m.countByCountryFunc(ctx)
+	}
+	return nil, nil
+}
+
+func (m *mockQueries) InsertLinkCreator(ctx context.Context, arg db.InsertLinkCreatorParams) error {
+	if m.insertCreatorFunc != nil {
+		return m.insertCreatorFunc(ctx, arg)
+	}
+	return nil
+}
+
+func (m *mockQueries) GetLinkCreator(ctx context.Context, linkID uuid.UUID) (db.LinkCreator, error) {
+	if m.getCreatorFunc != nil {
+		return m.getCreatorFunc(ctx, linkID)
+	}
+	return db.LinkCreator{}, nil
+}
+
+func (m *mockQueries) InsertLinkAlias(ctx context.Context, arg db.InsertLinkAliasParams) error {
+	if m.insertAliasFunc != nil {
+		return m.insertAliasFunc(ctx, arg)
+	}
 	return nil
 }
 
+func (m *mockQueries) GetSlugByAlias(ctx context.Context, aliasKey string) (string, error) {
+	if m.slugByAliasFunc != nil {
+		return m.slugByAliasFunc(ctx, aliasKey)
+	}
+	return "", pgx.ErrNoRows
+}
+
+func (m *mockQueries) ListLinkAliases(ctx context.Context, linkID uuid.UUID) ([]string, error) {
+	if m.listAliasesFunc != nil {
+		return m.listAliasesFunc(ctx, linkID)
+	}
+	return nil, nil
+}
+
+func (m *mockQueries) GetClickTimeSeries(ctx context.Context, arg db.GetClickTimeSeriesParams) ([]db.GetClickTimeSeriesRow, error) {
+	if m.clickSeriesFunc != nil {
+		return m.clickSeriesFunc(ctx, arg)
+	}
+	return nil, nil
+}
+
+func (m *mockQueries) PurgeExpiredLinks(ctx context.Context, arg db.PurgeExpiredLinksParams) (int64, error) {
+	if m.purgeExpiredFunc != nil {
+		return m.purgeExpiredFunc(ctx, arg)
+	}
+	return 0, nil
+}
+
+func (m *mockQueries) PurgeDeletedLinks(ctx context.Context, arg db.PurgeDeletedLinksParams) (int64, error) {
+	if m.purgeDeletedFunc != nil {
+		return m.purgeDeletedFunc(ctx, arg)
+	}
+	return 0, nil
+}
+
 // stubIDGen lets tests control generated IDs deterministically.
 type stubIDGen struct {
 	id    uuid.UUID
@@ -459,6 +630,333 @@ func TestRepoCreate(t *testing.T) {
 	})
 }
 
+func TestRepoCreate_ExpiresAt(t *testing.T) {
+	now := time.Date(2026, 4, 1, 10, 0, 0, 0, time.UTC)
+	expiresAt := now.Add(24 * time.Hour)
+
+	var got pgtype.Timestamptz
+	mock := &mockQueries{
+		createLinkFunc: func(_ context.Context, params db.CreateLinkParams) (db.Link, error) {
+			got = params.ExpiresAt
+			return makeTestDBLink(now), nil
+		},
+	}
+	r := NewRepository(mock, &RepositoryConfig{IDGenerator: &stubIDGen{id: makeUUIDv7Deterministic()}})
+
+	link := makeTestLink(now)
+	if _, err := r.Create(context.Background(), link); err != nil {
+		t.Fatalf("Create() unexpected error: %v", err)
+	}
+	if got.Valid {
+		t.Errorf("params.ExpiresAt = %v, want NULL for a link without expiry", got.Time)
+	}
+
+	link.ExpiresAt = &expiresAt
+	if _, err := r.Create(context.Background(), link); err != nil {
+		t.Fatalf("Create() unexpected error: %v", err)
+	}
+	if !got.Valid || !got.Time.Equal(expiresAt) {
+		t.Errorf("params.ExpiresAt = %v (valid %v), want %v", got.Time, got.Valid, expiresAt)
+	}
+}
+
+func TestRepoCreateOrUpdate(t *testing.T) {
+	now := time.Date(2026, 4, 1, 10, 0, 0, 0, time.UTC)
+
+	// upsertRow returns the row the query produces for params, as an
+	// insert or as an update of an existing link created a day earlier.
+	upsertRow := func(params db.CreateOrUpdateLinkParams, inserted bool) db.CreateOrUpdateLinkRow {
+		row := db.CreateOrUpdateLinkRow{
+			ID:          params.ID,
+			OriginalUrl: params.OriginalUrl,
+			Slug:        params.Slug,
+			CreatedAt:   makeValidTimestamp(now),
+			UpdatedAt:   makeValidTimestamp(now),
+			Inserted:    inserted,
+		}
+		if !inserted {
+			row.ID = uuid.MustParse("0194a9f0-0000-7000-8000-0000000000aa")
+			row.AccessCount = 42
+			row.CreatedAt = makeValidTimestamp(now.Add(-24 * time.Hour))
+		}
+		return row
+	}
+
+	t.Run("inserts a new link", func(t *testing.T) {
+		var got db.CreateOrUpdateLinkParams
+		mock := &mockQueries{
+			upsertLinkFunc: func(_ context.Context, params db.CreateOrUpdateLinkParams) (db.CreateOrUpdateLinkRow, error) {
+				got = params
+				return upsertRow(params, true), nil
+			},
+		}
+		wantID := makeUUIDv7Deterministic()
+		r := NewRepository(mock, &RepositoryConfig{IDGenerator: &stubIDGen{id: wantID}})
+
+		link := makeTestLink(now)
+		link.Owner = "alice"
+		stored, inserted, err := r.CreateOrUpdate(context.Background(), link)
+		if err != nil {
+			t.Fatalf("CreateOrUpdate() unexpected error: %v", err)
+		}
+		if !inserted {
+			t.Error("inserted=false want true")
+		}
+		if got.ID != wantID || got.Slug != "test-slug" || got.Owner.String != "alice" {
+			t.Errorf("params=%+v want generated ID, slug and owner", got)
+		}
+		if stored.ID != wantID || stored.OriginalURL != "https://example.com" {
+			t.Errorf("stored=%+v want the inserted link", stored)
+		}
+	})
+
+	t.Run("updates the live link with the slug", func(t *testing.T) {
+		mock := &mockQueries{
+			upsertLinkFunc: func(_ context.Context, params db.CreateOrUpdateLinkParams) (db.CreateOrUpdateLinkRow, error) {
+				return upsertRow(params, false), nil
+			},
+		}
+		r := NewRepository(mock, &RepositoryConfig{IDGenerator: &stubIDGen{id: makeUUIDv7Deterministic()}})
+
+		link := makeTestLink(now)
+		link.OriginalURL = "https://example.com/new"
+		stored, inserted, err := r.CreateOrUpdate(context.Background(), link)
+		if err != nil {
+			t.Fatalf("CreateOrUpdate() unexpected error: %v", err)
+		}
+		if inserted {
+			t.Error("inserted=true want false")
+		}
+		if stored.OriginalURL != "https://example.com/new" || stored.AccessCount != 42 ||
+			!stored.CreatedAt.Equal(now.Add(-24*time.Hour)) {
+			t.Errorf("stored=%+v want the existing link pointed at the new URL", stored)
+		}
+	})
+
+	t.Run("returns Conflict for a slug held by a deleted link", func(t *testing.T) {
+		mock := &mockQueries{
+			upsertLinkFunc: func(_ context.Context, _ db.CreateOrUpdateLinkParams) (db.CreateOrUpdateLinkRow, error) {
+				return db.CreateOrUpdateLinkRow{}, pgx.ErrNoRows
+			},
+		}
+		r := NewRepository(mock, &RepositoryConfig{IDGenerator: &stubIDGen{id: makeUUIDv7Deterministic()}})
+
+		_, _, err := r.CreateOrUpdate(context.Background(), makeTestLink(now))
+		if errx.KindOf(err) != errx.Conflict {
+			t.Errorf("KindOf(err)=%v want %v", errx.KindOf(err), errx.Conflict)
+		}
+		if errx.OpOf(err) != "shortener.repo.CreateOrUpdate" {
+			t.Errorf("OpOf(err)=%q want %q", errx.OpOf(err), "shortener.repo.CreateOrUpdate")
+		}
+	})
+
+	t.Run("returns Conflict for a slug used by an alias", func(t *testing.T) {
+		mock := &mockQueries{
+			upsertLinkFunc: func(_ context.Context, _ db.CreateOrUpdateLinkParams) (db.CreateOrUpdateLinkRow, error) {
+				return db.CreateOrUpdateLinkRow{}, &pgconn.PgError{Code: "23505", ConstraintName: "links_slug_unique"}
+			},
+		}
+		r := NewRepository(mock, &RepositoryConfig{IDGenerator: &stubIDGen{id: makeUUIDv7Deterministic()}})
+
+		if _, _, err := r.CreateOrUpdate(context.Background(), makeTestLink(now)); errx.KindOf(err) != errx.Conflict {
+			t.Errorf("KindOf(err)=%v want %v", errx.KindOf(err), errx.Conflict)
+		}
+	})
+}
+
+func TestRepoCreate_Source(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   pgtype.Text
+	}{
+		{"stores the source", SourceDashboard, pgtype.Text{String: SourceDashboard, Valid: true}},
+		{"stores NULL when untagged", "", pgtype.Text{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Now()
+			mock := &mockQueries{
+				createLinkFunc: func(_ context.Context, params db.CreateLinkParams) (db.Link, error) {
+					if params.Source != tt.want {
+						t.Errorf("params.Source=%+v want %+v", params.Source, tt.want)
+					}
+					row := makeTestDBLink(now)
+					row.Source = params.Source
+					return row, nil
+				},
+			}
+
+			r := NewRepository(mock, &RepositoryConfig{IDGenerator: &stubIDGen{id: makeUUIDv7Deterministic()}})
+
+			link := makeTestLink(now)
+			link.Source = tt.source
+			got, err := r.Create(context.Background(), link)
+			if err != nil {
+				t.Fatalf("Create() unexpected error: %v", err)
+			}
+			if got.Source != tt.source {
+				t.Errorf("created.Source=%q want %q", got.Source, tt.source)
+			}
+		})
+	}
+}
+
+func TestRepoCreate_UTM(t *testing.T) {
+	now := time.Now()
+	mock := &mockQueries{
+		createLinkFunc: func(_ context.Context, params db.CreateLinkParams) (db.Link, error) {
+			if want := (pgtype.Text{String: "newsletter", Valid: true}); params.UtmSource != want {
+				t.Errorf("params.UtmSource=%+v want %+v", params.UtmSource, want)
+			}
+			if params.RedirectStatus.Valid {
+				t.Errorf("params.RedirectStatus=%+v want NULL", params.RedirectStatus)
+			}
+			if params.UtmMedium.Valid {
+				t.Errorf("params.UtmMedium=%+v want NULL", params.UtmMedium)
+			}
+			row := makeTestDBLink(now)
+			row.UtmSource = params.UtmSource
+			row.UtmMedium = params.UtmMedium
+			row.UtmCampaign = params.UtmCampaign
+			return row, nil
+		},
+	}
+
+	r := NewRepository(mock, &RepositoryConfig{IDGenerator: &stubIDGen{id: makeUUIDv7Deterministic()}})
+
+	link := makeTestLink(now)
+	link.UTM = UTMParams{Source: "newsletter", Campaign: "fall"}
+	got, err := r.Create(context.Background(), link)
+	if err != nil {
+		t.Fatalf("Create() unexpected error: %v", err)
+	}
+	if got.UTM != link.UTM {
+		t.Errorf("created.UTM=%+v want %+v", got.UTM, link.UTM)
+	}
+}
+
+func TestRepoCountByOwner(t *testing.T) {
+	mock := &mockQueries{
+		countByOwnerFunc: func(_ context.Context, owner pgtype.Text) (int64, error) {
+			if owner != (pgtype.Text{String: "alice", Valid: true}) {
+				t.Errorf("owner=%+v want alice", owner)
+			}
+			return 7, nil
+		},
+	}
+
+	r := NewRepository(mock, nil)
+
+	got, err := r.CountByOwner(context.Background(), "alice")
+	if err != nil {
+		t.Fatalf("CountByOwner() unexpected error: %v", err)
+	}
+	if got != 7 {
+		t.Errorf("CountByOwner()=%d want 7", got)
+	}
+}
+
+func TestRepoCountLinks(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	alice := pgtype.Text{String: "alice", Valid: true}
+
+	tests := []struct {
+		name   string
+		filter CountFilter
+		want   db.CountLinksFilteredParams
+	}{
+		{"all links", CountFilter{}, db.CountLinksFilteredParams{Status: ""}},
+		{"active", CountFilter{Status: LinkStatusActive}, db.CountLinksFilteredParams{Status: "active"}},
+		{"expired", CountFilter{Status: LinkStatusExpired}, db.CountLinksFilteredParams{Status: "expired"}},
+		{"deleted", CountFilter{Status: LinkStatusDeleted}, db.CountLinksFilteredParams{Status: "deleted"}},
+		{"paused", CountFilter{Status: LinkStatusPaused}, db.CountLinksFilteredParams{Status: "paused"}},
+		{"owner", CountFilter{Owner: "alice"}, db.CountLinksFilteredParams{Owner: alice}},
+		{"owner active", CountFilter{Status: LinkStatusActive, Owner: "alice"}, db.CountLinksFilteredParams{Owner: alice, Status: "active"}},
+		{"owner expired", CountFilter{Status: LinkStatusExpired, Owner: "alice"}, db.CountLinksFilteredParams{Owner: alice, Status: "expired"}},
+		{"owner deleted", CountFilter{Status: LinkStatusDeleted, Owner: "alice"}, db.CountLinksFilteredParams{Owner: alice, Status: "deleted"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got db.CountLinksFilteredParams
+			mock := &mockQueries{
+				countFilteredFunc: func(_ context.Context, arg db.CountLinksFilteredParams) (int64, error) {
+					got = arg
+					return 3, nil
+				},
+			}
+			r := NewRepository(mock, &RepositoryConfig{Clock: clock.NewFake(now)})
+
+			n, err := r.CountLinks(context.Background(), tt.filter)
+			if err != nil {
+				t.Fatalf("CountLinks() unexpected error: %v", err)
+			}
+			if n != 3 {
+				t.Errorf("CountLinks()=%d want 3", n)
+			}
+
+			tt.want.Now = pgtype.Timestamptz{Time: now, Valid: true}
+			if got != tt.want {
+				t.Errorf("params=%+v want %+v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("maps query failure to Unavailable", func(t *testing.T) {
+		mock := &mockQueries{
+			countFilteredFunc: func(_ context.Context, _ db.CountLinksFilteredParams) (int64, error) {
+				return 0, errors.New("connection reset")
+			},
+		}
+
+		_, err := NewRepository(mock, nil).CountLinks(context.Background(), CountFilter{Status: LinkStatusActive})
+		if errx.KindOf(err) != errx.Unavailable {
+			t.Errorf("KindOf(err)=%v want %v", errx.KindOf(err), errx.Unavailable)
+		}
+		if errx.OpOf(err) != "shortener.repo.CountLinks" {
+			t.Errorf("OpOf(err)=%q want %q", errx.OpOf(err), "shortener.repo.CountLinks")
+		}
+	})
+}
+
+func TestRepoCountBySource(t *testing.T) {
+	t.Run("maps rows", func(t *testing.T) {
+		mock := &mockQueries{
+			countBySourceFunc: func(_ context.Context) ([]db.CountLinksBySourceRow, error) {
+				return []db.CountLinksBySourceRow{{Source: SourceAPI, Links: 5}, {Source: "", Links: 2}}, nil
+			},
+		}
+
+		r := NewRepository(mock, nil)
+
+		got, err := r.CountBySource(context.Background())
+		if err != nil {
+			t.Fatalf("CountBySource() unexpected error: %v", err)
+		}
+		want := []SourceCount{{SourceAPI, 5}, {"", 2}}
+		if !slices.Equal(got, want) {
+			t.Errorf("CountBySource()=%v want %v", got, want)
+		}
+	})
+
+	t.Run("maps query failure to Unavailable", func(t *testing.T) {
+		mock := &mockQueries{
+			countBySourceFunc: func(_ context.Context) ([]db.CountLinksBySourceRow, error) {
+				return nil, errors.New("connection reset")
+			},
+		}
+
+		r := NewRepository(mock, nil)
+
+		_, err := r.CountBySource(context.Background())
+		if errx.KindOf(err) != errx.Unavailable {
+			t.Errorf("KindOf(err)=%v want %v", errx.KindOf(err), errx.Unavailable)
+		}
+	})
+}
+
 func TestRepoGetBySlug(t *testing.T) {
 	t.Run("retrieves link successfully", func(t *testing.T) {
 		now := time.Now()
@@ -507,6 +1005,100 @@ func TestRepoGetBySlug(t *testing.T) {
 	})
 }
 
+func TestRepoCaseInsensitiveSlugs(t *testing.T) {
+	now := time.Now()
+	dbLink := makeTestDBLink(now)
+	dbLink.Slug, dbLink.SlugKey = "MyLink1", "mylink1"
+
+	mock := &mockQueries{
+		createLinkFunc: func(_ context.Context, params db.CreateLinkParams) (db.Link, error) {
+			if params.Slug != "MyLink1" || params.SlugKey != "mylink1" {
+				t.Errorf("params Slug=%q SlugKey=%q, want MyLink1 mylink1", params.Slug, params.SlugKey)
+			}
+			return dbLink, nil
+		},
+		getLinkBySlugFunc: func(_ context.Context, slugKey string) (db.Link, error) {
+			if slugKey != "mylink1" {
+				t.Errorf("slugKey=%q want %q", slugKey, "mylink1")
+			}
+			return dbLink, nil
+		},
+	}
+
+	r := NewRepository(mock, &RepositoryConfig{
+		IDGenerator:          &stubIDGen{id: makeUUIDv7Deterministic()},
+		CaseInsensitiveSlugs: true,
+	})
+
+	link := makeTestLink(now)
+	link.Slug = "MyLink1"
+	if _, err := r.Create(context.Background(), link); err != nil {
+		t.Fatalf("Create() unexpected error: %v", err)
+	}
+
+	got, err := r.GetBySlug(context.Background(), "MYLINK1")
+	if err != nil {
+		t.Fatalf("GetBySlug() unexpected error: %v", err)
+	}
+	if got.Slug != "MyLink1" {
+		t.Errorf("Slug=%q want %q", got.Slug, "MyLink1")
+	}
+	var alias db.InsertLinkAliasParams
+	mock.insertAliasFunc = func(_ context.Context, arg db.InsertLinkAliasParams) error {
+		alias = arg
+		return nil
+	}
+	if err := r.AddAlias(context.Background(), dbLink.ID, "Promo12"); err != nil {
+		t.Fatalf("AddAlias() unexpected error: %v", err)
+	}
+	if alias.Alias != "Promo12" || alias.AliasKey != "promo12" {
+		t.Errorf("alias params Alias=%q AliasKey=%q, want Promo12 promo12", alias.Alias, alias.AliasKey)
+	}
+}
+
+func TestRepoGetByID(t *testing.T) {
+	t.Run("retrieves link successfully", func(t *testing.T) {
+		dbLink := makeTestDBLink(time.Now())
+
+		mock := &mockQueries{
+			getLinkByIDFunc: func(_ context.Context, id uuid.UUID) (db.Link, error) {
+				if id != dbLink.ID {
+					t.Errorf("id=%v want %v", id, dbLink.ID)
+				}
+				return dbLink, nil
+			},
+		}
+
+		r := NewRepository(mock, &RepositoryConfig{IDGenerator: &stubIDGen{id: makeUUIDv7Deterministic()}})
+
+		got, err := r.GetByID(context.Background(), dbLink.ID)
+		if err != nil {
+			t.Fatalf("GetByID() unexpected error: %v", err)
+		}
+		if got.ID != dbLink.ID || got.Slug != dbLink.Slug {
+			t.Errorf("got ID=%v Slug=%q, want %v %q", got.ID, got.Slug, dbLink.ID, dbLink.Slug)
+		}
+	})
+
+	t.Run("returns NotFound for non-existent id", func(t *testing.T) {
+		mock := &mockQueries{
+			getLinkByIDFunc: func(_ context.Context, _ uuid.UUID) (db.Link, error) {
+				return db.Link{}, pgx.ErrNoRows
+			},
+		}
+
+		r := NewRepository(mock, &RepositoryConfig{IDGenerator: &stubIDGen{id: makeUUIDv7Deterministic()}})
+
+		_, err := r.GetByID(context.Background(), uuid.New())
+		if errx.KindOf(err) != errx.NotFound {
+			t.Errorf("KindOf(err)=%v want %v", errx.KindOf(err), errx.NotFound)
+		}
+		if errx.OpOf(err) != "shortener.repo.GetByID" {
+			t.Errorf("OpOf(err)=%q want %q", errx.OpOf(err), "shortener.repo.GetByID")
+		}
+	})
+}
+
 func TestRepoResolveAndTrack(t *testing.T) {
 	t.Run("resolves and tracks successfully", func(t *testing.T) {
 		now := time.Now()
@@ -522,9 +1114,9 @@ func TestRepoResolveAndTrack(t *testing.T) {
 		}
 
 		mock := &mockQueries{
-			resolveAndTrackFunc: func(_ context.Context, slug string) (db.Link, error) {
-				if slug != testSlug {
-					t.Errorf("slug=%q want %q", slug, testSlug)
+			resolveAndTrackFunc: func(_ context.Context, arg db.ResolveAndTrackLinkParams) (db.Link, error) {
+				if arg.SlugKey != testSlug {
+					t.Errorf("slug key=%q want %q", arg.SlugKey, testSlug)
 				}
 				return dbLink, nil
 			},
@@ -532,7 +1124,7 @@ func TestRepoResolveAndTrack(t *testing.T) {
 
 		r := NewRepository(mock, &RepositoryConfig{IDGenerator: &stubIDGen{id: makeUUIDv7Deterministic()}})
 
-		got, err := r.ResolveAndTrack(context.Background(), testSlug)
+		got, err := r.ResolveAndTrack(context.Background(), testSlug, 0)
 		if err != nil {
 			t.Fatalf("ResolveAndTrack() unexpected error: %v", err)
 		}
@@ -546,14 +1138,14 @@ func TestRepoResolveAndTrack(t *testing.T) {
 
 	t.Run("returns NotFound for non-existent slug", func(t *testing.T) {
 		mock := &mockQueries{
-			resolveAndTrackFunc: func(_ context.Context, _ string) (db.Link, error) {
+			resolveAndTrackFunc: func(_ context.Context, _ db.ResolveAndTrackLinkParams) (db.Link, error) {
 				return db.Link{}, pgx.ErrNoRows
 			},
 		}
 
 		r := NewRepository(mock, &RepositoryConfig{IDGenerator: &stubIDGen{id: makeUUIDv7Deterministic()}})
 
-		_, err := r.ResolveAndTrack(context.Background(), "missing")
+		_, err := r.ResolveAndTrack(context.Background(), "missing", 0)
 		if err == nil {
 			t.Fatal("expected error")
 		}
@@ -564,37 +1156,193 @@ func TestRepoResolveAndTrack(t *testing.T) {
 			t.Errorf("OpOf(err)=%q want %q", errx.OpOf(err), "shortener.repo.ResolveAndTrack")
 		}
 	})
-}
 
-func TestRepoDelete(t *testing.T) {
-	t.Run("deletes successfully", func(t *testing.T) {
-		testSlug := "test-slug"
+	t.Run("checks expiry against the configured clock", func(t *testing.T) {
+		now := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+		expiresAt := now.Add(time.Hour)
+
+		// Mirrors the query: a link resolves only while expires_at > expires_after.
 		mock := &mockQueries{
-			deleteLinkFunc: func(_ context.Context, slug string) error {
-				if slug != testSlug {
-					t.Errorf("slug=%q want %q", slug, testSlug)
+			resolveAndTrackFunc: func(_ context.Context, arg db.ResolveAndTrackLinkParams) (db.Link, error) {
+				if !arg.ExpiresAfter.Valid || !expiresAt.After(arg.ExpiresAfter.Time) {
+					return db.Link{}, pgx.ErrNoRows
 				}
+				return db.Link{
+					ID:             uuid.New(),
+					OriginalUrl:    "https://example.com",
+					Slug:           arg.SlugKey,
+					CreatedAt:      makeValidTimestamp(now),
+					UpdatedAt:      makeValidTimestamp(now),
+					LastAccessedAt: arg.Now,
+					ExpiresAt:      makeValidTimestamp(expiresAt),
+				}, nil
+			},
+		}
+		clk := clock.NewFake(now)
+		r := NewRepository(mock, &RepositoryConfig{
+			IDGenerator: &stubIDGen{id: makeUUIDv7Deterministic()},
+			Clock:       clk,
+		})
+
+		got, err := r.ResolveAndTrack(context.Background(), "soon-gone", 0)
+		if err != nil {
+			t.Fatalf("ResolveAndTrack() before expiry unexpected error: %v", err)
+		}
+		if got.LastAccessedAt == nil || !got.LastAccessedAt.Equal(now) {
+			t.Errorf("LastAccessedAt=%v want %v", got.LastAccessedAt, now)
+		}
+
+		clk.Advance(time.Hour)
+		_, err = r.ResolveAndTrack(context.Background(), "soon-gone", 0)
+		if errx.KindOf(err) != errx.NotFound {
+			t.Errorf("after expiry KindOf(err)=%v want %v", errx.KindOf(err), errx.NotFound)
+		}
+
+		clk.Advance(29 * time.Minute)
+		if _, err := r.ResolveAndTrack(context.Background(), "soon-gone", 30*time.Minute); err != nil {
+			t.Errorf("within grace unexpected error: %v", err)
+		}
+		clk.Advance(time.Minute)
+		_, err = r.ResolveAndTrack(context.Background(), "soon-gone", 30*time.Minute)
+		if errx.KindOf(err) != errx.NotFound {
+			t.Errorf("after grace KindOf(err)=%v want %v", errx.KindOf(err), errx.NotFound)
+		}
+	})
+}
+
+func TestRepoCreator(t *testing.T) {
+	linkID := uuid.New()
+
+	t.Run("stores NULL for missing fields", func(t *testing.T) {
+		var got db.InsertLinkCreatorParams
+		mock := &mockQueries{
+			insertCreatorFunc: func(_ context.Context, arg db.InsertLinkCreatorParams) error {
+				got = arg
+				return nil
+			},
+		}
+		r := NewRepository(mock, &RepositoryConfig{IDGenerator: &stubIDGen{id: makeUUIDv7Deterministic()}})
+
+		if err := r.SaveCreator(context.Background(), linkID, Creator{IP: "203.0.113.7"}); err != nil {
+			t.Fatalf("SaveCreator() unexpected error: %v", err)
+		}
+		want := db.InsertLinkCreatorParams{
+			LinkID:    linkID,
+			CreatedIp: pgtype.Text{String: "203.0.113.7", Valid: true},
+		}
+		if got != want {
+			t.Errorf("params=%+v want %+v", got, want)
+		}
+	})
+
+	t.Run("returns NotFound when nothing was recorded", func(t *testing.T) {
+		mock := &mockQueries{
+			getCreatorFunc: func(_ context.Context, _ uuid.UUID) (db.LinkCreator, error) {
+				return db.LinkCreator{}, pgx.ErrNoRows
+			},
+		}
+		r := NewRepository(mock, &RepositoryConfig{IDGenerator: &stubIDGen{id: makeUUIDv7Deterministic()}})
+
+		_, err := r.GetCreator(context.Background(), linkID)
+		if errx.KindOf(err) != errx.NotFound {
+			t.Errorf("KindOf(err)=%v want %v", errx.KindOf(err), errx.NotFound)
+		}
+	})
+}
+
+func TestRepoAliases(t *testing.T) {
+	linkID := makeUUIDv7Deterministic()
+
+	t.Run("adds an alias", func(t *testing.T) {
+		var got db.InsertLinkAliasParams
+		mock := &mockQueries{
+			insertAliasFunc: func(_ context.Context, arg db.InsertLinkAliasParams) error {
+				got = arg
 				return nil
 			},
 		}
 
+		if err := NewRepository(mock, nil).AddAlias(context.Background(), linkID, "old-name"); err != nil {
+			t.Fatalf("AddAlias() unexpected error: %v", err)
+		}
+		if want := (db.InsertLinkAliasParams{Alias: "old-name", LinkID: linkID, AliasKey: "old-name"}); got != want {
+			t.Errorf("params=%+v want %+v", got, want)
+		}
+	})
+
+	for _, constraint := range []string{"links_slug_unique", "link_aliases_pkey"} {
+		t.Run("maps "+constraint+" to Conflict", func(t *testing.T) {
+			mock := &mockQueries{
+				insertAliasFunc: func(_ context.Context, _ db.InsertLinkAliasParams) error {
+					return &pgconn.PgError{Code: "23505", ConstraintName: constraint}
+				},
+			}
+
+			err := NewRepository(mock, nil).AddAlias(context.Background(), linkID, "old-name")
+			if errx.KindOf(err) != errx.Conflict {
+				t.Errorf("KindOf(err)=%v want %v", errx.KindOf(err), errx.Conflict)
+			}
+		})
+	}
+
+	t.Run("looks up the slug behind an alias", func(t *testing.T) {
+		mock := &mockQueries{
+			slugByAliasFunc: func(_ context.Context, alias string) (string, error) {
+				if alias != "old-name" {
+					return "", pgx.ErrNoRows
+				}
+				return "new-name", nil
+			},
+		}
+		r := NewRepository(mock, nil)
+
+		slug, err := r.SlugForAlias(context.Background(), "old-name")
+		if err != nil || slug != "new-name" {
+			t.Errorf("SlugForAlias() = %q, %v, want new-name", slug, err)
+		}
+		if _, err := r.SlugForAlias(context.Background(), "unknown"); errx.KindOf(err) != errx.NotFound {
+			t.Errorf("KindOf(err)=%v want %v", errx.KindOf(err), errx.NotFound)
+		}
+	})
+}
+
+func TestRepoDelete(t *testing.T) {
+	t.Run("deletes successfully", func(t *testing.T) {
+		testSlug := "test-slug"
+		now := time.Now()
+		mock := &mockQueries{
+			deleteLinkFunc: func(_ context.Context, slug string) (db.Link, error) {
+				if slug != testSlug {
+					t.Errorf("slug=%q want %q", slug, testSlug)
+				}
+				row := makeTestDBLink(now)
+				row.Owner = pgtype.Text{String: "alice", Valid: true}
+				row.DeletedAt = pgtype.Timestamptz{Time: now, Valid: true}
+				return row, nil
+			},
+		}
+
 		r := NewRepository(mock, &RepositoryConfig{IDGenerator: &stubIDGen{id: makeUUIDv7Deterministic()}})
 
-		if err := r.Delete(context.Background(), "test-slug"); err != nil {
+		deleted, err := r.Delete(context.Background(), "test-slug")
+		if err != nil {
 			t.Fatalf("Delete() unexpected error: %v", err)
 		}
+		if deleted.Owner != "alice" {
+			t.Errorf("deleted.Owner=%q want %q", deleted.Owner, "alice")
+		}
 	})
 
 	t.Run("returns NotFound for missing slug", func(t *testing.T) {
 		mock := &mockQueries{
-			deleteLinkFunc: func(_ context.Context, _ string) error {
-				return pgx.ErrNoRows
+			deleteLinkFunc: func(_ context.Context, _ string) (db.Link, error) {
+				return db.Link{}, pgx.ErrNoRows
 			},
 		}
 
 		r := NewRepository(mock, &RepositoryConfig{IDGenerator: &stubIDGen{id: makeUUIDv7Deterministic()}})
 
-		err := r.Delete(context.Background(), "missing")
+		_, err := r.Delete(context.Background(), "missing")
 		if err == nil {
 			t.Fatal("expected error")
 		}
@@ -607,6 +1355,564 @@ func TestRepoDelete(t *testing.T) {
 	})
 }
 
+func TestRepoSetPaused(t *testing.T) {
+	t.Run("maps params and paused state", func(t *testing.T) {
+		var got db.SetLinkPausedParams
+		mock := &mockQueries{
+			setPausedFunc: func(_ context.Context, arg db.SetLinkPausedParams) (db.Link, error) {
+				got = arg
+				row := makeTestDBLink(time.Now())
+				row.Paused = arg.Paused
+				return row, nil
+			},
+		}
+		r := NewRepository(mock, &RepositoryConfig{IDGenerator: &stubIDGen{id: makeUUIDv7Deterministic()}})
+
+		link, err := r.SetPaused(context.Background(), "test-slug", true)
+		if err != nil {
+			t.Fatalf("SetPaused() unexpected error: %v", err)
+		}
+		if want := (db.SetLinkPausedParams{Paused: true, SlugKey: "test-slug"}); got != want {
+			t.Errorf("params=%+v want %+v", got, want)
+		}
+		if !link.Paused {
+			t.Error("link.Paused=false want true")
+		}
+	})
+
+	t.Run("returns NotFound for missing slug", func(t *testing.T) {
+		mock := &mockQueries{
+			setPausedFunc: func(_ context.Context, _ db.SetLinkPausedParams) (db.Link, error) {
+				return db.Link{}, pgx.ErrNoRows
+			},
+		}
+		r := NewRepository(mock, &RepositoryConfig{IDGenerator: &stubIDGen{id: makeUUIDv7Deterministic()}})
+
+		_, err := r.SetPaused(context.Background(), "missing", false)
+		if errx.KindOf(err) != errx.NotFound {
+			t.Errorf("KindOf(err)=%v want %v", errx.KindOf(err), errx.NotFound)
+		}
+	})
+}
+
+func TestRepoCount(t *testing.T) {
+	t.Run("returns count successfully", func(t *testing.T) {
+		mock := &mockQueries{
+			countLinksFunc: func(_ context.Context) (int64, error) {
+				return 42, nil
+			},
+		}
+
+		r := NewRepository(mock, &RepositoryConfig{IDGenerator: &stubIDGen{id: makeUUIDv7Deterministic()}})
+
+		n, err := r.Count(context.Background())
+		if err != nil {
+			t.Fatalf("Count() unexpected error: %v", err)
+		}
+		if n != 42 {
+			t.Errorf("Count()=%d want 42", n)
+		}
+	})
+
+	t.Run("maps query failure to Unavailable", func(t *testing.T) {
+		mock := &mockQueries{
+			countLinksFunc: func(_ context.Context) (int64, error) {
+				return 0, errors.New("connection reset")
+			},
+		}
+
+		r := NewRepository(mock, &RepositoryConfig{IDGenerator: &stubIDGen{id: makeUUIDv7Deterministic()}})
+
+		_, err := r.Count(context.Background())
+		if errx.KindOf(err) != errx.Unavailable {
+			t.Errorf("KindOf(err)=%v want %v", errx.KindOf(err), errx.Unavailable)
+		}
+		if errx.OpOf(err) != "shortener.repo.Count" {
+			t.Errorf("OpOf(err)=%q want %q", errx.OpOf(err), "shortener.repo.Count")
+		}
+	})
+}
+
+func TestRepoList(t *testing.T) {
+	now := time.Now()
+	row := db.Link{
+		ID:          makeUUIDv7Deterministic(),
+		OriginalUrl: "https://example.com",
+		Slug:        "abc1234",
+		CreatedAt:   makeValidTimestamp(now),
+		UpdatedAt:   makeValidTimestamp(now),
+	}
+
+	t.Run("first page has no cursor", func(t *testing.T) {
+		var got db.ListLinksParams
+		mock := &mockQueries{
+			listLinksFunc: func(_ context.Context, arg db.ListLinksParams) ([]db.Link, error) {
+				got = arg
+				return []db.Link{row}, nil
+			},
+		}
+
+		r := NewRepository(mock, nil)
+
+		links, err := r.List(context.Background(), nil, 11)
+		if err != nil {
+			t.Fatalf("List() unexpected error: %v", err)
+		}
+		if len(links) != 1 || links[0].Slug != row.Slug {
+			t.Errorf("List()=%+v want one link with slug %q", links, row.Slug)
+		}
+		if got.CursorCreatedAt.Valid || got.CursorID.Valid {
+			t.Errorf("cursor params should be NULL, got %+v", got)
+		}
+		if got.RowLimit != 11 {
+			t.Errorf("RowLimit=%d want 11", got.RowLimit)
+		}
+	})
+
+	t.Run("passes cursor through", func(t *testing.T) {
+		cursor := &LinkCursor{CreatedAt: now, ID: row.ID}
+
+		var got db.ListLinksParams
+		mock := &mockQueries{
+			listLinksFunc: func(_ context.Context, arg db.ListLinksParams) ([]db.Link, error) {
+				got = arg
+				return nil, nil
+			},
+		}
+
+		r := NewRepository(mock, nil)
+
+		if _, err := r.List(context.Background(), cursor, 5); err != nil {
+			t.Fatalf("List() unexpected error: %v", err)
+		}
+		if !got.CursorCreatedAt.Valid || !got.CursorCreatedAt.Time.Equal(now) {
+			t.Errorf("CursorCreatedAt=%+v want %v", got.CursorCreatedAt, now)
+		}
+		if !got.CursorID.Valid || uuid.UUID(got.CursorID.Bytes) != row.ID {
+			t.Errorf("CursorID=%+v want %v", got.CursorID, row.ID)
+		}
+	})
+
+	t.Run("maps query failure to Unavailable", func(t *testing.T) {
+		mock := &mockQueries{
+			listLinksFunc: func(_ context.Context, _ db.ListLinksParams) ([]db.Link, error) {
+				return nil, errors.New("connection reset")
+			},
+		}
+
+		r := NewRepository(mock, nil)
+
+		_, err := r.List(context.Background(), nil, 5)
+		if errx.KindOf(err) != errx.Unavailable {
+			t.Errorf("KindOf(err)=%v want %v", errx.KindOf(err), errx.Unavailable)
+		}
+	})
+}
+
+func TestRepoListByURL(t *testing.T) {
+	now := time.Now()
+	const target = "https://example.com/landing"
+
+	t.Run("returns every slug for the url", func(t *testing.T) {
+		var gotURL string
+		mock := &mockQueries{
+			getLinksByURLFunc: func(_ context.Context, originalUrl string) ([]db.Link, error) {
+				gotURL = originalUrl
+				return []db.Link{
+					{ID: makeUUIDv7Deterministic(), OriginalUrl: target, Slug: "promo-b", CreatedAt: makeValidTimestamp(now), UpdatedAt: makeValidTimestamp(now)},
+					{ID: makeUUIDv7Deterministic(), OriginalUrl: target, Slug: "promo-a", CreatedAt: makeValidTimestamp(now), UpdatedAt: makeValidTimestamp(now)},
+				}, nil
+			},
+		}
+
+		r := NewRepository(mock, nil)
+
+		links, err := r.ListByURL(context.Background(), target)
+		if err != nil {
+			t.Fatalf("ListByURL() unexpected error: %v", err)
+		}
+		if gotURL != target {
+			t.Errorf("query url=%q want %q", gotURL, target)
+		}
+		if len(links) != 2 || links[0].Slug != "promo-b" || links[1].Slug != "promo-a" {
+			t.Fatalf("ListByURL()=%+v want slugs [promo-b promo-a]", links)
+		}
+		for _, l := range links {
+			if l.OriginalURL != target {
+				t.Errorf("OriginalURL=%q want %q", l.OriginalURL, target)
+			}
+		}
+	})
+
+	t.Run("no rows is an empty result", func(t *testing.T) {
+		r := NewRepository(&mockQueries{}, nil)
+
+		links, err := r.ListByURL(context.Background(), target)
+		if err != nil {
+			t.Fatalf("ListByURL() unexpected error: %v", err)
+		}
+		if len(links) != 0 {
+			t.Errorf("ListByURL()=%+v want empty", links)
+		}
+	})
+
+	t.Run("maps query failure to Unavailable", func(t *testing.T) {
+		mock := &mockQueries{
+			getLinksByURLFunc: func(_ context.Context, _ string) ([]db.Link, error) {
+				return nil, errors.New("connection reset")
+			},
+		}
+
+		r := NewRepository(mock, nil)
+
+		_, err := r.ListByURL(context.Background(), target)
+		if errx.KindOf(err) != errx.Unavailable {
+			t.Errorf("KindOf(err)=%v want %v", errx.KindOf(err), errx.Unavailable)
+		}
+	})
+}
+
+func TestRepoTakenSlugs(t *testing.T) {
+	t.Run("reports the slugs found", func(t *testing.T) {
+		mock := &mockQueries{
+			getTakenSlugsFunc: func(_ context.Context, _ []string) ([]string, error) {
+				return []string{"promo-2"}, nil
+			},
+		}
+
+		r := NewRepository(mock, nil)
+
+		taken, err := r.TakenSlugs(context.Background(), []string{"promo-2", "promo-3"})
+		if err != nil {
+			t.Fatalf("TakenSlugs() unexpected error: %v", err)
+		}
+		if !taken["promo-2"] || taken["promo-3"] {
+			t.Errorf("TakenSlugs()=%v want only promo-2", taken)
+		}
+	})
+
+	t.Run("looks up every slug in one query", func(t *testing.T) {
+		var calls [][]string
+		mock := &mockQueries{
+			getTakenSlugsFunc: func(_ context.Context, slugKeys []string) ([]string, error) {
+				calls = append(calls, slugKeys)
+				return []string{"summer1", "winter1"}, nil
+			},
+		}
+
+		r := NewRepository(mock, nil)
+
+		slugs := []string{"summer1", "autumn1", "winter1", "spring1"}
+		taken, err := r.TakenSlugs(context.Background(), slugs)
+		if err != nil {
+			t.Fatalf("TakenSlugs() unexpected error: %v", err)
+		}
+		if len(calls) != 1 || !slices.Equal(calls[0], slugs) {
+			t.Errorf("queries = %v, want one with %v", calls, slugs)
+		}
+		want := map[string]bool{"summer1": true, "winter1": true}
+		if !maps.Equal(taken, want) {
+			t.Errorf("TakenSlugs()=%v want %v", taken, want)
+		}
+	})
+
+	t.Run("skips the query for no slugs", func(t *testing.T) {
+		mock := &mockQueries{
+			getTakenSlugsFunc: func(_ context.Context, _ []string) ([]string, error) {
+				t.Fatal("query should not run for an empty slug list")
+				return nil, nil
+			},
+		}
+
+		r := NewRepository(mock, nil)
+
+		if _, err := r.TakenSlugs(context.Background(), nil); err != nil {
+			t.Fatalf("TakenSlugs() unexpected error: %v", err)
+		}
+	})
+
+	t.Run("maps query failure to Unavailable", func(t *testing.T) {
+		mock := &mockQueries{
+			getTakenSlugsFunc: func(_ context.Context, _ []string) ([]string, error) {
+				return nil, errors.New("connection reset")
+			},
+		}
+
+		r := NewRepository(mock, nil)
+
+		_, err := r.TakenSlugs(context.Background(), []string{"promo-2"})
+		if errx.KindOf(err) != errx.Unavailable {
+			t.Errorf("KindOf(err)=%v want %v", errx.KindOf(err), errx.Unavailable)
+		}
+	})
+}
+
+func TestRepoRecordClick(t *testing.T) {
+	linkID := makeUUIDv7Deterministic()
+
+	tests := []struct {
+		name      string
+		requestID string
+		want      pgtype.Text
+	}{
+		{"stores the request ID", "req-7f3a", pgtype.Text{String: "req-7f3a", Valid: true}},
+		{"stores NULL without one", "", pgtype.Text{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got db.RecordClickParams
+			mock := &mockQueries{
+				recordClickFunc: func(_ context.Context, arg db.RecordClickParams) error {
+					got = arg
+					return nil
+				},
+			}
+
+			r := NewRepository(mock, nil)
+
+			if err := r.RecordClick(context.Background(), ClickEvent{LinkID: linkID, RequestID: tt.requestID}); err != nil {
+				t.Fatalf("RecordClick() unexpected error: %v", err)
+			}
+			if got.LinkID != linkID || got.RequestID != tt.want {
+				t.Errorf("params=%+v want link %v request ID %+v", got, linkID, tt.want)
+			}
+		})
+	}
+}
+
+func TestRepoClickCountry(t *testing.T) {
+	t.Run("records the country", func(t *testing.T) {
+		var got db.RecordClickParams
+		mock := &mockQueries{
+			recordClickFunc: func(_ context.Context, arg db.RecordClickParams) error {
+				got = arg
+				return nil
+			},
+		}
+
+		click := ClickEvent{LinkID: makeUUIDv7Deterministic(), Country: "DE"}
+		if err := NewRepository(mock, nil).RecordClick(context.Background(), click); err != nil {
+			t.Fatalf("RecordClick() unexpected error: %v", err)
+		}
+		if want := (pgtype.Text{String: "DE", Valid: true}); got.Country != want {
+			t.Errorf("params.Country=%+v want %+v", got.Country, want)
+		}
+	})
+
+	t.Run("counts clicks by country", func(t *testing.T) {
+		mock := &mockQueries{
+			countByCountryFunc: func(_ context.Context) ([]db.CountClicksByCountryRow, error) {
+				return []db.CountClicksByCountryRow{{Country: "DE", Clicks: 5}, {Country: "", Clicks: 2}}, nil
+			},
+		}
+
+		got, err := NewRepository(mock, nil).CountClicksByCountry(context.Background())
+		if err != nil {
+			t.Fatalf("CountClicksByCountry() unexpected error: %v", err)
+		}
+		want := []CountryCount{{"DE", 5}, {"", 2}}
+		if !slices.Equal(got, want) {
+			t.Errorf("CountClicksByCountry() = %+v, want %+v", got, want)
+		}
+	})
+}
+
+func TestRepoClickTimeSeries(t *testing.T) {
+	linkID := makeUUIDv7Deterministic()
+	day := func(d int) time.Time { return time.Date(2025, 3, d, 0, 0, 0, 0, time.UTC) }
+
+	t.Run("zero-fills missing buckets", func(t *testing.T) {
+		var got db.GetClickTimeSeriesParams
+		mock := &mockQueries{
+			clickSeriesFunc: func(_ context.Context, arg db.GetClickTimeSeriesParams) ([]db.GetClickTimeSeriesRow, error) {
+				got = arg
+				return []db.GetClickTimeSeriesRow{
+					{BucketStart: makeValidTimestamp(day(2)), Clicks: 4},
+					{BucketStart: makeValidTimestamp(day(4)), Clicks: 1},
+				}, nil
+			},
+		}
+
+		r := NewRepository(mock, nil)
+
+		buckets, err := r.ClickTimeSeries(context.Background(), linkID, BucketDay, day(1), day(5))
+		if err != nil {
+			t.Fatalf("ClickTimeSeries() unexpected error: %v", err)
+		}
+		if got.Bucket != "day" || got.LinkID != linkID ||
+			!got.FromTime.Time.Equal(day(1)) || !got.ToTime.Time.Equal(day(5)) {
+			t.Errorf("params=%+v want day bucket over [%v, %v)", got, day(1), day(5))
+		}
+
+		want := []ClickBucket{{day(1), 0}, {day(2), 4}, {day(3), 0}, {day(4), 1}}
+		if len(buckets) != len(want) {
+			t.Fatalf("len(buckets)=%d want %d: %+v", len(buckets), len(want), buckets)
+		}
+		for i := range want {
+			if !buckets[i].Start.Equal(want[i].Start) || buckets[i].Clicks != want[i].Clicks {
+				t.Errorf("buckets[%d]=%+v want %+v", i, buckets[i], want[i])
+			}
+		}
+	})
+
+	t.Run("hourly buckets align to the hour in UTC", func(t *testing.T) {
+		loc := time.FixedZone("UTC+5:30", 5*3600+1800)
+		from := time.Date(2025, 3, 1, 10, 45, 0, 0, loc) // 05:15 UTC
+		to := from.Add(2 * time.Hour)
+
+		mock := &mockQueries{
+			clickSeriesFunc: func(_ context.Context, _ db.GetClickTimeSeriesParams) ([]db.GetClickTimeSeriesRow, error) {
+				return []db.GetClickTimeSeriesRow{
+					{BucketStart: makeValidTimestamp(time.Date(2025, 3, 1, 6, 0, 0, 0, time.UTC)), Clicks: 2},
+				}, nil
+			},
+		}
+
+		r := NewRepository(mock, nil)
+
+		buckets, err := r.ClickTimeSeries(context.Background(), linkID, BucketHour, from, to)
+		if err != nil {
+			t.Fatalf("ClickTimeSeries() unexpected error: %v", err)
+		}
+
+		wantStarts := []int{5, 6, 7}
+		if len(buckets) != len(wantStarts) {
+			t.Fatalf("len(buckets)=%d want %d: %+v", len(buckets), len(wantStarts), buckets)
+		}
+		for i, h := range wantStarts {
+			want := time.Date(2025, 3, 1, h, 0, 0, 0, time.UTC)
+			if !buckets[i].Start.Equal(want) || buckets[i].Start.Location() != time.UTC {
+				t.Errorf("buckets[%d].Start=%v want %v", i, buckets[i].Start, want)
+			}
+		}
+		if buckets[1].Clicks != 2 {
+			t.Errorf("buckets[1].Clicks=%d want 2", buckets[1].Clicks)
+		}
+	})
+
+	t.Run("maps query failure to Unavailable", func(t *testing.T) {
+		mock := &mockQueries{
+			clickSeriesFunc: func(_ context.Context, _ db.GetClickTimeSeriesParams) ([]db.GetClickTimeSeriesRow, error) {
+				return nil, errors.New("connection reset")
+			},
+		}
+
+		r := NewRepository(mock, nil)
+
+		_, err := r.ClickTimeSeries(context.Background(), linkID, BucketDay, day(1), day(2))
+		if errx.KindOf(err) != errx.Unavailable {
+			t.Errorf("KindOf(err)=%v want %v", errx.KindOf(err), errx.Unavailable)
+		}
+	})
+}
+
+func TestRepoPurge(t *testing.T) {
+	before := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("purge expired passes cutoff and batch size", func(t *testing.T) {
+		mock := &mockQueries{
+			purgeExpiredFunc: func(_ context.Context, arg db.PurgeExpiredLinksParams) (int64, error) {
+				if !arg.ExpiredBefore.Valid || !arg.ExpiredBefore.Time.Equal(before) {
+					t.Errorf("ExpiredBefore=%+v want %v", arg.ExpiredBefore, before)
+				}
+				if arg.BatchSize != 100 {
+					t.Errorf("BatchSize=%d want 100", arg.BatchSize)
+				}
+				return 7, nil
+			},
+		}
+
+		n, err := NewRepository(mock, nil).PurgeExpired(context.Background(), before, 100)
+		if err != nil {
+			t.Fatalf("PurgeExpired() unexpected error: %v", err)
+		}
+		if n != 7 {
+			t.Errorf("PurgeExpired()=%d want 7", n)
+		}
+	})
+
+	t.Run("purge deleted passes cutoff and batch size", func(t *testing.T) {
+		mock := &mockQueries{
+			purgeDeletedFunc: func(_ context.Context, arg db.PurgeDeletedLinksParams) (int64, error) {
+				if !arg.DeletedBefore.Valid || !arg.DeletedBefore.Time.Equal(before) {
+					t.Errorf("DeletedBefore=%+v want %v", arg.DeletedBefore, before)
+				}
+				if arg.BatchSize != 100 {
+					t.Errorf("BatchSize=%d want 100", arg.BatchSize)
+				}
+				return 3, nil
+			},
+		}
+
+		n, err := NewRepository(mock, nil).PurgeDeleted(context.Background(), before, 100)
+		if err != nil {
+			t.Fatalf("PurgeDeleted() unexpected error: %v", err)
+		}
+		if n != 3 {
+			t.Errorf("PurgeDeleted()=%d want 3", n)
+		}
+	})
+
+	t.Run("maps query failure to Unavailable", func(t *testing.T) {
+		mock := &mockQueries{
+			purgeExpiredFunc: func(_ context.Context, _ db.PurgeExpiredLinksParams) (int64, error) {
+				return 0, errors.New("connection reset")
+			},
+		}
+
+		_, err := NewRepository(mock, nil).PurgeExpired(context.Background(), before, 100)
+		if errx.KindOf(err) != errx.Unavailable {
+			t.Errorf("KindOf(err)=%v want %v", errx.KindOf(err), errx.Unavailable)
+		}
+	})
+}
+
+func TestRepoTrackUniqueVisitor(t *testing.T) {
+	linkID := makeUUIDv7Deterministic()
+
+	tests := []struct {
+		name     string
+		rows     int64
+		err      error
+		wantNew  bool
+		wantKind errx.Kind
+	}{
+		{name: "first visit", rows: 1, wantNew: true},
+		{name: "repeat visit", rows: 0, wantNew: false},
+		{name: "query failure", err: errors.New("connection reset"), wantKind: errx.Unavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &mockQueries{
+				trackVisitorFunc: func(_ context.Context, arg db.TrackUniqueVisitorParams) (int64, error) {
+					if arg.LinkID != linkID || arg.Fingerprint != "fp" {
+						t.Errorf("unexpected params: %+v", arg)
+					}
+					return tt.rows, tt.err
+				},
+			}
+
+			r := NewRepository(mock, &RepositoryConfig{IDGenerator: &stubIDGen{id: linkID}})
+
+			isNew, err := r.TrackUniqueVisitor(context.Background(), linkID, "fp")
+			if tt.err != nil {
+				if errx.KindOf(err) != tt.wantKind {
+					t.Errorf("KindOf(err)=%v want %v", errx.KindOf(err), tt.wantKind)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("TrackUniqueVisitor() unexpected error: %v", err)
+			}
+			if isNew != tt.wantNew {
+				t.Errorf("TrackUniqueVisitor()=%v want %v", isNew, tt.wantNew)
+			}
+		})
+	}
+}
+
 /***************
  * Constructor tests (UUIDv7 default)
  ***************/
@@ -3,32 +3,177 @@ package shortener
 import (
 	"context"
 	"errors"
+	"fmt"
+	"log/slog"
+	"net/netip"
 	"net/url"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode"
+	"unicode/utf8"
 
+	"github.com/google/uuid"
+
+	"github.com/sundayezeilo/urlshortener/internal/clock"
 	"github.com/sundayezeilo/urlshortener/internal/errx"
+	"github.com/sundayezeilo/urlshortener/internal/httpx"
 	"github.com/sundayezeilo/urlshortener/sluggen"
 )
 
 const (
-	DefaultSlugLength     = 7
-	MaxSlugLength         = 64
-	MinSlugLength         = 3
+	DefaultSlugLength = 7
+	// MaxSlugLength bounds generated slugs and is the default limit for
+	// custom ones.
+	MaxSlugLength = 64
+	// MaxCustomSlugLength is the longest custom slug any configuration may
+	// allow. It matches the upper bound of the links_slug_length check
+	// constraint.
+	MaxCustomSlugLength = 128
+	// MinSlugLength is the default minimum slug length. It matches the
+	// links_slug_length check constraint so short slugs are rejected as
+	// invalid input instead of failing at the database.
+	MinSlugLength         = 7
 	MaxURLLength          = 2048
 	DefaultSlugMaxRetries = 3
+
+	// DefaultListLimit and MaxListLimit bound the page size of List, unless
+	// ServiceConfig.ListMaxLimit overrides the cap.
+	DefaultListLimit = 20
+	MaxListLimit     = 100
+
+	// MaxSlugPrefixLength bounds tenant slug prefixes so namespaced slugs
+	// still leave room for a generated part.
+	MaxSlugPrefixLength = 16
+
+	// DefaultSlugSuggestions is how many available alternatives a custom
+	// slug conflict offers.
+	DefaultSlugSuggestions = 3
+	// slugSuggestionSuffixLength is the length of random suggestion suffixes.
+	slugSuggestionSuffixLength = 3
+
+	// MaxTimeSeriesBuckets caps the points in one time series: a month of
+	// hourly buckets.
+	MaxTimeSeriesBuckets = 744
+
+	// MaxClickRequestIDLength bounds stored request IDs, which may come from
+	// a client-supplied header. Longer IDs are not stored.
+	MaxClickRequestIDLength = 128
+
+	// DefaultSlugLengthCacheTTL is how long the link count used for slug
+	// length scaling is reused before it is queried again.
+	DefaultSlugLengthCacheTTL = time.Minute
+
+	// DefaultSlugCollisionTarget keeps the chance that a generated slug
+	// collides with a stored one below one in a thousand when slug length
+	// is chosen automatically.
+	DefaultSlugCollisionTarget = 0.001
 )
 
+// ErrSlugRetriesExhausted is returned, as errx.Unavailable, when every
+// generated slug collided with an existing one.
+var ErrSlugRetriesExhausted = errors.New("could not generate unique slug after retries")
+
+// SlugLengthThreshold raises the generated slug length to Length once the
+// number of stored links reaches MinLinks.
+type SlugLengthThreshold struct {
+	MinLinks int64
+	Length   int
+}
+
 // CreateLinkRequest represents the parameters for creating a new link.
 type CreateLinkRequest struct {
 	OriginalURL string
-	CustomSlug  string // Optional: if empty, a slug will be generated
+	CustomSlug  string    // Optional: if empty, a slug will be generated
+	Principal   string    // Optional: authenticated caller, selects the slug prefix
+	Source      string    // Optional: creation channel, one of Sources
+	Creator     Creator   // Optional: requesting client, stored with RecordCreators
+	UTM         UTMParams // Optional: campaign defaults added on resolve
+
+	// RedirectStatus optionally overrides the server's redirect status for
+	// the link, one of RedirectStatuses. 0 uses the server default.
+	RedirectStatus int
+}
+
+// Resolution is where a resolved link redirects to.
+type Resolution struct {
+	URL            string
+	RedirectStatus int  // 0 means the server default
+	Expired        bool // Past its expiry but within ServiceConfig.ExpiryGrace
+}
+
+// ListLinksRequest represents the parameters for listing links.
+type ListLinksRequest struct {
+	Limit  int    // Page size; 0 means DefaultListLimit, capped at ServiceConfig.ListMaxLimit
+	Cursor string // Optional: NextCursor from the previous page
+}
+
+// TimeSeriesRequest represents the parameters for a click time series.
+type TimeSeriesRequest struct {
+	Slug   string
+	Bucket TimeBucket // Default: BucketDay
+	From   time.Time  // Default: 30 buckets before To; rounded down to a bucket start
+	To     time.Time  // Exclusive; default: now
+}
+
+// TimeSeries is a link's clicks per bucket over [From, To).
+type TimeSeries struct {
+	Slug    string
+	Bucket  TimeBucket
+	From    time.Time
+	To      time.Time
+	Buckets []ClickBucket
+	Total   int64
+}
+
+// LinkMetadata is the admin view of a link.
+type LinkMetadata struct {
+	Link    Link
+	Creator *Creator // Nil when no creator was recorded
+}
+
+// LinkPage is one page of links, newest first.
+type LinkPage struct {
+	Links      []Link
+	Limit      int
+	NextCursor string // Empty when HasMore is false
+	HasMore    bool
 }
 
 // Service defines the business logic operations for URL shortening.
 type Service interface {
 	Create(ctx context.Context, req CreateLinkRequest) (Link, error)
+	CreateBatch(ctx context.Context, reqs []CreateLinkRequest) ([]BatchResult, error)
 	GetBySlug(ctx context.Context, slug string) (Link, error)
-	Resolve(ctx context.Context, slug string) (string, error)
+	GetByID(ctx context.Context, id uuid.UUID) (Link, error)
+	GetMetadata(ctx context.Context, slug string) (LinkMetadata, error)
+	List(ctx context.Context, req ListLinksRequest) (LinkPage, error)
+	GetByURL(ctx context.Context, rawURL string) ([]Link, error)
+	CountBySource(ctx context.Context) ([]SourceCount, error)
+	CountClicksByCountry(ctx context.Context) ([]CountryCount, error)
+	TimeSeries(ctx context.Context, req TimeSeriesRequest) (TimeSeries, error)
+	Resolve(ctx context.Context, slug string) (Resolution, error)
+	// RedirectStatus returns the redirect status override (0 for the
+	// server default) of the link slug resolves to, without counting an
+	// access, so a request can be refused before Resolve tracks it.
+	RedirectStatus(ctx context.Context, slug string) (int, error)
+	AddAlias(ctx context.Context, slug, alias string) (string, error)
+	// CheckSlugs reports whether each of slugs is free for principal to
+	// claim as a custom slug.
+	CheckSlugs(ctx context.Context, principal string, slugs []string) (map[string]SlugAvailability, error)
+	// RecommendedSlugLength returns the generated slug length that keeps
+	// collisions with stored slugs below the configured target.
+	RecommendedSlugLength(ctx context.Context) (int, error)
+	// CheckSlugGenerator generates a slug the way Create would now, with
+	// and without each configured prefix, and checks it against the
+	// configured slug rules.
+	CheckSlugGenerator(ctx context.Context) error
+	// SetActive pauses (active false) or resumes the link with slug and
+	// returns it. Paused links resolve as not found but keep their stats.
+	SetActive(ctx context.Context, slug string, active bool) (Link, error)
 	Delete(ctx context.Context, slug string) error
 }
 
@@ -36,17 +181,265 @@ type Service interface {
 type service struct {
 	repo           Repository
 	slugGenerator  sluggen.Generator
+	slugValidator  SlugValidator
 	slugLength     int
 	slugMaxRetries int
+
+	maxCustomSlugLength    int
+	maxGeneratedSlugLength int
+
+	slugLengthThresholds []SlugLengthThreshold
+	countCacheTTL        time.Duration
+	autoSlugLength       bool
+	collisionTarget      float64
+	slugAlphabet         int
+
+	trackUniqueVisitors bool
+	recordClicks        bool
+	recordRequestIDs    bool
+	geo                 GeoResolver
+	recordCreators      bool
+
+	slugPrefixes    map[string]string // principal -> prefix
+	caseInsensitive bool
+
+	slugSuggestions     int
+	duplicateSlugPolicy DuplicateSlugPolicy
+	maxBatchSize        int
+	listMaxLimit        int
+	slugNoLeadingDigit  bool
+
+	maxLinksPerOwner int64
+	idempotentCreate bool
+
+	preserveFragments bool
+
+	blockedDomains domainList
+	allowedDomains domainList
+	allowListMode  bool
+
+	reachability ReachabilityChecker
+
+	expiryGrace    time.Duration
+	defaultLinkTTL time.Duration
+
+	accessCountThreshold int64
+	autoPause            bool
+	accessAlerts         atomic.Uint64
+	logger               *slog.Logger
+
+	audit       AuditLogger
+	invalidator CacheInvalidator
+	notFound    SlugCache // nil when negative caching is off
+	clock       clock.Clock
+
+	clickTracker *ClickTracker // nil when tracking runs inline
+
+	countMu        sync.Mutex
+	cachedCount    int64
+	countFetchedAt time.Time
 }
 
 // ServiceConfig holds configuration for the service.
 type ServiceConfig struct {
 	SlugGenerator  sluggen.Generator
+	SlugValidator  SlugValidator // Checks custom slugs (default: built-in rules with MinSlugLength)
 	SlugLength     int
 	SlugMaxRetries int
+
+	// MinSlugLength overrides the minimum custom slug length enforced by the
+	// default validator (default: MinSlugLength). Lower it only if the
+	// links_slug_length constraint has been relaxed to match.
+	MinSlugLength int
+	// MaxCustomSlugLength overrides the maximum custom slug length enforced
+	// by the default validator (default: MaxSlugLength). It may be raised up
+	// to MaxCustomSlugLength to allow longer vanity slugs.
+	MaxCustomSlugLength int
+	// MaxGeneratedSlugLength caps generated slugs, including those
+	// lengthened by SlugLengthThresholds or by retries after a collision
+	// (default and limit: MaxSlugLength).
+	MaxGeneratedSlugLength int
+	// SlugCharset selects the characters the default validator accepts in
+	// custom slugs (default: AlphanumDashUnderscore).
+	SlugCharset SlugCharset
+	// SlugNoLeadingDigit keeps slugs from starting with a digit: the default
+	// validator rejects such custom slugs, generated ones are regenerated,
+	// and slug prefixes starting with a digit are ignored.
+	SlugNoLeadingDigit bool
+
+	// SlugLengthThresholds optionally lengthens generated slugs as the link
+	// table grows, keeping the collision probability low. The largest Length
+	// whose MinLinks has been reached wins; SlugLength is used below all of them.
+	SlugLengthThresholds []SlugLengthThreshold
+	// SlugLengthCacheTTL controls how long the link count is cached
+	// (default: DefaultSlugLengthCacheTTL).
+	SlugLengthCacheTTL time.Duration
+	// AutoSlugLength generates slugs at RecommendedSlugLength instead of
+	// following SlugLengthThresholds.
+	AutoSlugLength bool
+	// SlugCollisionTarget is the highest acceptable chance that a generated
+	// slug collides with a stored one, used by RecommendedSlugLength
+	// (default: DefaultSlugCollisionTarget).
+	SlugCollisionTarget float64
+	// SlugAlphabet is the number of characters SlugGenerator draws from,
+	// used to size the keyspace (default: DefaultKeyspaceAlphabet).
+	SlugAlphabet int
+
+	// TrackUniqueVisitors counts distinct daily visitors per link using a
+	// hashed fingerprint of the Visitor attached via WithVisitor.
+	TrackUniqueVisitors bool
+	// RecordClicks stores a timestamped event per resolution so TimeSeries
+	// has data. Without it every bucket is zero.
+	RecordClicks bool
+	// RecordClickRequestIDs stores the request ID (see httpx.GetRequestID)
+	// with each click event so analytics can be joined with request logs.
+	RecordClickRequestIDs bool
+	// GeoResolver, when set, tags each click event with the client's
+	// country (see WithVisitor). The lookup runs wherever the click is
+	// recorded: pair it with a ClickTracker so a slow resolver never
+	// delays the redirect.
+	GeoResolver GeoResolver
+	// ClickTracker, when set, runs unique visitor tracking and click
+	// recording in the background instead of on the redirect path. The
+	// access count is still incremented inline by ResolveAndTrack.
+	ClickTracker *ClickTracker
+	// RecordCreators stores the IP address and user agent of the client
+	// creating each link, for abuse investigation. They are personal data:
+	// enable only where that is permitted. They are shown only by
+	// GetMetadata, never on public responses.
+	RecordCreators bool
+
+	// SlugPrefixes maps an authenticated principal to the namespace its
+	// slugs are created under, e.g. "acme" yields "acme-<slug>". Prefixes
+	// must be alphanumeric and at most MaxSlugPrefixLength long; others are
+	// ignored. Callers without a prefix cannot claim slugs in a namespace.
+	SlugPrefixes map[string]string
+
+	// CaseInsensitiveSlugs treats slugs differing only in case as the same
+	// slug, matching RepositoryConfig.CaseInsensitiveSlugs.
+	CaseInsensitiveSlugs bool
+
+	// SlugSuggestions is how many alternatives are offered when a custom
+	// slug is taken (default: DefaultSlugSuggestions; negative disables).
+	SlugSuggestions int
+
+	// DuplicateSlugPolicy decides what CreateBatch does when two rows of a
+	// batch request the same custom slug (default: DuplicateSlugFail).
+	DuplicateSlugPolicy DuplicateSlugPolicy
+	// MaxBatchSize bounds the rows of one CreateBatch call
+	// (default: DefaultMaxBatchSize).
+	MaxBatchSize int
+
+	// ListMaxLimit caps the page size of List so no request can scan an
+	// unbounded number of links; larger limits are clamped to it, and the
+	// default page size never exceeds it (default: MaxListLimit).
+	ListMaxLimit int
+
+	// MaxLinksPerOwner caps the live links a principal may own; creates
+	// beyond it fail with errx.QuotaExceeded. 0 means unlimited. Anonymous
+	// links have no owner and are not counted. The check is not atomic with
+	// the insert, so concurrent creates may overshoot slightly.
+	MaxLinksPerOwner int
+
+	// IdempotentCreate makes a create retried with the same custom slug,
+	// URL and owner return the existing link instead of a conflict, so
+	// clients can safely retry after a lost response. A different URL or
+	// owner is still a conflict.
+	IdempotentCreate bool
+
+	// PreserveURLFragments keeps a destination's "#fragment" when the link
+	// is stored. It is then part of every redirect's Location, which
+	// browsers follow to the fragment. By default fragments are stripped:
+	// they never reach the server, so most are accidental leftovers.
+	PreserveURLFragments bool
+
+	// BlockedDomains lists destination hosts that may not be shortened;
+	// creates for them fail with errx.Forbidden. "example.com" blocks that
+	// host, "*.example.com" blocks its subdomains.
+	BlockedDomains []string
+	// AllowedDomains, with AllowListMode set, are the only destination
+	// hosts that may be shortened; others fail with errx.Forbidden. They
+	// use the BlockedDomains patterns and take precedence over them: an
+	// allowed host is accepted even if it is also blocked.
+	AllowedDomains []string
+	AllowListMode  bool
+
+	// ReachabilityChecker, when set, checks each destination created by
+	// CreateBatch and reports the outcome per row. It sends requests to
+	// user-supplied URLs, so it is off by default (nil).
+	ReachabilityChecker ReachabilityChecker
+
+	// AuditLogger records every link mutation (default: discard).
+	AuditLogger AuditLogger
+
+	// ExpiryGrace keeps expired links resolving for this long after they
+	// expire, flagged as Resolution.Expired, to ease migrating clients off
+	// them. Zero cuts them off at expiry.
+	ExpiryGrace time.Duration
+
+	// DefaultLinkTTL, when positive, makes created links expire this long
+	// after creation, for deployments serving ephemeral links. Zero
+	// creates links that never expire.
+	DefaultLinkTTL time.Duration
+
+	// AccessCountThreshold, when positive, flags a link whose access count
+	// reaches it, a sign of bot traffic, with a warning. With
+	// AutoPauseOverThreshold the link is also paused until resumed by hand.
+	AccessCountThreshold   int64
+	AutoPauseOverThreshold bool
+	// Logger receives access count warnings (default: slog.Default).
+	Logger *slog.Logger
+
+	// CacheInvalidator is told about every slug and alias whose redirect
+	// target stops being valid, by its key (see CaseInsensitiveSlugs), so
+	// caches in front of Resolve drop it (default: none).
+	CacheInvalidator CacheInvalidator
+
+	// NotFoundCache, when set, remembers slugs that failed to resolve so
+	// repeated probes of unknown slugs don't each query the database. A
+	// MemoryCache with a short TTL fits. Slugs and aliases created through
+	// this service are dropped from it at once; other instances keep
+	// answering 404 until their entry expires, so keep the TTL short.
+	NotFoundCache SlugCache
+
+	// Clock stamps audit entries, buckets unique visitors by day, bounds
+	// time series queries and ages the link count cache (default: clock.Real).
+	Clock clock.Clock
+}
+
+// CacheInvalidator evicts a slug from any cache holding its resolved URL.
+// It is called after the change is committed and must not block for long;
+// delivery to remote caches is best-effort.
+type CacheInvalidator interface {
+	Invalidate(ctx context.Context, slug string)
+}
+
+// SlugCache is a cache keyed by slug, such as MemoryCache.
+type SlugCache interface {
+	Get(slug string) (string, bool)
+	Set(slug, url string)
+	CacheInvalidator
+}
+
+// nopCacheInvalidator is used when no cache is configured.
+type nopCacheInvalidator struct{}
+
+func (nopCacheInvalidator) Invalidate(context.Context, string) {}
+
+// SlugTakenError reports a custom slug conflict together with available
+// alternatives the caller can retry with.
+type SlugTakenError struct {
+	Slug        string
+	Suggestions []string
+	Err         error
+}
+
+func (e *SlugTakenError) Error() string {
+	return fmt.Sprintf("slug %q is already taken", e.Slug)
 }
 
+func (e *SlugTakenError) Unwrap() error { return e.Err }
+
 // NewService creates a new service instance.
 func NewService(repo Repository, config *ServiceConfig) Service {
 	if config == nil {
@@ -58,9 +451,30 @@ func NewService(repo Repository, config *ServiceConfig) Service {
 		slugGen = sluggen.NewBase62()
 	}
 
+	maxCustom := config.MaxCustomSlugLength
+	if maxCustom <= 0 || maxCustom > MaxCustomSlugLength {
+		maxCustom = MaxSlugLength
+	}
+
+	slugValidator := config.SlugValidator
+	if slugValidator == nil {
+		slugValidator = NewSlugValidator(SlugRules{
+			MinLength: config.MinSlugLength,
+			MaxLength: maxCustom,
+			Charset:   config.SlugCharset,
+
+			NoLeadingDigit: config.SlugNoLeadingDigit,
+		})
+	}
+
+	maxGenerated := config.MaxGeneratedSlugLength
+	if maxGenerated < MinSlugLength || maxGenerated > MaxSlugLength {
+		maxGenerated = MaxSlugLength
+	}
+
 	slugLength := config.SlugLength
-	if slugLength < MinSlugLength || slugLength > MaxSlugLength {
-		slugLength = DefaultSlugLength
+	if slugLength < MinSlugLength || slugLength > maxGenerated {
+		slugLength = min(DefaultSlugLength, maxGenerated)
 	}
 
 	retries := config.SlugMaxRetries
@@ -72,52 +486,240 @@ func NewService(repo Repository, config *ServiceConfig) Service {
 		retries = 1 // At least one attempt
 	}
 
+	var thresholds []SlugLengthThreshold
+	for _, t := range config.SlugLengthThresholds {
+		if t.MinLinks < 0 || t.Length < MinSlugLength || t.Length > maxGenerated {
+			continue
+		}
+		thresholds = append(thresholds, t)
+	}
+
+	prefixes := make(map[string]string, len(config.SlugPrefixes))
+	for principal, prefix := range config.SlugPrefixes {
+		if principal == "" || !validSlugPrefix(prefix) ||
+			(config.SlugNoLeadingDigit && startsWithDigit(prefix)) {
+			continue
+		}
+		prefixes[principal] = prefix
+	}
+
+	countCacheTTL := config.SlugLengthCacheTTL
+	if countCacheTTL <= 0 {
+		countCacheTTL = DefaultSlugLengthCacheTTL
+	}
+
+	collisionTarget := config.SlugCollisionTarget
+	if collisionTarget <= 0 || collisionTarget >= 1 {
+		collisionTarget = DefaultSlugCollisionTarget
+	}
+
+	alphabet := config.SlugAlphabet
+	if alphabet < 2 {
+		alphabet = DefaultKeyspaceAlphabet
+	}
+
+	suggestions := config.SlugSuggestions
+	if suggestions == 0 {
+		suggestions = DefaultSlugSuggestions
+	}
+
+	maxBatch := config.MaxBatchSize
+	if maxBatch <= 0 {
+		maxBatch = DefaultMaxBatchSize
+	}
+
+	listMax := config.ListMaxLimit
+	if listMax <= 0 {
+		listMax = MaxListLimit
+	}
+
+	audit := config.AuditLogger
+	if audit == nil {
+		audit = nopAuditLogger{}
+	}
+
+	logger := config.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	invalidator := config.CacheInvalidator
+	if invalidator == nil {
+		invalidator = nopCacheInvalidator{}
+	}
+
+	clk := config.Clock
+	if clk == nil {
+		clk = clock.Real
+	}
+
 	return &service{
-		repo:           repo,
-		slugGenerator:  slugGen,
-		slugLength:     slugLength,
-		slugMaxRetries: retries,
+		repo:                   repo,
+		slugGenerator:          slugGen,
+		slugValidator:          slugValidator,
+		slugLength:             slugLength,
+		slugMaxRetries:         retries,
+		maxCustomSlugLength:    maxCustom,
+		maxGeneratedSlugLength: maxGenerated,
+		slugLengthThresholds:   thresholds,
+		countCacheTTL:          countCacheTTL,
+		autoSlugLength:         config.AutoSlugLength,
+		collisionTarget:        collisionTarget,
+		slugAlphabet:           alphabet,
+		trackUniqueVisitors:    config.TrackUniqueVisitors,
+		recordClicks:           config.RecordClicks,
+		recordRequestIDs:       config.RecordClickRequestIDs,
+		geo:                    config.GeoResolver,
+		recordCreators:         config.RecordCreators,
+		slugPrefixes:           prefixes,
+		caseInsensitive:        config.CaseInsensitiveSlugs,
+		slugSuggestions:        max(suggestions, 0),
+		duplicateSlugPolicy:    config.DuplicateSlugPolicy,
+		maxBatchSize:           maxBatch,
+		listMaxLimit:           listMax,
+		slugNoLeadingDigit:     config.SlugNoLeadingDigit,
+		maxLinksPerOwner:       int64(max(config.MaxLinksPerOwner, 0)),
+		idempotentCreate:       config.IdempotentCreate,
+		preserveFragments:      config.PreserveURLFragments,
+		blockedDomains:         newDomainList(config.BlockedDomains),
+		allowedDomains:         newDomainList(config.AllowedDomains),
+		allowListMode:          config.AllowListMode,
+		reachability:           config.ReachabilityChecker,
+		expiryGrace:            max(config.ExpiryGrace, 0),
+		defaultLinkTTL:         max(config.DefaultLinkTTL, 0),
+		accessCountThreshold:   max(config.AccessCountThreshold, 0),
+		autoPause:              config.AutoPauseOverThreshold,
+		logger:                 logger,
+		audit:                  audit,
+		invalidator:            invalidator,
+		notFound:               config.NotFoundCache,
+		clickTracker:           config.ClickTracker,
+		clock:                  clk,
+	}
+}
+
+// trimmed returns req with surrounding whitespace removed from the URL and
+// custom slug. A custom slug of only whitespace is rejected rather than
+// silently replaced by a generated one; whitespace inside either value is
+// still left for validation to reject.
+func (req CreateLinkRequest) trimmed() (CreateLinkRequest, error) {
+	req.OriginalURL = strings.TrimSpace(req.OriginalURL)
+	if req.CustomSlug != "" {
+		if req.CustomSlug = strings.TrimSpace(req.CustomSlug); req.CustomSlug == "" {
+			return req, errors.New("custom slug cannot be blank")
+		}
 	}
+	return req, nil
 }
 
 // Create creates a new short link with optional custom slug.
 func (s *service) Create(ctx context.Context, req CreateLinkRequest) (Link, error) {
 	const op = "shortener.service.Create"
 
+	req, err := req.trimmed()
+	if err != nil {
+		return Link{}, errx.E(op, errx.Invalid, err)
+	}
 	if err := validateURL(req.OriginalURL); err != nil {
 		return Link{}, errx.E(op, errx.Invalid, err)
 	}
+	if err := s.checkDestination(req.OriginalURL); err != nil {
+		return Link{}, errx.E(op, errx.KindOf(err), err)
+	}
+	if !validSource(req.Source) {
+		return Link{}, errx.E(op, errx.Invalid,
+			fmt.Errorf("unknown source %q (must be one of: %s)", req.Source, strings.Join(Sources, ", ")))
+	}
+	if err := req.UTM.validate(); err != nil {
+		return Link{}, errx.E(op, errx.Invalid, err)
+	}
+	if !validRedirectStatus(req.RedirectStatus) {
+		return Link{}, errx.E(op, errx.Invalid,
+			fmt.Errorf("unsupported redirect status %d (must be one of: 301, 302, 307, 308)", req.RedirectStatus))
+	}
+	originalURL := normalizeURL(req.OriginalURL, s.preserveFragments)
+	prefix := s.slugPrefixes[req.Principal]
 
-	// Custom slug path: validate and create once
+	var slug string
 	if req.CustomSlug != "" {
-		if err := validateSlug(req.CustomSlug); err != nil {
-			return Link{}, errx.E(op, errx.Invalid, err)
+		var err error
+		slug, err = s.namespacedCustomSlug(prefix, req.CustomSlug)
+		if err != nil {
+			return Link{}, errx.E(op, errx.KindOf(err), err)
 		}
+	}
 
+	if err := s.checkOwnerQuota(ctx, req.Principal); err != nil {
+		// A retry of a create that already succeeded needs no new quota.
+		if existing, ok := s.findReplay(ctx, slug, originalURL, req.Principal); ok {
+			return existing, nil
+		}
+		return Link{}, errx.E(op, errx.KindOf(err), err)
+	}
+
+	// Custom slug path: create once
+	if slug != "" {
 		created, err := s.repo.Create(ctx, Link{
-			OriginalURL: req.OriginalURL,
-			Slug:        req.CustomSlug,
+			OriginalURL: originalURL,
+			Slug:        slug,
+			Source:      req.Source,
+			Owner:       req.Principal,
+			UTM:         req.UTM,
+			ExpiresAt:   s.defaultExpiry(),
+
+			RedirectStatus: req.RedirectStatus,
 		})
+		if errx.KindOf(err) == errx.Conflict {
+			if existing, ok := s.findReplay(ctx, slug, originalURL, req.Principal); ok {
+				return existing, nil
+			}
+			return Link{}, errx.E(op, errx.Conflict, &SlugTakenError{
+				Slug:        slug,
+				Suggestions: s.suggestSlugs(ctx, slug),
+				Err:         err,
+			})
+		}
 		if err != nil {
 			return Link{}, errx.E(op, errx.KindOf(err), err)
 		}
+		s.recordAudit(ctx, AuditCreate, created)
+		s.recordCreator(ctx, created, req.Creator)
+		s.forgetNotFound(ctx, created.Slug)
 		return created, nil
 	}
 
-	// Generated slug path: retry on conflicts
+	// Generated slug path: retry on conflicts, lengthening the slug by one
+	// character per collision up to the generated length cap.
 	maxAttempts := s.slugMaxRetries
+	maxLength := s.maxGeneratedSlugLength
+	if prefix != "" {
+		maxLength -= len(prefix) + 1
+	}
+	slugLength := min(s.generatedSlugLength(ctx), maxLength)
 
 	for range maxAttempts {
-		slug, err := s.slugGenerator.Generate(s.slugLength)
+		slug, err := s.generateSlug(slugLength, prefix == "")
 		if err != nil {
 			return Link{}, errx.E(op, errx.Unavailable, err)
 		}
+		if prefix != "" {
+			slug = prefix + "-" + slug
+		}
 
 		created, err := s.repo.Create(ctx, Link{
-			OriginalURL: req.OriginalURL,
+			OriginalURL: originalURL,
 			Slug:        slug,
+			Source:      req.Source,
+			Owner:       req.Principal,
+			UTM:         req.UTM,
+			ExpiresAt:   s.defaultExpiry(),
+
+			RedirectStatus: req.RedirectStatus,
 		})
 		if err == nil {
+			s.recordAudit(ctx, AuditCreate, created)
+			s.recordCreator(ctx, created, req.Creator)
+			s.forgetNotFound(ctx, created.Slug)
 			return created, nil
 		}
 
@@ -125,10 +727,261 @@ func (s *service) Create(ctx context.Context, req CreateLinkRequest) (Link, erro
 		if errx.KindOf(err) != errx.Conflict {
 			return Link{}, errx.E(op, errx.KindOf(err), err)
 		}
+		slugLength = min(slugLength+1, maxLength)
+	}
+
+	return Link{}, errx.E(op, errx.Unavailable, ErrSlugRetriesExhausted)
+}
+
+// defaultExpiry returns when a link created now expires under
+// DefaultLinkTTL, or nil when links don't expire by default.
+func (s *service) defaultExpiry() *time.Time {
+	if s.defaultLinkTTL == 0 {
+		return nil
+	}
+	expiresAt := s.clock.Now().Add(s.defaultLinkTTL)
+	return &expiresAt
+}
+
+// maxLeadingDigitRerolls bounds how often generateSlug regenerates a slug
+// starting with a digit. With base62 a roll leads with one about one time
+// in six, so running out means the generator cannot do otherwise.
+const maxLeadingDigitRerolls = 32
+
+// generateSlug generates a slug of length. When the slug leads the final
+// one (it has no prefix) and SlugNoLeadingDigit is set, slugs starting with
+// a digit are regenerated.
+func (s *service) generateSlug(length int, leads bool) (string, error) {
+	for range maxLeadingDigitRerolls {
+		slug, err := s.slugGenerator.Generate(length)
+		if err != nil {
+			return "", err
+		}
+		if !leads || !s.slugNoLeadingDigit || !startsWithDigit(slug) {
+			return slug, nil
+		}
+	}
+	return "", errors.New("slug generator keeps producing slugs with a leading digit")
+}
+
+// CheckSlugGenerator generates one slug per namespace at the length Create
+// would use and validates it with the configured slug rules, so a generator
+// that can't satisfy them is caught before the first create.
+func (s *service) CheckSlugGenerator(ctx context.Context) error {
+	const op = "shortener.service.CheckSlugGenerator"
+
+	prefixes := []string{""}
+	for _, prefix := range s.slugPrefixes {
+		prefixes = append(prefixes, prefix)
+	}
+	slices.Sort(prefixes)
+	prefixes = slices.Compact(prefixes)
+
+	// Same length choice as Create's generated slug path.
+	base := s.generatedSlugLength(ctx)
+	for _, prefix := range prefixes {
+		maxLength := s.maxGeneratedSlugLength
+		if prefix != "" {
+			maxLength -= len(prefix) + 1
+		}
+		length := min(base, maxLength)
+
+		slug, err := s.generateSlug(length, prefix == "")
+		if err != nil {
+			return errx.E(op, errx.Unavailable, err)
+		}
+		if len(slug) != length {
+			return errx.E(op, errx.Internal,
+				fmt.Errorf("slug generator returned %q, want %d characters", slug, length))
+		}
+		if prefix != "" {
+			slug = prefix + "-" + slug
+		}
+		if err := s.slugValidator.Validate(slug); err != nil {
+			return errx.E(op, errx.Invalid, fmt.Errorf("slug generator returned invalid slug %q: %w", slug, err))
+		}
+	}
+	return nil
+}
+
+// startsWithDigit reports whether s starts with an ASCII digit.
+func startsWithDigit(s string) bool {
+	return s != "" && s[0] >= '0' && s[0] <= '9'
+}
+
+// findReplay returns the live link at slug when idempotent creates are
+// enabled and it has the given URL and owner, i.e. the create is a retry.
+func (s *service) findReplay(ctx context.Context, slug, originalURL, owner string) (Link, bool) {
+	if !s.idempotentCreate || slug == "" {
+		return Link{}, false
+	}
+
+	existing, err := s.repo.GetBySlug(ctx, slug)
+	if err != nil {
+		return Link{}, false
+	}
+	if existing.OriginalURL != originalURL || existing.Owner != owner {
+		return Link{}, false
+	}
+	if existing.ExpiresAt != nil && !existing.ExpiresAt.After(s.clock.Now()) {
+		return Link{}, false
+	}
+
+	existing.Replayed = true
+	return existing, true
+}
+
+// checkDestination applies the domain allow and deny lists to a validated
+// URL. In allow-list mode only the allow list is consulted.
+func (s *service) checkDestination(rawURL string) error {
+	const op = "shortener.service.checkDestination"
+
+	host := destinationHost(rawURL)
+	if s.allowListMode {
+		if !s.allowedDomains.matches(host) {
+			return errx.E(op, errx.Forbidden,
+				fmt.Errorf("destination domain %q is not allowed", host))
+		}
+		return nil
+	}
+	if s.blockedDomains.matches(host) {
+		return errx.E(op, errx.Forbidden,
+			fmt.Errorf("destination domain %q is blocked", host))
+	}
+	return nil
+}
+
+// checkOwnerQuota fails with QuotaExceeded once owner holds
+// maxLinksPerOwner live links.
+func (s *service) checkOwnerQuota(ctx context.Context, owner string) error {
+	const op = "shortener.service.checkOwnerQuota"
+
+	if s.maxLinksPerOwner == 0 || owner == "" {
+		return nil
+	}
+
+	n, err := s.repo.CountByOwner(ctx, owner)
+	if err != nil {
+		return errx.E(op, errx.KindOf(err), err)
+	}
+	if n >= s.maxLinksPerOwner {
+		return errx.E(op, errx.QuotaExceeded,
+			fmt.Errorf("link limit of %d reached", s.maxLinksPerOwner))
+	}
+	return nil
+}
+
+// suggestSlugs returns up to s.slugSuggestions free alternatives to a taken
+// slug: counter suffixes first ("my-link-2", "my-link-3"), then short random
+// ones ("my-link-x7q"). Suggestions are best effort; a failed availability
+// lookup yields none rather than failing the request.
+func (s *service) suggestSlugs(ctx context.Context, slug string) []string {
+	n := s.slugSuggestions
+	if n == 0 {
+		return nil
+	}
+
+	base, next := splitSlugCounter(slug)
+	candidates := make([]string, 0, 2*n)
+	for i := range n {
+		candidates = append(candidates, withSlugSuffix(base, strconv.Itoa(next+i), s.maxCustomSlugLength))
+	}
+	for range n {
+		suffix, err := s.slugGenerator.Generate(slugSuggestionSuffixLength)
+		if err != nil {
+			break
+		}
+		candidates = append(candidates, withSlugSuffix(base, suffix, s.maxCustomSlugLength))
+	}
+
+	seen := map[string]bool{slug: true}
+	valid := candidates[:0]
+	for _, c := range candidates {
+		if seen[c] || s.slugValidator.Validate(c) != nil {
+			continue
+		}
+		seen[c] = true
+		valid = append(valid, c)
+	}
+	if len(valid) == 0 {
+		return nil
+	}
+
+	taken, err := s.repo.TakenSlugs(ctx, valid)
+	if err != nil {
+		return nil
+	}
+
+	var free []string
+	for _, c := range valid {
+		if !taken[c] {
+			free = append(free, c)
+			if len(free) == n {
+				break
+			}
+		}
+	}
+	return free
+}
+
+// splitSlugCounter splits a trailing "-<n>" counter off slug and returns
+// the base and the next counter value, so "my-link-2" continues at 3.
+func splitSlugCounter(slug string) (string, int) {
+	if i := strings.LastIndexByte(slug, '-'); i > 0 {
+		digits := slug[i+1:]
+		if len(digits) > 0 && len(digits) <= 6 && strings.Trim(digits, "0123456789") == "" {
+			n, _ := strconv.Atoi(digits)
+			return slug[:i], n + 1
+		}
+	}
+	return slug, 2
+}
+
+// withSlugSuffix joins base and suffix with a dash, shortening base so the
+// result fits maxLength without ending the base in punctuation.
+func withSlugSuffix(base, suffix string, maxLength int) string {
+	if limit := maxLength - len(suffix) - 1; len(base) > limit {
+		base = strings.TrimRight(base[:limit], "-_.")
+	}
+	return base + "-" + suffix
+}
+
+// namespacedCustomSlug places a custom slug under prefix and validates the
+// result. Callers with a prefix get it prepended unless the slug already
+// carries it, so they can never land in another namespace; callers without
+// one are refused slugs that fall in any configured namespace.
+func (s *service) namespacedCustomSlug(prefix, slug string) (string, error) {
+	const op = "shortener.service.namespacedCustomSlug"
+
+	if prefix != "" {
+		if !strings.HasPrefix(s.key(slug), s.key(prefix)+"-") {
+			slug = prefix + "-" + slug
+		}
+	} else if owner, ok := s.slugNamespace(slug); ok {
+		return "", errx.E(op, errx.Forbidden,
+			fmt.Errorf("slug is reserved for the %q namespace", owner))
+	}
+
+	if err := s.slugValidator.Validate(slug); err != nil {
+		return "", errx.E(op, errx.Invalid, err)
+	}
+	return slug, nil
+}
+
+// slugNamespace returns the configured prefix slug falls under, if any.
+func (s *service) slugNamespace(slug string) (string, bool) {
+	key := s.key(slug)
+	for _, prefix := range s.slugPrefixes {
+		if strings.HasPrefix(key, s.key(prefix)+"-") {
+			return prefix, true
+		}
 	}
+	return "", false
+}
 
-	return Link{}, errx.E(op, errx.Unavailable,
-		errors.New("could not generate unique slug after retries"))
+// key folds slug the way the repository compares slugs.
+func (s *service) key(slug string) string {
+	return slugKey(slug, s.caseInsensitive)
 }
 
 func (s *service) GetBySlug(ctx context.Context, slug string) (Link, error) {
@@ -145,18 +998,320 @@ func (s *service) GetBySlug(ctx context.Context, slug string) (Link, error) {
 	return link, nil
 }
 
-func (s *service) Resolve(ctx context.Context, slug string) (string, error) {
+// GetByID returns the live link with id, for clients holding the ID
+// returned on create rather than the slug.
+func (s *service) GetByID(ctx context.Context, id uuid.UUID) (Link, error) {
+	const op = "shortener.service.GetByID"
+
+	if id == uuid.Nil {
+		return Link{}, errx.E(op, errx.Invalid, errors.New("id cannot be empty"))
+	}
+
+	link, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return Link{}, errx.E(op, errx.KindOf(err), err)
+	}
+	return link, nil
+}
+
+// GetMetadata returns the admin view of the live link with slug, including
+// its recorded creator.
+func (s *service) GetMetadata(ctx context.Context, slug string) (LinkMetadata, error) {
+	const op = "shortener.service.GetMetadata"
+
+	link, err := s.GetBySlug(ctx, slug)
+	if err != nil {
+		return LinkMetadata{}, errx.E(op, errx.KindOf(err), err)
+	}
+
+	creator, err := s.repo.GetCreator(ctx, link.ID)
+	switch {
+	case errx.KindOf(err) == errx.NotFound:
+		return LinkMetadata{Link: link}, nil
+	case err != nil:
+		return LinkMetadata{}, errx.E(op, errx.KindOf(err), err)
+	}
+	return LinkMetadata{Link: link, Creator: &creator}, nil
+}
+
+// List returns a page of links. It fetches one row beyond the limit to learn
+// whether another page exists without a separate count query.
+func (s *service) List(ctx context.Context, req ListLinksRequest) (LinkPage, error) {
+	const op = "shortener.service.List"
+
+	limit := req.Limit
+	if limit <= 0 {
+		limit = DefaultListLimit
+	}
+	limit = min(limit, s.listMaxLimit)
+
+	var after *LinkCursor
+	if req.Cursor != "" {
+		c, err := decodeCursor(req.Cursor)
+		if err != nil {
+			return LinkPage{}, errx.E(op, errx.Invalid, err)
+		}
+		after = &c
+	}
+
+	links, err := s.repo.List(ctx, after, limit+1)
+	if err != nil {
+		return LinkPage{}, errx.E(op, errx.KindOf(err), err)
+	}
+
+	page := LinkPage{Links: links, Limit: limit}
+	if len(links) > limit {
+		page.Links = links[:limit]
+		page.HasMore = true
+		page.NextCursor = cursorAfter(page.Links[limit-1]).Encode()
+	}
+	return page, nil
+}
+
+// GetByURL returns all links pointing at rawURL after normalizing it.
+func (s *service) GetByURL(ctx context.Context, rawURL string) ([]Link, error) {
+	const op = "shortener.service.GetByURL"
+
+	if err := validateURL(rawURL); err != nil {
+		return nil, errx.E(op, errx.Invalid, err)
+	}
+
+	links, err := s.repo.ListByURL(ctx, normalizeURL(rawURL, s.preserveFragments))
+	if err != nil {
+		return nil, errx.E(op, errx.KindOf(err), err)
+	}
+	if len(links) == 0 {
+		return nil, errx.E(op, errx.NotFound, errors.New("no links for url"))
+	}
+	return links, nil
+}
+
+// CountBySource returns the number of live links per creation source.
+func (s *service) CountBySource(ctx context.Context) ([]SourceCount, error) {
+	const op = "shortener.service.CountBySource"
+
+	counts, err := s.repo.CountBySource(ctx)
+	if err != nil {
+		return nil, errx.E(op, errx.KindOf(err), err)
+	}
+	return counts, nil
+}
+
+func (s *service) Resolve(ctx context.Context, slug string) (Resolution, error) {
 	const op = "shortener.service.Resolve"
 
+	if slug == "" {
+		return Resolution{}, errx.E(op, errx.Invalid, errors.New("slug cannot be empty"))
+	}
+
+	if s.notFound != nil {
+		if _, ok := s.notFound.Get(s.key(slug)); ok {
+			return Resolution{}, errx.E(op, errx.NotFound, errors.New("link not found (cached)"))
+		}
+	}
+
+	link, err := s.repo.ResolveAndTrack(ctx, slug, s.expiryGrace)
+	if errx.KindOf(err) == errx.NotFound {
+		link, err = s.resolveAlias(ctx, slug, err)
+	}
+	if err != nil {
+		if errx.KindOf(err) == errx.NotFound && s.notFound != nil {
+			s.notFound.Set(s.key(slug), "")
+		}
+		return Resolution{}, errx.E(op, errx.KindOf(err), err)
+	}
+	if s.accessCountThreshold > 0 && link.AccessCount == s.accessCountThreshold {
+		s.accessCountReached(ctx, link)
+	}
+
+	if s.trackUniqueVisitors || s.recordClicks {
+		if s.clickTracker != nil {
+			s.clickTracker.Track(ctx, func(ctx context.Context) { s.track(ctx, link) })
+		} else {
+			s.track(ctx, link)
+		}
+	}
+	return Resolution{
+		URL:            link.Destination(),
+		RedirectStatus: link.RedirectStatus,
+		Expired:        link.ExpiresAt != nil && !link.ExpiresAt.After(s.clock.Now()),
+	}, nil
+}
+
+// track records the unique visitor and click event of a resolution, as
+// configured. It runs on the ClickTracker when there is one.
+func (s *service) track(ctx context.Context, link Link) {
+	if s.trackUniqueVisitors {
+		s.trackVisitor(ctx, link)
+	}
+	if s.recordClicks {
+		click := ClickEvent{LinkID: link.ID}
+		if id := httpx.GetRequestID(ctx); s.recordRequestIDs && len(id) <= MaxClickRequestIDLength {
+			click.RequestID = id
+		}
+		if s.geo != nil {
+			s.recordClickWithCountry(ctx, click)
+		} else {
+			// Best-effort like unique counting: never fail the redirect.
+			_ = s.repo.RecordClick(ctx, click)
+		}
+	}
+}
+
+// resolveAlias resolves alias through the link it points at. An unknown
+// alias keeps the primary lookup's notFound error.
+func (s *service) resolveAlias(ctx context.Context, alias string, notFound error) (Link, error) {
+	slug, err := s.repo.SlugForAlias(ctx, alias)
+	if errx.KindOf(err) == errx.NotFound {
+		return Link{}, notFound
+	}
+	if err != nil {
+		return Link{}, err
+	}
+	return s.repo.ResolveAndTrack(ctx, slug, s.expiryGrace)
+}
+
+func (s *service) RedirectStatus(ctx context.Context, slug string) (int, error) {
+	const op = "shortener.service.RedirectStatus"
+
+	if slug == "" {
+		return 0, errx.E(op, errx.Invalid, errors.New("slug cannot be empty"))
+	}
+
+	link, err := s.repo.GetBySlug(ctx, slug)
+	if errx.KindOf(err) == errx.NotFound {
+		var primary string
+		if primary, err = s.repo.SlugForAlias(ctx, slug); err == nil {
+			link, err = s.repo.GetBySlug(ctx, primary)
+		}
+	}
+	if err != nil {
+		return 0, errx.E(op, errx.KindOf(err), err)
+	}
+	if link.Paused || (link.ExpiresAt != nil && !link.ExpiresAt.Add(s.expiryGrace).After(s.clock.Now())) {
+		return 0, errx.E(op, errx.NotFound, errors.New("link does not resolve"))
+	}
+	return link.RedirectStatus, nil
+}
+
+// AddAlias makes alias an additional slug of the live link with slug, so
+// the link keeps resolving under both. The alias follows the custom slug
+// rules, including the owner's namespace, and returns as stored. It fails
+// with errx.Conflict when alias is already a slug or another alias.
+func (s *service) AddAlias(ctx context.Context, slug, alias string) (string, error) {
+	const op = "shortener.service.AddAlias"
+
 	if slug == "" {
 		return "", errx.E(op, errx.Invalid, errors.New("slug cannot be empty"))
 	}
+	if alias == "" {
+		return "", errx.E(op, errx.Invalid, errors.New("alias cannot be empty"))
+	}
 
-	link, err := s.repo.ResolveAndTrack(ctx, slug)
+	link, err := s.repo.GetBySlug(ctx, slug)
 	if err != nil {
 		return "", errx.E(op, errx.KindOf(err), err)
 	}
-	return link.OriginalURL, nil
+
+	alias, err = s.namespacedCustomSlug(s.slugPrefixes[link.Owner], alias)
+	if err != nil {
+		return "", errx.E(op, errx.KindOf(err), err)
+	}
+	if err := s.repo.AddAlias(ctx, link.ID, alias); err != nil {
+		return "", errx.E(op, errx.KindOf(err), err)
+	}
+	s.recordAudit(ctx, AuditUpdate, link)
+	s.forgetNotFound(ctx, alias)
+	return alias, nil
+}
+
+// forgetNotFound drops a newly created slug or alias from the not-found
+// cache so it resolves immediately.
+func (s *service) forgetNotFound(ctx context.Context, slug string) {
+	if s.notFound != nil {
+		s.notFound.Invalidate(ctx, s.key(slug))
+	}
+}
+
+// recordClickWithCountry tags click with the visitor's country and stores
+// it. Failures are dropped, like other best-effort tracking.
+func (s *service) recordClickWithCountry(ctx context.Context, click ClickEvent) {
+	if v, ok := visitorFrom(ctx); ok {
+		if ip, err := netip.ParseAddr(v.IP); err == nil {
+			click.Country = normalizeCountry(s.geo.Country(ctx, ip))
+		}
+	}
+	_ = s.repo.RecordClick(ctx, click)
+}
+
+// CountClicksByCountry returns the number of recorded clicks per country.
+func (s *service) CountClicksByCountry(ctx context.Context) ([]CountryCount, error) {
+	const op = "shortener.service.CountClicksByCountry"
+
+	counts, err := s.repo.CountClicksByCountry(ctx)
+	if err != nil {
+		return nil, errx.E(op, errx.KindOf(err), err)
+	}
+	return counts, nil
+}
+
+// TimeSeries returns the clicks on a link bucketed over time. The range is
+// aligned to bucket starts and may span at most MaxTimeSeriesBuckets.
+func (s *service) TimeSeries(ctx context.Context, req TimeSeriesRequest) (TimeSeries, error) {
+	const op = "shortener.service.TimeSeries"
+
+	if req.Slug == "" {
+		return TimeSeries{}, errx.E(op, errx.Invalid, errors.New("slug cannot be empty"))
+	}
+
+	bucket := req.Bucket
+	if bucket == "" {
+		bucket = BucketDay
+	}
+	if bucket != BucketDay && bucket != BucketHour {
+		return TimeSeries{}, errx.E(op, errx.Invalid, fmt.Errorf("unknown bucket %q", bucket))
+	}
+	step := bucket.Duration()
+
+	to := req.To.UTC()
+	if req.To.IsZero() {
+		to = s.clock.Now().UTC()
+	}
+	from := req.From.UTC()
+	if req.From.IsZero() {
+		from = to.Add(-30 * step)
+	}
+	if !from.Before(to) {
+		return TimeSeries{}, errx.E(op, errx.Invalid, errors.New("from must be before to"))
+	}
+	from = from.Truncate(step)
+
+	if n := (to.Sub(from) + step - 1) / step; n > MaxTimeSeriesBuckets {
+		return TimeSeries{}, errx.E(op, errx.Invalid,
+			fmt.Errorf("range spans %d buckets (maximum %d)", n, MaxTimeSeriesBuckets))
+	}
+
+	link, err := s.repo.GetBySlug(ctx, req.Slug)
+	if err != nil {
+		return TimeSeries{}, errx.E(op, errx.KindOf(err), err)
+	}
+
+	buckets, err := s.repo.ClickTimeSeries(ctx, link.ID, bucket, from, to)
+	if err != nil {
+		return TimeSeries{}, errx.E(op, errx.KindOf(err), err)
+	}
+
+	series := TimeSeries{
+		Slug:    link.Slug,
+		Bucket:  bucket,
+		From:    from,
+		To:      to,
+		Buckets: buckets,
+	}
+	for _, b := range buckets {
+		series.Total += b.Clicks
+	}
+	return series, nil
 }
 
 func (s *service) Delete(ctx context.Context, slug string) error {
@@ -166,12 +1321,166 @@ func (s *service) Delete(ctx context.Context, slug string) error {
 		return errx.E(op, errx.Invalid, errors.New("slug cannot be empty"))
 	}
 
-	if err := s.repo.Delete(ctx, slug); err != nil {
+	deleted, err := s.repo.Delete(ctx, slug)
+	if err != nil {
 		return errx.E(op, errx.KindOf(err), err)
 	}
+	s.invalidate(ctx, deleted)
+	s.recordAudit(ctx, AuditDelete, deleted)
 	return nil
 }
 
+func (s *service) SetActive(ctx context.Context, slug string, active bool) (Link, error) {
+	const op = "shortener.service.SetActive"
+
+	if slug == "" {
+		return Link{}, errx.E(op, errx.Invalid, errors.New("slug cannot be empty"))
+	}
+
+	link, err := s.repo.SetPaused(ctx, slug, !active)
+	if err != nil {
+		return Link{}, errx.E(op, errx.KindOf(err), err)
+	}
+	if active {
+		// Resolves while paused may have cached the slug as not found
+		s.forgetNotFound(ctx, link.Slug)
+	} else {
+		s.invalidate(ctx, link)
+	}
+	s.recordAudit(ctx, AuditUpdate, link)
+	return link, nil
+}
+
+// invalidate evicts link from caches under its slug and every alias, all
+// of which stop resolving with it.
+func (s *service) invalidate(ctx context.Context, link Link) {
+	s.invalidator.Invalidate(ctx, s.key(link.Slug))
+
+	aliases, err := s.repo.Aliases(ctx, link.ID)
+	if err != nil {
+		s.logger.WarnContext(ctx, "failed to list aliases to invalidate",
+			"slug", link.Slug,
+			"error", err,
+		)
+		return
+	}
+	for _, alias := range aliases {
+		s.invalidator.Invalidate(ctx, s.key(alias))
+	}
+}
+
+// recordAudit appends an audit entry for a committed mutation of link.
+func (s *service) recordAudit(ctx context.Context, op AuditOp, link Link) {
+	s.audit.Record(ctx, AuditEntry{
+		Op:        op,
+		Slug:      link.Slug,
+		Owner:     link.Owner,
+		RequestID: httpx.GetRequestID(ctx),
+		At:        s.clock.Now().UTC(),
+	})
+}
+
+// recordCreator stores the client that created link when enabled. Like
+// click recording it is best-effort and never fails the create.
+func (s *service) recordCreator(ctx context.Context, link Link, c Creator) {
+	if !s.recordCreators || c == (Creator{}) {
+		return
+	}
+	if len(c.UserAgent) > MaxCreatorUserAgentLength {
+		c.UserAgent = strings.ToValidUTF8(c.UserAgent[:MaxCreatorUserAgentLength], "")
+	}
+	_ = s.repo.SaveCreator(ctx, link.ID, c)
+}
+
+// trackVisitor records the request's visitor against link. Unique counting
+// is best-effort: a failure here must not break the redirect.
+func (s *service) trackVisitor(ctx context.Context, link Link) {
+	v, ok := visitorFrom(ctx)
+	if !ok {
+		return
+	}
+	_, _ = s.repo.TrackUniqueVisitor(ctx, link.ID, v.fingerprint(s.clock.Now()))
+}
+
+// generatedSlugLength returns the length to use for generated slugs given the
+// current link population. If the count can't be determined, the configured
+// base length is used so creates aren't blocked by the lookup.
+func (s *service) generatedSlugLength(ctx context.Context) int {
+	if s.autoSlugLength {
+		length, err := s.RecommendedSlugLength(ctx)
+		if err != nil {
+			return s.slugLength
+		}
+		return length
+	}
+	if len(s.slugLengthThresholds) == 0 {
+		return s.slugLength
+	}
+
+	count, err := s.linkCount(ctx)
+	if err != nil {
+		return s.slugLength
+	}
+	return slugLengthForCount(s.slugLength, s.slugLengthThresholds, count)
+}
+
+// linkCount returns the number of stored links, cached for countCacheTTL.
+func (s *service) linkCount(ctx context.Context) (int64, error) {
+	s.countMu.Lock()
+	defer s.countMu.Unlock()
+
+	if !s.countFetchedAt.IsZero() && s.clock.Now().Sub(s.countFetchedAt) < s.countCacheTTL {
+		return s.cachedCount, nil
+	}
+
+	count, err := s.repo.Count(ctx)
+	if err != nil {
+		return 0, err
+	}
+	s.cachedCount = count
+	s.countFetchedAt = s.clock.Now()
+	return count, nil
+}
+
+// RecommendedSlugLength returns the shortest generated slug length, no
+// shorter than the configured SlugLength, at which a new slug collides with
+// a stored one with probability below the collision target. The link count
+// behind it is cached for SlugLengthCacheTTL. The length is capped at the
+// generated maximum even if the target can't be met there.
+func (s *service) RecommendedSlugLength(ctx context.Context) (int, error) {
+	const op = "shortener.service.RecommendedSlugLength"
+
+	count, err := s.linkCount(ctx)
+	if err != nil {
+		return 0, errx.E(op, errx.KindOf(err), err)
+	}
+	return recommendedSlugLength(s.slugAlphabet, s.slugLength, s.maxGeneratedSlugLength, s.collisionTarget, count), nil
+}
+
+// recommendedSlugLength returns the shortest length in [minLength,
+// maxLength) whose keyspace utilization by count links is below target,
+// or maxLength if none is.
+func recommendedSlugLength(alphabet, minLength, maxLength int, target float64, count int64) int {
+	for length := minLength; length < maxLength; length++ {
+		if EstimateKeyspace(alphabet, length, count).Utilization < target {
+			return length
+		}
+	}
+	return maxLength
+}
+
+// slugLengthForCount picks the longest threshold length reached by count,
+// never going below base.
+func slugLengthForCount(base int, thresholds []SlugLengthThreshold, count int64) int {
+	length := base
+	for _, t := range thresholds {
+		if count >= t.MinLinks && t.Length > length {
+			length = t.Length
+		}
+	}
+	return length
+}
+
 func validateURL(rawURL string) error {
 	if rawURL == "" {
 		return errors.New("url cannot be empty")
@@ -179,6 +1488,9 @@ func validateURL(rawURL string) error {
 	if len(rawURL) > MaxURLLength {
 		return errors.New("url too long (max 2048 characters)")
 	}
+	if !utf8.ValidString(rawURL) {
+		return errors.New("url is not valid UTF-8")
+	}
 
 	parsedURL, err := url.Parse(rawURL)
 	if err != nil {
@@ -193,33 +1505,112 @@ func validateURL(rawURL string) error {
 	if parsedURL.Host == "" {
 		return errors.New("url must include host")
 	}
+	if parsedURL.User != nil {
+		return errors.New("url must not include credentials")
+	}
+	return validateHost(parsedURL)
+}
+
+// validateHost checks the authority url.Parse accepted: a non-empty host
+// name free of whitespace and control characters, IPv6 literals only in
+// brackets, and a port in range when one is given.
+func validateHost(u *url.URL) error {
+	host := u.Hostname()
+	if host == "" {
+		return errors.New("url must include host")
+	}
+	if strings.IndexFunc(host, func(r rune) bool {
+		return unicode.IsSpace(r) || unicode.IsControl(r)
+	}) >= 0 {
+		return errors.New("url host contains invalid characters")
+	}
+
+	if strings.HasPrefix(u.Host, "[") {
+		addr, err := netip.ParseAddr(host)
+		if err != nil || !addr.Is6() || addr.Zone() != "" {
+			return errors.New("url host is not a valid IPv6 address")
+		}
+	} else if strings.Contains(host, ":") {
+		return errors.New("IPv6 url hosts must be enclosed in brackets")
+	}
+
+	if port := u.Port(); port != "" {
+		n, err := strconv.Atoi(port)
+		if err != nil || n < 1 || n > 65535 {
+			return errors.New("url port must be between 1 and 65535")
+		}
+	}
 	return nil
 }
 
-func validateSlug(slug string) error {
+// normalizeURL lowercases the scheme and host of an already validated URL,
+// which are case-insensitive, leaving the path and query untouched. IPv6
+// literals keep their brackets, so "https://[2001:DB8::1]:8080" becomes
+// "https://[2001:db8::1]:8080". The fragment is dropped unless keepFragment
+// is set.
+func normalizeURL(rawURL string, keepFragment bool) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = strings.ToLower(u.Host)
+	if !keepFragment {
+		u.Fragment, u.RawFragment = "", ""
+	}
+	return u.String()
+}
+
+func validateSlug(slug string, rules SlugRules) error {
+	rules = rules.withDefaults()
+
 	if slug == "" {
 		return errors.New("slug cannot be empty")
 	}
-	if len(slug) < MinSlugLength {
-		return errors.New("slug too short (minimum 3 characters)")
+	if len(slug) < rules.MinLength {
+		return fmt.Errorf("slug too short (minimum %d characters)", rules.MinLength)
 	}
-	if len(slug) > MaxSlugLength {
-		return errors.New("slug too long (maximum 64 characters)")
+	if len(slug) > rules.MaxLength {
+		return fmt.Errorf("slug too long (maximum %d characters)", rules.MaxLength)
+	}
+	if !utf8.ValidString(slug) {
+		return errors.New("slug is not valid UTF-8")
 	}
 
 	if strings.HasPrefix(slug, "-") || strings.HasPrefix(slug, "_") ||
 		strings.HasSuffix(slug, "-") || strings.HasSuffix(slug, "_") {
 		return errors.New("slug cannot start or end with dash or underscore")
 	}
+	if rules.NoLeadingDigit && startsWithDigit(slug) {
+		return errors.New("slug cannot start with a digit")
+	}
+	// Dots at either end would allow "." and ".." path segments.
+	if strings.HasPrefix(slug, ".") || strings.HasSuffix(slug, ".") {
+		return errors.New("slug cannot start or end with a dot")
+	}
 
 	for _, char := range slug {
-		if !isValidSlugChar(char) {
-			return errors.New("slug contains invalid characters (only alphanumeric, dash, and underscore allowed)")
+		if !rules.Charset.allows(char) {
+			return fmt.Errorf("slug contains invalid characters (only %s allowed)", rules.Charset.describe())
 		}
 	}
 	return nil
 }
 
+// validSlugPrefix reports whether prefix can namespace slugs. Prefixes are
+// alphanumeric so the dash separator is unambiguous.
+func validSlugPrefix(prefix string) bool {
+	if prefix == "" || len(prefix) > MaxSlugPrefixLength {
+		return false
+	}
+	for _, c := range prefix {
+		if !isValidSlugChar(c) || c == '-' || c == '_' {
+			return false
+		}
+	}
+	return true
+}
+
 func isValidSlugChar(c rune) bool {
 	switch {
 	case c >= 'a' && c <= 'z':
@@ -0,0 +1,113 @@
+package shortener
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sundayezeilo/urlshortener/internal/errx"
+)
+
+// DefaultSimulationWorkers is how many creates SimulateCreates runs at once.
+const DefaultSimulationWorkers = 16
+
+// SimulationResult summarizes a SimulateCreates run.
+type SimulationResult struct {
+	Requested int
+	Created   int // Successful creates, Duplicates included
+
+	// Duplicates counts created links whose slug an earlier create in the
+	// run already returned. Anything but zero means the repository let two
+	// links share a slug.
+	Duplicates int
+
+	// Exhausted counts creates that failed with ErrSlugRetriesExhausted:
+	// every retry collided, so the slug length is too short for the
+	// keyspace in use. Collisions the service absorbed by retrying are not
+	// visible here.
+	Exhausted int
+
+	Errors       int // All failed creates, Exhausted included
+	ErrorsByKind map[errx.Kind]int
+
+	Elapsed time.Duration
+}
+
+// CollisionRate is the fraction of requested creates that ran out of slug
+// retries.
+func (r SimulationResult) CollisionRate() float64 {
+	if r.Requested == 0 {
+		return 0
+	}
+	return float64(r.Exhausted) / float64(r.Requested)
+}
+
+// ErrorRate is the fraction of requested creates that failed.
+func (r SimulationResult) ErrorRate() float64 {
+	if r.Requested == 0 {
+		return 0
+	}
+	return float64(r.Errors) / float64(r.Requested)
+}
+
+// SimulateCreates creates n links with generated slugs through svc, with
+// DefaultSimulationWorkers creates in flight at a time, and reports how
+// many collided or failed. It is meant for tests and benchmarks validating
+// a slug length choice under concurrency, not for production traffic.
+// Creates still pending when ctx is done are not attempted.
+func SimulateCreates(ctx context.Context, svc Service, n int) SimulationResult {
+	res := SimulationResult{Requested: n, ErrorsByKind: make(map[errx.Kind]int)}
+	start := time.Now()
+
+	var (
+		mu    sync.Mutex
+		slugs = make(map[string]bool, n)
+		wg    sync.WaitGroup
+		jobs  = make(chan int)
+	)
+
+	for range min(n, DefaultSimulationWorkers) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				link, err := svc.Create(ctx, CreateLinkRequest{
+					OriginalURL: fmt.Sprintf("https://example.com/simulate/%d", i),
+				})
+
+				mu.Lock()
+				switch {
+				case err != nil:
+					res.Errors++
+					res.ErrorsByKind[errx.KindOf(err)]++
+					if errors.Is(err, ErrSlugRetriesExhausted) {
+						res.Exhausted++
+					}
+				case slugs[link.Slug]:
+					res.Created++
+					res.Duplicates++
+				default:
+					res.Created++
+					slugs[link.Slug] = true
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+feed:
+	for i := range n {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	res.Elapsed = time.Since(start)
+	return res
+}
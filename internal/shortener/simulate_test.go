@@ -0,0 +1,76 @@
+package shortener
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/sundayezeilo/urlshortener/internal/errx"
+)
+
+// uniqueSlugRepository returns a mockRepository that, like the links_slug_unique
+// constraint, rejects a second link with the same slug.
+func uniqueSlugRepository() *mockRepository {
+	var (
+		mu    sync.Mutex
+		taken = make(map[string]bool)
+	)
+	return &mockRepository{
+		createFunc: func(_ context.Context, link Link) (Link, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			if taken[link.Slug] {
+				return Link{}, errx.E("repo.Create", errx.Conflict, errors.New("slug taken"))
+			}
+			taken[link.Slug] = true
+			return link, nil
+		},
+	}
+}
+
+// constSlugGenerator always generates the same slug. Unlike
+// mockSlugGenerator it is safe for concurrent use.
+type constSlugGenerator string
+
+func (g constSlugGenerator) Generate(int) (string, error) { return string(g), nil }
+
+func TestSimulateCreates(t *testing.T) {
+	t.Run("no duplicate slugs", func(t *testing.T) {
+		svc := NewService(uniqueSlugRepository(), &ServiceConfig{})
+
+		res := SimulateCreates(context.Background(), svc, 500)
+
+		if res.Created != 500 || res.Duplicates != 0 || res.Errors != 0 {
+			t.Errorf("result = %+v, want 500 created without duplicates or errors", res)
+		}
+		if res.CollisionRate() != 0 || res.ErrorRate() != 0 {
+			t.Errorf("CollisionRate() = %v, ErrorRate() = %v, want 0", res.CollisionRate(), res.ErrorRate())
+		}
+	})
+
+	t.Run("reports exhausted retries", func(t *testing.T) {
+		svc := NewService(uniqueSlugRepository(), &ServiceConfig{
+			SlugGenerator: constSlugGenerator("same123"),
+		})
+
+		res := SimulateCreates(context.Background(), svc, 10)
+
+		if res.Created != 1 || res.Exhausted != 9 || res.ErrorsByKind[errx.Unavailable] != 9 {
+			t.Errorf("result = %+v, want 1 created and 9 exhausted", res)
+		}
+		if got := res.CollisionRate(); got != 0.9 {
+			t.Errorf("CollisionRate() = %v, want 0.9", got)
+		}
+	})
+}
+
+func BenchmarkSimulateCreates(b *testing.B) {
+	for b.Loop() {
+		svc := NewService(uniqueSlugRepository(), &ServiceConfig{})
+		res := SimulateCreates(context.Background(), svc, 1000)
+		if res.Duplicates != 0 {
+			b.Fatalf("duplicates = %d, want 0", res.Duplicates)
+		}
+	}
+}
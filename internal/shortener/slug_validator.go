@@ -0,0 +1,105 @@
+package shortener
+
+import "fmt"
+
+// SlugValidator decides whether a custom slug is acceptable.
+// Implementations should be safe for concurrent use.
+type SlugValidator interface {
+	Validate(slug string) error
+}
+
+// SlugValidatorFunc adapts an ordinary function to a SlugValidator.
+type SlugValidatorFunc func(slug string) error
+
+// Validate calls f(slug).
+func (f SlugValidatorFunc) Validate(slug string) error {
+	return f(slug)
+}
+
+// SlugCharset selects which characters custom slugs may contain.
+type SlugCharset int
+
+const (
+	// AlphanumDashUnderscore allows ASCII letters, digits, '-' and '_'.
+	AlphanumDashUnderscore SlugCharset = iota
+	// AlphanumDashUnderscoreDot additionally allows '.', e.g. "my.link".
+	AlphanumDashUnderscoreDot
+)
+
+// ParseSlugCharset maps a configuration name to a SlugCharset.
+func ParseSlugCharset(name string) (SlugCharset, error) {
+	switch name {
+	case "", "alphanum_dash_underscore":
+		return AlphanumDashUnderscore, nil
+	case "alphanum_dash_underscore_dot":
+		return AlphanumDashUnderscoreDot, nil
+	default:
+		return 0, fmt.Errorf("unknown slug charset %q", name)
+	}
+}
+
+// allows reports whether c may appear in a slug.
+func (cs SlugCharset) allows(c rune) bool {
+	if c == '.' {
+		return cs == AlphanumDashUnderscoreDot
+	}
+	return isValidSlugChar(c)
+}
+
+// describe lists the allowed characters for error messages.
+func (cs SlugCharset) describe() string {
+	if cs == AlphanumDashUnderscoreDot {
+		return "alphanumeric, dash, underscore, and dot"
+	}
+	return "alphanumeric, dash, and underscore"
+}
+
+// SlugRules configures the built-in slug validator.
+// Zero values fall back to the package defaults.
+type SlugRules struct {
+	MinLength int         // default: MinSlugLength
+	MaxLength int         // default: MaxSlugLength, at most MaxCustomSlugLength
+	Charset   SlugCharset // default: AlphanumDashUnderscore
+
+	// NoLeadingDigit rejects slugs starting with a digit, so they can't be
+	// mistaken for numeric IDs.
+	NoLeadingDigit bool
+}
+
+// withDefaults fills unset or out-of-range rules with package defaults.
+func (r SlugRules) withDefaults() SlugRules {
+	if r.MinLength <= 0 {
+		r.MinLength = MinSlugLength
+	}
+	if r.MaxLength <= 0 {
+		r.MaxLength = MaxSlugLength
+	}
+	if r.MaxLength > MaxCustomSlugLength {
+		r.MaxLength = MaxCustomSlugLength
+	}
+	if r.MinLength > r.MaxLength {
+		r.MinLength = r.MaxLength
+	}
+	return r
+}
+
+// ruleSlugValidator enforces the built-in slug rules.
+type ruleSlugValidator struct {
+	rules SlugRules
+}
+
+// NewSlugValidator returns a validator enforcing length bounds and the
+// character set from rules, with no leading or trailing punctuation.
+func NewSlugValidator(rules SlugRules) SlugValidator {
+	return ruleSlugValidator{rules: rules.withDefaults()}
+}
+
+// NewDefaultSlugValidator returns the validator used when none is configured.
+// Custom validators can wrap it to add rules.
+func NewDefaultSlugValidator() SlugValidator {
+	return NewSlugValidator(SlugRules{})
+}
+
+func (v ruleSlugValidator) Validate(slug string) error {
+	return validateSlug(slug, v.rules)
+}
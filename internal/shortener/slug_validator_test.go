@@ -0,0 +1,334 @@
+package shortener
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"unicode"
+
+	"github.com/sundayezeilo/urlshortener/internal/errx"
+	"github.com/sundayezeilo/urlshortener/sluggen"
+)
+
+func TestDefaultSlugValidator_MatchesBuiltInRules(t *testing.T) {
+	v := NewDefaultSlugValidator()
+
+	for _, slug := range []string{"abc1234", "abc-123", "Abc-123_XYZ"} {
+		if err := v.Validate(slug); err != nil {
+			t.Errorf("Validate(%q) unexpected error: %v", slug, err)
+		}
+	}
+	for _, slug := range []string{"", "abc", "abc123", "-abcdef", "abcdef_", "abc def", "abc.def"} {
+		if err := v.Validate(slug); err == nil {
+			t.Errorf("Validate(%q) expected error, got nil", slug)
+		}
+	}
+}
+
+func TestServiceCreate_CustomSlugValidator(t *testing.T) {
+	// Require slugs to start with a letter on top of the default rules.
+	startsWithLetter := SlugValidatorFunc(func(slug string) error {
+		if err := NewDefaultSlugValidator().Validate(slug); err != nil {
+			return err
+		}
+		if !unicode.IsLetter(rune(slug[0])) {
+			return errors.New("slug must start with a letter")
+		}
+		return nil
+	})
+
+	svc := NewService(&mockRepository{}, &ServiceConfig{SlugValidator: startsWithLetter})
+
+	t.Run("accepts slug satisfying custom rule", func(t *testing.T) {
+		link, err := svc.Create(context.Background(), CreateLinkRequest{
+			OriginalURL: "https://example.com",
+			CustomSlug:  "promo-2025",
+		})
+		if err != nil {
+			t.Fatalf("Create() unexpected error: %v", err)
+		}
+		if link.Slug != "promo-2025" {
+			t.Errorf("Slug = %q, want %q", link.Slug, "promo-2025")
+		}
+	})
+
+	t.Run("rejects slug violating custom rule", func(t *testing.T) {
+		_, err := svc.Create(context.Background(), CreateLinkRequest{
+			OriginalURL: "https://example.com",
+			CustomSlug:  "2025-promo",
+		})
+		if errx.KindOf(err) != errx.Invalid {
+			t.Errorf("error kind = %v, want %v", errx.KindOf(err), errx.Invalid)
+		}
+	})
+
+	t.Run("does not apply to generated slugs", func(t *testing.T) {
+		svc := NewService(&mockRepository{}, &ServiceConfig{
+			SlugGenerator: &mockSlugGenerator{slugs: []string{"9abcdef"}},
+			SlugValidator: startsWithLetter,
+		})
+		if _, err := svc.Create(context.Background(), CreateLinkRequest{OriginalURL: "https://example.com"}); err != nil {
+			t.Errorf("Create() unexpected error: %v", err)
+		}
+	})
+}
+
+func TestNewSlugValidator_MinLength(t *testing.T) {
+	tests := []struct {
+		name    string
+		rules   SlugRules
+		slug    string
+		wantErr bool
+	}{
+		{"default rejects 3 chars", SlugRules{}, "abc", true},
+		{"default accepts 7 chars", SlugRules{}, "abcdefg", false},
+		{"raised minimum rejects 7 chars", SlugRules{MinLength: 10}, "abcdefg", true},
+		{"raised minimum accepts 10 chars", SlugRules{MinLength: 10}, "abcdefghij", false},
+		{"max length still enforced", SlugRules{MinLength: 10}, strings.Repeat("a", MaxSlugLength+1), true},
+		{"raised maximum accepts longer slugs", SlugRules{MaxLength: 100}, strings.Repeat("a", 100), false},
+		{"maximum capped at schema bound", SlugRules{MaxLength: 500}, strings.Repeat("a", MaxCustomSlugLength+1), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewSlugValidator(tt.rules).Validate(tt.slug)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate(%q) error = %v, wantErr %v", tt.slug, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestServiceCreate_MinSlugLength(t *testing.T) {
+	repo := &mockRepository{
+		createFunc: func(ctx context.Context, link Link) (Link, error) {
+			t.Fatal("repository should not be called for a short custom slug")
+			return Link{}, nil
+		},
+	}
+
+	t.Run("rejects slug shorter than schema minimum", func(t *testing.T) {
+		svc := NewService(repo, nil)
+		_, err := svc.Create(context.Background(), CreateLinkRequest{
+			OriginalURL: "https://example.com",
+			CustomSlug:  "abc",
+		})
+		if errx.KindOf(err) != errx.Invalid {
+			t.Errorf("error kind = %v, want %v", errx.KindOf(err), errx.Invalid)
+		}
+	})
+
+	t.Run("honours configured minimum", func(t *testing.T) {
+		svc := NewService(repo, &ServiceConfig{MinSlugLength: 12})
+		_, err := svc.Create(context.Background(), CreateLinkRequest{
+			OriginalURL: "https://example.com",
+			CustomSlug:  "promo-2025",
+		})
+		if errx.KindOf(err) != errx.Invalid {
+			t.Errorf("error kind = %v, want %v", errx.KindOf(err), errx.Invalid)
+		}
+	})
+}
+
+func TestServiceCreate_MaxCustomSlugLength(t *testing.T) {
+	long := strings.Repeat("a", MaxSlugLength+16)
+
+	t.Run("defaults to the generated maximum", func(t *testing.T) {
+		svc := NewService(&mockRepository{}, nil)
+		_, err := svc.Create(context.Background(), CreateLinkRequest{
+			OriginalURL: "https://example.com",
+			CustomSlug:  long,
+		})
+		if errx.KindOf(err) != errx.Invalid {
+			t.Errorf("error kind = %v, want %v", errx.KindOf(err), errx.Invalid)
+		}
+	})
+
+	t.Run("accepts longer custom slugs when configured", func(t *testing.T) {
+		svc := NewService(&mockRepository{}, &ServiceConfig{MaxCustomSlugLength: MaxCustomSlugLength})
+		link, err := svc.Create(context.Background(), CreateLinkRequest{
+			OriginalURL: "https://example.com",
+			CustomSlug:  long,
+		})
+		if err != nil {
+			t.Fatalf("Create() unexpected error: %v", err)
+		}
+		if link.Slug != long {
+			t.Errorf("Slug = %q, want %q", link.Slug, long)
+		}
+	})
+
+	t.Run("generated slugs keep their own maximum", func(t *testing.T) {
+		var gotLength int
+		gen := &mockSlugGenerator{generateFunc: func(length int) (string, error) {
+			gotLength = length
+			return strings.Repeat("g", length), nil
+		}}
+		svc := NewService(&mockRepository{}, &ServiceConfig{
+			SlugGenerator:          gen,
+			MaxCustomSlugLength:    MaxCustomSlugLength,
+			MaxGeneratedSlugLength: 10,
+			SlugLengthThresholds:   []SlugLengthThreshold{{MinLinks: 0, Length: MaxSlugLength + 1}},
+		})
+		if _, err := svc.Create(context.Background(), CreateLinkRequest{OriginalURL: "https://example.com"}); err != nil {
+			t.Fatalf("Create() unexpected error: %v", err)
+		}
+		if gotLength != DefaultSlugLength {
+			t.Errorf("generated length = %d, want %d", gotLength, DefaultSlugLength)
+		}
+	})
+}
+
+func TestNewSlugValidator_Charset(t *testing.T) {
+	tests := []struct {
+		name    string
+		charset SlugCharset
+		slug    string
+		wantErr bool
+	}{
+		{"default accepts dash and underscore", AlphanumDashUnderscore, "my-link_01", false},
+		{"default rejects dot", AlphanumDashUnderscore, "my.link1", true},
+		{"default rejects unicode", AlphanumDashUnderscore, "café-link", true},
+		{"dot charset accepts dot", AlphanumDashUnderscoreDot, "my.link1", false},
+		{"dot charset accepts dash and underscore", AlphanumDashUnderscoreDot, "my.link-01_x", false},
+		{"dot charset rejects leading dot", AlphanumDashUnderscoreDot, ".mylink1", true},
+		{"dot charset rejects trailing dot", AlphanumDashUnderscoreDot, "mylink1.", true},
+		{"dot charset rejects other punctuation", AlphanumDashUnderscoreDot, "my.link~1", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewSlugValidator(SlugRules{Charset: tt.charset}).Validate(tt.slug)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate(%q) error = %v, wantErr %v", tt.slug, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewSlugValidator_NoLeadingDigit(t *testing.T) {
+	tests := []struct {
+		slug    string
+		strict  bool
+		wantErr bool
+	}{
+		{"2024-sale", false, false},
+		{"2024-sale", true, true},
+		{"9abcdef", true, true},
+		{"sale-2024", true, false},
+		{"a1234567", true, false},
+	}
+
+	for _, tt := range tests {
+		err := NewSlugValidator(SlugRules{NoLeadingDigit: tt.strict}).Validate(tt.slug)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Validate(%q) with NoLeadingDigit=%v error = %v, wantErr %v", tt.slug, tt.strict, err, tt.wantErr)
+		}
+	}
+}
+
+func TestServiceCreate_SlugNoLeadingDigit(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("rejects custom slugs with a leading digit", func(t *testing.T) {
+		svc := NewService(&mockRepository{}, &ServiceConfig{SlugNoLeadingDigit: true})
+
+		_, err := svc.Create(ctx, CreateLinkRequest{OriginalURL: "https://example.com", CustomSlug: "2024-sale"})
+		if errx.KindOf(err) != errx.Invalid {
+			t.Errorf("error kind = %v, want %v (err: %v)", errx.KindOf(err), errx.Invalid, err)
+		}
+	})
+
+	t.Run("regenerates slugs with a leading digit", func(t *testing.T) {
+		gen := &mockSlugGenerator{slugs: []string{"1abcdef", "9zzzzzz", "abc1234"}}
+		svc := NewService(&mockRepository{}, &ServiceConfig{SlugGenerator: gen, SlugNoLeadingDigit: true})
+
+		link, err := svc.Create(ctx, CreateLinkRequest{OriginalURL: "https://example.com"})
+		if err != nil {
+			t.Fatalf("Create() unexpected error: %v", err)
+		}
+		if link.Slug != "abc1234" || gen.callCount != 3 {
+			t.Errorf("slug = %q after %d generations, want abc1234 after 3", link.Slug, gen.callCount)
+		}
+	})
+
+	t.Run("generated slugs never lead with a digit", func(t *testing.T) {
+		svc := NewService(&mockRepository{}, &ServiceConfig{SlugGenerator: sluggen.NewBase62(), SlugNoLeadingDigit: true})
+
+		for range 200 {
+			link, err := svc.Create(ctx, CreateLinkRequest{OriginalURL: "https://example.com"})
+			if err != nil {
+				t.Fatalf("Create() unexpected error: %v", err)
+			}
+			if startsWithDigit(link.Slug) {
+				t.Fatalf("generated slug %q starts with a digit", link.Slug)
+			}
+		}
+	})
+
+	t.Run("gives up on a generator producing only digits", func(t *testing.T) {
+		gen := &mockSlugGenerator{generateFunc: func(length int) (string, error) {
+			return strings.Repeat("7", length), nil
+		}}
+		svc := NewService(&mockRepository{}, &ServiceConfig{SlugGenerator: gen, SlugNoLeadingDigit: true})
+
+		_, err := svc.Create(ctx, CreateLinkRequest{OriginalURL: "https://example.com"})
+		if errx.KindOf(err) != errx.Unavailable {
+			t.Errorf("error kind = %v, want %v (err: %v)", errx.KindOf(err), errx.Unavailable, err)
+		}
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		gen := &mockSlugGenerator{slugs: []string{"1abcdef"}}
+		svc := NewService(&mockRepository{}, &ServiceConfig{SlugGenerator: gen})
+
+		link, err := svc.Create(ctx, CreateLinkRequest{OriginalURL: "https://example.com"})
+		if err != nil || link.Slug != "1abcdef" {
+			t.Errorf("Create() = %q, %v; want 1abcdef", link.Slug, err)
+		}
+		if _, err := svc.Create(ctx, CreateLinkRequest{OriginalURL: "https://example.com", CustomSlug: "2024-sale"}); err != nil {
+			t.Errorf("Create(2024-sale) unexpected error: %v", err)
+		}
+	})
+}
+
+func TestParseSlugCharset(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    SlugCharset
+		wantErr bool
+	}{
+		{"", AlphanumDashUnderscore, false},
+		{"alphanum_dash_underscore", AlphanumDashUnderscore, false},
+		{"alphanum_dash_underscore_dot", AlphanumDashUnderscoreDot, false},
+		{"unicode", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseSlugCharset(tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseSlugCharset(%q) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseSlugCharset(%q) = %v, want %v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestServiceCreate_SlugCharset(t *testing.T) {
+	svc := NewService(&mockRepository{}, &ServiceConfig{SlugCharset: AlphanumDashUnderscoreDot})
+
+	link, err := svc.Create(context.Background(), CreateLinkRequest{
+		OriginalURL: "https://example.com",
+		CustomSlug:  "my.link",
+	})
+	if err != nil {
+		t.Fatalf("Create() unexpected error: %v", err)
+	}
+	if link.Slug != "my.link" {
+		t.Errorf("Slug = %q, want %q", link.Slug, "my.link")
+	}
+}
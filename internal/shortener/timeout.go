@@ -0,0 +1,133 @@
+package shortener
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	db "github.com/sundayezeilo/urlshortener/internal/db/sqlc"
+)
+
+// timeoutQuerier bounds every query of the wrapped querier by timeout. The
+// generated queries scan their rows before returning, so cancelling the
+// context once the call returns never cuts a finished query short.
+type timeoutQuerier struct {
+	q       querier
+	timeout time.Duration
+}
+
+var _ querier = (*timeoutQuerier)(nil)
+
+// timeoutCall runs fn with a context that expires after timeout.
+func timeoutCall[T any](ctx context.Context, timeout time.Duration, fn func(ctx context.Context) (T, error)) (T, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	return fn(ctx)
+}
+
+func (tq *timeoutQuerier) CreateLink(ctx context.Context, arg db.CreateLinkParams) (db.Link, error) {
+	return timeoutCall(ctx, tq.timeout, func(ctx context.Context) (db.Link, error) { return tq.q.CreateLink(ctx, arg) })
+}
+
+func (tq *timeoutQuerier) CreateOrUpdateLink(ctx context.Context, arg db.CreateOrUpdateLinkParams) (db.CreateOrUpdateLinkRow, error) {
+	return timeoutCall(ctx, tq.timeout, func(ctx context.Context) (db.CreateOrUpdateLinkRow, error) { return tq.q.CreateOrUpdateLink(ctx, arg) })
+}
+
+func (tq *timeoutQuerier) GetLinkBySLug(ctx context.Context, slug string) (db.Link, error) {
+	return timeoutCall(ctx, tq.timeout, func(ctx context.Context) (db.Link, error) { return tq.q.GetLinkBySLug(ctx, slug) })
+}
+
+func (tq *timeoutQuerier) GetLinkByID(ctx context.Context, id uuid.UUID) (db.Link, error) {
+	return timeoutCall(ctx, tq.timeout, func(ctx context.Context) (db.Link, error) { return tq.q.GetLinkByID(ctx, id) })
+}
+
+func (tq *timeoutQuerier) ResolveAndTrackLink(ctx context.Context, arg db.ResolveAndTrackLinkParams) (db.Link, error) {
+	return timeoutCall(ctx, tq.timeout, func(ctx context.Context) (db.Link, error) { return tq.q.ResolveAndTrackLink(ctx, arg) })
+}
+
+func (tq *timeoutQuerier) DeleteLink(ctx context.Context, slug string) (db.Link, error) {
+	return timeoutCall(ctx, tq.timeout, func(ctx context.Context) (db.Link, error) { return tq.q.DeleteLink(ctx, slug) })
+}
+
+func (tq *timeoutQuerier) SetLinkPaused(ctx context.Context, arg db.SetLinkPausedParams) (db.Link, error) {
+	return timeoutCall(ctx, tq.timeout, func(ctx context.Context) (db.Link, error) { return tq.q.SetLinkPaused(ctx, arg) })
+}
+
+func (tq *timeoutQuerier) CountLinks(ctx context.Context) (int64, error) {
+	return timeoutCall(ctx, tq.timeout, func(ctx context.Context) (int64, error) { return tq.q.CountLinks(ctx) })
+}
+
+func (tq *timeoutQuerier) CountLinksByOwner(ctx context.Context, owner pgtype.Text) (int64, error) {
+	return timeoutCall(ctx, tq.timeout, func(ctx context.Context) (int64, error) { return tq.q.CountLinksByOwner(ctx, owner) })
+}
+
+func (tq *timeoutQuerier) CountLinksBySource(ctx context.Context) ([]db.CountLinksBySourceRow, error) {
+	return timeoutCall(ctx, tq.timeout, func(ctx context.Context) ([]db.CountLinksBySourceRow, error) { return tq.q.CountLinksBySource(ctx) })
+}
+
+func (tq *timeoutQuerier) CountLinksFiltered(ctx context.Context, arg db.CountLinksFilteredParams) (int64, error) {
+	return timeoutCall(ctx, tq.timeout, func(ctx context.Context) (int64, error) { return tq.q.CountLinksFiltered(ctx, arg) })
+}
+
+func (tq *timeoutQuerier) ListLinks(ctx context.Context, arg db.ListLinksParams) ([]db.Link, error) {
+	return timeoutCall(ctx, tq.timeout, func(ctx context.Context) ([]db.Link, error) { return tq.q.ListLinks(ctx, arg) })
+}
+
+func (tq *timeoutQuerier) GetLinksByURL(ctx context.Context, originalUrl string) ([]db.Link, error) {
+	return timeoutCall(ctx, tq.timeout, func(ctx context.Context) ([]db.Link, error) { return tq.q.GetLinksByURL(ctx, originalUrl) })
+}
+
+func (tq *timeoutQuerier) GetTakenSlugs(ctx context.Context, slugKeys []string) ([]string, error) {
+	return timeoutCall(ctx, tq.timeout, func(ctx context.Context) ([]string, error) { return tq.q.GetTakenSlugs(ctx, slugKeys) })
+}
+
+func (tq *timeoutQuerier) PurgeExpiredLinks(ctx context.Context, arg db.PurgeExpiredLinksParams) (int64, error) {
+	return timeoutCall(ctx, tq.timeout, func(ctx context.Context) (int64, error) { return tq.q.PurgeExpiredLinks(ctx, arg) })
+}
+
+func (tq *timeoutQuerier) PurgeDeletedLinks(ctx context.Context, arg db.PurgeDeletedLinksParams) (int64, error) {
+	return timeoutCall(ctx, tq.timeout, func(ctx context.Context) (int64, error) { return tq.q.PurgeDeletedLinks(ctx, arg) })
+}
+
+func (tq *timeoutQuerier) CountClicksByCountry(ctx context.Context) ([]db.CountClicksByCountryRow, error) {
+	return timeoutCall(ctx, tq.timeout, func(ctx context.Context) ([]db.CountClicksByCountryRow, error) { return tq.q.CountClicksByCountry(ctx) })
+}
+
+func (tq *timeoutQuerier) RecordClick(ctx context.Context, arg db.RecordClickParams) error {
+	_, err := timeoutCall(ctx, tq.timeout, func(ctx context.Context) (struct{}, error) { return struct{}{}, tq.q.RecordClick(ctx, arg) })
+	return err
+}
+
+func (tq *timeoutQuerier) InsertLinkCreator(ctx context.Context, arg db.InsertLinkCreatorParams) error {
+	_, err := timeoutCall(ctx, tq.timeout, func(ctx context.Context) (struct{}, error) { return struct{}{}, tq.q.InsertLinkCreator(ctx, arg) })
+	return err
+}
+
+func (tq *timeoutQuerier) GetLinkCreator(ctx context.Context, linkID uuid.UUID) (db.LinkCreator, error) {
+	return timeoutCall(ctx, tq.timeout, func(ctx context.Context) (db.LinkCreator, error) { return tq.q.GetLinkCreator(ctx, linkID) })
+}
+
+func (tq *timeoutQuerier) InsertLinkAlias(ctx context.Context, arg db.InsertLinkAliasParams) error {
+	_, err := timeoutCall(ctx, tq.timeout, func(ctx context.Context) (struct{}, error) { return struct{}{}, tq.q.InsertLinkAlias(ctx, arg) })
+	return err
+}
+
+func (tq *timeoutQuerier) ListLinkAliases(ctx context.Context, linkID uuid.UUID) ([]string, error) {
+	return timeoutCall(ctx, tq.timeout, func(ctx context.Context) ([]string, error) { return tq.q.ListLinkAliases(ctx, linkID) })
+}
+
+func (tq *timeoutQuerier) GetSlugByAlias(ctx context.Context, aliasKey string) (string, error) {
+	return timeoutCall(ctx, tq.timeout, func(ctx context.Context) (string, error) { return tq.q.GetSlugByAlias(ctx, aliasKey) })
+}
+
+func (tq *timeoutQuerier) GetClickTimeSeries(ctx context.Context, arg db.GetClickTimeSeriesParams) ([]db.GetClickTimeSeriesRow, error) {
+	return timeoutCall(ctx, tq.timeout, func(ctx context.Context) ([]db.GetClickTimeSeriesRow, error) {
+		return tq.q.GetClickTimeSeries(ctx, arg)
+	})
+}
+
+func (tq *timeoutQuerier) TrackUniqueVisitor(ctx context.Context, arg db.TrackUniqueVisitorParams) (int64, error) {
+	return timeoutCall(ctx, tq.timeout, func(ctx context.Context) (int64, error) { return tq.q.TrackUniqueVisitor(ctx, arg) })
+}
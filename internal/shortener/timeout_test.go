@@ -0,0 +1,91 @@
+package shortener
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sundayezeilo/urlshortener/internal/errx"
+)
+
+func TestQueryTimeout(t *testing.T) {
+	t.Run("query blocking past the timeout fails with Timeout", func(t *testing.T) {
+		mock := &mockQueries{
+			countLinksFunc: func(ctx context.Context) (int64, error) {
+				<-ctx.Done()
+				return 0, ctx.Err()
+			},
+		}
+		r := NewRepository(mock, &RepositoryConfig{QueryTimeout: 10 * time.Millisecond})
+
+		_, err := r.Count(context.Background())
+		if errx.KindOf(err) != errx.Timeout {
+			t.Fatalf("KindOf(err)=%v want %v (err=%v)", errx.KindOf(err), errx.Timeout, err)
+		}
+		if errx.OpOf(err) != "shortener.repo.Count" {
+			t.Errorf("OpOf(err)=%q want %q", errx.OpOf(err), "shortener.repo.Count")
+		}
+	})
+
+	t.Run("fast query is not cancelled", func(t *testing.T) {
+		var deadline time.Time
+		mock := &mockQueries{
+			countLinksFunc: func(ctx context.Context) (int64, error) {
+				deadline, _ = ctx.Deadline()
+				return 42, ctx.Err()
+			},
+		}
+		r := NewRepository(mock, &RepositoryConfig{QueryTimeout: time.Minute})
+
+		n, err := r.Count(context.Background())
+		if err != nil {
+			t.Fatalf("Count() unexpected error: %v", err)
+		}
+		if n != 42 {
+			t.Errorf("Count()=%d want 42", n)
+		}
+		if deadline.IsZero() {
+			t.Error("query context has no deadline")
+		}
+	})
+
+	t.Run("no deadline when disabled", func(t *testing.T) {
+		hasDeadline := true
+		mock := &mockQueries{
+			countLinksFunc: func(ctx context.Context) (int64, error) {
+				_, hasDeadline = ctx.Deadline()
+				return 0, nil
+			},
+		}
+		r := NewRepository(mock, nil)
+
+		if _, err := r.Count(context.Background()); err != nil {
+			t.Fatalf("Count() unexpected error: %v", err)
+		}
+		if hasDeadline {
+			t.Error("query context has a deadline with QueryTimeout unset")
+		}
+	})
+
+	t.Run("timeouts count against the breaker", func(t *testing.T) {
+		calls := 0
+		mock := &mockQueries{
+			countLinksFunc: func(ctx context.Context) (int64, error) {
+				calls++
+				<-ctx.Done()
+				return 0, ctx.Err()
+			},
+		}
+		r := NewRepository(mock, &RepositoryConfig{
+			QueryTimeout:     time.Millisecond,
+			BreakerThreshold: 1,
+			BreakerCooldown:  time.Minute,
+		})
+
+		r.Count(context.Background())
+		_, err := r.Count(context.Background())
+		if errx.KindOf(err) != errx.Unavailable || calls != 1 {
+			t.Errorf("KindOf(err)=%v calls=%d, want Unavailable after one call", errx.KindOf(err), calls)
+		}
+	})
+}
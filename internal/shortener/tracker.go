@@ -0,0 +1,223 @@
+package shortener
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	DefaultTrackingBufferSize   = 1024
+	DefaultTrackingWorkers      = 4
+	DefaultTrackingBlockTimeout = 50 * time.Millisecond
+)
+
+// TrackingOverflowPolicy decides what ClickTracker.Track does when its
+// buffer is full.
+type TrackingOverflowPolicy int
+
+const (
+	// TrackingOverflowBlock holds the redirect until a buffer slot frees
+	// up, for at most the block timeout, then drops the click.
+	TrackingOverflowBlock TrackingOverflowPolicy = iota
+	// TrackingOverflowDrop skips tracking the click.
+	TrackingOverflowDrop
+	// TrackingOverflowSync tracks the click inline, as if the tracker
+	// weren't there.
+	TrackingOverflowSync
+)
+
+// ParseTrackingOverflowPolicy maps a configuration name to a
+// TrackingOverflowPolicy.
+func ParseTrackingOverflowPolicy(name string) (TrackingOverflowPolicy, error) {
+	switch name {
+	case "", "block":
+		return TrackingOverflowBlock, nil
+	case "drop":
+		return TrackingOverflowDrop, nil
+	case "sync-fallback":
+		return TrackingOverflowSync, nil
+	default:
+		return 0, fmt.Errorf("unknown tracking overflow policy %q", name)
+	}
+}
+
+// ClickTrackerConfig holds configuration for the click tracker.
+type ClickTrackerConfig struct {
+	BufferSize int // Clicks waiting for a worker (default: DefaultTrackingBufferSize)
+	Workers    int // default: DefaultTrackingWorkers
+
+	// OverflowPolicy decides what happens to clicks arriving while the
+	// buffer is full (default: TrackingOverflowBlock).
+	OverflowPolicy TrackingOverflowPolicy
+	// BlockTimeout bounds the wait under TrackingOverflowBlock
+	// (default: DefaultTrackingBlockTimeout).
+	BlockTimeout time.Duration
+
+	Logger *slog.Logger
+}
+
+// ClickTrackerStats counts clicks that did not go through the buffer.
+type ClickTrackerStats struct {
+	Dropped uint64 `json:"dropped"` // Lost to a full buffer
+	Inline  uint64 `json:"inline"`  // Tracked inline under TrackingOverflowSync
+}
+
+// ClickTracker moves click tracking writes (unique visitors and click
+// events) off the redirect path onto a pool of background workers.
+type ClickTracker struct {
+	queue        chan func()
+	workers      int
+	policy       TrackingOverflowPolicy
+	blockTimeout time.Duration
+	logger       *slog.Logger
+
+	dropped atomic.Uint64
+	inline  atomic.Uint64
+
+	mu      sync.Mutex
+	started bool
+	stop    chan struct{}
+	wg      sync.WaitGroup
+}
+
+// NewClickTracker creates a ClickTracker. Call Start to run its workers;
+// until then tracked clicks only fill the buffer.
+func NewClickTracker(cfg ClickTrackerConfig) *ClickTracker {
+	bufferSize := cfg.BufferSize
+	if bufferSize <= 0 {
+		bufferSize = DefaultTrackingBufferSize
+	}
+
+	workers := cfg.Workers
+	if workers <= 0 {
+		workers = DefaultTrackingWorkers
+	}
+
+	blockTimeout := cfg.BlockTimeout
+	if blockTimeout <= 0 {
+		blockTimeout = DefaultTrackingBlockTimeout
+	}
+
+	logger := cfg.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &ClickTracker{
+		queue:        make(chan func(), bufferSize),
+		workers:      workers,
+		policy:       cfg.OverflowPolicy,
+		blockTimeout: blockTimeout,
+		logger:       logger,
+		stop:         make(chan struct{}),
+	}
+}
+
+// Start runs the workers until Stop is called. Calling Start twice is a
+// no-op.
+func (t *ClickTracker) Start() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.started {
+		return
+	}
+	t.started = true
+
+	stop := t.stop
+	for range t.workers {
+		t.wg.Add(1)
+		go func() {
+			defer t.wg.Done()
+			for {
+				select {
+				case task := <-t.queue:
+					task()
+				case <-stop:
+					t.drain()
+					return
+				}
+			}
+		}()
+	}
+}
+
+// drain runs the tasks still buffered at shutdown.
+func (t *ClickTracker) drain() {
+	for {
+		select {
+		case task := <-t.queue:
+			task()
+		default:
+			return
+		}
+	}
+}
+
+// Stop tracks the clicks still buffered and waits for the workers to
+// exit. Clicks tracked after Stop stay buffered until the next Start.
+func (t *ClickTracker) Stop() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !t.started {
+		return
+	}
+	t.started = false
+	close(t.stop)
+	t.wg.Wait()
+	t.stop = make(chan struct{})
+}
+
+// Track queues task to run on a worker with a context that carries ctx's
+// values but not its cancellation, so it outlives the request. When the
+// buffer is full the overflow policy applies.
+func (t *ClickTracker) Track(ctx context.Context, task func(ctx context.Context)) {
+	ctx = context.WithoutCancel(ctx)
+	job := func() { task(ctx) }
+
+	select {
+	case t.queue <- job:
+		return
+	default:
+	}
+
+	switch t.policy {
+	case TrackingOverflowSync:
+		t.inline.Add(1)
+		task(ctx)
+	case TrackingOverflowBlock:
+		timer := time.NewTimer(t.blockTimeout)
+		defer timer.Stop()
+		select {
+		case t.queue <- job:
+		case <-timer.C:
+			t.drop(ctx)
+		}
+	default:
+		t.drop(ctx)
+	}
+}
+
+// drop counts a lost click. It logs at each power of two so a sustained
+// overload is visible without a line per click.
+func (t *ClickTracker) drop(ctx context.Context) {
+	n := t.dropped.Add(1)
+	if n&(n-1) == 0 {
+		t.logger.WarnContext(ctx, "click tracking buffer full, dropping clicks",
+			"dropped_total", n,
+		)
+	}
+}
+
+// Stats returns the current counters.
+func (t *ClickTracker) Stats() ClickTrackerStats {
+	return ClickTrackerStats{
+		Dropped: t.dropped.Load(),
+		Inline:  t.inline.Load(),
+	}
+}
@@ -0,0 +1,172 @@
+package shortener
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/sundayezeilo/urlshortener/internal/httpx"
+)
+
+func TestParseTrackingOverflowPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    TrackingOverflowPolicy
+		wantErr bool
+	}{
+		{"", TrackingOverflowBlock, false},
+		{"block", TrackingOverflowBlock, false},
+		{"drop", TrackingOverflowDrop, false},
+		{"sync-fallback", TrackingOverflowSync, false},
+		{"sync", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseTrackingOverflowPolicy(tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseTrackingOverflowPolicy(%q) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseTrackingOverflowPolicy(%q) = %v, want %v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+// fullTracker returns a stopped tracker whose one-slot buffer is taken.
+func fullTracker(policy TrackingOverflowPolicy, blockTimeout time.Duration) *ClickTracker {
+	tr := NewClickTracker(ClickTrackerConfig{
+		BufferSize:     1,
+		Workers:        1,
+		OverflowPolicy: policy,
+		BlockTimeout:   blockTimeout,
+		Logger:         slog.New(slog.NewTextHandler(io.Discard, nil)),
+	})
+	tr.Track(context.Background(), func(context.Context) {})
+	return tr
+}
+
+func TestClickTracker_Overflow(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("drop skips the click", func(t *testing.T) {
+		tr := fullTracker(TrackingOverflowDrop, 0)
+
+		var ran atomic.Bool
+		tr.Track(ctx, func(context.Context) { ran.Store(true) })
+		tr.Start()
+		tr.Stop()
+
+		if ran.Load() {
+			t.Error("dropped click was tracked")
+		}
+		if got := tr.Stats(); got != (ClickTrackerStats{Dropped: 1}) {
+			t.Errorf("Stats() = %+v, want one drop", got)
+		}
+	})
+
+	t.Run("sync-fallback tracks inline", func(t *testing.T) {
+		tr := fullTracker(TrackingOverflowSync, 0)
+
+		var ran bool
+		tr.Track(ctx, func(context.Context) { ran = true })
+
+		if !ran {
+			t.Error("click not tracked before Track returned")
+		}
+		if got := tr.Stats(); got != (ClickTrackerStats{Inline: 1}) {
+			t.Errorf("Stats() = %+v, want one inline click", got)
+		}
+	})
+
+	t.Run("block waits for the timeout, then drops", func(t *testing.T) {
+		tr := fullTracker(TrackingOverflowBlock, 20*time.Millisecond)
+
+		start := time.Now()
+		tr.Track(ctx, func(context.Context) { t.Error("dropped click was tracked") })
+		if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+			t.Errorf("Track returned after %v, want it to block for the timeout", elapsed)
+		}
+		if got := tr.Stats(); got != (ClickTrackerStats{Dropped: 1}) {
+			t.Errorf("Stats() = %+v, want one drop", got)
+		}
+	})
+
+	t.Run("block queues once a slot frees", func(t *testing.T) {
+		tr := fullTracker(TrackingOverflowBlock, 5*time.Second)
+
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			tr.Start()
+		}()
+		var ran atomic.Bool
+		tr.Track(ctx, func(context.Context) { ran.Store(true) })
+		tr.Stop()
+
+		if !ran.Load() {
+			t.Error("blocked click was not tracked")
+		}
+		if got := tr.Stats(); got != (ClickTrackerStats{}) {
+			t.Errorf("Stats() = %+v, want no drops", got)
+		}
+	})
+}
+
+func TestClickTracker_StopDrainsBuffer(t *testing.T) {
+	tr := NewClickTracker(ClickTrackerConfig{BufferSize: 10})
+
+	var tracked atomic.Int32
+	for range 10 {
+		tr.Track(context.Background(), func(context.Context) { tracked.Add(1) })
+	}
+	tr.Start()
+	tr.Stop()
+
+	if got := tracked.Load(); got != 10 {
+		t.Errorf("tracked %d clicks, want 10", got)
+	}
+}
+
+func TestServiceResolve_ClickTracker(t *testing.T) {
+	var clicks []ClickEvent
+	repo := &mockRepository{
+		resolveAndTrackFunc: func(ctx context.Context, slug string) (Link, error) {
+			return Link{ID: uuid.New(), Slug: slug, OriginalURL: "https://example.com"}, nil
+		},
+		recordClickFunc: func(ctx context.Context, click ClickEvent) error {
+			if ctx.Err() != nil {
+				t.Errorf("click recorded with a done context: %v", ctx.Err())
+			}
+			clicks = append(clicks, click)
+			return nil
+		},
+	}
+	tr := NewClickTracker(ClickTrackerConfig{Workers: 1})
+	svc := NewService(repo, &ServiceConfig{
+		RecordClicks:          true,
+		RecordClickRequestIDs: true,
+		ClickTracker:          tr,
+	})
+
+	ctx, cancel := context.WithCancel(httpx.WithRequestID(context.Background(), "req-42"))
+	if _, err := svc.Resolve(ctx, "abc1234"); err != nil {
+		t.Fatalf("Resolve() unexpected error: %v", err)
+	}
+	cancel() // The request is over before the click is written
+
+	if len(clicks) != 0 {
+		t.Fatalf("click recorded inline: %+v", clicks)
+	}
+	tr.Start()
+	tr.Stop()
+
+	if len(clicks) != 1 || clicks[0].RequestID != "req-42" {
+		t.Errorf("clicks = %+v, want one for req-42", clicks)
+	}
+}
@@ -0,0 +1,40 @@
+package shortener
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
+
+// Visitor identifies the client resolving a link.
+type Visitor struct {
+	IP        string
+	UserAgent string
+}
+
+type visitorContextKey struct{}
+
+// WithVisitor attaches the resolving client to ctx so the service can count
+// unique visitors without widening the Resolve signature.
+func WithVisitor(ctx context.Context, v Visitor) context.Context {
+	return context.WithValue(ctx, visitorContextKey{}, v)
+}
+
+// visitorFrom returns the visitor attached to ctx, if any.
+func visitorFrom(ctx context.Context) (Visitor, bool) {
+	v, ok := ctx.Value(visitorContextKey{}).(Visitor)
+	return v, ok
+}
+
+// fingerprint hashes the visitor together with the UTC day of at, so a
+// visitor counts once per link per day and raw identifiers are never stored.
+func (v Visitor) fingerprint(at time.Time) string {
+	h := sha256.New()
+	h.Write([]byte(v.IP))
+	h.Write([]byte{0})
+	h.Write([]byte(v.UserAgent))
+	h.Write([]byte{0})
+	h.Write([]byte(at.UTC().Format(time.DateOnly)))
+	return hex.EncodeToString(h.Sum(nil))
+}
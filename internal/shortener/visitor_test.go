@@ -0,0 +1,37 @@
+package shortener
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestVisitorFingerprint(t *testing.T) {
+	day := time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+	v := Visitor{IP: "203.0.113.7", UserAgent: "Firefox"}
+
+	if v.fingerprint(day) != v.fingerprint(day.Add(10*time.Hour)) {
+		t.Error("fingerprint changed within the same UTC day")
+	}
+	if v.fingerprint(day) == v.fingerprint(day.Add(24*time.Hour)) {
+		t.Error("fingerprint did not change on the next day")
+	}
+	if v.fingerprint(day) == (Visitor{IP: v.IP, UserAgent: "Safari"}).fingerprint(day) {
+		t.Error("fingerprint ignores user agent")
+	}
+	if v.fingerprint(day) == (Visitor{IP: "198.51.100.2", UserAgent: v.UserAgent}).fingerprint(day) {
+		t.Error("fingerprint ignores IP")
+	}
+}
+
+func TestWithVisitor(t *testing.T) {
+	if _, ok := visitorFrom(context.Background()); ok {
+		t.Error("visitorFrom() found a visitor on an empty context")
+	}
+
+	want := Visitor{IP: "203.0.113.7", UserAgent: "Firefox"}
+	got, ok := visitorFrom(WithVisitor(context.Background(), want))
+	if !ok || got != want {
+		t.Errorf("visitorFrom() = %+v, %v; want %+v, true", got, ok, want)
+	}
+}
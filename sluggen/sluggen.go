@@ -5,10 +5,17 @@ package sluggen
 import (
 	"crypto/rand"
 	"errors"
+	"fmt"
 )
 
 const (
 	base62Chars = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+	// base32Chars is the RFC 4648 alphabet in lowercase, so slugs survive
+	// case-folding.
+	base32Chars = "abcdefghijklmnopqrstuvwxyz234567"
+	// base58Chars is the Bitcoin alphabet: base62 without 0, O, I and l,
+	// which are easily confused when read aloud or retyped.
+	base58Chars = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
 )
 
 // Generator generates URL slugs.
@@ -17,29 +24,93 @@ type Generator interface {
 	Generate(length int) (string, error)
 }
 
-// base62Generator implements Generator using base62 encoding.
+// alphabetGenerator implements Generator by drawing characters uniformly
+// from an alphabet of at most 256 characters.
 // It is safe for concurrent use.
-type base62Generator struct{}
+type alphabetGenerator struct {
+	alphabet string
+}
 
 // NewBase62 returns a new base62 slug generator.
 func NewBase62() Generator {
-	return &base62Generator{}
+	return &alphabetGenerator{alphabet: base62Chars}
+}
+
+// NewBase32 returns a slug generator using lowercase letters and the digits
+// 2-7, for deployments that treat slugs case-insensitively. Base32 slugs
+// need more characters than base62 for the same collision resistance.
+func NewBase32() Generator {
+	return &alphabetGenerator{alphabet: base32Chars}
 }
 
-// Generate generates a random base62 string of the specified length.
-func (g *base62Generator) Generate(length int) (string, error) {
+// NewBase58 returns a slug generator that avoids the look-alike characters
+// 0, O, I and l.
+func NewBase58() Generator {
+	return &alphabetGenerator{alphabet: base58Chars}
+}
+
+// New returns the generator for an encoding name: "base62" (also the
+// default for ""), "base32" or "base58".
+func New(encoding string) (Generator, error) {
+	switch encoding {
+	case "", "base62":
+		return NewBase62(), nil
+	case "base32":
+		return NewBase32(), nil
+	case "base58":
+		return NewBase58(), nil
+	default:
+		return nil, fmt.Errorf("unknown slug encoding %q", encoding)
+	}
+}
+
+// AlphabetSize returns how many characters the generator for an encoding
+// name draws from, for estimating how many distinct slugs it can produce.
+func AlphabetSize(encoding string) (int, error) {
+	switch encoding {
+	case "", "base62":
+		return len(base62Chars), nil
+	case "base32":
+		return len(base32Chars), nil
+	case "base58":
+		return len(base58Chars), nil
+	default:
+		return 0, fmt.Errorf("unknown slug encoding %q", encoding)
+	}
+}
+
+// Generate generates a random string of the specified length.
+func (g *alphabetGenerator) Generate(length int) (string, error) {
 	if length <= 0 {
 		return "", errors.New("length must be positive")
 	}
+	return randomString(g.alphabet, length)
+}
 
-	b := make([]byte, length)
-	if _, err := rand.Read(b); err != nil {
-		return "", err
-	}
+// randomString draws length characters uniformly from alphabet. Random
+// bytes at or above the largest multiple of len(alphabet) are rejected, so
+// the modulo does not favor the first characters of the alphabet.
+func randomString(alphabet string, length int) (string, error) {
+	n := len(alphabet)
+	limit := 256 - 256%n
 
-	for i := range b {
-		b[i] = base62Chars[int(b[i])%len(base62Chars)]
+	out := make([]byte, 0, length)
+	// Over-draw slightly so one read usually suffices despite rejections.
+	buf := make([]byte, length+length/4+1)
+	for len(out) < length {
+		if _, err := rand.Read(buf); err != nil {
+			return "", err
+		}
+		for _, b := range buf {
+			if int(b) >= limit {
+				continue
+			}
+			out = append(out, alphabet[int(b)%n])
+			if len(out) == length {
+				break
+			}
+		}
 	}
 
-	return string(b), nil
+	return string(out), nil
 }
@@ -208,6 +208,90 @@ func TestBase62Chars(t *testing.T) {
 	}
 }
 
+func TestEncoders(t *testing.T) {
+	tests := []struct {
+		name     string
+		gen      Generator
+		alphabet string
+		size     int
+	}{
+		{"base62", NewBase62(), base62Chars, 62},
+		{"base32", NewBase32(), base32Chars, 32},
+		{"base58", NewBase58(), base58Chars, 58},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if len(tt.alphabet) != tt.size {
+				t.Fatalf("alphabet length = %d, want %d", len(tt.alphabet), tt.size)
+			}
+
+			for _, length := range []int{1, 7, 64} {
+				slug, err := tt.gen.Generate(length)
+				if err != nil {
+					t.Fatalf("Generate(%d) unexpected error: %v", length, err)
+				}
+				if len(slug) != length {
+					t.Errorf("Generate(%d) returned length %d", length, len(slug))
+				}
+				for i, char := range slug {
+					if !strings.ContainsRune(tt.alphabet, char) {
+						t.Errorf("Generate(%d) produced %c at position %d, outside the %s alphabet", length, char, i, tt.name)
+					}
+				}
+			}
+
+			// A long sample should draw on the whole alphabet.
+			slug, err := tt.gen.Generate(10000)
+			if err != nil {
+				t.Fatalf("Generate(10000) unexpected error: %v", err)
+			}
+			for _, char := range tt.alphabet {
+				if !strings.ContainsRune(slug, char) {
+					t.Errorf("character %c never generated", char)
+				}
+			}
+		})
+	}
+}
+
+func TestBase58Chars_ExcludesLookalikes(t *testing.T) {
+	if strings.ContainsAny(base58Chars, "0OIl") {
+		t.Errorf("base58Chars = %q contains a look-alike character", base58Chars)
+	}
+}
+
+func TestBase32Chars_IsLowercase(t *testing.T) {
+	if base32Chars != strings.ToLower(base32Chars) {
+		t.Errorf("base32Chars = %q, want lowercase only", base32Chars)
+	}
+}
+
+func TestNew(t *testing.T) {
+	for _, encoding := range []string{"", "base62", "base32", "base58"} {
+		if gen, err := New(encoding); err != nil || gen == nil {
+			t.Errorf("New(%q) = %v, %v; want a generator", encoding, gen, err)
+		}
+	}
+
+	if _, err := New("base64"); err == nil {
+		t.Error("New(\"base64\") expected error, got nil")
+	}
+}
+
+func TestAlphabetSize(t *testing.T) {
+	tests := map[string]int{"": 62, "base62": 62, "base32": 32, "base58": 58}
+	for encoding, want := range tests {
+		if got, err := AlphabetSize(encoding); err != nil || got != want {
+			t.Errorf("AlphabetSize(%q) = %d, %v; want %d", encoding, got, err, want)
+		}
+	}
+
+	if _, err := AlphabetSize("base64"); err == nil {
+		t.Error("AlphabetSize(\"base64\") expected error, got nil")
+	}
+}
+
 // Benchmark tests
 func BenchmarkBase62Generator_Generate(b *testing.B) {
 	gen := NewBase62()
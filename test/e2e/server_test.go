@@ -17,9 +17,11 @@ import (
 	"github.com/testcontainers/testcontainers-go/modules/postgres"
 	"github.com/testcontainers/testcontainers-go/wait"
 
+	"github.com/sundayezeilo/urlshortener/db/migrations"
 	"github.com/sundayezeilo/urlshortener/internal/config"
 	db "github.com/sundayezeilo/urlshortener/internal/db/sqlc"
 	"github.com/sundayezeilo/urlshortener/internal/httpx"
+	"github.com/sundayezeilo/urlshortener/internal/migrate"
 	"github.com/sundayezeilo/urlshortener/internal/server"
 	"github.com/sundayezeilo/urlshortener/internal/shortener"
 )
@@ -483,8 +485,6 @@ func TestConcurrentLinkCreation_E2E(t *testing.T) {
 // Helper functions
 
 func runMigrations(connStr string) error {
-	// This is a simplified migration runner for tests
-	// In production, you'd use golang-migrate or similar
 	ctx := context.Background()
 	pool, err := pgxpool.New(ctx, connStr)
 	if err != nil {
@@ -492,41 +492,20 @@ func runMigrations(connStr string) error {
 	}
 	defer pool.Close()
 
-	// Read and execute migration
-	migrationSQL := `
-			CREATE TABLE links (
-		    id               UUID PRIMARY KEY,
-		    original_url     TEXT NOT NULL,
-		    slug             TEXT NOT NULL,
-		    access_count     BIGINT NOT NULL DEFAULT 0,
-		    created_at       TIMESTAMPTZ NOT NULL DEFAULT now(),
-		    updated_at       TIMESTAMPTZ NOT NULL DEFAULT now(),
-		    last_accessed_at TIMESTAMPTZ,
-
-		    CONSTRAINT links_slug_unique UNIQUE (slug),
-		    CONSTRAINT links_slug_length CHECK (char_length(slug) BETWEEN 7 AND 64)
-		);
-
-		CREATE OR REPLACE FUNCTION set_updated_at()
-		RETURNS trigger AS $$
-		BEGIN
-			IF (NEW IS DISTINCT FROM OLD) THEN
-				NEW.updated_at = now();
-			END IF;
-			RETURN NEW;
-		END;
-		$$ LANGUAGE plpgsql;
-
-		DROP TRIGGER IF EXISTS links_set_updated_at ON links;
-
-		CREATE TRIGGER links_set_updated_at
-		BEFORE UPDATE ON links
-		FOR EACH ROW
-		EXECUTE FUNCTION set_updated_at();
-	`
-
-	_, err = pool.Exec(ctx, migrationSQL)
-	return err
+	logger := setupTestLogger()
+	if _, err := migrate.Up(ctx, pool, migrations.FS, logger); err != nil {
+		return err
+	}
+
+	// A second run must find nothing left to apply.
+	applied, err := migrate.Up(ctx, pool, migrations.FS, logger)
+	if err != nil {
+		return err
+	}
+	if applied != 0 {
+		return fmt.Errorf("re-running migrations applied %d, want 0", applied)
+	}
+	return nil
 }
 
 func setupTestLogger() *slog.Logger {